@@ -0,0 +1,139 @@
+package gocomposer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	inlineAliasRegex   = regexp.MustCompile(`(?:^|\| *|, *)([^,\s#|]+)(?:#[^ ]+)? +as +([^,\s|]+)(?:$| *\|| *,)`)
+	prettyAliasRegex   = regexp.MustCompile(`(\.9{7})+`)
+	versionPrefixRegex = regexp.MustCompile(`^v`)
+)
+
+// BranchAliases returns extra.branch-alias, see Extra.BranchAliases.
+func (c ComposerJSON) BranchAliases() map[string]string {
+	return c.Extra.BranchAliases()
+}
+
+// BranchAliases returns extra.branch-alias, see Extra.BranchAliases.
+func (p InlinePackage) BranchAliases() map[string]string {
+	return p.Extra.BranchAliases()
+}
+
+// BranchAliases returns extra.branch-alias, see Extra.BranchAliases.
+func (p LockedPackage) BranchAliases() map[string]string {
+	return p.Extra.BranchAliases()
+}
+
+// BranchAlias returns the version a dev branch is aliased to, like Composer's
+// ArrayLoader::getBranchAlias. A branch alias must alias the branch itself to a
+// numeric -dev version, e.g. "dev-main": "2.x-dev", and a numeric branch can only be
+// aliased to a version within it, e.g. "2.x-dev": "2.1.x-dev". Without a branch alias,
+// the default branch is aliased to DefaultBranchAlias unless it is a numeric branch.
+// It returns false if version is not a dev branch or is not aliased.
+func BranchAlias(version string, branchAliases map[string]string, defaultBranch bool) (Version, bool) {
+	if !strings.HasPrefix(version, "dev-") && !strings.HasSuffix(version, "-dev") {
+		return Version{}, false
+	}
+
+	for _, source := range sortedKeys(branchAliases) {
+		target := branchAliases[source]
+
+		// Ensure it is an alias to a -dev package.
+		if !strings.HasSuffix(target, "-dev") {
+			continue
+		}
+
+		// Normalize without -dev and ensure it's a numeric branch that is parseable.
+		validated := DefaultBranchAlias
+		if target != DefaultBranchAlias {
+			validated = NormalizeBranch(strings.TrimSuffix(target, "-dev"))
+		}
+		if !strings.HasSuffix(validated, "-dev") {
+			continue
+		}
+
+		// Ensure that it is the current branch aliasing itself.
+		if !strings.EqualFold(version, source) {
+			continue
+		}
+
+		// If using numeric aliases ensure the alias is a valid subversion.
+		sourcePrefix := ParseNumericAliasPrefix(source)
+		targetPrefix := ParseNumericAliasPrefix(target)
+		if sourcePrefix != "" && targetPrefix != "" && !strings.HasPrefix(strings.ToLower(targetPrefix), strings.ToLower(sourcePrefix)) {
+			continue
+		}
+		return aliasVersion(validated), true
+	}
+
+	if defaultBranch && ParseNumericAliasPrefix(versionPrefixRegex.ReplaceAllString(version, "")) == "" {
+		return aliasVersion(DefaultBranchAlias), true
+	}
+	return Version{}, false
+}
+
+// aliasVersion returns the version of a normalized branch alias, which is written with
+// .x in place of the 9999999 parts, e.g. "2.1.x-dev".
+func aliasVersion(normalized string) Version {
+	return Version{Pretty: prettyAliasRegex.ReplaceAllString(normalized, ".x"), Normalized: normalized}
+}
+
+// BranchAlias returns the version the package is aliased to if it is a dev branch with
+// a branch alias or the default branch, see BranchAlias.
+func (p InlinePackage) BranchAlias() (Version, bool) {
+	return BranchAlias(p.Version, p.BranchAliases(), p.DefaultBranch)
+}
+
+// BranchAlias returns the version the package is aliased to if it is a dev branch with
+// a branch alias or the default branch, see BranchAlias.
+func (p LockedPackage) BranchAlias() (Version, bool) {
+	return BranchAlias(p.Version, p.BranchAliases(), p.DefaultBranch)
+}
+
+// ParseInlineAlias parses the inline alias of a requirement such as "dev-main as
+// 1.0.x-dev", which makes the required version of the package also count as the alias
+// version. It returns false if the constraint has no alias.
+func ParseInlineAlias(name, constraint string) (LockAlias, bool, error) {
+	m := inlineAliasRegex.FindStringSubmatch(constraint)
+	if m == nil {
+		if strings.Contains(constraint, " as ") {
+			return LockAlias{}, false, fmt.Errorf(`invalid alias definition in "%s": "%s". Aliases should be in the form "exact-version as other-exact-version"`, name, constraint)
+		}
+		return LockAlias{}, false, nil
+	}
+	version, err := NormalizeVersion(m[1])
+	if err != nil {
+		return LockAlias{}, false, fmt.Errorf("alias in %s: %w", name, err)
+	}
+	alias, err := NormalizeVersion(m[2])
+	if err != nil {
+		return LockAlias{}, false, fmt.Errorf("alias in %s: %w", name, err)
+	}
+	return LockAlias{
+		Package:         strings.ToLower(name),
+		Version:         version,
+		Alias:           m[2],
+		AliasNormalized: alias,
+	}, true, nil
+}
+
+// InlineAliases returns the inline aliases of the require and require-dev sections,
+// like Composer records them in the aliases of the lock file.
+func (c ComposerJSON) InlineAliases() ([]LockAlias, error) {
+	aliases := make([]LockAlias, 0)
+	for _, requires := range []map[string]string{c.Require, c.RequireDev} {
+		for _, name := range sortedKeys(requires) {
+			alias, ok, err := ParseInlineAlias(name, requires[name])
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				aliases = append(aliases, alias)
+			}
+		}
+	}
+	return aliases, nil
+}
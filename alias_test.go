@@ -0,0 +1,191 @@
+package gocomposer
+
+import (
+	is2 "github.com/matryer/is"
+	"testing"
+)
+
+func TestBranchAlias(t *testing.T) {
+	tests := []struct {
+		name          string
+		version       string
+		branchAliases map[string]string
+		defaultBranch bool
+		expected      Version
+		ok            bool
+	}{
+		{
+			name:          `NamedBranch`,
+			version:       "dev-main",
+			branchAliases: map[string]string{"dev-main": "2.x-dev"},
+			expected:      Version{Pretty: "2.x-dev", Normalized: "2.9999999.9999999.9999999-dev"},
+			ok:            true,
+		},
+		{
+			name:          `CaseInsensitive`,
+			version:       "dev-Main",
+			branchAliases: map[string]string{"dev-main": "2.1.x-dev"},
+			expected:      Version{Pretty: "2.1.x-dev", Normalized: "2.1.9999999.9999999-dev"},
+			ok:            true,
+		},
+		{
+			name:          `NumericBranch`,
+			version:       "2.x-dev",
+			branchAliases: map[string]string{"2.x-dev": "2.1.x-dev"},
+			expected:      Version{Pretty: "2.1.x-dev", Normalized: "2.1.9999999.9999999-dev"},
+			ok:            true,
+		},
+		{
+			name:          `NumericBranchOutside`,
+			version:       "2.x-dev",
+			branchAliases: map[string]string{"2.x-dev": "3.0.x-dev"},
+		},
+		{
+			name:          `OtherBranch`,
+			version:       "dev-feature",
+			branchAliases: map[string]string{"dev-main": "2.x-dev"},
+		},
+		{
+			name:          `NotDev`,
+			version:       "dev-main",
+			branchAliases: map[string]string{"dev-main": "2.0.0"},
+		},
+		{
+			name:          `NotNumeric`,
+			version:       "dev-main",
+			branchAliases: map[string]string{"dev-main": "next-dev"},
+		},
+		{
+			name:          `Tag`,
+			version:       "2.0.0",
+			branchAliases: map[string]string{"2.0.0": "2.x-dev"},
+			defaultBranch: true,
+		},
+		{
+			name:          `DefaultBranch`,
+			version:       "dev-main",
+			defaultBranch: true,
+			expected:      Version{Pretty: "9999999-dev", Normalized: "9999999-dev"},
+			ok:            true,
+		},
+		{
+			name:          `NumericDefaultBranch`,
+			version:       "v2.x-dev",
+			defaultBranch: true,
+		},
+		{
+			name:          `BranchAliasOverDefaultBranch`,
+			version:       "dev-main",
+			branchAliases: map[string]string{"dev-main": "9999999-dev", "dev-next": "3.x-dev"},
+			defaultBranch: true,
+			expected:      Version{Pretty: "9999999-dev", Normalized: "9999999-dev"},
+			ok:            true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			alias, ok := BranchAlias(test.version, test.branchAliases, test.defaultBranch)
+			is.Equal(ok, test.ok)
+			is.Equal(alias, test.expected)
+		})
+	}
+}
+
+func TestInlinePackage_BranchAlias(t *testing.T) {
+	is := is2.New(t)
+
+	pkg := InlinePackage{
+		Name:    "acme/log",
+		Version: "dev-main",
+		Extra: Extra{
+			{"branch-alias", Extra{{"dev-main", "1.x-dev"}, {"dev-next", false}}},
+		},
+	}
+	is.Equal(pkg.BranchAliases(), map[string]string{"dev-main": "1.x-dev"})
+	alias, ok := pkg.BranchAlias()
+	is.True(ok)
+	is.Equal(alias.Pretty, "1.x-dev")
+
+	lock, err := LoadLock("testdata/composer.lock")
+	is.NoErr(err)
+	instantiator, ok := lock.GetPackage("doctrine/instantiator")
+	is.True(ok)
+	alias, ok = instantiator.BranchAlias()
+	is.True(ok)
+	is.Equal(alias.Normalized, DefaultBranchAlias)
+
+	is.Equal(ComposerJSON{}.BranchAliases(), nil)
+}
+
+func TestParseInlineAlias(t *testing.T) {
+	tests := []struct {
+		name       string
+		constraint string
+		expected   LockAlias
+		ok         bool
+		err        string
+	}{
+		{
+			name:       `Branch`,
+			constraint: "dev-main as 1.0.x-dev",
+			expected:   LockAlias{Package: "acme/log", Version: "dev-main", Alias: "1.0.x-dev", AliasNormalized: "1.0.9999999.9999999-dev"},
+			ok:         true,
+		},
+		{
+			name:       `Reference`,
+			constraint: "dev-main#a1b2c3 as 1.2.0",
+			expected:   LockAlias{Package: "acme/log", Version: "dev-main", Alias: "1.2.0", AliasNormalized: "1.2.0.0"},
+			ok:         true,
+		},
+		{
+			name:       `OrConstraint`,
+			constraint: "^2.0 || 1.x-dev as 1.5.0",
+			expected:   LockAlias{Package: "acme/log", Version: "1.9999999.9999999.9999999-dev", Alias: "1.5.0", AliasNormalized: "1.5.0.0"},
+			ok:         true,
+		},
+		{
+			name:       `NoAlias`,
+			constraint: "^1.0",
+		},
+		{
+			name:       `Invalid`,
+			constraint: "^1.0 as 1.5.0 as 2.0",
+			err:        `invalid alias definition in "Acme/Log": "^1.0 as 1.5.0 as 2.0". Aliases should be in the form "exact-version as other-exact-version"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			alias, ok, err := ParseInlineAlias("Acme/Log", test.constraint)
+			if test.err != "" {
+				is.Equal(err.Error(), test.err)
+				return
+			}
+			is.NoErr(err)
+			is.Equal(ok, test.ok)
+			if test.ok {
+				is.Equal(alias, test.expected)
+			}
+		})
+	}
+}
+
+func TestComposerJSON_InlineAliases(t *testing.T) {
+	is := is2.New(t)
+
+	c := ComposerJSON{
+		Require:    map[string]string{"acme/log": "dev-main as 1.0.x-dev", "acme/http": "^2.0"},
+		RequireDev: map[string]string{"acme/test": "dev-fix as 3.1.0"},
+	}
+	aliases, err := c.InlineAliases()
+	is.NoErr(err)
+	is.Equal(aliases, []LockAlias{
+		{Package: "acme/log", Version: "dev-main", Alias: "1.0.x-dev", AliasNormalized: "1.0.9999999.9999999-dev"},
+		{Package: "acme/test", Version: "dev-fix", Alias: "3.1.0", AliasNormalized: "3.1.0.0"},
+	})
+}
@@ -0,0 +1,376 @@
+package gocomposer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	// AuditStatusOK is the status of an audit that found nothing to report.
+	AuditStatusOK = 0
+
+	// AuditStatusVulnerable is set in the status of an audit that found packages
+	// affected by a security advisory.
+	AuditStatusVulnerable = 1
+
+	// AuditStatusAbandoned is set in the status of an audit that found abandoned
+	// packages while abandoned packages make the audit fail.
+	AuditStatusAbandoned = 2
+)
+
+// SecurityAdvisory is a security advisory in the format of the security advisories API
+// of Packagist.
+type SecurityAdvisory struct {
+	// ID of the advisory on the repository, e.g. "PKSA-1234-abcd".
+	AdvisoryID string `json:"advisoryId"`
+
+	// Name of the affected package.
+	PackageName string `json:"packageName"`
+
+	// ID of the advisory in the database it was reported in, e.g. a GHSA ID.
+	RemoteID string `json:"remoteId,omitempty"`
+
+	// Short description of the vulnerability.
+	Title string `json:"title"`
+
+	// URL with the details of the advisory.
+	Link string `json:"link,omitempty"`
+
+	// CVE ID of the vulnerability, e.g. "CVE-2022-24775".
+	CVE string `json:"cve,omitempty"`
+
+	// Version constraint of the affected versions, e.g. ">=1.0,<1.2.3|>=2.0,<2.1.4".
+	AffectedVersions string `json:"affectedVersions"`
+
+	// Name of the database the advisory was reported in, e.g. "GitHub".
+	Source string `json:"source,omitempty"`
+
+	// When the advisory was reported, e.g. "2022-03-21 17:00:00".
+	ReportedAt string `json:"reportedAt,omitempty"`
+
+	// URL of the repository the advisory applies to, e.g. "https://packagist.org".
+	ComposerRepository string `json:"composerRepository,omitempty"`
+
+	// Severity of the vulnerability, e.g. "low", "medium", "high" or "critical". Empty
+	// if it is not known.
+	Severity string `json:"severity,omitempty"`
+
+	// The databases the advisory was reported in.
+	Sources []SecurityAdvisorySource `json:"sources,omitempty"`
+}
+
+// SecurityAdvisorySource is a database a security advisory was reported in.
+type SecurityAdvisorySource struct {
+	// Name of the database, e.g. "GitHub" or "FriendsOfPHP/security-advisories".
+	Name string `json:"name"`
+
+	// ID of the advisory in the database.
+	RemoteID string `json:"remoteId"`
+}
+
+// Affects returns true if a version is one of the affected versions of the advisory.
+// Like Composer, an advisory whose affected versions cannot be parsed affects no
+// version.
+func (a SecurityAdvisory) Affects(version Version) bool {
+	constraint, err := ParseConstraint(a.AffectedVersions)
+	if err != nil {
+		return false
+	}
+	return version.Satisfies(constraint)
+}
+
+// SecurityAdvisories are security advisories by package name.
+type SecurityAdvisories map[string][]SecurityAdvisory
+
+// UnmarshalJSON decodes the empty array PHP writes for an empty object too.
+func (s *SecurityAdvisories) UnmarshalJSON(data []byte) error {
+	*s = SecurityAdvisories{}
+	if isArray(data) {
+		list := make([]SecurityAdvisory, 0)
+		if err := json.Unmarshal(data, &list); err != nil {
+			return err
+		}
+		if len(list) > 0 {
+			return fmt.Errorf("security advisories must be an object by package name")
+		}
+		return nil
+	}
+	return json.Unmarshal(data, (*map[string][]SecurityAdvisory)(s))
+}
+
+// ParseSecurityAdvisories parses a response of the security advisories API, which is an
+// object with the advisories by package name in its advisories key.
+func ParseSecurityAdvisories(data []byte) (SecurityAdvisories, error) {
+	response := struct {
+		Advisories SecurityAdvisories `json:"advisories"`
+	}{}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+	if response.Advisories == nil {
+		return SecurityAdvisories{}, nil
+	}
+	return response.Advisories, nil
+}
+
+// LoadSecurityAdvisories reads a file in the format of the security advisories API,
+// e.g. a saved response, and returns its advisories.
+func LoadSecurityAdvisories(path string) (SecurityAdvisories, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSecurityAdvisories(data)
+}
+
+// GetSecurityAdvisories returns the security advisories of the named packages from the
+// security advisories API of the repository, which is queried the way Composer does.
+// Like Composer, no advisories are returned if the repository does not have the API.
+func (c *RepositoryClient) GetSecurityAdvisories(ctx context.Context, names ...string) (SecurityAdvisories, error) {
+	metadata, err := c.Metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if metadata.SecurityAdvisories == nil || metadata.SecurityAdvisories.APIURL == "" || len(names) == 0 {
+		return SecurityAdvisories{}, nil
+	}
+	apiURL, err := c.resolve(metadata.SecurityAdvisories.APIURL)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	for _, name := range names {
+		form.Add("packages[]", strings.ToLower(name))
+	}
+	data, err := c.post(ctx, apiURL, form)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("%s was not found", apiURL)
+	}
+	advisories, err := ParseSecurityAdvisories(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", apiURL, err)
+	}
+	return advisories, nil
+}
+
+// IgnoredSecurityAdvisory is a security advisory that affects a package but is ignored
+// by the audit.
+type IgnoredSecurityAdvisory struct {
+	SecurityAdvisory
+
+	// Why the advisory is ignored, which is empty if no reason was given.
+	IgnoreReason string `json:"ignoreReason,omitempty"`
+}
+
+// AbandonedPackage is an abandoned package found by an audit.
+type AbandonedPackage struct {
+	// Name of the package.
+	Name string
+
+	// Name of the package that should be used instead. Empty if none is suggested.
+	Replacement string
+}
+
+// AuditReport is the result of an audit.
+type AuditReport struct {
+	// The advisories affecting the audited packages, by package name.
+	Advisories SecurityAdvisories
+
+	// The advisories affecting the audited packages that are ignored, by package name.
+	IgnoredAdvisories map[string][]IgnoredSecurityAdvisory
+
+	// The abandoned packages sorted by name. Empty if abandoned packages are ignored.
+	Abandoned []AbandonedPackage
+
+	// How abandoned packages are treated, one of ignore, report or fail.
+	AbandonedMode string
+}
+
+// Status returns the status of the audit like the exit code of `composer audit`:
+// AuditStatusVulnerable is set if a package is affected by an advisory that is not
+// ignored and AuditStatusAbandoned if a package is abandoned and abandoned packages
+// make the audit fail.
+func (r AuditReport) Status() int {
+	status := AuditStatusOK
+	if len(r.Advisories) > 0 {
+		status |= AuditStatusVulnerable
+	}
+	if len(r.Abandoned) > 0 && r.AbandonedMode == "fail" {
+		status |= AuditStatusAbandoned
+	}
+	return status
+}
+
+// Auditor checks packages against security advisories like `composer audit`.
+type Auditor struct {
+	// The known security advisories by package name.
+	Advisories SecurityAdvisories
+
+	// Advisory IDs, remote IDs, CVE IDs or package names (keys) whose advisories are
+	// ignored, with the reason (values).
+	Ignore AuditIgnore
+
+	// Severities of the advisories that are ignored, e.g. "low".
+	IgnoreSeverity []string
+
+	// How abandoned packages are treated, one of ignore, report or fail. Empty means
+	// DefaultAuditAbandoned.
+	Abandoned string
+}
+
+// NewAuditor creates an Auditor for the advisories that uses the audit options of a
+// config.
+func NewAuditor(advisories SecurityAdvisories, config Config) *Auditor {
+	a := &Auditor{Advisories: advisories, Abandoned: config.GetAuditAbandoned()}
+	if config.Audit != nil {
+		a.Ignore = config.Audit.Ignore
+		a.IgnoreSeverity = config.Audit.IgnoreSeverity
+	}
+	return a
+}
+
+// auditPackage is a package checked by an Auditor.
+type auditPackage struct {
+	name      string
+	version   Version
+	abandoned *StringOrBool
+}
+
+// AuditLock checks the packages of a lock file, including the development packages if
+// dev is true.
+func (a Auditor) AuditLock(lock ComposerLock, dev bool) (*AuditReport, error) {
+	locked := lock.Packages
+	if dev {
+		locked = append(append([]LockedPackage{}, lock.Packages...), lock.PackagesDev...)
+	}
+	packages := make([]auditPackage, 0, len(locked))
+	for _, pkg := range locked {
+		version, err := ParseVersion(pkg.Version)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", pkg.Name, err)
+		}
+		packages = append(packages, auditPackage{name: pkg.Name, version: version, abandoned: pkg.Abandoned})
+	}
+	return a.audit(packages), nil
+}
+
+// AuditInstalledJSON checks the packages of an installed.json file.
+func (a Auditor) AuditInstalledJSON(installed InstalledJSON) (*AuditReport, error) {
+	packages := make([]auditPackage, 0, len(installed.Packages))
+	for _, pkg := range installed.Packages {
+		version := Version{Pretty: pkg.Version, Normalized: pkg.VersionNormalized}
+		if version.Normalized == "" {
+			var err error
+			if version, err = ParseVersion(pkg.Version); err != nil {
+				return nil, fmt.Errorf("%s: %w", pkg.Name, err)
+			}
+		}
+		packages = append(packages, auditPackage{name: pkg.Name, version: version, abandoned: pkg.Abandoned})
+	}
+	return a.audit(packages), nil
+}
+
+// AuditInstalled checks the installed packages of an installed repository. The root
+// package and the packages that are only provided or replaced are not checked.
+// installed.php does not record whether packages are abandoned, so no abandoned
+// packages are reported.
+func (a Auditor) AuditInstalled(repo InstalledRepository) *AuditReport {
+	packages := make([]auditPackage, 0, len(repo.Versions))
+	for _, name := range sortedKeys(repo.Versions) {
+		installed := repo.Versions[name]
+		if installed.Version == "" || (repo.Root.Name != "" && name == repo.Root.Name) {
+			continue
+		}
+		packages = append(packages, auditPackage{
+			name:    name,
+			version: Version{Pretty: installed.PrettyVersion, Normalized: installed.Version},
+		})
+	}
+	return a.audit(packages)
+}
+
+// audit checks packages against the advisories, like Composer's Auditor.
+func (a Auditor) audit(packages []auditPackage) *AuditReport {
+	report := &AuditReport{
+		Advisories:        SecurityAdvisories{},
+		IgnoredAdvisories: make(map[string][]IgnoredSecurityAdvisory),
+		Abandoned:         make([]AbandonedPackage, 0),
+		AbandonedMode:     a.Abandoned,
+	}
+	if report.AbandonedMode == "" {
+		report.AbandonedMode = DefaultAuditAbandoned
+	}
+
+	for _, pkg := range packages {
+		name := strings.ToLower(pkg.name)
+		for _, advisory := range a.advisories(name) {
+			if !advisory.Affects(pkg.version) {
+				continue
+			}
+			if reason, ignored := a.ignored(name, advisory); ignored {
+				report.IgnoredAdvisories[name] = append(report.IgnoredAdvisories[name], IgnoredSecurityAdvisory{
+					SecurityAdvisory: advisory,
+					IgnoreReason:     reason,
+				})
+				continue
+			}
+			report.Advisories[name] = append(report.Advisories[name], advisory)
+		}
+
+		if report.AbandonedMode != "ignore" && pkg.abandoned != nil && pkg.abandoned.Bool() {
+			abandoned := AbandonedPackage{Name: pkg.name}
+			if !pkg.abandoned.IsBool() {
+				abandoned.Replacement = pkg.abandoned.String()
+			}
+			report.Abandoned = append(report.Abandoned, abandoned)
+		}
+	}
+
+	sort.SliceStable(report.Abandoned, func(i, j int) bool {
+		return report.Abandoned[i].Name < report.Abandoned[j].Name
+	})
+	return report
+}
+
+// advisories returns the advisories of a lower case package name.
+func (a Auditor) advisories(name string) []SecurityAdvisory {
+	advisories := make([]SecurityAdvisory, 0)
+	for _, packageName := range sortedKeys(a.Advisories) {
+		if strings.ToLower(packageName) == name {
+			advisories = append(advisories, a.Advisories[packageName]...)
+		}
+	}
+	return advisories
+}
+
+// ignored returns true and the reason if an advisory of a package is ignored, like
+// Composer's Auditor::processAdvisories. The checks are done in the same order, so the
+// reason of the last matching one is returned.
+func (a Auditor) ignored(name string, advisory SecurityAdvisory) (string, bool) {
+	reason, ignored := "", false
+	check := func(key string) {
+		if r, ok := a.Ignore[key]; ok && key != "" {
+			reason, ignored = r, true
+		}
+	}
+
+	check(name)
+	if advisory.Severity != "" && contains(a.IgnoreSeverity, advisory.Severity) {
+		reason, ignored = "Ignored via --ignore-severity="+advisory.Severity, true
+	}
+	check(advisory.CVE)
+	for _, source := range advisory.Sources {
+		check(source.RemoteID)
+	}
+	check(advisory.AdvisoryID)
+	return reason, ignored
+}
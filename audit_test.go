@@ -0,0 +1,286 @@
+package gocomposer
+
+import (
+	"context"
+	is2 "github.com/matryer/is"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+// advisoryIDs returns the advisory IDs of advisories by package name.
+func advisoryIDs(advisories SecurityAdvisories) map[string][]string {
+	ids := make(map[string][]string)
+	for name, list := range advisories {
+		for _, advisory := range list {
+			ids[name] = append(ids[name], advisory.AdvisoryID)
+		}
+	}
+	return ids
+}
+
+func TestSecurityAdvisory_Affects(t *testing.T) {
+	tests := []struct {
+		affectedVersions string
+		version          string
+		expected         bool
+	}{
+		{affectedVersions: "<1.8.4|>=2,<2.1.1", version: "1.8.3", expected: true},
+		{affectedVersions: "<1.8.4|>=2,<2.1.1", version: "1.8.4", expected: false},
+		{affectedVersions: "<1.8.4|>=2,<2.1.1", version: "v2.1.0", expected: true},
+		{affectedVersions: "<1.8.4|>=2,<2.1.1", version: "2.1.1", expected: false},
+		{affectedVersions: ">=2,<2.4.5", version: "2.4.5-beta1", expected: false},
+		{affectedVersions: ">=1.0,<1.4.2", version: "dev-main", expected: false},
+		{affectedVersions: "dev-main", version: "dev-main", expected: true},
+		{affectedVersions: "not a constraint", version: "1.0.0", expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.affectedVersions+" "+test.version, func(t *testing.T) {
+			is := is2.New(t)
+
+			advisory := SecurityAdvisory{AffectedVersions: test.affectedVersions}
+			is.Equal(advisory.Affects(MustParseVersion(test.version)), test.expected)
+		})
+	}
+}
+
+func TestParseSecurityAdvisories(t *testing.T) {
+	is := is2.New(t)
+
+	advisories, err := LoadSecurityAdvisories("testdata/audit/advisories.json")
+	is.NoErr(err)
+	is.Equal(advisoryIDs(advisories), map[string][]string{
+		"guzzlehttp/psr7": {"PKSA-n3sd-2mzk-ny8d", "PKSA-3gt4-2tqq-wbwn"},
+		"acme/http":       {"PKSA-acme-0001", "PKSA-acme-0002"},
+		"acme/log":        {"PKSA-acme-0003"},
+	})
+	is.Equal(advisories["guzzlehttp/psr7"][0].Sources[1], SecurityAdvisorySource{
+		Name:     "FriendsOfPHP/security-advisories",
+		RemoteID: "guzzlehttp/psr7/CVE-2022-24775.yaml",
+	})
+	is.Equal(advisories["acme/http"][0].CVE, "")
+	is.Equal(advisories["acme/http"][0].Severity, "")
+
+	advisories, err = ParseSecurityAdvisories([]byte(`{"advisories": []}`))
+	is.NoErr(err)
+	is.Equal(advisories, SecurityAdvisories{})
+
+	advisories, err = ParseSecurityAdvisories([]byte(`{}`))
+	is.NoErr(err)
+	is.Equal(advisories, SecurityAdvisories{})
+
+	_, err = ParseSecurityAdvisories([]byte(`{"advisories": [{"advisoryId": "PKSA-1"}]}`))
+	is.Equal(err.Error(), "security advisories must be an object by package name")
+}
+
+func TestAuditor_AuditLock(t *testing.T) {
+	advisories, err := LoadSecurityAdvisories("testdata/audit/advisories.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock, err := LoadLock("testdata/audit/composer.lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	allAdvisories := map[string][]string{
+		"guzzlehttp/psr7": {"PKSA-n3sd-2mzk-ny8d", "PKSA-3gt4-2tqq-wbwn"},
+		"acme/http":       {"PKSA-acme-0001"},
+	}
+	abandoned := []AbandonedPackage{
+		{Name: "acme/legacy"},
+		{Name: "acme/log", Replacement: "monolog/monolog"},
+	}
+
+	tests := []struct {
+		name              string
+		audit             *AuditConfig
+		dev               bool
+		expected          map[string][]string
+		expectedIgnored   map[string]string
+		expectedAbandoned []AbandonedPackage
+		expectedStatus    int
+	}{
+		{
+			name:              "Default",
+			expected:          allAdvisories,
+			expectedIgnored:   map[string]string{},
+			expectedAbandoned: abandoned,
+			expectedStatus:    AuditStatusVulnerable | AuditStatusAbandoned,
+		},
+		{
+			name:              "Dev",
+			dev:               true,
+			expected:          allAdvisories,
+			expectedIgnored:   map[string]string{},
+			expectedAbandoned: append(append([]AbandonedPackage{}, abandoned...), AbandonedPackage{Name: "acme/tools"}),
+			expectedStatus:    AuditStatusVulnerable | AuditStatusAbandoned,
+		},
+		{
+			name:  "IgnoreCVE",
+			audit: &AuditConfig{Ignore: AuditIgnore{"CVE-2022-24775": "Headers are validated by the proxy"}},
+			expected: map[string][]string{
+				"guzzlehttp/psr7": {"PKSA-3gt4-2tqq-wbwn"},
+				"acme/http":       {"PKSA-acme-0001"},
+			},
+			expectedIgnored:   map[string]string{"PKSA-n3sd-2mzk-ny8d": "Headers are validated by the proxy"},
+			expectedAbandoned: abandoned,
+			expectedStatus:    AuditStatusVulnerable | AuditStatusAbandoned,
+		},
+		{
+			name:  "IgnoreRemoteID",
+			audit: &AuditConfig{Ignore: AuditIgnore{"guzzlehttp/psr7/CVE-2022-24775.yaml": ""}},
+			expected: map[string][]string{
+				"guzzlehttp/psr7": {"PKSA-3gt4-2tqq-wbwn"},
+				"acme/http":       {"PKSA-acme-0001"},
+			},
+			expectedIgnored:   map[string]string{"PKSA-n3sd-2mzk-ny8d": ""},
+			expectedAbandoned: abandoned,
+			expectedStatus:    AuditStatusVulnerable | AuditStatusAbandoned,
+		},
+		{
+			name:     "IgnoreAdvisoryIDAndPackage",
+			audit:    &AuditConfig{Ignore: AuditIgnore{"PKSA-acme-0001": "Not exposed", "guzzlehttp/psr7": "Only used in tests"}, Abandoned: "report"},
+			expected: map[string][]string{},
+			expectedIgnored: map[string]string{
+				"PKSA-n3sd-2mzk-ny8d": "Only used in tests",
+				"PKSA-3gt4-2tqq-wbwn": "Only used in tests",
+				"PKSA-acme-0001":      "Not exposed",
+			},
+			expectedAbandoned: abandoned,
+			expectedStatus:    AuditStatusOK,
+		},
+		{
+			name:  "IgnoreSeverity",
+			audit: &AuditConfig{IgnoreSeverity: []string{"medium", "low"}, Abandoned: "ignore"},
+			expected: map[string][]string{
+				"guzzlehttp/psr7": {"PKSA-n3sd-2mzk-ny8d"},
+				"acme/http":       {"PKSA-acme-0001"},
+			},
+			expectedIgnored:   map[string]string{"PKSA-3gt4-2tqq-wbwn": "Ignored via --ignore-severity=medium"},
+			expectedAbandoned: []AbandonedPackage{},
+			expectedStatus:    AuditStatusVulnerable,
+		},
+		{
+			name:  "IgnoreSeverityReasonIsReplacedByID",
+			audit: &AuditConfig{IgnoreSeverity: []string{"medium"}, Ignore: AuditIgnore{"CVE-2023-29197": "Fixed by a patch"}},
+			expected: map[string][]string{
+				"guzzlehttp/psr7": {"PKSA-n3sd-2mzk-ny8d"},
+				"acme/http":       {"PKSA-acme-0001"},
+			},
+			expectedIgnored:   map[string]string{"PKSA-3gt4-2tqq-wbwn": "Fixed by a patch"},
+			expectedAbandoned: abandoned,
+			expectedStatus:    AuditStatusVulnerable | AuditStatusAbandoned,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			auditor := NewAuditor(advisories, Config{Audit: test.audit})
+			report, err := auditor.AuditLock(*lock, test.dev)
+			is.NoErr(err)
+			is.Equal(advisoryIDs(report.Advisories), test.expected)
+
+			ignored := make(map[string]string)
+			for _, list := range report.IgnoredAdvisories {
+				for _, advisory := range list {
+					ignored[advisory.AdvisoryID] = advisory.IgnoreReason
+				}
+			}
+			is.Equal(ignored, test.expectedIgnored)
+			is.Equal(report.Abandoned, test.expectedAbandoned)
+			is.Equal(report.Status(), test.expectedStatus)
+		})
+	}
+}
+
+func TestAuditor_AuditLock_Errors(t *testing.T) {
+	is := is2.New(t)
+
+	lock := ComposerLock{Packages: []LockedPackage{{Name: "acme/http", Version: "not a version"}}}
+	_, err := (&Auditor{}).AuditLock(lock, false)
+	is.True(err != nil)
+}
+
+func TestAuditor_AuditInstalled(t *testing.T) {
+	is := is2.New(t)
+
+	advisories, err := LoadSecurityAdvisories("testdata/audit/advisories.json")
+	is.NoErr(err)
+	repo := InstalledRepository{
+		Root: InstalledVersion{Name: "acme/app", PrettyVersion: "dev-main", Version: "dev-main"},
+		Versions: map[string]InstalledVersion{
+			"acme/app":                        {Name: "acme/app", PrettyVersion: "dev-main", Version: "dev-main"},
+			"acme/http":                       {Name: "acme/http", PrettyVersion: "dev-main", Version: "dev-main"},
+			"guzzlehttp/psr7":                 {Name: "guzzlehttp/psr7", PrettyVersion: "2.4.5", Version: "2.4.5.0"},
+			"psr/http-message-implementation": {Name: "psr/http-message-implementation", Provided: []string{"1.0"}},
+		},
+	}
+
+	report := NewAuditor(advisories, Config{}).AuditInstalled(repo)
+	is.Equal(advisoryIDs(report.Advisories), map[string][]string{"acme/http": {"PKSA-acme-0002"}})
+	is.Equal(len(report.Abandoned), 0)
+	is.Equal(report.Status(), AuditStatusVulnerable)
+}
+
+func TestAuditor_AuditInstalledJSON(t *testing.T) {
+	is := is2.New(t)
+
+	advisories, err := LoadSecurityAdvisories("testdata/audit/advisories.json")
+	is.NoErr(err)
+	installed, err := ParseInstalledJSON([]byte(`{
+		"packages": [
+			{"name": "Acme/HTTP", "version": "v1.2.0", "version_normalized": "1.2.0.0"},
+			{"name": "acme/log", "version": "1.0.0", "abandoned": "monolog/monolog"}
+		],
+		"dev": true,
+		"dev-package-names": []
+	}`))
+	is.NoErr(err)
+
+	report, err := (&Auditor{Advisories: advisories, Abandoned: "report"}).AuditInstalledJSON(*installed)
+	is.NoErr(err)
+	is.Equal(advisoryIDs(report.Advisories), map[string][]string{"acme/http": {"PKSA-acme-0001"}})
+	is.Equal(report.Abandoned, []AbandonedPackage{{Name: "acme/log", Replacement: "monolog/monolog"}})
+	is.Equal(report.Status(), AuditStatusVulnerable)
+}
+
+func TestRepositoryClient_GetSecurityAdvisories(t *testing.T) {
+	is := is2.New(t)
+
+	advisories, err := os.ReadFile("testdata/audit/advisories.json")
+	is.NoErr(err)
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/packages.json":
+			_, _ = w.Write([]byte(`{"packages": [], "security-advisories": {"metadata": false, "api-url": "/api/security-advisories/"}}`))
+		case "/api/security-advisories/":
+			if r.Method != http.MethodPost || r.ParseForm() != nil {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			requested = r.PostForm["packages[]"]
+			_, _ = w.Write(advisories)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := &RepositoryClient{URL: server.URL}
+	result, err := client.GetSecurityAdvisories(context.Background(), "Acme/HTTP", "guzzlehttp/psr7")
+	is.NoErr(err)
+	is.Equal(requested, []string{"acme/http", "guzzlehttp/psr7"})
+	is.Equal(len(result["guzzlehttp/psr7"]), 2)
+
+	// A repository without the security advisories API has no advisories.
+	repo := newFakeRepository(t, "testdata/repository-lazy")
+	result, err = (&RepositoryClient{URL: repo.URL}).GetSecurityAdvisories(context.Background(), "acme/http")
+	is.NoErr(err)
+	is.Equal(result, SecurityAdvisories{})
+}
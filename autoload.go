@@ -0,0 +1,811 @@
+package gocomposer
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Names of the autoloader files written to the composer directory of the vendor
+// directory.
+const (
+	AutoloadNamespacesFile = "autoload_namespaces.php"
+	AutoloadPSR4File       = "autoload_psr4.php"
+	AutoloadClassMapFile   = "autoload_classmap.php"
+	AutoloadFilesFile      = "autoload_files.php"
+	AutoloadStaticFile     = "autoload_static.php"
+)
+
+// rootPackageName is the name Composer gives a root package without a name.
+const rootPackageName = "__root__"
+
+// AutoloadGenerator generates the autoloader files Composer writes to vendor/composer,
+// as `composer dump-autoload` does for the same root package and installed packages.
+type AutoloadGenerator struct {
+	// Absolute path of the project directory, which holds composer.json. Symbolic
+	// links are not resolved, so it should be the real path.
+	BaseDir string
+
+	// Path of the vendor directory. A relative path is relative to BaseDir. If it is
+	// empty, the vendor-dir option of the root package is used.
+	VendorDir string
+
+	// The root package.
+	Root ComposerJSON
+
+	// The installed packages, e.g. the packages of composer.lock.
+	Packages []LockedPackage
+
+	// The installed development packages, e.g. the packages-dev of composer.lock.
+	PackagesDev []LockedPackage
+
+	// If true, the development packages and the autoload-dev of the root package are
+	// left out like `composer dump-autoload --no-dev`.
+	NoDev bool
+
+	// If true, the classes in the PSR-4 and PSR-0 directories are added to the class
+	// map like `composer dump-autoload --optimize`. The optimize-autoloader and
+	// classmap-authoritative options of the root package do the same.
+	Optimize bool
+
+	// The suffix of the ComposerStaticInit class. If it is empty, the
+	// autoloader-suffix option of the root package is used, otherwise the
+	// content-hash of the root package like Composer does for a locked project.
+	Suffix string
+}
+
+// autoloadPackage is a package with autoload rules and the path it is installed in.
+type autoloadPackage struct {
+	name     string
+	requires []string
+	autoload Autoload
+
+	// The install path, which is empty for the root package.
+	installPath string
+
+	includePath []string
+}
+
+// autoloadFile is an entry of autoload_files.php.
+type autoloadFile struct {
+	identifier string
+	path       string
+}
+
+// autoloads are the autoload rules of every package. Paths are relative to the base
+// directory or absolute.
+type autoloads struct {
+	psr0     map[string][]string
+	psr4     map[string][]string
+	classMap []string
+	files    []autoloadFile
+
+	// Regular expressions of the exclude-from-classmap patterns.
+	exclude []string
+}
+
+// autoloadPath is a path written to the autoloader files, relative to a directory
+// variable.
+type autoloadPath struct {
+	// "$vendorDir", "$baseDir" or empty if path is absolute.
+	dir  string
+	path string
+	phar bool
+}
+
+// autoloadDirs holds the normalized absolute paths of the directories of the
+// autoloader.
+type autoloadDirs struct {
+	base   string
+	vendor string
+}
+
+// Generate returns the contents of the autoloader files by file name. The
+// autoload_files.php file is only returned if a package autoloads files.
+func (g AutoloadGenerator) Generate() (map[string][]byte, error) {
+	dirs, err := g.dirs()
+	if err != nil {
+		return nil, err
+	}
+	targetDir := dirs.vendor + "/composer"
+	vendorPathCode := findShortestPathCode(targetDir, dirs.vendor, true, false)
+	appBaseDirCode := strings.ReplaceAll(findShortestPathCode(dirs.vendor, dirs.base, true, false), "__DIR__", "$vendorDir")
+	header := func(file string) *bytes.Buffer {
+		b := &bytes.Buffer{}
+		fmt.Fprintf(b, "<?php\n\n// %s @generated by Composer\n\n$vendorDir = %s;\n$baseDir = %s;\n\n", file, vendorPathCode, appBaseDirCode)
+		return b
+	}
+
+	loads := g.parseAutoloads(dirs)
+	files := make(map[string][]byte)
+
+	namespaces := header(AutoloadNamespacesFile)
+	namespaces.WriteString("return array(\n")
+	for _, namespace := range reverseSortedKeys(loads.psr0) {
+		fmt.Fprintf(namespaces, "    %s => array(%s),\n", phpKey(namespace), dirs.pathCodes(loads.psr0[namespace]))
+	}
+	namespaces.WriteString(");\n")
+	files[AutoloadNamespacesFile] = namespaces.Bytes()
+
+	psr4 := header(AutoloadPSR4File)
+	psr4.WriteString("return array(\n")
+	for _, namespace := range reverseSortedKeys(loads.psr4) {
+		fmt.Fprintf(psr4, "    %s => array(%s),\n", phpKey(namespace), dirs.pathCodes(loads.psr4[namespace]))
+	}
+	psr4.WriteString(");\n")
+	files[AutoloadPSR4File] = psr4.Bytes()
+
+	scanned, err := g.scanClassMap(dirs, loads)
+	if err != nil {
+		return nil, err
+	}
+	classMap := map[string]string{
+		"Composer\\InstalledVersions": dirs.vendor + "/composer/InstalledVersions.php",
+	}
+	for class, file := range scanned.Classes {
+		if class != "Composer\\InstalledVersions" {
+			classMap[class] = file
+		}
+	}
+	classes := header(AutoloadClassMapFile)
+	classes.WriteString("return array(\n")
+	for _, class := range sortedKeys(classMap) {
+		fmt.Fprintf(classes, "    %s => %s,\n", phpString(class), dirs.pathCode(classMap[class]).code())
+	}
+	classes.WriteString(");\n")
+	files[AutoloadClassMapFile] = classes.Bytes()
+
+	if len(loads.files) > 0 {
+		included := header(AutoloadFilesFile)
+		included.WriteString("return array(\n")
+		for _, file := range loads.files {
+			fmt.Fprintf(included, "    %s => %s,\n", phpKey(file.identifier), dirs.pathCode(file.path).code())
+		}
+		included.WriteString(");\n")
+		files[AutoloadFilesFile] = included.Bytes()
+	}
+
+	suffix, err := g.suffix()
+	if err != nil {
+		return nil, err
+	}
+	files[AutoloadStaticFile] = g.staticFile(dirs, suffix, loads, classMap)
+	return files, nil
+}
+
+// Dump writes the autoloader files to the composer directory of the vendor directory.
+// Files that did not change are not written again and autoload_files.php is removed if
+// no package autoloads files.
+func (g AutoloadGenerator) Dump() error {
+	files, err := g.Generate()
+	if err != nil {
+		return err
+	}
+	dirs, err := g.dirs()
+	if err != nil {
+		return err
+	}
+	targetDir := filepath.FromSlash(dirs.vendor + "/composer")
+	if err := os.MkdirAll(targetDir, 0o777); err != nil {
+		return err
+	}
+
+	for _, name := range sortedKeys(files) {
+		file := filepath.Join(targetDir, name)
+		if current, err := os.ReadFile(file); err == nil && bytes.Equal(current, files[name]) {
+			continue
+		}
+		if err := os.WriteFile(file, files[name], 0o666); err != nil {
+			return err
+		}
+	}
+	if _, ok := files[AutoloadFilesFile]; !ok {
+		err := os.Remove(filepath.Join(targetDir, AutoloadFilesFile))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// dirs returns the base and vendor directories.
+func (g AutoloadGenerator) dirs() (autoloadDirs, error) {
+	base, err := filepath.Abs(g.BaseDir)
+	if err != nil {
+		return autoloadDirs{}, err
+	}
+	vendor := g.VendorDir
+	if vendor == "" {
+		vendor = g.Root.Config.GetVendorDir()
+	}
+	if !filepath.IsAbs(vendor) {
+		vendor = filepath.Join(base, vendor)
+	}
+	return autoloadDirs{
+		base:   normalizePath(filepath.ToSlash(base)),
+		vendor: normalizePath(filepath.ToSlash(vendor)),
+	}, nil
+}
+
+// suffix returns the suffix of the autoloader classes.
+func (g AutoloadGenerator) suffix() (string, error) {
+	if g.Suffix != "" {
+		return g.Suffix, nil
+	}
+	if g.Root.Config.AutoloaderSuffix != "" {
+		return g.Root.Config.AutoloaderSuffix, nil
+	}
+	return g.Root.ContentHash()
+}
+
+// packages returns the root package followed by the installed packages that can be
+// autoloaded.
+func (g AutoloadGenerator) packages(dirs autoloadDirs) []autoloadPackage {
+	root := autoloadPackage{
+		name:        g.Root.Name,
+		requires:    append(sortedKeys(g.Root.Require), sortedKeys(g.Root.RequireDev)...),
+		autoload:    g.Root.Autoload,
+		includePath: g.Root.IncludePath,
+	}
+	if root.name == "" {
+		root.name = rootPackageName
+	}
+	if !g.NoDev {
+		root.autoload = mergeAutoload(root.autoload, Autoload(g.Root.AutoloadDev))
+	}
+
+	packages := []autoloadPackage{root}
+	installed := g.Packages
+	if !g.NoDev {
+		installed = append(append([]LockedPackage{}, installed...), g.PackagesDev...)
+	}
+	for _, pkg := range installed {
+		// Metapackages are not installed, so they cannot autoload anything.
+		if pkg.Type == "metapackage" {
+			continue
+		}
+		loaded := autoloadPackage{
+			name:        pkg.Name,
+			requires:    sortedKeys(pkg.Require),
+			installPath: dirs.vendor + "/" + pkg.Name,
+			includePath: pkg.IncludePath,
+		}
+		if pkg.Autoload != nil {
+			loaded.autoload = *pkg.Autoload
+		}
+		packages = append(packages, loaded)
+	}
+	return packages
+}
+
+// mergeAutoload returns the rules of both a and b like PHP's array_merge_recursive.
+func mergeAutoload(a, b Autoload) Autoload {
+	merged := Autoload{
+		Files:               append(append([]string{}, a.Files...), b.Files...),
+		PSR4:                make(map[string]StringOrSlice),
+		PSR0:                make(map[string]StringOrSlice),
+		ClassMap:            append(append([]string{}, a.ClassMap...), b.ClassMap...),
+		ExcludeFromClassMap: append(append([]string{}, a.ExcludeFromClassMap...), b.ExcludeFromClassMap...),
+	}
+	for _, rules := range []map[string]StringOrSlice{a.PSR4, b.PSR4} {
+		for namespace, paths := range rules {
+			merged.PSR4[namespace] = append(merged.PSR4[namespace], paths...)
+		}
+	}
+	for _, rules := range []map[string]StringOrSlice{a.PSR0, b.PSR0} {
+		for namespace, paths := range rules {
+			merged.PSR0[namespace] = append(merged.PSR0[namespace], paths...)
+		}
+	}
+	return merged
+}
+
+// parseAutoloads collects the autoload rules of every package like Composer's
+// AutoloadGenerator::parseAutoloads. The rules of the root package come first and
+// the rules of dependencies last, so the root package can override them, except for
+// files, which are included with the dependencies first.
+func (g AutoloadGenerator) parseAutoloads(dirs autoloadDirs) autoloads {
+	packages := g.packages(dirs)
+	sorted := append(sortPackages(packages[1:]), packages[0])
+	reversed := make([]autoloadPackage, 0, len(sorted))
+	for i := len(sorted) - 1; i >= 0; i-- {
+		reversed = append(reversed, sorted[i])
+	}
+
+	loads := autoloads{
+		psr0: make(map[string][]string),
+		psr4: make(map[string][]string),
+	}
+	for _, pkg := range reversed {
+		for _, namespace := range sortedKeys(pkg.autoload.PSR0) {
+			for _, p := range pkg.autoload.PSR0[namespace] {
+				loads.psr0[namespace] = append(loads.psr0[namespace], pkg.relativePath(p))
+			}
+		}
+		for _, namespace := range sortedKeys(pkg.autoload.PSR4) {
+			for _, p := range pkg.autoload.PSR4[namespace] {
+				loads.psr4[namespace] = append(loads.psr4[namespace], pkg.relativePath(p))
+			}
+		}
+		for _, p := range pkg.autoload.ClassMap {
+			loads.classMap = append(loads.classMap, pkg.relativePath(p))
+		}
+	}
+
+	index := make(map[string]int)
+	for _, pkg := range sorted {
+		for _, p := range pkg.autoload.ExcludeFromClassMap {
+			installPath := pkg.installPath
+			if installPath == "" {
+				installPath = dirs.base
+			}
+			if pattern := ExcludeFromClassMapPattern(installPath, p); pattern != "" {
+				loads.exclude = append(loads.exclude, pattern)
+			}
+		}
+		for _, p := range pkg.autoload.Files {
+			file := autoloadFile{fileIdentifier(pkg.name, p), pkg.relativePath(p)}
+			if i, ok := index[file.identifier]; ok {
+				loads.files[i] = file
+				continue
+			}
+			index[file.identifier] = len(loads.files)
+			loads.files = append(loads.files, file)
+		}
+	}
+	return loads
+}
+
+// ClassMap scans the classmap paths of the root package and the installed packages for
+// classes, skipping the files that match exclude-from-classmap. When optimizing, the
+// PSR-4 and PSR-0 directories are scanned too. The class map holds the classes that
+// are written to autoload_classmap.php, the warnings Composer shows for ambiguous
+// classes and the PSR violations outside of the vendor directory.
+func (g AutoloadGenerator) ClassMap() (*ClassMap, error) {
+	dirs, err := g.dirs()
+	if err != nil {
+		return nil, err
+	}
+	return g.scanClassMap(dirs, g.parseAutoloads(dirs))
+}
+
+// CheckPSR scans the PSR-4 and PSR-0 directories of the root package and the
+// installed packages and returns the classes that do not comply with the autoloading
+// standard of their rule, as `composer dump-autoload --optimize` warns about. Like
+// Composer, violations in the vendor directory are not reported.
+func (g AutoloadGenerator) CheckPSR() ([]PSRViolation, error) {
+	g.Optimize = true
+	classMap, err := g.ClassMap()
+	if err != nil {
+		return nil, err
+	}
+	return classMap.PSRViolations, nil
+}
+
+// scanClassMap scans the classmap paths of the autoload rules, and the PSR-4 and PSR-0
+// directories when optimizing.
+func (g AutoloadGenerator) scanClassMap(dirs autoloadDirs, loads autoloads) (*ClassMap, error) {
+	excluded, err := compileExclusions(loads.exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	classMap := NewClassMap()
+	for _, p := range loads.classMap {
+		if !path.IsAbs(p) {
+			p = dirs.base + "/" + p
+		}
+		if err := classMap.ScanPaths(filepath.FromSlash(p), excluded); err != nil {
+			return nil, err
+		}
+	}
+	if !g.Optimize && !g.Root.Config.GetOptimizeAutoloader() {
+		return classMap, nil
+	}
+
+	// Longer namespaces are scanned first and PSR-4 rules before PSR-0 rules.
+	type rule struct {
+		autoloadType string
+		paths        []string
+	}
+	rules := make(map[string][]rule)
+	for namespace, paths := range loads.psr4 {
+		rules[namespace] = append(rules[namespace], rule{"psr-4", paths})
+	}
+	for namespace, paths := range loads.psr0 {
+		rules[namespace] = append(rules[namespace], rule{"psr-0", paths})
+	}
+	for _, namespace := range reverseSortedKeys(rules) {
+		for _, r := range rules[namespace] {
+			for _, dir := range r.paths {
+				if !path.IsAbs(dir) {
+					dir = dirs.base + "/" + dir
+				}
+				dir = normalizePath(dir)
+				if info, err := os.Stat(filepath.FromSlash(dir)); err != nil || !info.IsDir() {
+					continue
+				}
+
+				// The vendor directory is skipped if it is in the directory.
+				dirExcluded := excluded
+				if strings.HasPrefix(dirs.vendor, dir+"/") {
+					if dirExcluded, err = compileExclusions(append(append([]string{}, loads.exclude...), regexp.QuoteMeta(dirs.vendor+"/"))); err != nil {
+						return nil, err
+					}
+				}
+				if err := classMap.ScanNamespacePaths(filepath.FromSlash(dir), dirExcluded, r.autoloadType, namespace); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	classMap.ClearPSRViolations(dirs.vendor)
+	return classMap, nil
+}
+
+// compileExclusions combines the regular expressions of exclude-from-classmap
+// patterns. It returns nil if there are none.
+func compileExclusions(patterns []string) (*regexp.Regexp, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	return regexp.Compile("(" + strings.Join(patterns, "|") + ")")
+}
+
+// relativePath returns the path of an autoload rule of the package, which is relative
+// to the base directory for the root package.
+func (p autoloadPackage) relativePath(rulePath string) string {
+	if p.installPath == "" {
+		if rulePath == "" {
+			return "."
+		}
+		return rulePath
+	}
+	return p.installPath + "/" + rulePath
+}
+
+// fileIdentifier returns the key of a file in autoload_files.php, which Composer uses
+// to include each file only once.
+func fileIdentifier(name string, filePath string) string {
+	sum := md5.Sum([]byte(name + ":" + filePath))
+	return hex.EncodeToString(sum[:])
+}
+
+// sortPackages sorts packages so that dependencies come before the packages that
+// require them, like Composer's PackageSorter. Packages that are required by many
+// other packages come first and ties are broken by name in natural order.
+func sortPackages(packages []autoloadPackage) []autoloadPackage {
+	usage := make(map[string][]string)
+	for _, pkg := range packages {
+		for _, target := range pkg.requires {
+			usage[target] = append(usage[target], pkg.name)
+		}
+	}
+
+	computing := make(map[string]bool)
+	computed := make(map[string]int)
+	var importance func(name string) int
+	importance = func(name string) int {
+		if weight, ok := computed[name]; ok {
+			return weight
+		}
+		// A circular dependency does not add to the importance.
+		if computing[name] {
+			return 0
+		}
+		computing[name] = true
+		weight := 0
+		for _, user := range usage[name] {
+			weight -= 1 - importance(user)
+		}
+		delete(computing, name)
+		computed[name] = weight
+		return weight
+	}
+
+	weights := make([]int, len(packages))
+	for i, pkg := range packages {
+		weights[i] = importance(pkg.name)
+	}
+	order := make([]int, len(packages))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if weights[a] != weights[b] {
+			return weights[a] < weights[b]
+		}
+		return strnatcasecmp(packages[a].name, packages[b].name) < 0
+	})
+
+	sorted := make([]autoloadPackage, 0, len(packages))
+	for _, i := range order {
+		sorted = append(sorted, packages[i])
+	}
+	return sorted
+}
+
+// pathCode returns a path relative to the vendor or base directory if possible, like
+// Composer's AutoloadGenerator::getPathCode.
+func (d autoloadDirs) pathCode(p string) autoloadPath {
+	if !path.IsAbs(p) {
+		p = d.base + "/" + p
+	}
+	p = normalizePath(p)
+
+	code := autoloadPath{phar: strings.Contains(p, ".phar")}
+	if strings.HasPrefix(p+"/", d.vendor+"/") {
+		code.dir = "$vendorDir"
+		code.path = p[len(d.vendor):]
+		return code
+	}
+	code.path = normalizePath(findShortestPath(d.base, p, true))
+	if !path.IsAbs(code.path) {
+		code.dir = "$baseDir"
+		code.path = "/" + code.path
+	}
+	return code
+}
+
+// pathCodes returns the PHP code of several paths separated by commas.
+func (d autoloadDirs) pathCodes(paths []string) string {
+	codes := make([]string, 0, len(paths))
+	for _, p := range paths {
+		codes = append(codes, d.pathCode(p).code())
+	}
+	return strings.Join(codes, ", ")
+}
+
+// code returns the PHP expression of the path.
+func (p autoloadPath) code() string {
+	code := phpString(p.path)
+	if p.dir != "" {
+		code = p.dir + " . " + code
+	}
+	if p.phar {
+		code = "'phar://' . " + code
+	}
+	return code
+}
+
+// value returns the path the PHP expression of the path evaluates to.
+func (p autoloadPath) value(d autoloadDirs) string {
+	value := p.path
+	switch p.dir {
+	case "$vendorDir":
+		value = d.vendor + value
+	case "$baseDir":
+		value = d.base + value
+	}
+	if p.phar {
+		value = "phar://" + value
+	}
+	return value
+}
+
+// staticFile returns autoload_static.php, which holds the properties of the
+// ClassLoader as static arrays for PHP's opcache, like Composer's
+// AutoloadGenerator::getStaticFile.
+func (g AutoloadGenerator) staticFile(dirs autoloadDirs, suffix string, loads autoloads, classMap map[string]string) []byte {
+	value := func(p string) string {
+		return dirs.pathCode(p).value(dirs)
+	}
+
+	// The properties are in the order the ClassLoader declares them.
+	type property struct {
+		name  string
+		value phpArray
+	}
+	properties := make([]property, 0, 7)
+
+	files := phpArray{}
+	for _, file := range loads.files {
+		files = append(files, phpArrayItem{file.identifier, value(file.path)})
+	}
+
+	prefixLengthsPSR4, prefixDirsPSR4, fallbackDirsPSR4 := phpArray{}, phpArray{}, phpArray{}
+	for _, namespace := range reverseSortedKeys(loads.psr4) {
+		paths := make([]string, 0, len(loads.psr4[namespace]))
+		for _, p := range loads.psr4[namespace] {
+			paths = append(paths, value(p))
+		}
+		if namespace == "" {
+			fallbackDirsPSR4 = phpList(paths...)
+			continue
+		}
+		prefixLengthsPSR4 = addToGroup(prefixLengthsPSR4, namespace[:1], phpArrayItem{namespace, len(namespace)})
+		prefixDirsPSR4 = append(prefixDirsPSR4, phpArrayItem{namespace, phpList(paths...)})
+	}
+
+	prefixesPSR0, fallbackDirsPSR0 := phpArray{}, phpArray{}
+	for _, namespace := range reverseSortedKeys(loads.psr0) {
+		paths := make([]string, 0, len(loads.psr0[namespace]))
+		for _, p := range loads.psr0[namespace] {
+			paths = append(paths, value(p))
+		}
+		if namespace == "" {
+			fallbackDirsPSR0 = phpList(paths...)
+			continue
+		}
+		prefixesPSR0 = addToGroup(prefixesPSR0, namespace[:1], phpArrayItem{namespace, phpList(paths...)})
+	}
+
+	classes := phpArray{}
+	for _, class := range sortedKeys(classMap) {
+		classes = append(classes, phpArrayItem{class, value(classMap[class])})
+	}
+
+	properties = append(properties,
+		property{"files", files},
+		property{"prefixLengthsPsr4", prefixLengthsPSR4},
+		property{"prefixDirsPsr4", prefixDirsPSR4},
+		property{"fallbackDirsPsr4", fallbackDirsPSR4},
+		property{"prefixesPsr0", prefixesPSR0},
+		property{"fallbackDirsPsr0", fallbackDirsPSR0},
+		property{"classMap", classes},
+	)
+
+	// Absolute paths are replaced by paths relative to the file.
+	targetDir := dirs.vendor + "/composer"
+	vendorCode := findShortestPathCode(targetDir, dirs.vendor, true, true)
+	baseCode := findShortestPathCode(targetDir, dirs.base, true, true)
+	absolute := func(dir string) string {
+		code := phpString(strings.TrimRight(dir, "/") + "/")
+		return " => " + code[:len(code)-1]
+	}
+	replacements := map[string]string{
+		absolute(dirs.vendor):             " => " + vendorCode + " . '/",
+		absolute("phar://" + dirs.vendor): " => 'phar://' . " + vendorCode + " . '/",
+		absolute(dirs.base):               " => " + baseCode + " . '/",
+		absolute("phar://" + dirs.base):   " => 'phar://' . " + baseCode + " . '/",
+	}
+
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "<?php\n\n// autoload_static.php @generated by Composer\n\nnamespace Composer\\Autoload;\n\nclass ComposerStaticInit%s\n{\n", suffix)
+	initializer := strings.Builder{}
+	for _, prop := range properties {
+		if len(prop.value) == 0 {
+			continue
+		}
+		exported := phpStrtr(varExport(prop.value), replacements)
+		lines := strings.Split(exported, "\n")
+		for i, line := range lines {
+			indent := len(line) - len(strings.TrimLeft(line, " "))
+			lines[i] = strings.Repeat(" ", 4+indent) + line
+		}
+		exported = strings.TrimLeft(strings.Join(lines, "\n"), " \t\n\r\x00\x0b")
+
+		fmt.Fprintf(b, "    public static $%s = %s;\n\n", prop.name, exported)
+		if prop.name != "files" {
+			fmt.Fprintf(&initializer, "            $loader->%s = ComposerStaticInit%s::$%s;\n", prop.name, suffix, prop.name)
+		}
+	}
+	fmt.Fprintf(b, "    public static function getInitializer(ClassLoader $loader)\n    {\n        return \\Closure::bind(function () use ($loader) {\n%s\n        }, null, ClassLoader::class);\n    }\n}\n", initializer.String())
+	return b.Bytes()
+}
+
+// addToGroup adds an item to the nested array of the group key, which is created if
+// it does not exist yet.
+func addToGroup(groups phpArray, group string, item phpArrayItem) phpArray {
+	for i := range groups {
+		if groups[i].key == group {
+			groups[i].value = append(groups[i].value.(phpArray), item)
+			return groups
+		}
+	}
+	return append(groups, phpArrayItem{group, phpArray{item}})
+}
+
+// reverseSortedKeys returns the keys of a map in reverse order, like PHP's krsort.
+func reverseSortedKeys[V any](m map[string]V) []string {
+	keys := sortedKeys(m)
+	sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	return keys
+}
+
+// normalizePath removes "." segments, duplicate slashes and ".." segments that can be
+// resolved from a slash separated path, like Composer's Filesystem::normalizePath.
+func normalizePath(p string) string {
+	absolute := ""
+	if strings.HasPrefix(p, "/") {
+		absolute = "/"
+		p = p[1:]
+	}
+
+	parts := make([]string, 0)
+	up := false
+	for _, chunk := range strings.Split(p, "/") {
+		if chunk == ".." && (absolute != "" || up) {
+			if len(parts) > 0 {
+				parts = parts[:len(parts)-1]
+			}
+			up = !(len(parts) == 0 || parts[len(parts)-1] == "..")
+		} else if chunk != "." && chunk != "" {
+			parts = append(parts, chunk)
+			up = chunk != ".."
+		}
+	}
+	return absolute + strings.Join(parts, "/")
+}
+
+// findShortestPath returns the relative path from one absolute path to another, or
+// the target path if they have nothing in common, like Composer's
+// Filesystem::findShortestPath.
+func findShortestPath(from, to string, directories bool) string {
+	from = normalizePath(from)
+	to = normalizePath(to)
+	if directories {
+		from = strings.TrimRight(from, "/") + "/dummy_file"
+	}
+	if path.Dir(from) == to {
+		return "./"
+	}
+
+	commonPath := to
+	for !strings.HasPrefix(from+"/", commonPath+"/") && commonPath != "/" {
+		commonPath = path.Dir(commonPath)
+	}
+	if !strings.HasPrefix(from, commonPath) {
+		return to
+	}
+	commonPath = strings.TrimRight(commonPath, "/") + "/"
+	depth := strings.Count(phpSubstr(from, len(commonPath)), "/")
+
+	// Top level directories such as /app and /vendor are addressed absolutely.
+	if commonPath == "/" && depth > 1 {
+		return to
+	}
+	result := strings.Repeat("../", depth) + phpSubstr(to, len(commonPath))
+	if result == "" {
+		return "./"
+	}
+	return result
+}
+
+// findShortestPathCode returns the PHP code of the path to one absolute path from
+// another, based on __DIR__, like Composer's Filesystem::findShortestPathCode.
+func findShortestPathCode(from, to string, directories bool, staticCode bool) string {
+	from = normalizePath(from)
+	to = normalizePath(to)
+	if from == to {
+		if directories {
+			return "__DIR__"
+		}
+		return "__FILE__"
+	}
+
+	commonPath := to
+	for !strings.HasPrefix(from+"/", commonPath+"/") && commonPath != "/" && commonPath != "." {
+		commonPath = path.Dir(commonPath)
+	}
+	if !strings.HasPrefix(from, commonPath) || commonPath == "/" || commonPath == "." {
+		return phpString(to)
+	}
+	commonPath = strings.TrimRight(commonPath, "/") + "/"
+	if strings.HasPrefix(to, from+"/") {
+		return "__DIR__ . " + phpString(phpSubstr(to, len(from)))
+	}
+
+	depth := strings.Count(phpSubstr(from, len(commonPath)), "/")
+	if directories {
+		depth++
+	}
+	var code string
+	if staticCode {
+		code = "__DIR__ . '" + strings.Repeat("/..", depth) + "'"
+	} else {
+		code = strings.Repeat("dirname(", depth) + "__DIR__" + strings.Repeat(")", depth)
+	}
+	if relTarget := phpSubstr(to, len(commonPath)); relTarget != "" {
+		code += "." + phpString("/"+relTarget)
+	}
+	return code
+}
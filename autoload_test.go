@@ -0,0 +1,296 @@
+package gocomposer
+
+import (
+	is2 "github.com/matryer/is"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// loadAutoloadGenerator returns a generator for the project in testdata/autoload,
+// which is installed at /app.
+func loadAutoloadGenerator(t *testing.T) AutoloadGenerator {
+	is := is2.New(t)
+	root, err := Load("testdata/autoload/composer.json")
+	is.NoErr(err)
+	lock, err := LoadLock("testdata/autoload/composer.lock")
+	is.NoErr(err)
+	return AutoloadGenerator{
+		BaseDir:     "/app",
+		Root:        *root,
+		Packages:    lock.Packages,
+		PackagesDev: lock.PackagesDev,
+	}
+}
+
+func TestAutoloadGenerator_Generate(t *testing.T) {
+	is := is2.New(t)
+
+	files, err := loadAutoloadGenerator(t).Generate()
+	is.NoErr(err)
+	is.Equal(sortedKeys(files), []string{
+		AutoloadClassMapFile,
+		AutoloadFilesFile,
+		AutoloadNamespacesFile,
+		AutoloadPSR4File,
+		AutoloadStaticFile,
+	})
+	for name, data := range files {
+		expected, err := os.ReadFile(filepath.Join("testdata/autoload/vendor/composer", name))
+		is.NoErr(err)
+		is.Equal(string(data), string(expected)) // file differs from testdata
+	}
+}
+
+func TestAutoloadGenerator_Generate_NoDev(t *testing.T) {
+	is := is2.New(t)
+
+	generator := loadAutoloadGenerator(t)
+	generator.NoDev = true
+	files, err := generator.Generate()
+	is.NoErr(err)
+
+	psr4 := string(files[AutoloadPSR4File])
+	is.True(strings.Contains(psr4, "    'App\\\\' => array($baseDir . '/src'),\n"))
+	is.True(!strings.Contains(psr4, "App\\\\Tests"))
+	is.True(!strings.Contains(psr4, "'' => "))
+	is.True(!strings.Contains(string(files[AutoloadNamespacesFile]), "Acme_Testing_"))
+	is.True(!strings.Contains(string(files[AutoloadFilesFile]), "acme/testing"))
+	is.True(!strings.Contains(string(files[AutoloadStaticFile]), "fallbackDirsPsr4"))
+}
+
+func TestAutoloadGenerator_Generate_Paths(t *testing.T) {
+	is := is2.New(t)
+
+	generator := AutoloadGenerator{
+		BaseDir:   "/srv/app",
+		VendorDir: "/opt/vendor",
+		Suffix:    "Outside",
+		Root: ComposerJSON{
+			Autoload: Autoload{
+				PSR4:  map[string]StringOrSlice{"App\\": {"", "/usr/share/php/app"}},
+				Files: []string{"../shared/helpers.php"},
+			},
+		},
+	}
+	files, err := generator.Generate()
+	is.NoErr(err)
+
+	is.Equal(string(files[AutoloadPSR4File]), `<?php
+
+// autoload_psr4.php @generated by Composer
+
+$vendorDir = dirname(__DIR__);
+$baseDir = '/srv/app';
+
+return array(
+    'App\\' => array($baseDir . '/', '/usr/share/php/app'),
+);
+`)
+	is.True(strings.Contains(string(files[AutoloadFilesFile]), "    '"+fileIdentifier(rootPackageName, "../shared/helpers.php")+"' => $baseDir . '/../shared/helpers.php',\n"))
+	is.True(strings.Contains(string(files[AutoloadStaticFile]), `
+    public static $prefixDirsPsr4 = array (
+        'App\\' => 
+        array (
+            0 => '/srv/app' . '/',
+            1 => '/usr/share/php/app',
+        ),
+    );
+`))
+}
+
+func TestAutoloadGenerator_Generate_Suffix(t *testing.T) {
+	is := is2.New(t)
+
+	generator := AutoloadGenerator{BaseDir: "/app", Root: ComposerJSON{Name: "acme/app"}}
+	hash, err := generator.Root.ContentHash()
+	is.NoErr(err)
+	files, err := generator.Generate()
+	is.NoErr(err)
+	is.True(strings.Contains(string(files[AutoloadStaticFile]), "class ComposerStaticInit"+hash+"\n"))
+	_, ok := files[AutoloadFilesFile]
+	is.True(!ok)
+
+	generator.Root.Config.AutoloaderSuffix = "Config"
+	files, err = generator.Generate()
+	is.NoErr(err)
+	is.True(strings.Contains(string(files[AutoloadStaticFile]), "class ComposerStaticInitConfig\n"))
+
+	generator.Suffix = "Field"
+	files, err = generator.Generate()
+	is.NoErr(err)
+	is.True(strings.Contains(string(files[AutoloadStaticFile]), "class ComposerStaticInitField\n"))
+}
+
+func TestAutoloadGenerator_Dump(t *testing.T) {
+	is := is2.New(t)
+
+	dir := t.TempDir()
+	generator := loadAutoloadGenerator(t)
+	generator.BaseDir = dir
+	is.NoErr(generator.Dump())
+
+	entries, err := os.ReadDir(filepath.Join(dir, "vendor", "composer"))
+	is.NoErr(err)
+	is.Equal(len(entries), 5)
+	psr4, err := os.ReadFile(filepath.Join(dir, "vendor", "composer", AutoloadPSR4File))
+	is.NoErr(err)
+	expected, err := os.ReadFile("testdata/autoload/vendor/composer/" + AutoloadPSR4File)
+	is.NoErr(err)
+	is.Equal(string(psr4), string(expected))
+
+	// autoload_files.php is removed when no package autoloads files.
+	generator.Root.Autoload.Files = nil
+	generator.Packages = nil
+	generator.PackagesDev = nil
+	is.NoErr(generator.Dump())
+	_, err = os.Stat(filepath.Join(dir, "vendor", "composer", AutoloadFilesFile))
+	is.True(os.IsNotExist(err))
+}
+
+func Test_sortPackages(t *testing.T) {
+	is := is2.New(t)
+
+	packages := []autoloadPackage{
+		{name: "acme/http", requires: []string{"acme/log", "php"}},
+		{name: "acme/log", requires: []string{"psr/log"}},
+		{name: "acme/lib10"},
+		{name: "psr/log"},
+		{name: "acme/lib2"},
+		{name: "cycle/a", requires: []string{"cycle/b"}},
+		{name: "cycle/b", requires: []string{"cycle/a"}},
+	}
+	names := make([]string, 0)
+	for _, pkg := range sortPackages(packages) {
+		names = append(names, pkg.name)
+	}
+	is.Equal(names, []string{"cycle/a", "psr/log", "acme/log", "cycle/b", "acme/http", "acme/lib2", "acme/lib10"})
+}
+
+func Test_normalizePath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/app/./src//Acme/", "/app/src/Acme"},
+		{"/app/vendor/../src", "/app/src"},
+		{"/../app", "/app"},
+		{"../lib/./src", "../lib/src"},
+		{"src/../../lib", "../lib"},
+		{"./", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			is := is2.New(t)
+			is.Equal(normalizePath(test.path), test.expected)
+		})
+	}
+}
+
+func Test_findShortestPath(t *testing.T) {
+	tests := []struct {
+		from        string
+		to          string
+		directories bool
+		expected    string
+	}{
+		{"/app", "/app/src", true, "src"},
+		{"/app", "/app", true, "./"},
+		{"/app/src", "/app/lib/file.php", true, "../lib/file.php"},
+		{"/app/src/file.php", "/app/lib/file.php", false, "../lib/file.php"},
+		{"/app", "/lib", true, "../lib"},
+		{"/srv/app", "/usr/share/php", true, "/usr/share/php"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.from+" "+test.to, func(t *testing.T) {
+			is := is2.New(t)
+			is.Equal(findShortestPath(test.from, test.to, test.directories), test.expected)
+		})
+	}
+}
+
+func Test_findShortestPathCode(t *testing.T) {
+	tests := []struct {
+		from        string
+		to          string
+		directories bool
+		staticCode  bool
+		expected    string
+	}{
+		{"/app/vendor/composer", "/app/vendor", true, false, "dirname(__DIR__)"},
+		{"/app/vendor", "/app", true, false, "dirname(__DIR__)"},
+		{"/app/lib/vendor", "/app", true, false, "dirname(dirname(__DIR__))"},
+		{"/app/vendor/composer", "/app", true, true, "__DIR__ . '/../..'"},
+		{"/app/vendor", "/app/vendor", true, false, "__DIR__"},
+		{"/app/vendor", "/app/vendor", false, false, "__FILE__"},
+		{"/app/vendor", "/app/vendor/composer", true, false, "__DIR__ . '/composer'"},
+		{"/app/bin/run", "/app/vendor/acme/run", false, false, "dirname(__DIR__).'/vendor/acme/run'"},
+		{"/app/vendor/composer", "/srv/app", true, false, "'/srv/app'"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.from+" "+test.to, func(t *testing.T) {
+			is := is2.New(t)
+			is.Equal(findShortestPathCode(test.from, test.to, test.directories, test.staticCode), test.expected)
+		})
+	}
+}
+
+func TestAutoloadGenerator_CheckPSR(t *testing.T) {
+	is := is2.New(t)
+
+	root, err := Load("testdata/psr/composer.json")
+	is.NoErr(err)
+	dir, err := filepath.Abs("testdata/psr")
+	is.NoErr(err)
+	generator := AutoloadGenerator{
+		BaseDir: dir,
+		Root:    *root,
+		Packages: []LockedPackage{{
+			Name:     "acme/lib",
+			Version:  "1.0.0",
+			Autoload: &Autoload{PSR4: map[string]StringOrSlice{`Acme\Lib\`: {"src/"}}},
+		}},
+	}
+
+	// Longer namespaces are scanned first and violations in the vendor directory are
+	// not reported.
+	violations, err := generator.CheckPSR()
+	is.NoErr(err)
+	messages := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		messages = append(messages, violation.Message(dir))
+	}
+	is.Equal(messages, []string{
+		`Class Legacy_Good located in ./lib/Legacy/Bad.php does not comply with psr-0 autoloading standard (rule: Legacy_ => ./lib). Skipping.`,
+		`Class Acme\Http\Right located in ./src/Http/Wrong.php does not comply with psr-4 autoloading standard (rule: Acme\ => ./src). Skipping.`,
+	})
+
+	// The class map only has the classes of PSR directories when optimizing.
+	files, err := generator.Generate()
+	is.NoErr(err)
+	is.True(!strings.Contains(string(files[AutoloadClassMapFile]), "Acme"))
+
+	generator.Optimize = true
+	files, err = generator.Generate()
+	is.NoErr(err)
+	is.Equal(string(files[AutoloadClassMapFile]), `<?php
+
+// autoload_classmap.php @generated by Composer
+
+$vendorDir = dirname(__DIR__);
+$baseDir = dirname($vendorDir);
+
+return array(
+    'Acme\\Helpers' => $baseDir . '/src/Helpers.php',
+    'Acme\\Http\\Client' => $baseDir . '/src/Http/Client.php',
+    'Acme\\Lib\\Thing' => $vendorDir . '/acme/lib/src/Thing.php',
+    'Acme\\Logger' => $baseDir . '/src/Logger.php',
+    'Composer\\InstalledVersions' => $vendorDir . '/composer/InstalledVersions.php',
+    'Legacy_Logger' => $baseDir . '/lib/Legacy/Logger.php',
+);
+`)
+}
@@ -0,0 +1,247 @@
+package gocomposer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ClassLoader finds the file of a class with PSR-4, PSR-0 and class map rules, like
+// Composer's ClassLoader does when a PHP application autoloads a class. Paths are
+// joined with "/".
+type ClassLoader struct {
+	prefixDirsPSR4   map[string][]string
+	firstCharsPSR4   map[byte]bool
+	fallbackDirsPSR4 []string
+	prefixesPSR0     map[byte][]classLoaderPrefix
+	fallbackDirsPSR0 []string
+	classMap         map[string]string
+
+	// If true, the directories of IncludePaths are searched for PSR-0 paths after the
+	// other rules.
+	UseIncludePath bool
+
+	// The directories of PHP's include_path, searched when UseIncludePath is true.
+	IncludePaths []string
+
+	// If true, classes that are not in the class map are not searched for.
+	ClassMapAuthoritative bool
+}
+
+// classLoaderPrefix is a PSR-0 prefix and its directories.
+type classLoaderPrefix struct {
+	prefix string
+	dirs   []string
+}
+
+// NewClassLoader creates a ClassLoader without rules.
+func NewClassLoader() *ClassLoader {
+	return &ClassLoader{
+		prefixDirsPSR4: make(map[string][]string),
+		firstCharsPSR4: make(map[byte]bool),
+		prefixesPSR0:   make(map[byte][]classLoaderPrefix),
+		classMap:       make(map[string]string),
+	}
+}
+
+// AddClassMap adds classes and their files to the class map. Classes that are already
+// in the class map keep their file.
+func (l *ClassLoader) AddClassMap(classMap map[string]string) {
+	for class, file := range classMap {
+		if _, ok := l.classMap[class]; !ok {
+			l.classMap[class] = file
+		}
+	}
+}
+
+// AddPSR0 adds directories to a PSR-0 prefix, or to the PSR-0 fallback directories if
+// the prefix is empty. If prepend is true, the directories are searched before the
+// directories that were added earlier.
+func (l *ClassLoader) AddPSR0(prefix string, dirs []string, prepend bool) {
+	if prefix == "" {
+		l.fallbackDirsPSR0 = addDirs(l.fallbackDirsPSR0, dirs, prepend)
+		return
+	}
+	first := prefix[0]
+	for i, existing := range l.prefixesPSR0[first] {
+		if existing.prefix == prefix {
+			l.prefixesPSR0[first][i].dirs = addDirs(existing.dirs, dirs, prepend)
+			return
+		}
+	}
+	l.prefixesPSR0[first] = append(l.prefixesPSR0[first], classLoaderPrefix{prefix, append([]string{}, dirs...)})
+}
+
+// AddPSR4 adds directories to a PSR-4 namespace prefix, or to the PSR-4 fallback
+// directories if the prefix is empty. A prefix must end with a namespace separator.
+// If prepend is true, the directories are searched before the directories that were
+// added earlier.
+func (l *ClassLoader) AddPSR4(prefix string, dirs []string, prepend bool) error {
+	if prefix == "" {
+		l.fallbackDirsPSR4 = addDirs(l.fallbackDirsPSR4, dirs, prepend)
+		return nil
+	}
+	if !strings.HasSuffix(prefix, `\`) {
+		return fmt.Errorf("a non-empty PSR-4 prefix must end with a namespace separator: %s", prefix)
+	}
+	l.firstCharsPSR4[prefix[0]] = true
+	l.prefixDirsPSR4[prefix] = addDirs(l.prefixDirsPSR4[prefix], dirs, prepend)
+	return nil
+}
+
+func addDirs(existing, dirs []string, prepend bool) []string {
+	if prepend {
+		return append(append([]string{}, dirs...), existing...)
+	}
+	return append(append([]string{}, existing...), dirs...)
+}
+
+// FindFile returns the file of a class like ClassLoader::findFile: the file in the
+// class map, otherwise the first candidate of the PSR-4, PSR-0 and include path rules
+// that exists. It returns false if the class cannot be found.
+func (l *ClassLoader) FindFile(class string) (string, bool) {
+	class = strings.TrimPrefix(class, `\`)
+	if file, ok := l.classMap[class]; ok {
+		return file, true
+	}
+	if l.ClassMapAuthoritative {
+		return "", false
+	}
+	for _, file := range l.candidates(class, ".php") {
+		if _, err := os.Stat(filepath.FromSlash(file)); err == nil {
+			return file, true
+		}
+	}
+	return "", false
+}
+
+// Candidates returns the files that may declare a class in the order FindFile checks
+// them, whether they exist or not. A class in the class map only has its file as
+// candidate.
+func (l *ClassLoader) Candidates(class string) []string {
+	class = strings.TrimPrefix(class, `\`)
+	if file, ok := l.classMap[class]; ok {
+		return []string{file}
+	}
+	if l.ClassMapAuthoritative {
+		return nil
+	}
+	return l.candidates(class, ".php")
+}
+
+// candidates returns the paths of the PSR-4, PSR-0 and include path rules for a class,
+// like ClassLoader::findFileWithExtension.
+func (l *ClassLoader) candidates(class, ext string) []string {
+	if class == "" {
+		return nil
+	}
+	candidates := make([]string, 0)
+
+	// PSR-4 lookup, from the longest namespace prefix to the shortest.
+	logicalPathPSR4 := strings.ReplaceAll(class, `\`, "/") + ext
+	first := class[0]
+	if l.firstCharsPSR4[first] {
+		subPath := class
+		for {
+			lastPos := strings.LastIndex(subPath, `\`)
+			if lastPos < 0 {
+				break
+			}
+			subPath = subPath[:lastPos]
+			if dirs, ok := l.prefixDirsPSR4[subPath+`\`]; ok {
+				pathEnd := "/" + logicalPathPSR4[lastPos+1:]
+				for _, dir := range dirs {
+					candidates = append(candidates, dir+pathEnd)
+				}
+			}
+		}
+	}
+
+	// PSR-4 fallback dirs.
+	for _, dir := range l.fallbackDirsPSR4 {
+		candidates = append(candidates, dir+"/"+logicalPathPSR4)
+	}
+
+	// PSR-0 lookup.
+	var logicalPathPSR0 string
+	if pos := strings.LastIndex(class, `\`); pos >= 0 {
+		// A namespaced class name.
+		logicalPathPSR0 = logicalPathPSR4[:pos+1] + strings.ReplaceAll(logicalPathPSR4[pos+1:], "_", "/")
+	} else {
+		// A PEAR-like class name.
+		logicalPathPSR0 = strings.ReplaceAll(class, "_", "/") + ext
+	}
+	for _, prefix := range l.prefixesPSR0[first] {
+		if strings.HasPrefix(class, prefix.prefix) {
+			for _, dir := range prefix.dirs {
+				candidates = append(candidates, dir+"/"+logicalPathPSR0)
+			}
+		}
+	}
+
+	// PSR-0 fallback dirs.
+	for _, dir := range l.fallbackDirsPSR0 {
+		candidates = append(candidates, dir+"/"+logicalPathPSR0)
+	}
+
+	// PSR-0 include paths.
+	if l.UseIncludePath {
+		for _, dir := range l.IncludePaths {
+			candidates = append(candidates, strings.TrimRight(dir, "/")+"/"+logicalPathPSR0)
+		}
+	}
+	return candidates
+}
+
+// ClassLoader returns a ClassLoader with the rules of the autoloader files Generate
+// returns, so classes are found in the same files the generated autoloader loads them
+// from. The include-path entries of the packages are the IncludePaths of the
+// ClassLoader.
+func (g AutoloadGenerator) ClassLoader() (*ClassLoader, error) {
+	dirs, err := g.dirs()
+	if err != nil {
+		return nil, err
+	}
+	loads := g.parseAutoloads(dirs)
+	values := func(paths []string) []string {
+		values := make([]string, 0, len(paths))
+		for _, p := range paths {
+			values = append(values, dirs.pathCode(p).value(dirs))
+		}
+		return values
+	}
+
+	loader := NewClassLoader()
+	for _, namespace := range reverseSortedKeys(loads.psr0) {
+		loader.AddPSR0(namespace, values(loads.psr0[namespace]), false)
+	}
+	for _, namespace := range reverseSortedKeys(loads.psr4) {
+		if err := loader.AddPSR4(namespace, values(loads.psr4[namespace]), false); err != nil {
+			return nil, err
+		}
+	}
+
+	scanned, err := g.scanClassMap(dirs, loads)
+	if err != nil {
+		return nil, err
+	}
+	classMap := map[string]string{
+		"Composer\\InstalledVersions": dirs.vendor + "/composer/InstalledVersions.php",
+	}
+	for class, file := range scanned.Classes {
+		if class != "Composer\\InstalledVersions" {
+			classMap[class] = dirs.pathCode(file).value(dirs)
+		}
+	}
+	loader.AddClassMap(classMap)
+
+	for _, pkg := range g.packages(dirs) {
+		for _, includePath := range pkg.includePath {
+			loader.IncludePaths = append(loader.IncludePaths, dirs.pathCode(pkg.relativePath(strings.Trim(includePath, "/"))).value(dirs))
+		}
+	}
+	loader.UseIncludePath = g.Root.Config.GetUseIncludePath()
+	loader.ClassMapAuthoritative = g.Root.Config.GetClassmapAuthoritative()
+	return loader, nil
+}
@@ -0,0 +1,146 @@
+package gocomposer
+
+import (
+	is2 "github.com/matryer/is"
+	"path/filepath"
+	"testing"
+)
+
+func TestClassLoader_Candidates(t *testing.T) {
+	is := is2.New(t)
+
+	loader := NewClassLoader()
+	is.NoErr(loader.AddPSR4(`Acme\`, []string{"/app/src"}, false))
+	is.NoErr(loader.AddPSR4(`Acme\Log\`, []string{"/vendor/log/src", "/vendor/log/lib"}, false))
+	is.NoErr(loader.AddPSR4(`Acme\Log\`, []string{"/app/overrides"}, true))
+	is.NoErr(loader.AddPSR4("", []string{"/app/fallback"}, false))
+	loader.AddPSR0("Legacy_", []string{"/app/lib"}, false)
+	loader.AddPSR0(`Acme\`, []string{"/vendor/psr0"}, false)
+	loader.AddPSR0("", []string{"/app/psr0"}, false)
+	loader.AddClassMap(map[string]string{`Acme\Mapped`: "/app/mapped.php"})
+	loader.AddClassMap(map[string]string{`Acme\Mapped`: "/app/other.php"})
+	loader.IncludePaths = []string{"/usr/share/php/"}
+
+	err := loader.AddPSR4(`Acme\Http`, []string{"/app/http"}, false)
+	is.Equal(err.Error(), `a non-empty PSR-4 prefix must end with a namespace separator: Acme\Http`)
+
+	tests := []struct {
+		class    string
+		expected []string
+	}{
+		{
+			class:    `Acme\Mapped`,
+			expected: []string{"/app/mapped.php"},
+		},
+		{
+			class: `\Acme\Log\Some_Logger`,
+			expected: []string{
+				"/app/overrides/Some_Logger.php",
+				"/vendor/log/src/Some_Logger.php",
+				"/vendor/log/lib/Some_Logger.php",
+				"/app/src/Log/Some_Logger.php",
+				"/app/fallback/Acme/Log/Some_Logger.php",
+				"/vendor/psr0/Acme/Log/Some/Logger.php",
+				"/app/psr0/Acme/Log/Some/Logger.php",
+			},
+		},
+		{
+			class: "Legacy_Db_Table",
+			expected: []string{
+				"/app/fallback/Legacy_Db_Table.php",
+				"/app/lib/Legacy/Db/Table.php",
+				"/app/psr0/Legacy/Db/Table.php",
+			},
+		},
+		{
+			class:    "",
+			expected: []string{},
+		},
+	}
+	for _, test := range tests {
+		candidates := loader.Candidates(test.class)
+		is.Equal(len(candidates), len(test.expected))
+		if len(test.expected) > 0 {
+			is.Equal(candidates, test.expected)
+		}
+	}
+
+	loader.UseIncludePath = true
+	candidates := loader.Candidates("Legacy_Db_Table")
+	is.Equal(candidates[len(candidates)-1], "/usr/share/php/Legacy/Db/Table.php")
+
+	loader.ClassMapAuthoritative = true
+	is.Equal(loader.Candidates(`Acme\Mapped`), []string{"/app/mapped.php"})
+	is.Equal(len(loader.Candidates("Legacy_Db_Table")), 0)
+}
+
+func TestClassLoader_FindFile(t *testing.T) {
+	is := is2.New(t)
+
+	dir, err := filepath.Abs("testdata/psr")
+	is.NoErr(err)
+	dir = filepath.ToSlash(dir)
+	loader := NewClassLoader()
+	is.NoErr(loader.AddPSR4(`Acme\`, []string{dir + "/missing", dir + "/src"}, false))
+	loader.AddPSR0("Legacy_", []string{dir + "/lib"}, false)
+
+	tests := []struct {
+		class    string
+		expected string
+		found    bool
+	}{
+		{class: `Acme\Http\Client`, expected: dir + "/src/Http/Client.php", found: true},
+		{class: `\Acme\Logger`, expected: dir + "/src/Logger.php", found: true},
+		{class: "Legacy_Logger", expected: dir + "/lib/Legacy/Logger.php", found: true},
+		{class: `Acme\Missing`, expected: "", found: false},
+		{class: "Other", expected: "", found: false},
+	}
+	for _, test := range tests {
+		file, found := loader.FindFile(test.class)
+		is.Equal(file, test.expected)
+		is.Equal(found, test.found)
+	}
+
+	loader.ClassMapAuthoritative = true
+	_, found := loader.FindFile(`Acme\Logger`)
+	is.True(!found)
+}
+
+func TestAutoloadGenerator_ClassLoader(t *testing.T) {
+	is := is2.New(t)
+
+	generator := loadAutoloadGenerator(t)
+	generator.Root.IncludePath = []string{"/lib/"}
+	generator.Packages[0].IncludePath = []string{"pear"}
+	loader, err := generator.ClassLoader()
+	is.NoErr(err)
+	is.Equal(loader.IncludePaths, []string{"/app/lib", "/app/vendor/acme/http/pear"})
+	is.True(!loader.UseIncludePath)
+
+	is.Equal(loader.Candidates(`Composer\InstalledVersions`), []string{"/app/vendor/composer/InstalledVersions.php"})
+	is.Equal(loader.Candidates(`Acme\Log\Logger`), []string{
+		"/app/vendor/acme/log/src/Logger.php",
+		"/app/vendor/acme/log/lib/Logger.php",
+		"/app/fallback/Acme/Log/Logger.php",
+	})
+	is.Equal(loader.Candidates(`App\Tests\Unit_Test`), []string{
+		"/app/tests/Unit_Test.php",
+		"/app/src/Tests/Unit_Test.php",
+		"/app/fallback/App/Tests/Unit_Test.php",
+	})
+	is.Equal(loader.Candidates("Acme_Testing_Case"), []string{
+		"/app/fallback/Acme_Testing_Case.php",
+		"/app/vendor/acme/testing/src/Acme/Testing/Case.php",
+	})
+
+	generator.NoDev = true
+	useIncludePath := true
+	generator.Root.Config.UseIncludePath = &useIncludePath
+	loader, err = generator.ClassLoader()
+	is.NoErr(err)
+	is.True(loader.UseIncludePath)
+	is.Equal(loader.Candidates("Acme_Testing_Case"), []string{
+		"/app/lib/Acme/Testing/Case.php",
+		"/app/vendor/acme/http/pear/Acme/Testing/Case.php",
+	})
+}
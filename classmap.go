@@ -0,0 +1,576 @@
+package gocomposer
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ClassMapExtensions are the extensions of the files scanned for classes.
+var ClassMapExtensions = []string{"php", "inc", "hh"}
+
+// ClassMap maps class names to the files that declare them, like the class map
+// Composer's ClassMapGenerator builds for autoload_classmap.php.
+type ClassMap struct {
+	// Paths of the files by fully qualified class name, e.g. "Acme\\Log\\Logger". The
+	// first file found that declares a class is used.
+	Classes map[string]string
+
+	// Paths of the other files that declare a class that is in Classes, by class
+	// name.
+	Ambiguous map[string][]string
+
+	// Classes that were skipped by ScanNamespacePaths because they do not match the
+	// path of their file.
+	PSRViolations []PSRViolation
+
+	// ambiguousOrder holds the keys of Ambiguous in the order they were found.
+	ambiguousOrder []string
+
+	// scanned holds the real paths of the files that were scanned, so they are not
+	// scanned twice.
+	scanned map[string]bool
+}
+
+// NewClassMap creates an empty ClassMap.
+func NewClassMap() *ClassMap {
+	return &ClassMap{
+		Classes:   make(map[string]string),
+		Ambiguous: make(map[string][]string),
+		scanned:   make(map[string]bool),
+	}
+}
+
+// ambiguousFilter matches the paths of tests, fixtures, examples and stubs, which are
+// not reported as ambiguous.
+var ambiguousFilter = regexp.MustCompile(`(?i)/(test|fixture|example|stub)s?/`)
+
+// ScanPaths adds the classes of a PHP file, or of the PHP files in a directory and its
+// subdirectories, to the class map. The path may contain "*" wildcards. Files whose
+// slash separated path matches excluded are skipped, which may be nil. Directories
+// and files starting with a dot are skipped like Composer does.
+func (m *ClassMap) ScanPaths(p string, excluded *regexp.Regexp) error {
+	return m.scan(p, excluded, "classmap", "")
+}
+
+// ScanNamespacePaths adds the classes of the PHP files in a directory that is mapped
+// to a namespace by a "psr-4" or "psr-0" autoload rule to the class map, like
+// `composer dump-autoload --optimize`. Classes whose name does not match the path of
+// their file cannot be autoloaded by the rule, so they are added to PSRViolations
+// instead if their file has no valid class.
+func (m *ClassMap) ScanNamespacePaths(dir string, excluded *regexp.Regexp, autoloadType string, namespace string) error {
+	if autoloadType != "psr-4" && autoloadType != "psr-0" {
+		return fmt.Errorf(`invalid autoload type "%s", expected "psr-4" or "psr-0"`, autoloadType)
+	}
+	return m.scan(dir, excluded, autoloadType, namespace)
+}
+
+// scan adds the classes of the files of a path to the class map, like Composer's
+// ClassMapGenerator::scanPaths.
+func (m *ClassMap) scan(p string, excluded *regexp.Regexp, autoloadType string, namespace string) error {
+	files, err := classMapFiles(p)
+	if err != nil {
+		return err
+	}
+	basePath, err := filepath.Abs(p)
+	if err != nil {
+		return err
+	}
+	basePath = normalizePath(filepath.ToSlash(basePath))
+
+	for _, file := range files {
+		if !hasClassMapExtension(file) {
+			continue
+		}
+		filePath, err := filepath.Abs(file)
+		if err != nil {
+			return err
+		}
+		filePath = normalizePath(filepath.ToSlash(filePath))
+		realPath, err := filepath.EvalSymlinks(filepath.FromSlash(filePath))
+		if err != nil {
+			return err
+		}
+		realPath = filepath.ToSlash(realPath)
+
+		if m.scanned[realPath] {
+			continue
+		}
+		if excluded != nil && (excluded.MatchString(realPath) || excluded.MatchString(filePath)) {
+			continue
+		}
+
+		classes, err := FindClasses(filePath)
+		if err != nil {
+			return err
+		}
+		if autoloadType != "classmap" {
+			classes = m.filterByNamespace(classes, filePath, namespace, autoloadType, basePath)
+			// A file without valid classes may still match another rule later.
+			if len(classes) > 0 {
+				m.scanned[realPath] = true
+			}
+		} else {
+			m.scanned[realPath] = true
+		}
+		for _, class := range classes {
+			m.add(class, filePath)
+		}
+	}
+	return nil
+}
+
+// filterByNamespace returns the classes of a file that match its path relative to the
+// directory of a PSR-4 or PSR-0 rule. If there are none, the classes are added to the
+// PSR violations.
+func (m *ClassMap) filterByNamespace(classes []string, filePath, namespace, autoloadType, basePath string) []string {
+	realSubPath := phpSubstr(filePath, len(basePath)+1)
+	if dot := strings.LastIndex(realSubPath, "."); dot >= 0 {
+		realSubPath = realSubPath[:dot]
+	}
+
+	valid := make([]string, 0, len(classes))
+	rejected := make([]string, 0)
+	for _, class := range classes {
+		var subPath string
+		if autoloadType == "psr-0" {
+			if i := strings.LastIndex(class, `\`); i >= 0 {
+				subPath = strings.ReplaceAll(class[:i+1], `\`, "/") + strings.ReplaceAll(class[i+1:], "_", "/")
+			} else {
+				subPath = strings.ReplaceAll(class, "_", "/")
+			}
+		} else {
+			subClass := class
+			if namespace != "" {
+				subClass = phpSubstr(class, len(namespace))
+			}
+			subPath = strings.ReplaceAll(subClass, `\`, "/")
+		}
+		if subPath == realSubPath {
+			valid = append(valid, class)
+		} else {
+			rejected = append(rejected, class)
+		}
+	}
+
+	// Invalid classes are only reported if the file has no valid class.
+	if len(valid) == 0 {
+		for _, class := range rejected {
+			m.PSRViolations = append(m.PSRViolations, PSRViolation{
+				Class:     class,
+				Path:      filePath,
+				Type:      autoloadType,
+				Namespace: namespace,
+				Dir:       basePath,
+			})
+		}
+	}
+	return valid
+}
+
+// ClearPSRViolations removes the PSR violations of the files in a directory, e.g. of
+// installed packages, which Composer does not report.
+func (m *ClassMap) ClearPSRViolations(dir string) {
+	dir = normalizePath(filepath.ToSlash(dir))
+	kept := m.PSRViolations[:0]
+	for _, violation := range m.PSRViolations {
+		if !strings.HasPrefix(violation.Path, dir+"/") {
+			kept = append(kept, violation)
+		}
+	}
+	m.PSRViolations = kept
+}
+
+// PSRViolation is a class that cannot be autoloaded by a PSR-4 or PSR-0 rule because
+// its name does not match the path of its file.
+type PSRViolation struct {
+	// Fully qualified name of the class.
+	Class string
+
+	// Absolute slash separated path of the file that declares the class.
+	Path string
+
+	// The type of the autoload rule, "psr-4" or "psr-0".
+	Type string
+
+	// The namespace prefix of the autoload rule.
+	Namespace string
+
+	// Absolute slash separated path of the directory of the autoload rule.
+	Dir string
+}
+
+// Message returns the warning Composer shows for the violation. Paths in dir, which is
+// usually the project directory, are shown relative to it.
+func (v PSRViolation) Message(dir string) string {
+	dir = normalizePath(filepath.ToSlash(dir))
+	short := func(p string) string {
+		if strings.HasPrefix(p, dir) {
+			return "." + p[len(dir):]
+		}
+		return p
+	}
+	return fmt.Sprintf("Class %s located in %s does not comply with %s autoloading standard (rule: %s => %s). Skipping.", v.Class, short(v.Path), v.Type, v.Namespace, short(v.Dir))
+}
+
+// add adds a class that is declared in a file to the class map.
+func (m *ClassMap) add(class, filePath string) {
+	existing, ok := m.Classes[class]
+	if !ok {
+		m.Classes[class] = filePath
+		return
+	}
+	if existing == filePath {
+		return
+	}
+	if _, ok := m.Ambiguous[class]; !ok {
+		m.ambiguousOrder = append(m.ambiguousOrder, class)
+	}
+	m.Ambiguous[class] = append(m.Ambiguous[class], filePath)
+}
+
+// AmbiguousClasses returns the classes that are declared in more than one file and the
+// paths of the files that are not used. Files in test, fixture, example and stub
+// directories are left out.
+func (m *ClassMap) AmbiguousClasses() map[string][]string {
+	ambiguous := make(map[string][]string)
+	for class, paths := range m.Ambiguous {
+		for _, p := range paths {
+			if !ambiguousFilter.MatchString(p) {
+				ambiguous[class] = append(ambiguous[class], p)
+			}
+		}
+	}
+	return ambiguous
+}
+
+// Warnings returns the warnings Composer shows for the ambiguous classes.
+func (m *ClassMap) Warnings() []string {
+	ambiguous := m.AmbiguousClasses()
+	warnings := make([]string, 0, len(ambiguous))
+	for _, class := range m.ambiguousOrder {
+		paths, ok := ambiguous[class]
+		if !ok {
+			continue
+		}
+		if len(paths) > 1 {
+			warnings = append(warnings, fmt.Sprintf(`Warning: Ambiguous class resolution, "%s" was found %dx: in "%s" and "%s", the first will be used.`, class, len(paths)+1, m.Classes[class], strings.Join(paths, `", "`)))
+		} else {
+			warnings = append(warnings, fmt.Sprintf(`Warning: Ambiguous class resolution, "%s" was found in both "%s" and "%s", the first will be used.`, class, m.Classes[class], paths[0]))
+		}
+	}
+	return warnings
+}
+
+// classMapFiles returns the files to scan for a path, which is a file, a directory or
+// a pattern with "*" wildcards that matches directories.
+func classMapFiles(p string) ([]string, error) {
+	if info, err := os.Stat(p); err == nil && !info.IsDir() {
+		return []string{p}, nil
+	}
+
+	dirs := []string{p}
+	if strings.Contains(p, "*") {
+		matches, err := filepath.Glob(p)
+		if err != nil {
+			return nil, err
+		}
+		dirs = matches
+	} else if info, err := os.Stat(p); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf(`could not scan for classes inside "%s" which does not appear to be a file nor a folder`, p)
+	}
+
+	files := make([]string, 0)
+	for _, dir := range dirs {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+		found, err := walkClassMapDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, found...)
+	}
+	return files, nil
+}
+
+// walkClassMapDir returns the files in a directory and its subdirectories, sorted by
+// path. Symbolic links are followed.
+func walkClassMapDir(dir string) ([]string, error) {
+	files := make([]string, 0)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		p := filepath.Join(dir, entry.Name())
+		isDir := entry.IsDir()
+		if entry.Type()&fs.ModeSymlink != 0 {
+			info, err := os.Stat(p)
+			if err != nil {
+				continue
+			}
+			isDir = info.IsDir()
+		}
+		if !isDir {
+			files = append(files, p)
+			continue
+		}
+		if isVCSDir(entry.Name()) {
+			continue
+		}
+		found, err := walkClassMapDir(p)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, found...)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// isVCSDir returns true for the version control directories Composer skips.
+func isVCSDir(name string) bool {
+	switch name {
+	case ".svn", "_svn", "CVS", "_darcs", ".arch-params", ".monotone", ".bzr", ".git", ".hg":
+		return true
+	}
+	return false
+}
+
+func hasClassMapExtension(file string) bool {
+	ext := strings.TrimPrefix(path.Ext(filepath.ToSlash(file)), ".")
+	for _, extension := range ClassMapExtensions {
+		if ext == extension {
+			return true
+		}
+	}
+	return false
+}
+
+// ExcludeFromClassMapPattern returns the regular expression of an
+// exclude-from-classmap pattern of a package installed at installPath, which is
+// matched against the slash separated absolute paths of files. "**" matches any
+// sequence of characters and "*" any sequence of characters except "/". Leading "./"
+// and "../" segments are resolved against installPath. It returns an empty string if
+// the directory the pattern starts in does not exist.
+func ExcludeFromClassMapPattern(installPath, pattern string) string {
+	pattern = strings.Trim(strings.ReplaceAll(pattern, `\`, "/"), "/")
+	pattern = regexp.MustCompile(`/+`).ReplaceAllString(regexp.QuoteMeta(pattern), "/")
+	pattern = strings.NewReplacer(`\*\*`, `.+?`, `\*`, `[^/]+?`).Replace(pattern)
+
+	updir := ""
+	if match := excludeUpdirRegex.FindString(pattern); match != "" {
+		updir = strings.ReplaceAll(match, `\.`, ".")
+		pattern = pattern[len(match):]
+	}
+
+	resolved, err := filepath.EvalSymlinks(filepath.Join(installPath, updir))
+	if err != nil {
+		return ""
+	}
+	resolved, err = filepath.Abs(resolved)
+	if err != nil {
+		return ""
+	}
+	return regexp.QuoteMeta(filepath.ToSlash(resolved)) + "/" + pattern + "($|/)"
+}
+
+// excludeUpdirRegex matches the leading "./" and "../" segments of a quoted
+// exclude-from-classmap pattern.
+var excludeUpdirRegex = regexp.MustCompile(`^(?:(?:\\\.){1,2}/)+`)
+
+// FindClasses returns the classes, interfaces, traits and enums declared in a PHP
+// file.
+func FindClasses(file string) ([]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParseClasses(string(data)), nil
+}
+
+var (
+	// classKeywordRegex matches a keyword that declares a class.
+	classKeywordRegex = regexp.MustCompile(`(?i)\b(?:class|interface|trait|enum)\s`)
+
+	// classDeclarationRegex matches class declarations and namespaces. Composer's
+	// regular expression also requires that the keyword does not follow "$", ":" or
+	// ">", which is checked separately.
+	classDeclarationRegex = regexp.MustCompile(`(?i)\b(?:(class|interface|trait|enum)\s+([a-zA-Z_\x{7f}-\x{10ffff}:][a-zA-Z0-9_\x{7f}-\x{10ffff}:\-]*)|(namespace)(\s+[a-zA-Z_\x{7f}-\x{10ffff}][a-zA-Z0-9_\x{7f}-\x{10ffff}]*(?:\s*\\\s*[a-zA-Z_\x{7f}-\x{10ffff}][a-zA-Z0-9_\x{7f}-\x{10ffff}]*)*)?\s*[{;])`)
+)
+
+// ParseClasses returns the classes, interfaces, traits and enums declared in PHP code,
+// like Composer's PhpFileParser::findClasses. Strings, comments and heredocs are
+// skipped.
+func ParseClasses(contents string) []string {
+	if !classKeywordRegex.MatchString(contents) {
+		return nil
+	}
+	contents = cleanPHP(contents)
+
+	classes := make([]string, 0)
+	namespace := ""
+	for offset := 0; offset < len(contents); {
+		match := classDeclarationRegex.FindStringSubmatchIndex(contents[offset:])
+		if match == nil {
+			break
+		}
+		start := offset + match[0]
+		if start > 0 && strings.ContainsRune("$:>", rune(contents[start-1])) {
+			offset = start + 1
+			continue
+		}
+		base := offset
+		group := func(i int) string {
+			if match[2*i] < 0 {
+				return ""
+			}
+			return contents[base+match[2*i] : base+match[2*i+1]]
+		}
+		offset += match[1]
+
+		if group(3) != "" {
+			namespace = strings.NewReplacer(" ", "", "\t", "", "\r", "", "\n", "").Replace(group(4)) + `\`
+			continue
+		}
+		name := group(2)
+		// Anonymous classes that extend or implement another class.
+		if name == "extends" || name == "implements" {
+			continue
+		}
+		if name[0] == ':' {
+			// An XHP class, https://github.com/facebook/xhp
+			name = "xhp" + strings.NewReplacer("-", "_", ":", "__").Replace(name)[1:]
+		} else if strings.EqualFold(group(1), "enum") {
+			// The name of a backed enum, e.g. "enum Suit: string", includes the colon.
+			if i := strings.LastIndex(name, ":"); i >= 0 {
+				name = name[:i]
+			}
+		}
+		classes = append(classes, strings.TrimLeft(namespace+name, `\`))
+	}
+	return classes
+}
+
+// heredocStartRegex matches the start of a heredoc or nowdoc.
+var heredocStartRegex = regexp.MustCompile(`^<<<[ \t]*(?:'([a-zA-Z_\x{80}-\x{10ffff}][a-zA-Z0-9_\x{80}-\x{10ffff}]*)'|"([a-zA-Z_\x{80}-\x{10ffff}][a-zA-Z0-9_\x{80}-\x{10ffff}]*)"|([a-zA-Z_\x{80}-\x{10ffff}][a-zA-Z0-9_\x{80}-\x{10ffff}]*))(?:\r\n|\n|\r)`)
+
+// cleanPHP returns the PHP code of a file with the text outside of PHP tags and the
+// comments removed and every string replaced with "null", like Composer's
+// PhpFileCleaner.
+func cleanPHP(contents string) string {
+	b := strings.Builder{}
+	i := 0
+	peek := func(c byte) bool {
+		return i+1 < len(contents) && contents[i+1] == c
+	}
+	skipToNewline := func() {
+		for i < len(contents) && contents[i] != '\r' && contents[i] != '\n' {
+			i++
+		}
+	}
+	skipString := func(delimiter byte) {
+		i++
+		for i < len(contents) {
+			if contents[i] == '\\' && (peek('\\') || peek(delimiter)) {
+				i += 2
+				continue
+			}
+			if contents[i] == delimiter {
+				i++
+				break
+			}
+			i++
+		}
+	}
+	skipComment := func() {
+		i += 2
+		for i < len(contents) {
+			if contents[i] == '*' && peek('/') {
+				i += 2
+				break
+			}
+			i++
+		}
+	}
+	skipHeredoc := func(delimiter string) {
+		for i < len(contents) {
+			switch contents[i] {
+			case ' ', '\t':
+				i++
+				continue
+			case delimiter[0]:
+				if strings.HasPrefix(contents[i:], delimiter) && !isIdentifierByte(contents, i+len(delimiter)) {
+					i += len(delimiter)
+					return
+				}
+			}
+			skipToNewline()
+			for i < len(contents) && (contents[i] == '\r' || contents[i] == '\n') {
+				i++
+			}
+		}
+	}
+
+	for i < len(contents) {
+		// Skip to the opening tag.
+		for i < len(contents) {
+			if contents[i] == '<' && peek('?') {
+				i += 2
+				break
+			}
+			i++
+		}
+		b.WriteString("<?")
+
+	code:
+		for i < len(contents) {
+			c := contents[i]
+			switch {
+			case c == '?' && peek('>'):
+				b.WriteString("?>")
+				i += 2
+				break code
+			case c == '"' || c == '\'':
+				skipString(c)
+				b.WriteString("null")
+				continue
+			case c == '<' && peek('<'):
+				if match := heredocStartRegex.FindStringSubmatch(contents[i:]); match != nil {
+					i += len(match[0])
+					skipHeredoc(match[1] + match[2] + match[3])
+					b.WriteString("null")
+					continue
+				}
+			case c == '/' && peek('/'):
+				skipToNewline()
+				continue
+			case c == '/' && peek('*'):
+				skipComment()
+				continue
+			}
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// isIdentifierByte returns true if the byte at i of s can be part of a PHP
+// identifier.
+func isIdentifierByte(s string, i int) bool {
+	if i >= len(s) {
+		return false
+	}
+	c := s[i]
+	return c == '_' || c >= 0x80 || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,266 @@
+package gocomposer
+
+import (
+	is2 "github.com/matryer/is"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParseClasses(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		expected []string
+	}{
+		{
+			name:     `NoClasses`,
+			contents: "<?php\n\nfunction classify() {}\n",
+			expected: nil,
+		},
+		{
+			name:     `GlobalNamespace`,
+			contents: "<?php\nclass Foo {}\nINTERFACE Bar {}\ntrait Baz {}\n",
+			expected: []string{"Foo", "Bar", "Baz"},
+		},
+		{
+			name:     `Namespace`,
+			contents: "<?php\nnamespace Acme\\Log;\n\nfinal class Logger {}\nabstract class Handler {}\n",
+			expected: []string{"Acme\\Log\\Logger", "Acme\\Log\\Handler"},
+		},
+		{
+			name:     `NamespaceWithSpaces`,
+			contents: "<?php\nnamespace Acme \\\n Http ;\nclass Client {}\n",
+			expected: []string{"Acme\\Http\\Client"},
+		},
+		{
+			name:     `BracedNamespaces`,
+			contents: "<?php\nnamespace Foo { class A {} }\nnamespace { class B {} }\n",
+			expected: []string{"Foo\\A", "B"},
+		},
+		{
+			name:     `Enums`,
+			contents: "<?php\nnamespace Acme;\nenum Suit: string {}\nenum Size:int {}\nenum Plain {}\n",
+			expected: []string{"Acme\\Suit", "Acme\\Size", "Acme\\Plain"},
+		},
+		{
+			name:     `Strings`,
+			contents: "<?php\n$a = 'class Single {}';\n$b = \"class Double {} \\\" class Escaped {}\";\nclass Real {}\n",
+			expected: []string{"Real"},
+		},
+		{
+			name:     `Comments`,
+			contents: "<?php\n// class Line {}\n# class Hash {}\n/* class Block {} */\n/** class Doc {} */\nclass Real {}\n",
+			expected: []string{"Hash", "Real"},
+		},
+		{
+			name:     `Heredoc`,
+			contents: "<?php\n$a = <<<EOT\nclass Heredoc {}\nEOT;\n$b = <<<\"EOT\"\nclass Quoted {}\nEOT;\n$c = <<<'EOT'\n  class Nowdoc {}\n  EOT;\nclass Real {}\n",
+			expected: []string{"Real"},
+		},
+		{
+			name:     `OutsidePHP`,
+			contents: "<p>class Html {}</p>\n<?php class Inside {} ?>\n<p>class After {}</p>\n<?php class Again {}\n",
+			expected: []string{"Inside", "Again"},
+		},
+		{
+			name:     `NotDeclarations`,
+			contents: "<?php\n$x = new class extends Base {};\n$y = new class implements Countable {};\n$class = Foo::class;\n$obj->class = 1;\nclass Real {}\n",
+			expected: []string{"Real"},
+		},
+		{
+			name:     `XHP`,
+			contents: "<?hh\nclass :ui:button-group {}\n",
+			expected: []string{"xhp_ui__button_group"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+			classes := ParseClasses(test.contents)
+			if len(test.expected) == 0 {
+				is.Equal(len(classes), 0)
+				return
+			}
+			is.Equal(classes, test.expected)
+		})
+	}
+}
+
+func TestClassMap_ScanPaths(t *testing.T) {
+	is := is2.New(t)
+
+	dir, err := filepath.Abs("testdata/classmap")
+	is.NoErr(err)
+	dir = filepath.ToSlash(dir)
+
+	classMap := NewClassMap()
+	is.NoErr(classMap.ScanPaths("testdata/classmap/src", regexp.MustCompile(`/Excluded/`)))
+	is.Equal(classMap.Classes, map[string]string{
+		"Acme\\Log\\LoggerInterface":  dir + "/src/Logger.php",
+		"Acme\\Log\\Logger":           dir + "/src/Logger.php",
+		"Acme\\Http\\RetriesRequests": dir + "/src/Http/Client.php",
+		"Acme\\Http\\Method":          dir + "/src/Http/Client.php",
+		"Acme\\Http\\Status":          dir + "/src/Http/Client.php",
+		"Acme\\Http\\Client":          dir + "/src/Http/Client.php",
+		"First\\One":                  dir + "/src/Http/Multiple.php",
+		"Second\\Two":                 dir + "/src/Http/Multiple.php",
+		"GlobalClass":                 dir + "/src/Http/Multiple.php",
+		"SkippedByPattern":            dir + "/src/Http/SkipMe.php",
+	})
+	is.Equal(classMap.Ambiguous, map[string][]string{
+		"Acme\\Log\\Logger": {dir + "/src/Tests/LoggerTest.php"},
+	})
+
+	// Duplicates in test directories are not reported.
+	is.Equal(len(classMap.AmbiguousClasses()), 0)
+	is.Equal(len(classMap.Warnings()), 0)
+
+	// Files are only scanned once.
+	is.NoErr(classMap.ScanPaths("testdata/classmap/src/Logger.php", nil))
+	is.Equal(len(classMap.Ambiguous), 1)
+
+	is.NoErr(classMap.ScanPaths("testdata/classmap/*/Duplicate.php", nil))
+	is.NoErr(classMap.ScanPaths("testdata/classmap/l*", nil))
+	is.NoErr(classMap.ScanPaths("testdata/classmap/other", nil))
+	is.Equal(classMap.Warnings(), []string{
+		`Warning: Ambiguous class resolution, "Acme_Duplicate" was found in both "` + dir + `/lib/Legacy.php" and "` + dir + `/other/Duplicate.php", the first will be used.`,
+	})
+
+	err = classMap.ScanPaths("testdata/classmap/missing", nil)
+	is.Equal(err.Error(), `could not scan for classes inside "testdata/classmap/missing" which does not appear to be a file nor a folder`)
+}
+
+func TestClassMap_Warnings(t *testing.T) {
+	is := is2.New(t)
+
+	classMap := NewClassMap()
+	classMap.add("Acme\\Foo", "/app/src/Foo.php")
+	classMap.add("Acme\\Foo", "/app/lib/Foo.php")
+	classMap.add("Acme\\Foo", "/app/legacy/Foo.php")
+	classMap.add("Acme\\Foo", "/app/fixtures/Foo.php")
+	classMap.add("Acme\\Bar", "/app/src/Bar.php")
+	classMap.add("Acme\\Bar", "/app/src/Bar.php")
+	is.Equal(classMap.Warnings(), []string{
+		`Warning: Ambiguous class resolution, "Acme\Foo" was found 3x: in "/app/src/Foo.php" and "/app/lib/Foo.php", "/app/legacy/Foo.php", the first will be used.`,
+	})
+}
+
+func TestExcludeFromClassMapPattern(t *testing.T) {
+	dir, err := filepath.Abs("testdata/classmap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	quoted := regexp.QuoteMeta(filepath.ToSlash(dir))
+
+	tests := []struct {
+		pattern  string
+		expected string
+	}{
+		{"src/Excluded/", quoted + `/src/Excluded($|/)`},
+		{"/src//Tests", quoted + `/src/Tests($|/)`},
+		{"**/Skip*.php", quoted + `/.+?/Skip[^/]+?\.php($|/)`},
+		{"./src/Tests", quoted + `/src/Tests($|/)`},
+		{"../classmap/src/Tests", quoted + `/src/Tests($|/)`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern, func(t *testing.T) {
+			is := is2.New(t)
+			is.Equal(ExcludeFromClassMapPattern(dir, test.pattern), test.expected)
+		})
+	}
+
+	// Patterns of packages that are not installed are left out.
+	is := is2.New(t)
+	is.Equal(ExcludeFromClassMapPattern(filepath.Join(dir, "missing"), "Tests"), "")
+}
+
+func TestAutoloadGenerator_ClassMap(t *testing.T) {
+	is := is2.New(t)
+
+	root, err := Load("testdata/classmap/composer.json")
+	is.NoErr(err)
+	dir, err := filepath.Abs("testdata/classmap")
+	is.NoErr(err)
+	generator := AutoloadGenerator{BaseDir: dir, Root: *root}
+
+	classMap, err := generator.ClassMap()
+	is.NoErr(err)
+	is.Equal(len(classMap.Warnings()), 1)
+
+	files, err := generator.Generate()
+	is.NoErr(err)
+	is.Equal(string(files[AutoloadClassMapFile]), `<?php
+
+// autoload_classmap.php @generated by Composer
+
+$vendorDir = dirname(__DIR__);
+$baseDir = dirname($vendorDir);
+
+return array(
+    'Acme\\Http\\Client' => $baseDir . '/src/Http/Client.php',
+    'Acme\\Http\\Method' => $baseDir . '/src/Http/Client.php',
+    'Acme\\Http\\RetriesRequests' => $baseDir . '/src/Http/Client.php',
+    'Acme\\Http\\Status' => $baseDir . '/src/Http/Client.php',
+    'Acme\\Log\\Logger' => $baseDir . '/src/Logger.php',
+    'Acme\\Log\\LoggerInterface' => $baseDir . '/src/Logger.php',
+    'Acme_Duplicate' => $baseDir . '/lib/Legacy.php',
+    'Composer\\InstalledVersions' => $vendorDir . '/composer/InstalledVersions.php',
+    'First\\One' => $baseDir . '/src/Http/Multiple.php',
+    'GlobalClass' => $baseDir . '/src/Http/Multiple.php',
+    'Legacy_Logger' => $baseDir . '/lib/Legacy.php',
+    'Second\\Two' => $baseDir . '/src/Http/Multiple.php',
+);
+`)
+	is.True(strings.Contains(string(files[AutoloadStaticFile]), "        'Acme\\\\Http\\\\Client' => __DIR__ . '/../..' . '/src/Http/Client.php',\n"))
+}
+
+func TestClassMap_ScanNamespacePaths(t *testing.T) {
+	is := is2.New(t)
+
+	dir, err := filepath.Abs("testdata/psr")
+	is.NoErr(err)
+	dir = filepath.ToSlash(dir)
+
+	classMap := NewClassMap()
+	is.NoErr(classMap.ScanNamespacePaths("testdata/psr/src", nil, "psr-4", `Acme\`))
+	is.NoErr(classMap.ScanNamespacePaths("testdata/psr/lib", nil, "psr-0", "Legacy_"))
+	is.Equal(classMap.Classes, map[string]string{
+		`Acme\Helpers`:     dir + "/src/Helpers.php",
+		`Acme\Http\Client`: dir + "/src/Http/Client.php",
+		`Acme\Logger`:      dir + "/src/Logger.php",
+		"Legacy_Logger":    dir + "/lib/Legacy/Logger.php",
+	})
+	is.Equal(classMap.PSRViolations, []PSRViolation{
+		{Class: `Acme\Http\Right`, Path: dir + "/src/Http/Wrong.php", Type: "psr-4", Namespace: `Acme\`, Dir: dir + "/src"},
+		{Class: "Legacy_Good", Path: dir + "/lib/Legacy/Bad.php", Type: "psr-0", Namespace: "Legacy_", Dir: dir + "/lib"},
+	})
+
+	// Files without valid classes are scanned again by other rules.
+	is.NoErr(classMap.ScanNamespacePaths("testdata/psr/src/Http", nil, "psr-4", `Acme\Http\`))
+	is.Equal(len(classMap.PSRViolations), 3)
+
+	classMap.ClearPSRViolations(dir + "/lib")
+	is.Equal(len(classMap.PSRViolations), 2)
+	is.Equal(classMap.PSRViolations[0].Class, `Acme\Http\Right`)
+
+	err = classMap.ScanNamespacePaths("testdata/psr/src", nil, "classmap", "")
+	is.Equal(err.Error(), `invalid autoload type "classmap", expected "psr-4" or "psr-0"`)
+}
+
+func TestPSRViolation_Message(t *testing.T) {
+	is := is2.New(t)
+
+	violation := PSRViolation{
+		Class:     `Acme\Http\Right`,
+		Path:      "/app/src/Http/Wrong.php",
+		Type:      "psr-4",
+		Namespace: `Acme\`,
+		Dir:       "/app/src",
+	}
+	is.Equal(violation.Message("/app"), `Class Acme\Http\Right located in ./src/Http/Wrong.php does not comply with psr-4 autoloading standard (rule: Acme\ => ./src). Skipping.`)
+	is.Equal(violation.Message("/srv"), `Class Acme\Http\Right located in /app/src/Http/Wrong.php does not comply with psr-4 autoloading standard (rule: Acme\ => /app/src). Skipping.`)
+}
@@ -0,0 +1,571 @@
+package gocomposer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Default values Composer uses for config options that are not set.
+const (
+	DefaultProcessTimeout    = 300
+	DefaultPreferredInstall  = "dist"
+	DefaultVendorDir         = "vendor"
+	DefaultBinDir            = "{$vendor-dir}/bin"
+	DefaultDataDir           = "{$home}"
+	DefaultCacheDir          = "{$home}/cache"
+	DefaultCacheFilesDir     = "{$cache-dir}/files"
+	DefaultCacheRepoDir      = "{$cache-dir}/repo"
+	DefaultCacheVCSDir       = "{$cache-dir}/vcs"
+	DefaultCacheTTL          = 15552000
+	DefaultCacheFilesMaxSize = "300MiB"
+	DefaultBinCompat         = "auto"
+	DefaultArchiveFormat     = "tar"
+	DefaultArchiveDir        = "."
+	DefaultAuditAbandoned    = "fail"
+)
+
+// GetProcessTimeout returns the process-timeout option in seconds.
+func (c Config) GetProcessTimeout() int {
+	if c.ProcessTimeout == nil {
+		return DefaultProcessTimeout
+	}
+	return *c.ProcessTimeout
+}
+
+// GetUseIncludePath returns the use-include-path option.
+func (c Config) GetUseIncludePath() bool {
+	return boolOrDefault(c.UseIncludePath, false)
+}
+
+// GetUseParentDir returns the use-parent-dir option.
+func (c Config) GetUseParentDir() StringOrBool {
+	return stringOrBoolOrDefault(c.UseParentDir, StringOrBoolFromString("prompt"))
+}
+
+// GetPreferredInstall returns the install method Composer prefers for the named
+// package. When preferred-install is an object the first matching pattern is used and
+// "auto" is returned if no pattern matches.
+func (c Config) GetPreferredInstall(name string) string {
+	if len(c.PreferredInstall) == 0 {
+		return DefaultPreferredInstall
+	}
+	for _, preference := range c.PreferredInstall {
+		if preference[0] == "" || matchPackagePattern(preference[0], name) {
+			return preference[1]
+		}
+	}
+	return "auto"
+}
+
+// AllowsPlugin returns whether the named package is allowed to run as a plugin. The
+// second value is false if allow-plugins does not decide, in which case Composer
+// prompts the user.
+func (c Config) AllowsPlugin(name string) (allowed bool, configured bool) {
+	if c.AllowPlugins == nil {
+		return false, false
+	}
+	return c.AllowPlugins.Allows(name)
+}
+
+// GetAuditAbandoned returns how the audit command treats abandoned packages, one of
+// ignore, report or fail.
+func (c Config) GetAuditAbandoned() string {
+	if c.Audit == nil || c.Audit.Abandoned == "" {
+		return DefaultAuditAbandoned
+	}
+	return c.Audit.Abandoned
+}
+
+// GetStoreAuths returns the store-auths option.
+func (c Config) GetStoreAuths() StringOrBool {
+	return stringOrBoolOrDefault(c.StoreAuths, StringOrBoolFromString("prompt"))
+}
+
+// GetGithubProtocols returns the protocols to use when cloning from github.com.
+func (c Config) GetGithubProtocols() []string {
+	if c.GithubProtocols == nil {
+		return []string{"https", "ssh", "git"}
+	}
+	return c.GithubProtocols
+}
+
+// GetGithubDomains returns the domains to use in github mode.
+func (c Config) GetGithubDomains() []string {
+	if c.GithubDomains == nil {
+		return []string{"github.com"}
+	}
+	return c.GithubDomains
+}
+
+// GetGitlabDomains returns the domains of GitLab servers.
+func (c Config) GetGitlabDomains() []string {
+	if c.GitlabDomains == nil {
+		return []string{"gitlab.com"}
+	}
+	return c.GitlabDomains
+}
+
+// GetDisableTLS returns the disable-tls option.
+func (c Config) GetDisableTLS() bool {
+	return boolOrDefault(c.DisableTLS, false)
+}
+
+// GetSecureHTTP returns the secure-http option.
+func (c Config) GetSecureHTTP() bool {
+	return boolOrDefault(c.SecureHTTP, true)
+}
+
+// GetVendorDir returns the vendor-dir option without trailing slashes.
+func (c Config) GetVendorDir() string {
+	return c.dir("vendor-dir", 0)
+}
+
+// GetBinDir returns the bin-dir option with "{$vendor-dir}" replaced.
+func (c Config) GetBinDir() string {
+	return c.dir("bin-dir", 0)
+}
+
+// GetDataDir returns the data-dir option. "{$home}" is left for the caller to replace
+// because the Composer home directory depends on the environment.
+func (c Config) GetDataDir() string {
+	return c.dir("data-dir", 0)
+}
+
+// GetCacheDir returns the cache-dir option. See GetDataDir for "{$home}".
+func (c Config) GetCacheDir() string {
+	return c.dir("cache-dir", 0)
+}
+
+// GetCacheFilesDir returns the cache-files-dir option with "{$cache-dir}" replaced.
+func (c Config) GetCacheFilesDir() string {
+	return c.dir("cache-files-dir", 0)
+}
+
+// GetCacheRepoDir returns the cache-repo-dir option with "{$cache-dir}" replaced.
+func (c Config) GetCacheRepoDir() string {
+	return c.dir("cache-repo-dir", 0)
+}
+
+// GetCacheVCSDir returns the cache-vcs-dir option with "{$cache-dir}" replaced.
+func (c Config) GetCacheVCSDir() string {
+	return c.dir("cache-vcs-dir", 0)
+}
+
+// GetCacheTTL returns the cache-ttl option in seconds.
+func (c Config) GetCacheTTL() int {
+	if c.CacheTTL == nil {
+		return DefaultCacheTTL
+	}
+	return *c.CacheTTL
+}
+
+// GetCacheFilesTTL returns the cache-files-ttl option in seconds, which falls back to
+// cache-ttl.
+func (c Config) GetCacheFilesTTL() int {
+	if c.CacheFilesTTL == nil {
+		return c.GetCacheTTL()
+	}
+	return *c.CacheFilesTTL
+}
+
+// cacheSizeRegex matches a cache-files-maxsize value like "300MiB" or "1.5G".
+var cacheSizeRegex = regexp.MustCompile(`(?i)^\s*([0-9.]+)\s*(?:([kmg])(?:i?b)?)?\s*$`)
+
+// GetCacheFilesMaxSize returns the cache-files-maxsize option in bytes.
+func (c Config) GetCacheFilesMaxSize() (int64, error) {
+	value := DefaultCacheFilesMaxSize
+	if c.CacheFilesMaxSize != nil {
+		value = c.CacheFilesMaxSize.String()
+	}
+	matches := cacheSizeRegex.FindStringSubmatch(value)
+	if matches == nil {
+		return 0, fmt.Errorf("could not parse the value of 'cache-files-maxsize': %s", value)
+	}
+	size, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse the value of 'cache-files-maxsize': %s", value)
+	}
+	switch strings.ToLower(matches[2]) {
+	case "g":
+		size *= 1024 * 1024 * 1024
+	case "m":
+		size *= 1024 * 1024
+	case "k":
+		size *= 1024
+	}
+	return int64(math.Max(0, size)), nil
+}
+
+// GetCacheReadOnly returns the cache-read-only option.
+func (c Config) GetCacheReadOnly() bool {
+	return boolOrDefault(c.CacheReadOnly, false)
+}
+
+// GetBinCompat returns the bin-compat option.
+func (c Config) GetBinCompat() string {
+	return stringOrDefault(c.BinCompat, DefaultBinCompat)
+}
+
+// GetPrependAutoloader returns the prepend-autoloader option.
+func (c Config) GetPrependAutoloader() bool {
+	return boolOrDefault(c.PrependAutoloader, true)
+}
+
+// GetOptimizeAutoloader returns whether the autoloader is optimized, which is also
+// implied by classmap-authoritative.
+func (c Config) GetOptimizeAutoloader() bool {
+	return boolOrDefault(c.OptimizeAutoloader, false) || c.GetClassmapAuthoritative()
+}
+
+// GetSortPackages returns the sort-packages option.
+func (c Config) GetSortPackages() bool {
+	return boolOrDefault(c.SortPackages, false)
+}
+
+// GetClassmapAuthoritative returns the classmap-authoritative option.
+func (c Config) GetClassmapAuthoritative() bool {
+	return boolOrDefault(c.ClassmapAuthoritative, false)
+}
+
+// GetAPCuAutoloader returns the apcu-autoloader option.
+func (c Config) GetAPCuAutoloader() bool {
+	return boolOrDefault(c.APCuAutoloader, false)
+}
+
+// GetGithubExposeHostname returns the github-expose-hostname option.
+func (c Config) GetGithubExposeHostname() bool {
+	return boolOrDefault(c.GithubExposeHostname, true)
+}
+
+// GetUseGithubAPI returns the use-github-api option.
+func (c Config) GetUseGithubAPI() bool {
+	return boolOrDefault(c.UseGithubAPI, true)
+}
+
+// GetNotifyOnInstall returns the notify-on-install option.
+func (c Config) GetNotifyOnInstall() bool {
+	return boolOrDefault(c.NotifyOnInstall, true)
+}
+
+// GetDiscardChanges returns the discard-changes option.
+func (c Config) GetDiscardChanges() StringOrBool {
+	return stringOrBoolOrDefault(c.DiscardChanges, StringOrBoolFromBool(false))
+}
+
+// GetArchiveFormat returns the archive-format option.
+func (c Config) GetArchiveFormat() string {
+	return stringOrDefault(c.ArchiveFormat, DefaultArchiveFormat)
+}
+
+// GetArchiveDir returns the archive-dir option.
+func (c Config) GetArchiveDir() string {
+	return c.dir("archive-dir", 0)
+}
+
+// GetHtaccessProtect returns the htaccess-protect option.
+func (c Config) GetHtaccessProtect() bool {
+	return boolOrDefault(c.HtaccessProtect, true)
+}
+
+// GetLock returns the lock option.
+func (c Config) GetLock() bool {
+	return boolOrDefault(c.Lock, true)
+}
+
+// GetPlatformCheck returns the platform-check option.
+func (c Config) GetPlatformCheck() StringOrBool {
+	return stringOrBoolOrDefault(c.PlatformCheck, StringOrBoolFromString("php-only"))
+}
+
+// GetBumpAfterUpdate returns the bump-after-update option.
+func (c Config) GetBumpAfterUpdate() StringOrBool {
+	return stringOrBoolOrDefault(c.BumpAfterUpdate, StringOrBoolFromBool(false))
+}
+
+// GetAllowMissingRequirements returns the allow-missing-requirements option.
+func (c Config) GetAllowMissingRequirements() bool {
+	return boolOrDefault(c.AllowMissingRequirements, false)
+}
+
+// configVarRegex matches a reference to another config option like "{$vendor-dir}".
+var configVarRegex = regexp.MustCompile(`\{\$([a-z-]+)}`)
+
+// dir returns a directory option with references to other options replaced and
+// trailing slashes removed, the same way Composer does. References to options that are
+// not directories, like "{$home}", are left as they are.
+func (c Config) dir(key string, depth int) string {
+	var value string
+	switch key {
+	case "vendor-dir":
+		value = stringOrDefault(c.VendorDir, DefaultVendorDir)
+	case "bin-dir":
+		value = stringOrDefault(c.BinDir, DefaultBinDir)
+	case "data-dir":
+		value = stringOrDefault(c.DataDir, DefaultDataDir)
+	case "cache-dir":
+		value = stringOrDefault(c.CacheDir, DefaultCacheDir)
+	case "cache-files-dir":
+		value = stringOrDefault(c.CacheFilesDir, DefaultCacheFilesDir)
+	case "cache-repo-dir":
+		value = stringOrDefault(c.CacheRepoDir, DefaultCacheRepoDir)
+	case "cache-vcs-dir":
+		value = stringOrDefault(c.CacheVCSDir, DefaultCacheVCSDir)
+	case "archive-dir":
+		value = stringOrDefault(c.ArchiveDir, DefaultArchiveDir)
+	default:
+		return "{$" + key + "}"
+	}
+
+	// A limit on the depth stops options that reference each other from looping.
+	if depth < 10 {
+		value = configVarRegex.ReplaceAllStringFunc(value, func(ref string) string {
+			return c.dir(ref[2:len(ref)-1], depth+1)
+		})
+	}
+	trimmed := strings.TrimRight(value, `/\`)
+	if trimmed == "" {
+		return value
+	}
+	return trimmed
+}
+
+func boolOrDefault(value *bool, def bool) bool {
+	if value == nil {
+		return def
+	}
+	return *value
+}
+
+func stringOrDefault(value string, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
+
+func stringOrBoolOrDefault(value *StringOrBool, def StringOrBool) StringOrBool {
+	if value == nil {
+		return def
+	}
+	return *value
+}
+
+// matchPackagePattern returns true if the package name matches the pattern, where "*"
+// matches any sequence of characters. Matching is case-insensitive like Composer's
+// package name patterns.
+func matchPackagePattern(pattern string, name string) bool {
+	expr := strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, `.*`)
+	matched, err := regexp.MatchString(`(?i)^`+expr+`$`, name)
+	return err == nil && matched
+}
+
+// AllowPlugins is the allow-plugins config option. It is either true or false to allow
+// or deny all plugins, or an object of package name patterns (keys) and whether the
+// matching packages are allowed (values). Because the first matching pattern wins the
+// order of the rules is kept.
+type AllowPlugins struct {
+	// All is set when allow-plugins is a boolean.
+	All *bool
+
+	// Rules of package name patterns in the order they are matched.
+	Rules []AllowPluginRule
+}
+
+// AllowPluginRule is a package name pattern and whether matching plugins are allowed.
+type AllowPluginRule struct {
+	Pattern string
+	Allow   bool
+}
+
+// Allows returns whether the named package is allowed to run as a plugin. The second
+// value is false if no rule matches the package.
+func (a AllowPlugins) Allows(name string) (allowed bool, configured bool) {
+	if a.All != nil {
+		return *a.All, true
+	}
+	for _, rule := range a.Rules {
+		if matchPackagePattern(rule.Pattern, name) {
+			return rule.Allow, true
+		}
+	}
+	return false, false
+}
+
+func (a *AllowPlugins) UnmarshalJSON(data []byte) error {
+	*a = AllowPlugins{}
+	switch string(data) {
+	case "true", "false":
+		all := string(data) == "true"
+		a.All = &all
+		return nil
+	}
+	if isArray(data) && len(strings.TrimSpace(string(data[1:len(data)-1]))) == 0 {
+		// PHP encodes an empty associative array as [].
+		return nil
+	}
+	if !isObject(data) {
+		return errors.New("allow-plugins must be a boolean or an object")
+	}
+	temp, err := decodeOrdered(data)
+	if err != nil {
+		return err
+	}
+	obj := temp.(*orderedObject)
+	for _, pattern := range obj.keys {
+		allow, ok := obj.values[pattern].(bool)
+		if !ok {
+			return fmt.Errorf("allow-plugins pattern %q must have a boolean value", pattern)
+		}
+		a.Rules = append(a.Rules, AllowPluginRule{Pattern: pattern, Allow: allow})
+	}
+	return nil
+}
+
+func (a AllowPlugins) MarshalJSON() ([]byte, error) {
+	if a.All != nil {
+		return json.Marshal(*a.All)
+	}
+
+	// Order matters so we serialize the JSON ourselves.
+	buf := strings.Builder{}
+	buf.WriteRune('{')
+	for i, rule := range a.Rules {
+		pattern, err := json.Marshal(rule.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(fmt.Sprintf(`%s:%t`, pattern, rule.Allow))
+		if i < len(a.Rules)-1 {
+			buf.WriteRune(',')
+		}
+	}
+	buf.WriteRune('}')
+	return []byte(buf.String()), nil
+}
+
+// AuditConfig holds the options of the audit command.
+type AuditConfig struct {
+	// Security advisory IDs, CVE IDs or package names that are ignored, with an
+	// optional reason.
+	Ignore AuditIgnore `json:"ignore,omitempty"`
+
+	// How abandoned packages are treated, one of ignore, report or fail. Defaults to
+	// fail.
+	Abandoned string `json:"abandoned,omitempty"`
+
+	// Severities of advisories that are ignored, e.g. ["low"].
+	IgnoreSeverity []string `json:"ignore-severity,omitempty"`
+
+	// If true, repositories that cannot be reached are ignored by the audit.
+	IgnoreUnreachable *bool `json:"ignore-unreachable,omitempty"`
+
+	// Keys that are not modeled by this struct, see decodeUnknown.
+	Unknown map[string]json.RawMessage `json:"-"`
+}
+
+func (a *AuditConfig) UnmarshalJSON(data []byte) error {
+	type plain AuditConfig
+	if err := json.Unmarshal(data, (*plain)(a)); err != nil {
+		return err
+	}
+	unknown, err := decodeUnknown(data, a)
+	if err != nil {
+		return err
+	}
+	a.Unknown = unknown
+	return nil
+}
+
+func (a AuditConfig) MarshalJSON() ([]byte, error) {
+	type plain AuditConfig
+	data, err := json.Marshal(plain(a))
+	if err != nil {
+		return nil, err
+	}
+	return encodeUnknown(data, a.Unknown, a)
+}
+
+// AuditIgnore is an object of advisory IDs (keys) and the reason they are ignored
+// (values). In composer.json it can also be an array of IDs without a reason.
+type AuditIgnore map[string]string
+
+func (a *AuditIgnore) UnmarshalJSON(data []byte) error {
+	*a = AuditIgnore{}
+	if isArray(data) {
+		ids := make([]string, 0)
+		if err := json.Unmarshal(data, &ids); err != nil {
+			return err
+		}
+		for _, id := range ids {
+			(*a)[id] = ""
+		}
+		return nil
+	}
+	return json.Unmarshal(data, (*map[string]string)(a))
+}
+
+func (a AuditIgnore) MarshalJSON() ([]byte, error) {
+	ids := make([]string, 0, len(a))
+	for id, reason := range a {
+		if reason != "" {
+			return json.Marshal(map[string]string(a))
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return json.Marshal(ids)
+}
+
+// GitlabToken is a GitLab private token. In composer.json it is either the token
+// string or an object with a username and token.
+type GitlabToken struct {
+	Username string `json:"username,omitempty"`
+	Token    string `json:"token"`
+}
+
+func (g *GitlabToken) UnmarshalJSON(data []byte) error {
+	if isString(data) {
+		*g = GitlabToken{}
+		return json.Unmarshal(data, &g.Token)
+	}
+	type plain GitlabToken
+	return json.Unmarshal(data, (*plain)(g))
+}
+
+func (g GitlabToken) MarshalJSON() ([]byte, error) {
+	if g.Username == "" {
+		return json.Marshal(g.Token)
+	}
+	type plain GitlabToken
+	return json.Marshal(plain(g))
+}
+
+// BitbucketOAuth is a Bitbucket OAuth consumer.
+type BitbucketOAuth struct {
+	ConsumerKey           string `json:"consumer-key"`
+	ConsumerSecret        string `json:"consumer-secret"`
+	AccessToken           string `json:"access-token,omitempty"`
+	AccessTokenExpiration int64  `json:"access-token-expiration,omitempty"`
+}
+
+// HTTPBasicAuth is a username and password used for HTTP basic authentication.
+type HTTPBasicAuth struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// ClientCertificate is a TLS client certificate used to authenticate with a domain.
+type ClientCertificate struct {
+	// Path to the certificate file, which may include the private key.
+	LocalCert string `json:"local_cert"`
+	// Path to the private key if it is not included in local_cert.
+	LocalPK string `json:"local_pk,omitempty"`
+	// Passphrase of the private key.
+	Passphrase string `json:"passphrase,omitempty"`
+}
@@ -0,0 +1,320 @@
+package gocomposer
+
+import (
+	"encoding/json"
+	is2 "github.com/matryer/is"
+	"os"
+	"testing"
+)
+
+func TestConfig_Defaults(t *testing.T) {
+	is := is2.New(t)
+
+	c := Config{}
+
+	is.Equal(c.GetProcessTimeout(), 300)
+	is.Equal(c.GetUseIncludePath(), false)
+	is.Equal(c.GetUseParentDir(), StringOrBoolFromString("prompt"))
+	is.Equal(c.GetPreferredInstall("acme/blog"), "dist")
+	is.Equal(c.GetAuditAbandoned(), "fail")
+	is.Equal(c.GetStoreAuths(), StringOrBoolFromString("prompt"))
+	is.Equal(c.GetGithubProtocols(), []string{"https", "ssh", "git"})
+	is.Equal(c.GetGithubDomains(), []string{"github.com"})
+	is.Equal(c.GetGitlabDomains(), []string{"gitlab.com"})
+	is.Equal(c.GetDisableTLS(), false)
+	is.Equal(c.GetSecureHTTP(), true)
+	is.Equal(c.GetVendorDir(), "vendor")
+	is.Equal(c.GetBinDir(), "vendor/bin")
+	is.Equal(c.GetDataDir(), "{$home}")
+	is.Equal(c.GetCacheDir(), "{$home}/cache")
+	is.Equal(c.GetCacheFilesDir(), "{$home}/cache/files")
+	is.Equal(c.GetCacheRepoDir(), "{$home}/cache/repo")
+	is.Equal(c.GetCacheVCSDir(), "{$home}/cache/vcs")
+	is.Equal(c.GetCacheTTL(), 15552000)
+	is.Equal(c.GetCacheFilesTTL(), 15552000)
+	size, err := c.GetCacheFilesMaxSize()
+	is.NoErr(err)
+	is.Equal(size, int64(300*1024*1024))
+	is.Equal(c.GetCacheReadOnly(), false)
+	is.Equal(c.GetBinCompat(), "auto")
+	is.Equal(c.GetPrependAutoloader(), true)
+	is.Equal(c.GetOptimizeAutoloader(), false)
+	is.Equal(c.GetSortPackages(), false)
+	is.Equal(c.GetClassmapAuthoritative(), false)
+	is.Equal(c.GetAPCuAutoloader(), false)
+	is.Equal(c.GetGithubExposeHostname(), true)
+	is.Equal(c.GetUseGithubAPI(), true)
+	is.Equal(c.GetNotifyOnInstall(), true)
+	is.Equal(c.GetDiscardChanges(), StringOrBoolFromBool(false))
+	is.Equal(c.GetArchiveFormat(), "tar")
+	is.Equal(c.GetArchiveDir(), ".")
+	is.Equal(c.GetHtaccessProtect(), true)
+	is.Equal(c.GetLock(), true)
+	is.Equal(c.GetPlatformCheck(), StringOrBoolFromString("php-only"))
+	is.Equal(c.GetBumpAfterUpdate(), StringOrBoolFromBool(false))
+	is.Equal(c.GetAllowMissingRequirements(), false)
+
+	allowed, configured := c.AllowsPlugin("composer/installers")
+	is.True(!allowed)
+	is.True(!configured)
+}
+
+func TestConfig_Values(t *testing.T) {
+	is := is2.New(t)
+
+	data, err := os.ReadFile("testdata/config.json")
+	is.NoErr(err)
+
+	c := Config{}
+	is.NoErr(json.Unmarshal(data, &c))
+
+	is.Equal(c.GetProcessTimeout(), 0)
+	is.Equal(c.GetUseIncludePath(), false)
+	is.Equal(c.GetPreferredInstall("acme/blog"), "source")
+	is.Equal(c.GetPreferredInstall("monolog/monolog"), "dist")
+	is.Equal(c.GetAuditAbandoned(), "report")
+	is.Equal(c.Audit.Ignore, AuditIgnore{"CVE-2022-24828": "Not exploitable here"})
+	is.Equal(c.GetStoreAuths(), StringOrBoolFromBool(false))
+	is.Equal(c.GetGithubProtocols(), []string{"https"})
+	is.Equal(c.GithubOAuth["github.com"], "ghp_token")
+	is.Equal(c.GitlabToken["gitlab.com"], GitlabToken{Token: "glpat-token"})
+	is.Equal(c.GitlabToken["gitlab.acme.test"], GitlabToken{Username: "ci", Token: "glpat-acme"})
+	is.Equal(c.GetSecureHTTP(), false)
+	is.Equal(c.BitbucketOAuth["bitbucket.org"].ConsumerKey, "key")
+	is.Equal(c.HTTPBasic["repo.acme.test"], HTTPBasicAuth{Username: "user", Password: "pass"})
+	is.Equal(c.Bearer["api.acme.test"], "token")
+	is.Equal(c.GetVendorDir(), "lib/vendor")
+	is.Equal(c.GetBinDir(), "lib/vendor/commands")
+	is.Equal(c.GetCacheFilesDir(), "/tmp/composer-cache/files")
+	is.Equal(c.GetCacheTTL(), 15552000)
+	is.Equal(c.GetCacheFilesTTL(), 3600)
+	size, err := c.GetCacheFilesMaxSize()
+	is.NoErr(err)
+	is.Equal(size, int64(1.5*1024*1024*1024))
+	is.Equal(c.GetOptimizeAutoloader(), true) // implied by classmap-authoritative
+	is.Equal(c.GetSortPackages(), true)
+	is.Equal(c.GetDiscardChanges(), StringOrBoolFromString("stash"))
+	is.Equal(c.GetArchiveFormat(), "zip")
+	is.Equal(c.GetLock(), false)
+	is.Equal(c.GetBumpAfterUpdate(), StringOrBoolFromString("dev"))
+	is.Equal(string(c.Unknown["x-acme-option"]), "1")
+
+	// Every key must be written back with the same value.
+	output, err := json.Marshal(c)
+	is.NoErr(err)
+	expected := map[string]interface{}{}
+	is.NoErr(json.Unmarshal(data, &expected))
+	result := map[string]interface{}{}
+	is.NoErr(json.Unmarshal(output, &result))
+	is.Equal(result, expected)
+}
+
+func TestConfig_dir_Loop(t *testing.T) {
+	is := is2.New(t)
+
+	c := Config{VendorDir: "{$bin-dir}/vendor", BinDir: "{$vendor-dir}/bin"}
+
+	// References that loop must not recurse forever.
+	is.True(len(c.GetVendorDir()) > 0)
+}
+
+func TestConfig_GetCacheFilesMaxSize(t *testing.T) {
+	tests := []struct {
+		name  string
+		value StringOrInt
+		size  int64
+		err   bool
+	}{
+		{
+			name:  `Bytes`,
+			value: StringOrIntFromInt(1024),
+			size:  1024,
+		},
+		{
+			name:  `Kilobytes`,
+			value: StringOrIntFromString("512k"),
+			size:  512 * 1024,
+		},
+		{
+			name:  `Megabytes`,
+			value: StringOrIntFromString("100 MB"),
+			size:  100 * 1024 * 1024,
+		},
+		{
+			name:  `Gigabytes`,
+			value: StringOrIntFromString("2GiB"),
+			size:  2 * 1024 * 1024 * 1024,
+		},
+		{
+			name:  `Invalid`,
+			value: StringOrIntFromString("lots"),
+			err:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			value := test.value
+			size, err := Config{CacheFilesMaxSize: &value}.GetCacheFilesMaxSize()
+			if test.err {
+				is.True(err != nil)
+				return
+			}
+			is.NoErr(err)
+			is.Equal(size, test.size)
+		})
+	}
+}
+
+func TestAllowPlugins_Allows(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		pkg        string
+		allowed    bool
+		configured bool
+	}{
+		{
+			name:       `True`,
+			input:      `true`,
+			pkg:        `acme/plugin`,
+			allowed:    true,
+			configured: true,
+		},
+		{
+			name:       `False`,
+			input:      `false`,
+			pkg:        `acme/plugin`,
+			allowed:    false,
+			configured: true,
+		},
+		{
+			name:       `FirstMatchWins`,
+			input:      `{"acme/*": false, "*/plugin": true}`,
+			pkg:        `acme/plugin`,
+			allowed:    false,
+			configured: true,
+		},
+		{
+			name:       `CaseInsensitive`,
+			input:      `{"Acme/Plugin": true}`,
+			pkg:        `acme/plugin`,
+			allowed:    true,
+			configured: true,
+		},
+		{
+			name:       `NoMatch`,
+			input:      `{"acme/*": true}`,
+			pkg:        `other/plugin`,
+			allowed:    false,
+			configured: false,
+		},
+		{
+			name:       `EmptyArray`,
+			input:      `[]`,
+			pkg:        `acme/plugin`,
+			allowed:    false,
+			configured: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			result := AllowPlugins{}
+			is.NoErr(json.Unmarshal([]byte(test.input), &result))
+
+			allowed, configured := result.Allows(test.pkg)
+			is.Equal(allowed, test.allowed)
+			is.Equal(configured, test.configured)
+		})
+	}
+}
+
+func TestAllowPlugins_MarshalJSON(t *testing.T) {
+	is := is2.New(t)
+
+	all := true
+	data, err := json.Marshal(AllowPlugins{All: &all})
+	is.NoErr(err)
+	is.Equal(string(data), `true`)
+
+	data, err = json.Marshal(AllowPlugins{Rules: []AllowPluginRule{
+		{Pattern: "z/*", Allow: true},
+		{Pattern: "a/*", Allow: false},
+	}})
+	is.NoErr(err)
+	is.Equal(string(data), `{"z/*":true,"a/*":false}`)
+}
+
+func TestAuditIgnore_JSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		output string
+		value  AuditIgnore
+	}{
+		{
+			name:   `Array`,
+			input:  `["PKSA-1", "CVE-2"]`,
+			output: `["CVE-2","PKSA-1"]`,
+			value:  AuditIgnore{"PKSA-1": "", "CVE-2": ""},
+		},
+		{
+			name:   `Object`,
+			input:  `{"CVE-2": "Not used"}`,
+			output: `{"CVE-2":"Not used"}`,
+			value:  AuditIgnore{"CVE-2": "Not used"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			result := AuditIgnore{}
+			is.NoErr(json.Unmarshal([]byte(test.input), &result))
+			is.Equal(result, test.value)
+
+			data, err := json.Marshal(result)
+			is.NoErr(err)
+			is.Equal(string(data), test.output)
+		})
+	}
+}
+
+func TestStringOrInt_JSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		value StringOrInt
+	}{
+		{
+			name:  `Int`,
+			input: `1024`,
+			value: StringOrIntFromInt(1024),
+		},
+		{
+			name:  `String`,
+			input: `"300MiB"`,
+			value: StringOrIntFromString("300MiB"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			result := StringOrInt{}
+			is.NoErr(json.Unmarshal([]byte(test.input), &result))
+			is.Equal(result, test.value)
+
+			data, err := json.Marshal(result)
+			is.NoErr(err)
+			is.Equal(string(data), test.input)
+		})
+	}
+}
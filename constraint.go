@@ -0,0 +1,590 @@
+package gocomposer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Constraint operators used by SingleConstraint.
+const (
+	OpEqual        = "=="
+	OpNotEqual     = "!="
+	OpLess         = "<"
+	OpLessEqual    = "<="
+	OpGreater      = ">"
+	OpGreaterEqual = ">="
+)
+
+// Constraint is a parsed version constraint such as "^1.2 || ~2.0.3".
+type Constraint interface {
+	// Matches returns true if the given version satisfies the constraint. The version
+	// is normalized first, so "v1.2", "1.2.0" and "1.2.0.0" are all equivalent. Invalid
+	// versions never match.
+	Matches(version string) bool
+
+	// Intersects returns true if at least one version could satisfy both this
+	// constraint and other. This is how Composer matches a requirement against the
+	// constraints a package provides or replaces.
+	Intersects(other Constraint) bool
+
+	// String returns the constraint in the same format Composer uses when dumping
+	// constraints, e.g. "[>= 1.2.0.0-dev < 2.0.0.0-dev]".
+	String() string
+}
+
+// SingleConstraint compares a version against a single normalized version with one of
+// the Op constants.
+type SingleConstraint struct {
+	Operator string
+	Version  string
+}
+
+// NewSingleConstraint creates a SingleConstraint, converting the operator aliases "="
+// and "<>" to "==" and "!=".
+func NewSingleConstraint(operator, version string) (*SingleConstraint, error) {
+	switch operator {
+	case "=", OpEqual:
+		operator = OpEqual
+	case "<>", OpNotEqual:
+		operator = OpNotEqual
+	case OpLess, OpLessEqual, OpGreater, OpGreaterEqual:
+	default:
+		return nil, fmt.Errorf(`invalid operator "%s"`, operator)
+	}
+	return &SingleConstraint{Operator: operator, Version: version}, nil
+}
+
+func (c *SingleConstraint) Matches(version string) bool {
+	normalized, err := NormalizeVersion(version)
+	if err != nil {
+		return false
+	}
+	return c.Intersects(&SingleConstraint{Operator: OpEqual, Version: normalized})
+}
+
+func (c *SingleConstraint) Intersects(other Constraint) bool {
+	if provider, ok := other.(*SingleConstraint); ok {
+		return c.matchSpecific(provider)
+	}
+	// Turn the matching around to find a match.
+	return other.Intersects(c)
+}
+
+func (c *SingleConstraint) String() string {
+	return c.Operator + " " + c.Version
+}
+
+// matchSpecific is a port of Composer's Constraint::matchSpecific.
+func (c *SingleConstraint) matchSpecific(provider *SingleConstraint) bool {
+	noEqualOp := strings.ReplaceAll(c.Operator, "=", "")
+	providerNoEqualOp := strings.ReplaceAll(provider.Operator, "=", "")
+
+	isEqualOp := c.Operator == OpEqual
+	isNonEqualOp := c.Operator == OpNotEqual
+	isProviderEqualOp := provider.Operator == OpEqual
+	isProviderNonEqualOp := provider.Operator == OpNotEqual
+
+	// The '!=' operator matches when the other operator is not '==' or the version does
+	// not match. These kinds of comparisons always have a solution.
+	if isNonEqualOp || isProviderNonEqualOp {
+		if isNonEqualOp && !isProviderNonEqualOp && !isProviderEqualOp && strings.HasPrefix(provider.Version, "dev-") {
+			return false
+		}
+		if isProviderNonEqualOp && !isNonEqualOp && !isEqualOp && strings.HasPrefix(c.Version, "dev-") {
+			return false
+		}
+		if !isEqualOp && !isProviderEqualOp {
+			return true
+		}
+		return versionCompare(provider.Version, c.Version, OpNotEqual, false)
+	}
+
+	// An example for the condition is <= 2.0 & < 1.0. These kinds of comparisons always
+	// have a solution.
+	if c.Operator != OpEqual && noEqualOp == providerNoEqualOp {
+		return !(strings.HasPrefix(c.Version, "dev-") || strings.HasPrefix(provider.Version, "dev-"))
+	}
+
+	version1, version2, operator := provider.Version, c.Version, c.Operator
+	if isEqualOp {
+		version1, version2, operator = c.Version, provider.Version, provider.Operator
+	}
+
+	if versionCompare(version1, version2, operator, false) {
+		// Special case, e.g. require >= 1.0 and provide < 1.0. 1.0 >= 1.0 but 1.0 is
+		// outside of the provided interval.
+		return !(provider.Operator == providerNoEqualOp &&
+			c.Operator != noEqualOp &&
+			phpVersionCompare(provider.Version, c.Version) == 0)
+	}
+
+	return false
+}
+
+// versionCompare compares two normalized versions with an operator. Dev branches can
+// only be compared for equality unless compareBranches is true, in which case they are
+// compared with PHP's version_compare like any other version.
+func versionCompare(a, b, operator string, compareBranches bool) bool {
+	aIsBranch := strings.HasPrefix(a, "dev-")
+	bIsBranch := strings.HasPrefix(b, "dev-")
+
+	if operator == OpNotEqual && (aIsBranch || bIsBranch) {
+		return a != b
+	}
+	if aIsBranch && bIsBranch {
+		return operator == OpEqual && a == b
+	}
+	// When branches are not comparable, we make sure dev branches never match anything.
+	if !compareBranches && (aIsBranch || bIsBranch) {
+		return false
+	}
+
+	compare := phpVersionCompare(a, b)
+	switch operator {
+	case OpEqual:
+		return compare == 0
+	case OpNotEqual:
+		return compare != 0
+	case OpLess:
+		return compare < 0
+	case OpLessEqual:
+		return compare <= 0
+	case OpGreater:
+		return compare > 0
+	case OpGreaterEqual:
+		return compare >= 0
+	}
+	return false
+}
+
+// MultiConstraint combines several constraints. A conjunctive MultiConstraint requires
+// all of its constraints to match (the "," or " " operator), otherwise only one of
+// them has to match (the "||" operator).
+type MultiConstraint struct {
+	Constraints []Constraint
+	Conjunctive bool
+}
+
+func (c *MultiConstraint) Matches(version string) bool {
+	normalized, err := NormalizeVersion(version)
+	if err != nil {
+		return false
+	}
+	return c.Intersects(&SingleConstraint{Operator: OpEqual, Version: normalized})
+}
+
+func (c *MultiConstraint) Intersects(other Constraint) bool {
+	if !c.Conjunctive {
+		for _, constraint := range c.Constraints {
+			if other.Intersects(constraint) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// When matching a conjunctive and a disjunctive multi constraint we have to iterate
+	// over the disjunctive one, otherwise [>1 <2] would match [<1 || >2].
+	if provider, ok := other.(*MultiConstraint); ok && !provider.Conjunctive {
+		return provider.Intersects(c)
+	}
+
+	for _, constraint := range c.Constraints {
+		if !other.Intersects(constraint) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *MultiConstraint) String() string {
+	parts := make([]string, 0, len(c.Constraints))
+	for _, constraint := range c.Constraints {
+		parts = append(parts, constraint.String())
+	}
+	separator := " || "
+	if c.Conjunctive {
+		separator = " "
+	}
+	return "[" + strings.Join(parts, separator) + "]"
+}
+
+// MatchAllConstraint matches every version, e.g. "*".
+type MatchAllConstraint struct{}
+
+func (MatchAllConstraint) Matches(string) bool {
+	return true
+}
+
+func (MatchAllConstraint) Intersects(Constraint) bool {
+	return true
+}
+
+func (MatchAllConstraint) String() string {
+	return "*"
+}
+
+// MatchNoneConstraint never matches any version.
+type MatchNoneConstraint struct{}
+
+func (MatchNoneConstraint) Matches(string) bool {
+	return false
+}
+
+func (MatchNoneConstraint) Intersects(Constraint) bool {
+	return false
+}
+
+func (MatchNoneConstraint) String() string {
+	return "[]"
+}
+
+// Intersect combines constraints so that a version has to satisfy all of them.
+func Intersect(constraints ...Constraint) Constraint {
+	combined := make([]Constraint, 0, len(constraints))
+	for _, constraint := range constraints {
+		switch constraint.(type) {
+		case MatchAllConstraint, *MatchAllConstraint:
+			continue
+		case MatchNoneConstraint, *MatchNoneConstraint:
+			return MatchNoneConstraint{}
+		}
+		combined = append(combined, constraint)
+	}
+	return newMultiConstraint(combined, true)
+}
+
+// Union combines constraints so that a version has to satisfy at least one of them.
+func Union(constraints ...Constraint) Constraint {
+	combined := make([]Constraint, 0, len(constraints))
+	for _, constraint := range constraints {
+		switch constraint.(type) {
+		case MatchAllConstraint, *MatchAllConstraint:
+			return MatchAllConstraint{}
+		case MatchNoneConstraint, *MatchNoneConstraint:
+			continue
+		}
+		combined = append(combined, constraint)
+	}
+	if len(combined) == 0 && len(constraints) > 0 {
+		return MatchNoneConstraint{}
+	}
+	return newMultiConstraint(combined, false)
+}
+
+// newMultiConstraint avoids wrapping zero or one constraints in a MultiConstraint.
+func newMultiConstraint(constraints []Constraint, conjunctive bool) Constraint {
+	switch len(constraints) {
+	case 0:
+		return MatchAllConstraint{}
+	case 1:
+		return constraints[0]
+	}
+	return &MultiConstraint{Constraints: constraints, Conjunctive: conjunctive}
+}
+
+var (
+	constraintOrRegex        = regexp.MustCompile(`\s*\|\|?\s*`)
+	constraintStabilityRegex = regexp.MustCompile(`(?i)^([^,\s]*?)@(` + stabilitiesRegex + `)$`)
+	constraintReferenceRegex = regexp.MustCompile(`(?i)^(dev-[^,\s@]+?|[^,\s@]+?\.x-dev)#.+$`)
+	constraintMatchAllRegex  = regexp.MustCompile(`(?i)^(v)?[xX*](\.[xX*])*$`)
+	constraintVersionRegex   = `v?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:\.(\d+))?` + modifierRegex + `(?:\+[^\s]+)?`
+	constraintTildeRegex     = regexp.MustCompile(`(?i)^~>?` + constraintVersionRegex + `$`)
+	constraintCaretRegex     = regexp.MustCompile(`(?i)^\^` + constraintVersionRegex + `$`)
+	constraintWildcardRegex  = regexp.MustCompile(`^v?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:\.[xX*])+$`)
+	constraintHyphenRegex    = regexp.MustCompile(`(?i)^(` + constraintVersionRegex + `) +- +(` + constraintVersionRegex + `)$`)
+	constraintBasicRegex     = regexp.MustCompile(`^(<>|!=|>=?|<=?|==?)?\s*(.*)`)
+	constraintModifierRegex  = regexp.MustCompile(`-` + modifierRegex + `$`)
+	constraintBranchRegex    = regexp.MustCompile(`^[0-9a-zA-Z-./]+$`)
+)
+
+// ParseConstraint parses a Composer version constraint such as "^1.2 || ~2.0.3",
+// ">=1.0 <2.0", "1.0 - 2.0", "2.*", "dev-main as 1.0.x-dev" or "^1.0@beta". This is a
+// port of Composer's VersionParser::parseConstraints.
+func ParseConstraint(constraint string) (Constraint, error) {
+	orConstraints := constraintOrRegex.Split(strings.TrimSpace(constraint), -1)
+	orGroups := make([]Constraint, 0, len(orConstraints))
+
+	for _, orConstraint := range orConstraints {
+		andConstraints := splitAndConstraints(orConstraint)
+		constraints := make([]Constraint, 0, len(andConstraints))
+		for _, andConstraint := range andConstraints {
+			parsed, err := parseSingleConstraint(andConstraint)
+			if err != nil {
+				return nil, err
+			}
+			constraints = append(constraints, parsed...)
+		}
+		if len(constraints) == 1 {
+			orGroups = append(orGroups, constraints[0])
+		} else {
+			orGroups = append(orGroups, &MultiConstraint{Constraints: constraints, Conjunctive: true})
+		}
+	}
+
+	return newMultiConstraint(orGroups, false), nil
+}
+
+// MustParseConstraint is like ParseConstraint but panics if the constraint cannot be
+// parsed.
+func MustParseConstraint(constraint string) Constraint {
+	c, err := ParseConstraint(constraint)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// splitAndConstraints splits a constraint on the "," and " " AND operators. Composer
+// does this with the regex `(?<!^|as|[=>< ,]) *(?<!-)[, ](?!-) *(?!,|as|$)`, which
+// relies on look-arounds the regexp package does not support.
+func splitAndConstraints(s string) []string {
+	parts := make([]string, 0, 1)
+	last := 0
+	for p := 0; p < len(s); p++ {
+		end := matchAndSeparator(s, p)
+		if end < 0 {
+			continue
+		}
+		parts = append(parts, s[last:p])
+		last = end
+		p = end - 1
+	}
+	return append(parts, s[last:])
+}
+
+// matchAndSeparator returns the end of the AND separator starting at p, or -1 if there
+// is none.
+func matchAndSeparator(s string, p int) int {
+	if p == 0 || strings.IndexByte("=>< ,", s[p-1]) >= 0 || strings.HasSuffix(s[:p], "as") {
+		return -1
+	}
+	lead := 0
+	for p+lead < len(s) && s[p+lead] == ' ' {
+		lead++
+	}
+	for ; lead >= 0; lead-- {
+		c := p + lead
+		if c >= len(s) || (s[c] != ',' && s[c] != ' ') {
+			continue
+		}
+		if c > 0 && s[c-1] == '-' {
+			continue
+		}
+		if c+1 < len(s) && s[c+1] == '-' {
+			continue
+		}
+		trail := 0
+		for c+1+trail < len(s) && s[c+1+trail] == ' ' {
+			trail++
+		}
+		for ; trail >= 0; trail-- {
+			e := c + 1 + trail
+			if e >= len(s) || s[e] == ',' || strings.HasPrefix(s[e:], "as") {
+				continue
+			}
+			return e
+		}
+	}
+	return -1
+}
+
+// parseSingleConstraint parses one part of an AND constraint. Tilde, caret, wildcard
+// and hyphen ranges are expanded into a lower and upper bound.
+func parseSingleConstraint(constraint string) ([]Constraint, error) {
+	// Strip off aliasing.
+	if m := versionAliasRegex.FindStringSubmatch(constraint); m != nil {
+		constraint = m[1]
+	}
+
+	// Strip @stability flags, and keep it for later use.
+	stabilityModifier := ""
+	if m := constraintStabilityRegex.FindStringSubmatch(constraint); m != nil {
+		constraint = m[1]
+		if constraint == "" {
+			constraint = "*"
+		}
+		if m[2] != "stable" {
+			stabilityModifier = m[2]
+		}
+	}
+
+	// Get rid of #refs as those are used by Composer only.
+	if m := constraintReferenceRegex.FindStringSubmatch(constraint); m != nil {
+		constraint = m[1]
+	}
+
+	if m := constraintMatchAllRegex.FindStringSubmatch(constraint); m != nil {
+		if m[1] != "" || m[2] != "" {
+			return []Constraint{&SingleConstraint{Operator: OpGreaterEqual, Version: "0.0.0.0-dev"}}, nil
+		}
+		return []Constraint{MatchAllConstraint{}}, nil
+	}
+
+	// Tilde range. Like wildcard constraints, unsuffixed tilde constraints say that
+	// they must be greater than the previous version, to ensure that unstable instances
+	// of the current version are allowed. However, if a stability suffix is added to the
+	// constraint, then a >= match on the current version is used instead.
+	if m := constraintTildeRegex.FindStringSubmatch(constraint); m != nil {
+		if strings.HasPrefix(constraint, "~>") {
+			return nil, fmt.Errorf(`could not parse version constraint %s: invalid operator "~>", you probably meant to use the "~" operator`, constraint)
+		}
+
+		position := 1
+		for i := 4; i > 1; i-- {
+			if m[i] != "" {
+				position = i
+				break
+			}
+		}
+
+		low, err := NormalizeVersion(constraint[1:] + stabilitySuffix(m[5], m[7]))
+		if err != nil {
+			return nil, err
+		}
+		highPosition := position - 1
+		if highPosition < 1 {
+			highPosition = 1
+		}
+		return []Constraint{
+			&SingleConstraint{Operator: OpGreaterEqual, Version: low},
+			&SingleConstraint{Operator: OpLess, Version: manipulateVersionString(m[1:5], highPosition, 1) + "-dev"},
+		}, nil
+	}
+
+	// Caret range. Allows changes that do not modify the left-most non-zero digit in the
+	// [major, minor, patch] tuple.
+	if m := constraintCaretRegex.FindStringSubmatch(constraint); m != nil {
+		position := 3
+		if m[1] != "0" || m[2] == "" {
+			position = 1
+		} else if m[2] != "0" || m[3] == "" {
+			position = 2
+		}
+
+		low, err := NormalizeVersion(constraint[1:] + stabilitySuffix(m[5], m[7]))
+		if err != nil {
+			return nil, err
+		}
+		return []Constraint{
+			&SingleConstraint{Operator: OpGreaterEqual, Version: low},
+			&SingleConstraint{Operator: OpLess, Version: manipulateVersionString(m[1:5], position, 1) + "-dev"},
+		}, nil
+	}
+
+	// X range. Any of X, x, or * may be used to "stand in" for one of the numeric values
+	// in the [major, minor, patch] tuple.
+	if m := constraintWildcardRegex.FindStringSubmatch(constraint); m != nil {
+		position := 1
+		if m[3] != "" {
+			position = 3
+		} else if m[2] != "" {
+			position = 2
+		}
+
+		parts := []string{m[1], m[2], m[3], ""}
+		low := manipulateVersionString(parts, position, 0) + "-dev"
+		high := manipulateVersionString(parts, position, 1) + "-dev"
+
+		if low == "0.0.0.0-dev" {
+			return []Constraint{&SingleConstraint{Operator: OpLess, Version: high}}, nil
+		}
+		return []Constraint{
+			&SingleConstraint{Operator: OpGreaterEqual, Version: low},
+			&SingleConstraint{Operator: OpLess, Version: high},
+		}, nil
+	}
+
+	// Hyphen range. Specifies an inclusive set. If a partial version is provided as the
+	// first version, the missing pieces are replaced with zeroes. If a partial version
+	// is provided as the second version, then all versions that start with the supplied
+	// parts of the tuple are accepted.
+	if m := constraintHyphenRegex.FindStringSubmatch(constraint); m != nil {
+		from, to := m[1:9], m[9:17]
+
+		low, err := NormalizeVersion(from[0])
+		if err != nil {
+			return nil, err
+		}
+		lower := &SingleConstraint{Operator: OpGreaterEqual, Version: low + stabilitySuffix(from[5], from[7])}
+
+		high, err := NormalizeVersion(to[0])
+		if err != nil {
+			return nil, err
+		}
+		if (to[2] != "" && to[3] != "") || to[5] != "" || to[7] != "" {
+			return []Constraint{lower, &SingleConstraint{Operator: OpLessEqual, Version: high}}, nil
+		}
+
+		position := 1
+		if to[2] != "" {
+			position = 2
+		}
+		high = manipulateVersionString(to[1:5], position, 1) + "-dev"
+		return []Constraint{lower, &SingleConstraint{Operator: OpLess, Version: high}}, nil
+	}
+
+	// Basic comparators.
+	if m := constraintBasicRegex.FindStringSubmatch(constraint); m != nil {
+		version, err := NormalizeVersion(m[2])
+		if err != nil {
+			// Recover from an invalid constraint like foobar-dev which should be
+			// dev-foobar, except if the constraint uses a known operator, in which case
+			// it must be a parse error.
+			if !strings.HasSuffix(m[2], "-dev") || !constraintBranchRegex.MatchString(m[2]) {
+				return nil, fmt.Errorf("could not parse version constraint %s: %w", constraint, err)
+			}
+			version, err = NormalizeVersion("dev-" + m[2][:len(m[2])-4])
+			if err != nil {
+				return nil, fmt.Errorf("could not parse version constraint %s: %w", constraint, err)
+			}
+		}
+
+		op := m[1]
+		if op == "" {
+			op = "="
+		}
+		if op != "==" && op != "=" && stabilityModifier != "" && ParseStability(version) == StabilityStable {
+			version += "-" + stabilityModifier
+		} else if op == "<" || op == ">=" {
+			if !constraintModifierRegex.MatchString(strings.ToLower(m[2])) && !strings.HasPrefix(m[2], "dev-") {
+				version += "-dev"
+			}
+		}
+
+		c, err := NewSingleConstraint(op, version)
+		if err != nil {
+			return nil, err
+		}
+		return []Constraint{c}, nil
+	}
+
+	return nil, fmt.Errorf("could not parse version constraint %s", constraint)
+}
+
+// stabilitySuffix returns "-dev" when a version in a range has no stability or dev
+// modifier, so that the range includes unstable versions of its lower bound.
+func stabilitySuffix(stability, dev string) string {
+	if stability == "" && dev == "" {
+		return "-dev"
+	}
+	return ""
+}
+
+// manipulateVersionString builds a four part version from the numeric parts of a
+// constraint, incrementing the part at position and zeroing the parts after it.
+func manipulateVersionString(parts []string, position, increment int) string {
+	result := make([]string, 4)
+	copy(result, parts)
+	for i := 4; i > 0; i-- {
+		if i > position {
+			result[i-1] = "0"
+		} else if i == position && increment != 0 {
+			n, _ := strconv.Atoi(result[i-1])
+			result[i-1] = strconv.Itoa(n + increment)
+		}
+	}
+	return strings.Join(result, ".")
+}
@@ -0,0 +1,309 @@
+package gocomposer
+
+import (
+	is2 "github.com/matryer/is"
+	"testing"
+)
+
+func TestParseConstraint(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		output string
+	}{
+		// Simple constraints.
+		{name: `MatchAny`, input: `*`, output: `*`},
+		{name: `MatchAnyStability`, input: `@dev`, output: `*`},
+		{name: `MatchAnyNumeric`, input: `*.*`, output: `>= 0.0.0.0-dev`},
+		{name: `NotEqual`, input: `<>1.0.0`, output: `!= 1.0.0.0`},
+		{name: `NotEqual2`, input: `!=1.0.0`, output: `!= 1.0.0.0`},
+		{name: `GreaterThan`, input: `>1.0.0`, output: `> 1.0.0.0`},
+		{name: `LesserThan`, input: `<1.2.3.4`, output: `< 1.2.3.4-dev`},
+		{name: `LessEqual`, input: `<=1.2.3`, output: `<= 1.2.3.0`},
+		{name: `GreatEqual`, input: `>=1.2.3`, output: `>= 1.2.3.0-dev`},
+		{name: `Equals`, input: `=1.2.3`, output: `== 1.2.3.0`},
+		{name: `DoubleEquals`, input: `==1.2.3`, output: `== 1.2.3.0`},
+		{name: `NoOpMeansEqual`, input: `1.2.3`, output: `== 1.2.3.0`},
+		{name: `CompletesVersion`, input: `=1.0`, output: `== 1.0.0.0`},
+		{name: `ShorthandBeta`, input: `1.2.3b5`, output: `== 1.2.3.0-beta5`},
+		{name: `ShorthandAlpha`, input: `1.2.3a1`, output: `== 1.2.3.0-alpha1`},
+		{name: `ShorthandPatch`, input: `1.2.3p1234`, output: `== 1.2.3.0-patch1234`},
+		{name: `ShorthandPatch2`, input: `1.2.3pl1234`, output: `== 1.2.3.0-patch1234`},
+		{name: `AcceptsSpaces`, input: `>= 1.2.3`, output: `>= 1.2.3.0-dev`},
+		{name: `AcceptsSpaces2`, input: `< 1.2.3`, output: `< 1.2.3.0-dev`},
+		{name: `AcceptsSpaces3`, input: `> 1.2.3`, output: `> 1.2.3.0`},
+		{name: `AcceptsMaster`, input: `>=dev-master`, output: `>= dev-master`},
+		{name: `AcceptsMaster2`, input: `dev-master`, output: `== dev-master`},
+		{name: `AcceptsArbitrary`, input: `dev-feature-a`, output: `== dev-feature-a`},
+		{name: `AcceptsCaps`, input: `dev-CAPS`, output: `== dev-CAPS`},
+		{name: `RecoversBranch`, input: `foobar-dev`, output: `== dev-foobar`},
+		{name: `IgnoresAliases`, input: `dev-master as 1.0.0`, output: `== dev-master`},
+		{name: `IgnoresReference`, input: `dev-main#abc123`, output: `== dev-main`},
+		{name: `IgnoresReference2`, input: `1.0.x-dev#abc123`, output: `== 1.0.9999999.9999999-dev`},
+		{name: `LesserThanOverride`, input: `<1.2.3.4-stable`, output: `< 1.2.3.4`},
+		{name: `GreatEqualOverride`, input: `>=1.2.3.4-stable`, output: `>= 1.2.3.4`},
+		{name: `StabilityFlag`, input: `1.0@dev`, output: `== 1.0.0.0`},
+		{name: `OperatorStabilityFlag`, input: `>=1.0@dev`, output: `>= 1.0.0.0-dev`},
+		{name: `OperatorStabilityFlag2`, input: `>=2.0@beta`, output: `>= 2.0.0.0-beta`},
+		{name: `StableFlag`, input: `>2.0@stable`, output: `> 2.0.0.0`},
+
+		// Wildcards.
+		{name: `Wildcard`, input: `2.*`, output: `[>= 2.0.0.0-dev < 3.0.0.0-dev]`},
+		{name: `Wildcard2`, input: `20.*`, output: `[>= 20.0.0.0-dev < 21.0.0.0-dev]`},
+		{name: `Wildcard3`, input: `2.0.*`, output: `[>= 2.0.0.0-dev < 2.1.0.0-dev]`},
+		{name: `Wildcard4`, input: `2.x`, output: `[>= 2.0.0.0-dev < 3.0.0.0-dev]`},
+		{name: `Wildcard5`, input: `2.x.x`, output: `[>= 2.0.0.0-dev < 3.0.0.0-dev]`},
+		{name: `Wildcard6`, input: `2.2.X`, output: `[>= 2.2.0.0-dev < 2.3.0.0-dev]`},
+		{name: `Wildcard7`, input: `2.10.x`, output: `[>= 2.10.0.0-dev < 2.11.0.0-dev]`},
+		{name: `Wildcard8`, input: `2.1.3.*`, output: `[>= 2.1.3.0-dev < 2.1.4.0-dev]`},
+		{name: `WildcardZero`, input: `0.*`, output: `< 1.0.0.0-dev`},
+		{name: `WildcardZero2`, input: `0.*.*`, output: `< 1.0.0.0-dev`},
+		{name: `WildcardZero3`, input: `0.x.x.x`, output: `< 1.0.0.0-dev`},
+
+		// Tilde ranges.
+		{name: `Tilde`, input: `~1`, output: `[>= 1.0.0.0-dev < 2.0.0.0-dev]`},
+		{name: `Tilde2`, input: `~1.0`, output: `[>= 1.0.0.0-dev < 2.0.0.0-dev]`},
+		{name: `Tilde3`, input: `~1.0.0`, output: `[>= 1.0.0.0-dev < 1.1.0.0-dev]`},
+		{name: `Tilde4`, input: `~1.2`, output: `[>= 1.2.0.0-dev < 2.0.0.0-dev]`},
+		{name: `Tilde5`, input: `~1.2.3`, output: `[>= 1.2.3.0-dev < 1.3.0.0-dev]`},
+		{name: `Tilde6`, input: `~1.2.3.4`, output: `[>= 1.2.3.4-dev < 1.2.4.0-dev]`},
+		{name: `TildeBeta`, input: `~1.2-beta`, output: `[>= 1.2.0.0-beta < 2.0.0.0-dev]`},
+		{name: `TildeBeta2`, input: `~1.2-b2`, output: `[>= 1.2.0.0-beta2 < 2.0.0.0-dev]`},
+		{name: `TildeBeta3`, input: `~1.2-BETA2`, output: `[>= 1.2.0.0-beta2 < 2.0.0.0-dev]`},
+		{name: `TildeDev`, input: `~1.2.2-dev`, output: `[>= 1.2.2.0-dev < 1.3.0.0-dev]`},
+		{name: `TildeStable`, input: `~1.2.2-stable`, output: `[>= 1.2.2.0 < 1.3.0.0-dev]`},
+
+		// Caret ranges.
+		{name: `Caret`, input: `^1`, output: `[>= 1.0.0.0-dev < 2.0.0.0-dev]`},
+		{name: `CaretZero`, input: `^0`, output: `[>= 0.0.0.0-dev < 1.0.0.0-dev]`},
+		{name: `CaretZero2`, input: `^0.0`, output: `[>= 0.0.0.0-dev < 0.1.0.0-dev]`},
+		{name: `Caret2`, input: `^1.2`, output: `[>= 1.2.0.0-dev < 2.0.0.0-dev]`},
+		{name: `CaretBeta`, input: `^1.2.3-beta.2`, output: `[>= 1.2.3.0-beta2 < 2.0.0.0-dev]`},
+		{name: `Caret3`, input: `^1.2.3.4`, output: `[>= 1.2.3.4-dev < 2.0.0.0-dev]`},
+		{name: `Caret4`, input: `^1.2.3`, output: `[>= 1.2.3.0-dev < 2.0.0.0-dev]`},
+		{name: `CaretMinor`, input: `^0.2.3`, output: `[>= 0.2.3.0-dev < 0.3.0.0-dev]`},
+		{name: `CaretMinor2`, input: `^0.2`, output: `[>= 0.2.0.0-dev < 0.3.0.0-dev]`},
+		{name: `CaretMinor3`, input: `^0.2.0`, output: `[>= 0.2.0.0-dev < 0.3.0.0-dev]`},
+		{name: `CaretPatch`, input: `^0.0.3`, output: `[>= 0.0.3.0-dev < 0.0.4.0-dev]`},
+		{name: `CaretPatchAlpha`, input: `^0.0.3-alpha`, output: `[>= 0.0.3.0-alpha < 0.0.4.0-dev]`},
+		{name: `CaretPatchDev`, input: `^0.0.3-dev`, output: `[>= 0.0.3.0-dev < 0.0.4.0-dev]`},
+
+		// Hyphen ranges.
+		{name: `Hyphen`, input: `1 - 2`, output: `[>= 1.0.0.0-dev < 3.0.0.0-dev]`},
+		{name: `Hyphen2`, input: `1.2.3 - 2.3.4.5`, output: `[>= 1.2.3.0-dev <= 2.3.4.5]`},
+		{name: `Hyphen3`, input: `1.2-beta - 2.3`, output: `[>= 1.2.0.0-beta < 2.4.0.0-dev]`},
+		{name: `Hyphen4`, input: `1.2-beta - 2.3-dev`, output: `[>= 1.2.0.0-beta <= 2.3.0.0-dev]`},
+		{name: `Hyphen5`, input: `1.2-RC - 2.3.1`, output: `[>= 1.2.0.0-RC <= 2.3.1.0]`},
+		{name: `Hyphen6`, input: `1.2.3-alpha - 2.3-RC`, output: `[>= 1.2.3.0-alpha <= 2.3.0.0-RC]`},
+		{name: `Hyphen7`, input: `1 - 2.0`, output: `[>= 1.0.0.0-dev < 2.1.0.0-dev]`},
+		{name: `Hyphen8`, input: `1 - 2.1`, output: `[>= 1.0.0.0-dev < 2.2.0.0-dev]`},
+		{name: `Hyphen9`, input: `1.2 - 2.1.0`, output: `[>= 1.2.0.0-dev <= 2.1.0.0]`},
+		{name: `Hyphen10`, input: `1.3 - 2.1.3`, output: `[>= 1.3.0.0-dev <= 2.1.3.0]`},
+
+		// AND and OR constraints.
+		{name: `And`, input: `>2.0,<=3.0`, output: `[> 2.0.0.0 <= 3.0.0.0]`},
+		{name: `And2`, input: `>2.0 <=3.0`, output: `[> 2.0.0.0 <= 3.0.0.0]`},
+		{name: `And3`, input: `>2.0  <=3.0`, output: `[> 2.0.0.0 <= 3.0.0.0]`},
+		{name: `And4`, input: `>2.0, <=3.0`, output: `[> 2.0.0.0 <= 3.0.0.0]`},
+		{name: `And5`, input: `>2.0 ,<=3.0`, output: `[> 2.0.0.0 <= 3.0.0.0]`},
+		{name: `And6`, input: `>2.0 , <=3.0`, output: `[> 2.0.0.0 <= 3.0.0.0]`},
+		{name: `And7`, input: `>2.0   , <=3.0`, output: `[> 2.0.0.0 <= 3.0.0.0]`},
+		{name: `And8`, input: `> 2.0   <=  3.0`, output: `[> 2.0.0.0 <= 3.0.0.0]`},
+		{name: `And9`, input: `> 2.0  ,  <=  3.0`, output: `[> 2.0.0.0 <= 3.0.0.0]`},
+		{name: `And10`, input: `  > 2.0  ,  <=  3.0 `, output: `[> 2.0.0.0 <= 3.0.0.0]`},
+		{name: `AndStability`, input: `>2.0@stable,<=3.0@dev`, output: `[> 2.0.0.0 <= 3.0.0.0-dev]`},
+		{name: `AndCaret`, input: `^1.0 !=1.2.0`, output: `[>= 1.0.0.0-dev < 2.0.0.0-dev != 1.2.0.0]`},
+		{name: `Or`, input: `>2.0||<=1.0`, output: `[> 2.0.0.0 || <= 1.0.0.0]`},
+		{name: `Or2`, input: `>2.0 || <=1.0`, output: `[> 2.0.0.0 || <= 1.0.0.0]`},
+		{name: `OrSingle`, input: `>2.0 | <=1.0`, output: `[> 2.0.0.0 || <= 1.0.0.0]`},
+		{name: `OrRanges`, input: `^2.0 || ~1.5.3`, output: `[[>= 2.0.0.0-dev < 3.0.0.0-dev] || [>= 1.5.3.0-dev < 1.6.0.0-dev]]`},
+		{name: `OrAnd`, input: `>=1.0 <1.1 || >=1.2`, output: `[[>= 1.0.0.0-dev < 1.1.0.0-dev] || >= 1.2.0.0-dev]`},
+		{name: `OrExact`, input: `1.0.0 || 2.0.0 || 3.0.0`, output: `[== 1.0.0.0 || == 2.0.0.0 || == 3.0.0.0]`},
+		{name: `InlineAlias`, input: `dev-main as 1.0.x-dev`, output: `== dev-main`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			result, err := ParseConstraint(test.input)
+
+			is.NoErr(err)
+			is.Equal(result.String(), test.output)
+		})
+	}
+}
+
+func TestParseConstraint_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: `Empty`, input: ``},
+		{name: `InvalidVersion`, input: `1.0.0-meh`},
+		{name: `OperatorOnly`, input: `>=`},
+		{name: `InvalidOperator`, input: `~>1.2`},
+		{name: `InvalidOperator2`, input: `=>1.2`},
+		{name: `InvalidAndPart`, input: `>=1.0 foo`},
+		{name: `InvalidOrPart`, input: `^1.0 || bar`},
+		{name: `BranchWithOperator`, input: `>=foo-bar`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			_, err := ParseConstraint(test.input)
+
+			is.True(err != nil)
+		})
+	}
+}
+
+func TestConstraint_Matches(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		matches    bool
+	}{
+		{constraint: `^2.0 || ~1.5.3`, version: `2.3.1`, matches: true},
+		{constraint: `^2.0 || ~1.5.3`, version: `1.5.9`, matches: true},
+		{constraint: `^2.0 || ~1.5.3`, version: `1.5.2`, matches: false},
+		{constraint: `^2.0 || ~1.5.3`, version: `1.6.0`, matches: false},
+		{constraint: `^2.0 || ~1.5.3`, version: `3.0.0`, matches: false},
+		{constraint: `^2.0`, version: `2.0.0-beta1`, matches: true},
+		{constraint: `^2.0`, version: `2.x-dev`, matches: true},
+		{constraint: `^2.0`, version: `v2.5`, matches: true},
+		{constraint: `^0.3`, version: `0.3.9`, matches: true},
+		{constraint: `^0.3`, version: `0.4.0`, matches: false},
+		{constraint: `~1.2`, version: `1.9.9`, matches: true},
+		{constraint: `~1.2`, version: `2.0.0-alpha1`, matches: false},
+		{constraint: `1.0.*`, version: `1.0.99`, matches: true},
+		{constraint: `1.0.*`, version: `1.1.0`, matches: false},
+		{constraint: `>=1.0 <1.1 || >=1.2`, version: `1.1.5`, matches: false},
+		{constraint: `>=1.0 <1.1 || >=1.2`, version: `1.2.0`, matches: true},
+		{constraint: `>=1.0, <1.1`, version: `1.0.5`, matches: true},
+		{constraint: `1.0 - 2.0`, version: `2.0.5`, matches: true},
+		{constraint: `1.0 - 2.0`, version: `2.1.0`, matches: false},
+		{constraint: `1.0.0 - 2.0.0`, version: `2.0.0`, matches: true},
+		{constraint: `1.0.0 - 2.0.0`, version: `2.0.1`, matches: false},
+		{constraint: `!=1.2.0`, version: `1.2.0`, matches: false},
+		{constraint: `!=1.2.0`, version: `1.2.1`, matches: true},
+		{constraint: `*`, version: `dev-main`, matches: true},
+		{constraint: `>=1.0`, version: `dev-main`, matches: false},
+		{constraint: `dev-main`, version: `dev-main`, matches: true},
+		{constraint: `dev-main`, version: `dev-develop`, matches: false},
+		{constraint: `dev-main as 1.0.x-dev`, version: `dev-main`, matches: true},
+		{constraint: `1.0.x-dev`, version: `1.0.x-dev`, matches: true},
+		{constraint: `<1.0.0`, version: `1.0.0-beta`, matches: false},
+		{constraint: `<1.0.0`, version: `0.9.9`, matches: true},
+		{constraint: `<=1.0.0`, version: `1.0.0`, matches: true},
+		{constraint: `>1.0.0`, version: `1.0.0-patch1`, matches: true},
+		{constraint: `1.0.0`, version: `1.0.0.0`, matches: true},
+		{constraint: `^1.0`, version: `not-a-version`, matches: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.constraint+"_"+test.version, func(t *testing.T) {
+			is := is2.New(t)
+
+			constraint, err := ParseConstraint(test.constraint)
+			is.NoErr(err)
+
+			is.Equal(constraint.Matches(test.version), test.matches)
+		})
+	}
+}
+
+func TestConstraint_Intersects(t *testing.T) {
+	tests := []struct {
+		a          string
+		b          string
+		intersects bool
+	}{
+		{a: `^1.0`, b: `1.0.0 || 2.0.0 || 3.0.0`, intersects: true},
+		{a: `^4.0`, b: `1.0.0 || 2.0.0 || 3.0.0`, intersects: false},
+		{a: `>1 <2`, b: `<1 || >2`, intersects: false},
+		{a: `<1 || >2`, b: `>1 <2`, intersects: false},
+		{a: `>=1.0`, b: `<1.0`, intersects: false},
+		{a: `>=1.0`, b: `<=1.0`, intersects: true},
+		{a: `<=2.0`, b: `<1.0`, intersects: true},
+		{a: `!=1.0`, b: `==1.0`, intersects: false},
+		{a: `!=1.0`, b: `>1.0`, intersects: true},
+		{a: `!=dev-main`, b: `>1.0`, intersects: true},
+		{a: `!=1.0`, b: `>=dev-main`, intersects: false},
+		{a: `^1.0`, b: `~1.5`, intersects: true},
+		{a: `*`, b: `dev-main`, intersects: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.a+"_"+test.b, func(t *testing.T) {
+			is := is2.New(t)
+
+			a := MustParseConstraint(test.a)
+			b := MustParseConstraint(test.b)
+
+			is.Equal(a.Intersects(b), test.intersects)
+		})
+	}
+}
+
+func TestIntersect(t *testing.T) {
+	is := is2.New(t)
+
+	c := Intersect(MustParseConstraint(`^1.0`), MustParseConstraint(`<1.5`))
+
+	is.Equal(c.String(), `[[>= 1.0.0.0-dev < 2.0.0.0-dev] < 1.5.0.0-dev]`)
+	is.True(c.Matches("1.4.0"))
+	is.True(!c.Matches("1.5.0"))
+	is.True(!c.Matches("0.9.0"))
+
+	is.Equal(Intersect(MatchAllConstraint{}, MustParseConstraint(`^1.0`)).String(), `[>= 1.0.0.0-dev < 2.0.0.0-dev]`)
+	is.Equal(Intersect(MatchNoneConstraint{}, MustParseConstraint(`^1.0`)), Constraint(MatchNoneConstraint{}))
+	is.Equal(Intersect(), Constraint(MatchAllConstraint{}))
+}
+
+func TestUnion(t *testing.T) {
+	is := is2.New(t)
+
+	c := Union(MustParseConstraint(`^1.0`), MustParseConstraint(`^3.0`))
+
+	is.Equal(c.String(), `[[>= 1.0.0.0-dev < 2.0.0.0-dev] || [>= 3.0.0.0-dev < 4.0.0.0-dev]]`)
+	is.True(c.Matches("1.4.0"))
+	is.True(!c.Matches("2.0.0"))
+	is.True(c.Matches("3.1.0"))
+
+	is.Equal(Union(MatchNoneConstraint{}, MustParseConstraint(`1.0`)).String(), `== 1.0.0.0`)
+	is.Equal(Union(MatchAllConstraint{}, MustParseConstraint(`1.0`)), Constraint(MatchAllConstraint{}))
+	is.Equal(Union(MatchNoneConstraint{}), Constraint(MatchNoneConstraint{}))
+}
+
+func TestNewSingleConstraint(t *testing.T) {
+	tests := []struct {
+		operator string
+		output   string
+		valid    bool
+	}{
+		{operator: `=`, output: `==`, valid: true},
+		{operator: `==`, output: `==`, valid: true},
+		{operator: `<>`, output: `!=`, valid: true},
+		{operator: `!=`, output: `!=`, valid: true},
+		{operator: `<`, output: `<`, valid: true},
+		{operator: `>=`, output: `>=`, valid: true},
+		{operator: `=>`, valid: false},
+		{operator: `~`, valid: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.operator, func(t *testing.T) {
+			is := is2.New(t)
+
+			c, err := NewSingleConstraint(test.operator, "1.0.0.0")
+
+			is.Equal(err == nil, test.valid)
+			if test.valid {
+				is.Equal(c.Operator, test.output)
+			}
+		})
+	}
+}
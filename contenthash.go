@@ -0,0 +1,152 @@
+package gocomposer
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sort"
+)
+
+// contentHashKeys are the top level composer.json keys Composer includes when
+// computing the content-hash stored in composer.lock.
+var contentHashKeys = []string{
+	"name",
+	"version",
+	"require",
+	"require-dev",
+	"conflict",
+	"replace",
+	"provide",
+	"minimum-stability",
+	"prefer-stable",
+	"repositories",
+	"extra",
+}
+
+// ComputeContentHash calculates the content-hash Composer stores in composer.lock from
+// the raw contents of a composer.json file. Because the hash depends on the order of
+// keys in the file, this is the most exact way to compute it.
+func ComputeContentHash(data []byte) (string, error) {
+	root, err := decodeOrdered(data)
+	if err != nil {
+		return "", err
+	}
+	content, ok := root.(*orderedObject)
+	if !ok {
+		return "", errors.New("composer.json must contain a JSON object")
+	}
+
+	relevant := newOrderedObject()
+	for _, key := range contentHashKeys {
+		if value, exists := content.get(key); exists {
+			relevant.set(key, value)
+		}
+	}
+	if config, exists := content.get("config"); exists {
+		if config, ok := config.(*orderedObject); ok {
+			if platform, exists := config.get("platform"); exists && platform != nil {
+				relevantConfig := newOrderedObject()
+				relevantConfig.set("platform", platform)
+				relevant.set("config", relevantConfig)
+			}
+		}
+	}
+
+	return hashRelevantContent(relevant)
+}
+
+// ContentHash calculates the content-hash Composer would store in composer.lock for
+// this composer.json.
+//
+// The struct does not remember the key order of the file it was decoded from, so maps
+// are hashed with their keys sorted and objects with their keys in struct order. Only
+// extra keeps the order of the file. Use ComputeContentHash with the raw file contents
+// when the file is available.
+func (c ComposerJSON) ContentHash() (string, error) {
+	relevant := newOrderedObject()
+
+	add := func(key string, value interface{}) error {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		decoded, err := decodeOrdered(data)
+		if err != nil {
+			return err
+		}
+		relevant.set(key, decoded)
+		return nil
+	}
+
+	fields := []struct {
+		key     string
+		value   interface{}
+		present bool
+	}{
+		{"name", c.Name, c.Name != ""},
+		{"version", c.Version, c.Version != ""},
+		{"require", c.Require, c.Require != nil},
+		{"require-dev", c.RequireDev, c.RequireDev != nil},
+		{"conflict", c.Conflict, c.Conflict != nil},
+		{"replace", c.Replace, c.Replace != nil},
+		{"provide", c.Provide, c.Provide != nil},
+		{"minimum-stability", c.MinimumStability, c.MinimumStability != ""},
+		{"prefer-stable", c.PreferStable, c.PreferStable},
+		{"repositories", c.Repositories, c.Repositories.Array != nil},
+		{"extra", c.Extra, c.Extra != nil},
+		{"config", map[string]interface{}{"platform": c.Config.Platform}, c.Config.Platform != nil},
+	}
+	for _, field := range fields {
+		if !field.present {
+			continue
+		}
+		if err := add(field.key, field.value); err != nil {
+			return "", err
+		}
+	}
+
+	return hashRelevantContent(relevant)
+}
+
+// hashRelevantContent sorts the top level keys like PHP's ksort and returns the MD5
+// hash of the PHP encoded JSON.
+func hashRelevantContent(relevant *orderedObject) (string, error) {
+	sort.Strings(relevant.keys)
+
+	buf := bytes.Buffer{}
+	if err := encodePHP(&buf, relevant); err != nil {
+		return "", err
+	}
+	sum := md5.Sum(buf.Bytes())
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// IsFresh returns true if the lock file was generated from the given composer.json
+// contents. Lock files written by Composer 1 without a content-hash are compared
+// using the legacy hash of the whole file.
+func (l ComposerLock) IsFresh(composerJSON []byte) (bool, error) {
+	if l.ContentHash == "" {
+		if l.Hash == "" {
+			return false, nil
+		}
+		sum := md5.Sum(composerJSON)
+		return hex.EncodeToString(sum[:]) == l.Hash, nil
+	}
+	hash, err := ComputeContentHash(composerJSON)
+	if err != nil {
+		return false, err
+	}
+	return hash == l.ContentHash, nil
+}
+
+// IsFreshFor returns true if the lock file content-hash matches the one computed for
+// the given ComposerJSON. See ComposerJSON.ContentHash for its limitations.
+func (l ComposerLock) IsFreshFor(c ComposerJSON) (bool, error) {
+	hash, err := c.ContentHash()
+	if err != nil {
+		return false, err
+	}
+	return hash == l.ContentHash, nil
+}
@@ -0,0 +1,192 @@
+package gocomposer
+
+import (
+	"encoding/json"
+	is2 "github.com/matryer/is"
+	"os"
+	"testing"
+)
+
+// The expected hashes are the content-hash values Composer writes to composer.lock for
+// each of the fixture files.
+func TestComputeContentHash(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		hash string
+	}{
+		{
+			name: `Minimal`,
+			file: `testdata/contenthash/minimal.json`,
+			hash: `bbe2f8b08ba456d7ae784e290e862e4a`,
+		},
+		{
+			name: `Project`,
+			file: `testdata/contenthash/project.json`,
+			hash: `fb422b35fdb4600e8df92fcc0442801f`,
+		},
+		{
+			name: `Repositories`,
+			file: `testdata/contenthash/repositories.json`,
+			hash: `cb28778716a51512774a56aa8c81f0fc`,
+		},
+		{
+			name: `Floats`,
+			file: `testdata/contenthash/floats.json`,
+			hash: `db0b416488d49c22c60cd22ad140ea6c`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			data, err := os.ReadFile(test.file)
+			is.NoErr(err)
+
+			hash, err := ComputeContentHash(data)
+
+			is.NoErr(err)
+			is.Equal(hash, test.hash)
+		})
+	}
+}
+
+func TestComputeContentHash_IgnoredKeys(t *testing.T) {
+	is := is2.New(t)
+
+	a, err := ComputeContentHash([]byte(`{"require": {"php": "^8.1"}}`))
+	is.NoErr(err)
+	b, err := ComputeContentHash([]byte(`{
+		"description": "Not relevant",
+		"require": {"php": "^8.1"},
+		"config": {"sort-packages": true}
+	}`))
+	is.NoErr(err)
+
+	is.Equal(a, b)
+}
+
+func TestComputeContentHash_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  `Syntax`,
+			input: `{"require": `,
+		},
+		{
+			name:  `Array`,
+			input: `["require"]`,
+		},
+		{
+			name:  `TrailingData`,
+			input: `{} {}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			_, err := ComputeContentHash([]byte(test.input))
+
+			is.True(err != nil)
+		})
+	}
+}
+
+func TestComposerJSON_ContentHash(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  `Minimal`,
+			input: `{"require": {"php": "^8.1"}}`,
+		},
+		{
+			name: `Sorted`,
+			input: `{
+				"name": "acme/sorted",
+				"require": {"monolog/monolog": "^3.0", "php": ">=8.1"},
+				"require-dev": {},
+				"minimum-stability": "beta",
+				"prefer-stable": true,
+				"config": {"platform": {"ext-redis": false, "php": "8.1.20"}},
+				"extra": {"branch-alias": {"dev-main": "1.x-dev"}}
+			}`,
+		},
+		{
+			name: `ExtraOrder`,
+			input: `{
+				"name": "acme/extra",
+				"extra": {"patches": {"acme/log": {"Fix the timezone": "timezone.patch"}}, "branch-alias": {"dev-main": "1.x-dev"}, "ratio": 1.0}
+			}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			c := ComposerJSON{}
+			is.NoErr(json.Unmarshal([]byte(test.input), &c))
+
+			expected, err := ComputeContentHash([]byte(test.input))
+			is.NoErr(err)
+			hash, err := c.ContentHash()
+			is.NoErr(err)
+
+			is.Equal(hash, expected)
+		})
+	}
+}
+
+func TestComposerLock_IsFresh(t *testing.T) {
+	manifest := []byte(`{"require": {"php": "^8.1"}}`)
+
+	tests := []struct {
+		name  string
+		lock  ComposerLock
+		fresh bool
+	}{
+		{
+			name:  `ContentHash`,
+			lock:  ComposerLock{ContentHash: "bbe2f8b08ba456d7ae784e290e862e4a"},
+			fresh: true,
+		},
+		{
+			name:  `Stale`,
+			lock:  ComposerLock{ContentHash: "2e3a6d5f4b1c8e0a7d9f6b3c1e2a4d5f"},
+			fresh: false,
+		},
+		{
+			name:  `LegacyHash`,
+			lock:  ComposerLock{Hash: "77adb80f183cc3d557ae65f628b889b1"},
+			fresh: true,
+		},
+		{
+			name:  `StaleLegacyHash`,
+			lock:  ComposerLock{Hash: "f6c8a15a9e6d0fc0d3e7c5f5b8a41d2c"},
+			fresh: false,
+		},
+		{
+			name:  `NoHash`,
+			lock:  ComposerLock{},
+			fresh: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			fresh, err := test.lock.IsFresh(manifest)
+
+			is.NoErr(err)
+			is.Equal(fresh, test.fresh)
+		})
+	}
+}
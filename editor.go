@@ -0,0 +1,479 @@
+package gocomposer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ManifestEditor changes a composer.json file the way Composer's commands do, like
+// `composer require`, `composer remove` and `composer config`. Only the top level
+// members that are changed are rewritten, the rest of the file keeps its formatting,
+// and the changed members are written with the indentation, line endings and slash
+// escaping of the file.
+type ManifestEditor struct {
+	data []byte
+}
+
+// NewManifestEditor creates a ManifestEditor for the contents of a composer.json file.
+func NewManifestEditor(data []byte) (*ManifestEditor, error) {
+	if _, err := Parse(data); err != nil {
+		return nil, err
+	}
+	return &ManifestEditor{data: append([]byte{}, data...)}, nil
+}
+
+// LoadManifestEditor creates a ManifestEditor for the composer.json file at path.
+func LoadManifestEditor(path string) (*ManifestEditor, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewManifestEditor(data)
+}
+
+// Editor returns a ManifestEditor for the contents Encode returns for c. Changes made
+// with the editor are not applied to c, use ManifestEditor.ComposerJSON to get the
+// result.
+func (c *ComposerJSON) Editor() (*ManifestEditor, error) {
+	data, err := c.Encode()
+	if err != nil {
+		return nil, err
+	}
+	return NewManifestEditor(data)
+}
+
+// Bytes returns the edited contents of the composer.json file.
+func (e *ManifestEditor) Bytes() []byte {
+	return append([]byte{}, e.data...)
+}
+
+// ComposerJSON parses the edited contents of the composer.json file.
+func (e *ManifestEditor) ComposerJSON() (*ComposerJSON, error) {
+	return Parse(e.data)
+}
+
+// Save writes the edited contents to the composer.json file at path. An existing file
+// keeps its permissions, new files are created with 0644.
+func (e *ManifestEditor) Save(path string) error {
+	return writeManifest(path, e.data)
+}
+
+// AddRequire adds a package to require, or to require-dev if dev is true, like
+// `composer require`. If the package is already required in the section, matching its
+// name without case, its constraint is replaced where it is. If it is required in the
+// other section, it is removed from there. New packages are added to the end of the
+// section, or the whole section is sorted if config.sort-packages is true.
+func (e *ManifestEditor) AddRequire(name, constraint string, dev bool) error {
+	if _, err := ParseConstraint(constraint); err != nil {
+		return fmt.Errorf("invalid constraint for %s: %w", name, err)
+	}
+	section, other := requireSections(dev)
+	if err := e.removeLink(other, name); err != nil {
+		return err
+	}
+	return e.addLink(section, name, constraint)
+}
+
+// RemoveRequire removes a package from require, or from require-dev if dev is true,
+// like `composer remove`. The name is matched without case. It returns false if the
+// package is not required in the section. The section is kept even if it becomes
+// empty.
+func (e *ManifestEditor) RemoveRequire(name string, dev bool) (bool, error) {
+	section, _ := requireSections(dev)
+	links, err := e.object(section)
+	if err != nil {
+		return false, err
+	}
+	if _, ok := findKeyFold(links, name); !ok {
+		return false, nil
+	}
+	return true, e.removeLink(section, name)
+}
+
+// MoveToDev moves a package from require to require-dev, keeping its constraint. It
+// returns an error if the package is not in require.
+func (e *ManifestEditor) MoveToDev(name string) error {
+	links, err := e.object("require")
+	if err != nil {
+		return err
+	}
+	key, ok := findKeyFold(links, name)
+	if !ok {
+		return fmt.Errorf("%s is not required in require", name)
+	}
+	constraint, _ := links.values[key].(string)
+	if err := e.removeLink("require", key); err != nil {
+		return err
+	}
+	return e.addLink("require-dev", key, constraint)
+}
+
+// SetConfig sets a config option like `composer config`. A key with a dot sets an
+// option of an object option, e.g. "platform.php" sets the php key of config.platform.
+// value is encoded with encoding/json. A nil value removes the option. New options
+// are added to the end of config.
+func (e *ManifestEditor) SetConfig(key string, value interface{}) error {
+	return e.setNested("config", strings.SplitN(key, ".", 2), value)
+}
+
+// SetExtra sets a value of extra like `composer config extra.key value`. Dots separate
+// the keys of nested objects, e.g. "branch-alias.dev-main". value is encoded with
+// encoding/json. A nil value removes the key.
+func (e *ManifestEditor) SetExtra(key string, value interface{}) error {
+	return e.setNested("extra", strings.Split(key, "."), value)
+}
+
+// AddRepository adds a repository with a name like `composer config repositories.name`.
+// A repository that already has the name is replaced. If prepend is true, the
+// repository is added before the other repositories so it takes precedence over them,
+// which is what Composer does unless --append is used. Like Composer, a list of
+// repositories is turned into an object keyed by the index of each repository, except
+// for a disabled packagist.org, which keeps its name.
+func (e *ManifestEditor) AddRepository(name string, repo Repository, prepend bool) error {
+	encoded, err := toOrdered(repo)
+	if err != nil {
+		return err
+	}
+	repos, err := e.repositories()
+	if err != nil {
+		return err
+	}
+
+	repos.remove(name)
+	if prepend {
+		repos.keys = append([]string{name}, repos.keys...)
+		repos.values[name] = encoded
+	} else {
+		repos.set(name, encoded)
+	}
+	return e.setMember("repositories", repos)
+}
+
+// RemoveRepository removes the repository with a name, which is its index if the
+// repositories are a list. It returns false if there is no such repository.
+func (e *ManifestEditor) RemoveRepository(name string) (bool, error) {
+	value, exists, err := e.member("repositories")
+	if err != nil || !exists {
+		return false, err
+	}
+	if list, ok := value.([]interface{}); ok {
+		for i := range list {
+			if fmt.Sprint(i) == name {
+				return true, e.setMember("repositories", append(list[:i:i], list[i+1:]...))
+			}
+		}
+		return false, nil
+	}
+	repos, ok := value.(*orderedObject)
+	if !ok {
+		return false, fmt.Errorf("repositories must be an object or an array")
+	}
+	if _, exists := repos.get(name); !exists {
+		return false, nil
+	}
+	repos.remove(name)
+	return true, e.setMember("repositories", repos)
+}
+
+// AddAutoloadNamespace maps a namespace to a path in the psr-4 or psr-0 rules of
+// autoload, or of autoload-dev if dev is true. A namespace that is already mapped gets
+// the path added to its paths, unless it is already one of them.
+func (e *ManifestEditor) AddAutoloadNamespace(autoloadType, namespace, path string, dev bool) error {
+	switch autoloadType {
+	case "psr-4":
+		if namespace != "" && !strings.HasSuffix(namespace, `\`) {
+			return fmt.Errorf("a non-empty PSR-4 prefix must end with a namespace separator: %s", namespace)
+		}
+	case "psr-0":
+	default:
+		return fmt.Errorf(`autoload type "%s" is not a namespace type, must be psr-4 or psr-0`, autoloadType)
+	}
+
+	section := "autoload"
+	if dev {
+		section = "autoload-dev"
+	}
+	autoload, err := e.object(section)
+	if err != nil {
+		return err
+	}
+	rules, err := objectValue(autoload, autoloadType)
+	if err != nil {
+		return fmt.Errorf("%s.%s: %w", section, autoloadType, err)
+	}
+
+	switch paths := rules.values[namespace].(type) {
+	case nil:
+		rules.set(namespace, path)
+	case string:
+		if paths != path {
+			rules.set(namespace, []interface{}{paths, path})
+		}
+	case []interface{}:
+		for _, existing := range paths {
+			if existing == path {
+				return nil
+			}
+		}
+		rules.set(namespace, append(paths, path))
+	default:
+		return fmt.Errorf("%s.%s: the paths of %s must be a string or an array", section, autoloadType, namespace)
+	}
+	autoload.set(autoloadType, rules)
+	return e.setMember(section, autoload)
+}
+
+// requireSections returns the section a link is added to and the other one.
+func requireSections(dev bool) (string, string) {
+	if dev {
+		return "require-dev", "require"
+	}
+	return "require", "require-dev"
+}
+
+// addLink adds or replaces a link of a require section, like Composer's
+// JsonManipulator::addLink.
+func (e *ManifestEditor) addLink(section, name, constraint string) error {
+	links, err := e.object(section)
+	if err != nil {
+		return err
+	}
+	if key, ok := findKeyFold(links, name); ok {
+		links.rename(key, name)
+	}
+	links.set(name, constraint)
+
+	sortPackages, err := e.sortPackages()
+	if err != nil {
+		return err
+	}
+	if sortPackages {
+		sort.SliceStable(links.keys, func(i, j int) bool {
+			return strnatcmp(sortPackagesKey(links.keys[i]), sortPackagesKey(links.keys[j])) < 0
+		})
+	}
+	return e.setMember(section, links)
+}
+
+// removeLink removes a link from a require section if the section requires it.
+func (e *ManifestEditor) removeLink(section, name string) error {
+	value, exists, err := e.member(section)
+	if err != nil || !exists {
+		return err
+	}
+	links, ok := value.(*orderedObject)
+	if !ok {
+		return nil
+	}
+	key, ok := findKeyFold(links, name)
+	if !ok {
+		return nil
+	}
+	links.remove(key)
+	return e.setMember(section, links)
+}
+
+// sortPackages returns the config.sort-packages option.
+func (e *ManifestEditor) sortPackages() (bool, error) {
+	c, err := e.ComposerJSON()
+	if err != nil {
+		return false, err
+	}
+	return c.Config.GetSortPackages(), nil
+}
+
+// sortPackagesPrefixes order the platform packages before the other packages when
+// config.sort-packages is true, like Composer's JsonManipulator::sortPackages.
+var sortPackagesPrefixes = []struct {
+	regex  *regexp.Regexp
+	prefix string
+}{
+	{regexp.MustCompile(`^php`), "0-"},
+	{regexp.MustCompile(`^hhvm`), "1-"},
+	{regexp.MustCompile(`^ext`), "2-"},
+	{regexp.MustCompile(`^lib`), "3-"},
+	{regexp.MustCompile(`^\D`), "4-"},
+}
+
+// sortPackagesKey returns the string a package name is sorted by.
+func sortPackagesKey(name string) string {
+	if !isPlatformPackage(name) {
+		return "5-" + name
+	}
+	for _, p := range sortPackagesPrefixes {
+		if p.regex.MatchString(name) {
+			name = p.prefix + name
+		}
+	}
+	return name
+}
+
+// setNested sets or, if value is nil, removes the value at a path of keys in a top
+// level object member.
+func (e *ManifestEditor) setNested(member string, keys []string, value interface{}) error {
+	if _, exists, err := e.member(member); value == nil && (err != nil || !exists) {
+		return err
+	}
+	root, err := e.object(member)
+	if err != nil {
+		return err
+	}
+	encoded, err := toOrdered(value)
+	if err != nil {
+		return err
+	}
+
+	parent := root
+	for i, key := range keys[:len(keys)-1] {
+		if value == nil {
+			child, ok := parent.values[key].(*orderedObject)
+			if !ok {
+				return nil
+			}
+			parent = child
+			continue
+		}
+		child, err := objectValue(parent, key)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", member, strings.Join(keys[:i+1], "."), err)
+		}
+		parent.set(key, child)
+		parent = child
+	}
+
+	last := keys[len(keys)-1]
+	if value == nil {
+		parent.remove(last)
+	} else {
+		parent.set(last, encoded)
+	}
+	return e.setMember(member, root)
+}
+
+// repositories returns the repositories as an object. A list is turned into an object
+// the way PHP's json_encode writes an array with both integer and string keys.
+func (e *ManifestEditor) repositories() (*orderedObject, error) {
+	value, exists, err := e.member("repositories")
+	if err != nil || !exists {
+		return newOrderedObject(), err
+	}
+	if repos, ok := value.(*orderedObject); ok {
+		return repos, nil
+	}
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("repositories must be an object or an array")
+	}
+
+	repos := newOrderedObject()
+	packagistDisabled := false
+	for i, item := range list {
+		if repo, ok := item.(*orderedObject); ok && !packagistDisabled && len(repo.keys) == 1 {
+			key := repo.keys[0]
+			if (key == "packagist" || key == "packagist.org") && repo.values[key] == false {
+				packagistDisabled = true
+				continue
+			}
+		}
+		repos.set(fmt.Sprint(i), item)
+	}
+	if packagistDisabled {
+		repos.set("packagist.org", false)
+	}
+	return repos, nil
+}
+
+// object returns a top level member that is an object, or a new object if the member
+// does not exist or is an empty array.
+func (e *ManifestEditor) object(key string) (*orderedObject, error) {
+	value, exists, err := e.member(key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return newOrderedObject(), nil
+	}
+	wrapper := newOrderedObject()
+	wrapper.set(key, value)
+	return objectValue(wrapper, key)
+}
+
+// objectValue returns the value of a key of o if it is an object, or a new object if
+// the key does not exist or is an empty array, which is how PHP encodes an empty
+// object.
+func objectValue(o *orderedObject, key string) (*orderedObject, error) {
+	switch value := o.values[key].(type) {
+	case nil:
+		return newOrderedObject(), nil
+	case *orderedObject:
+		return value, nil
+	case []interface{}:
+		if len(value) == 0 {
+			return newOrderedObject(), nil
+		}
+	}
+	return nil, fmt.Errorf("%s must be an object", key)
+}
+
+// findKeyFold returns the key of o that matches name without case.
+func findKeyFold(o *orderedObject, name string) (string, bool) {
+	if _, exists := o.values[name]; exists {
+		return name, true
+	}
+	for _, key := range o.keys {
+		if strings.EqualFold(key, name) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// toOrdered encodes a value with encoding/json and decodes it as an ordered value.
+func toOrdered(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return decodeOrdered(data)
+}
+
+// member returns the decoded value of a top level member.
+func (e *ManifestEditor) member(key string) (interface{}, bool, error) {
+	doc, err := scanJSONDocument(e.data)
+	if err != nil {
+		return nil, false, err
+	}
+	raw, exists := doc.rawValue(key)
+	if !exists {
+		return nil, false, nil
+	}
+	value, err := decodeOrdered(raw)
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// setMember replaces a top level member, or adds it to the end of the file if it does
+// not exist.
+func (e *ManifestEditor) setMember(key string, value interface{}) error {
+	doc, err := scanJSONDocument(e.data)
+	if err != nil {
+		return err
+	}
+	buf := bytes.Buffer{}
+	if err := encodePretty(&buf, value, doc.format(), 1); err != nil {
+		return err
+	}
+	data := doc.apply([]jsonEdit{{key: key, value: buf.Bytes()}})
+	if _, err := Parse(data); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	e.data = data
+	return nil
+}
@@ -0,0 +1,458 @@
+package gocomposer
+
+import (
+	"errors"
+	is2 "github.com/matryer/is"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManifestEditor(t *testing.T) {
+	tests := []struct {
+		name   string
+		edit   func(e *ManifestEditor) error
+		output string
+	}{
+		{
+			name: `AddRequire`,
+			edit: func(e *ManifestEditor) error {
+				return e.AddRequire("psr/log", "^3.0", false)
+			},
+			output: `{
+    "name": "acme\/blog",
+    "type": "project",
+    "description": "The Acme blog",
+    "license": "MIT",
+    "require": {
+        "php": "^8.1",
+        "symfony\/console": "^6.3",
+        "monolog\/monolog": "^3.0",
+        "psr\/log": "^3.0"
+    },
+    "require-dev": {
+        "phpunit\/phpunit": "^10.0"
+    },
+    "autoload": {
+        "psr-4": {
+            "Acme\\Blog\\": "src\/"
+        }
+    },
+    "x-custom": {"keep": [1, 2,   3]},
+    "minimum-stability": "stable"
+}
+`,
+		},
+		{
+			name: `AddRequireReplacesConstraint`,
+			edit: func(e *ManifestEditor) error {
+				return e.AddRequire("Monolog/Monolog", "^3.5", false)
+			},
+			output: `{
+    "name": "acme\/blog",
+    "type": "project",
+    "description": "The Acme blog",
+    "license": "MIT",
+    "require": {
+        "php": "^8.1",
+        "symfony\/console": "^6.3",
+        "Monolog\/Monolog": "^3.5"
+    },
+    "require-dev": {
+        "phpunit\/phpunit": "^10.0"
+    },
+    "autoload": {
+        "psr-4": {
+            "Acme\\Blog\\": "src\/"
+        }
+    },
+    "x-custom": {"keep": [1, 2,   3]},
+    "minimum-stability": "stable"
+}
+`,
+		},
+		{
+			name: `AddRequireSortPackages`,
+			edit: func(e *ManifestEditor) error {
+				if err := e.SetConfig("sort-packages", true); err != nil {
+					return err
+				}
+				if err := e.AddRequire("ext-json", "*", false); err != nil {
+					return err
+				}
+				// symfony/console is moved from require to require-dev.
+				return e.AddRequire("symfony/console", "^6.4", true)
+			},
+			output: `{
+    "name": "acme\/blog",
+    "type": "project",
+    "description": "The Acme blog",
+    "license": "MIT",
+    "require": {
+        "php": "^8.1",
+        "ext-json": "*",
+        "monolog\/monolog": "^3.0"
+    },
+    "require-dev": {
+        "phpunit\/phpunit": "^10.0",
+        "symfony\/console": "^6.4"
+    },
+    "autoload": {
+        "psr-4": {
+            "Acme\\Blog\\": "src\/"
+        }
+    },
+    "x-custom": {"keep": [1, 2,   3]},
+    "minimum-stability": "stable",
+    "config": {
+        "sort-packages": true
+    }
+}
+`,
+		},
+		{
+			name: `RemoveRequire`,
+			edit: func(e *ManifestEditor) error {
+				removed, err := e.RemoveRequire("PHPUnit/phpunit", true)
+				if !removed {
+					return errors.New("not removed")
+				}
+				return err
+			},
+			output: `{
+    "name": "acme\/blog",
+    "type": "project",
+    "description": "The Acme blog",
+    "license": "MIT",
+    "require": {
+        "php": "^8.1",
+        "symfony\/console": "^6.3",
+        "monolog\/monolog": "^3.0"
+    },
+    "require-dev": {},
+    "autoload": {
+        "psr-4": {
+            "Acme\\Blog\\": "src\/"
+        }
+    },
+    "x-custom": {"keep": [1, 2,   3]},
+    "minimum-stability": "stable"
+}
+`,
+		},
+		{
+			name: `MoveToDev`,
+			edit: func(e *ManifestEditor) error {
+				return e.MoveToDev("monolog/monolog")
+			},
+			output: `{
+    "name": "acme\/blog",
+    "type": "project",
+    "description": "The Acme blog",
+    "license": "MIT",
+    "require": {
+        "php": "^8.1",
+        "symfony\/console": "^6.3"
+    },
+    "require-dev": {
+        "phpunit\/phpunit": "^10.0",
+        "monolog\/monolog": "^3.0"
+    },
+    "autoload": {
+        "psr-4": {
+            "Acme\\Blog\\": "src\/"
+        }
+    },
+    "x-custom": {"keep": [1, 2,   3]},
+    "minimum-stability": "stable"
+}
+`,
+		},
+		{
+			name: `SetConfig`,
+			edit: func(e *ManifestEditor) error {
+				if err := e.SetConfig("platform.php", "8.1.0"); err != nil {
+					return err
+				}
+				return e.SetConfig("platform.ext-pcntl", false)
+			},
+			output: `{
+    "name": "acme\/blog",
+    "type": "project",
+    "description": "The Acme blog",
+    "license": "MIT",
+    "require": {
+        "php": "^8.1",
+        "symfony\/console": "^6.3",
+        "monolog\/monolog": "^3.0"
+    },
+    "require-dev": {
+        "phpunit\/phpunit": "^10.0"
+    },
+    "autoload": {
+        "psr-4": {
+            "Acme\\Blog\\": "src\/"
+        }
+    },
+    "x-custom": {"keep": [1, 2,   3]},
+    "minimum-stability": "stable",
+    "config": {
+        "platform": {
+            "php": "8.1.0",
+            "ext-pcntl": false
+        }
+    }
+}
+`,
+		},
+		{
+			name: `AddRepository`,
+			edit: func(e *ManifestEditor) error {
+				acme := Repository{Type: TypeComposer, Composer: ComposerRepository{Type: "composer", URL: "https://repo.acme.test"}}
+				if err := e.AddRepository("acme", acme, true); err != nil {
+					return err
+				}
+				local := Repository{Type: TypePath, Path: PathRepository{Type: "path", URL: "../lib"}}
+				return e.AddRepository("local", local, true)
+			},
+			output: `{
+    "name": "acme\/blog",
+    "type": "project",
+    "description": "The Acme blog",
+    "license": "MIT",
+    "require": {
+        "php": "^8.1",
+        "symfony\/console": "^6.3",
+        "monolog\/monolog": "^3.0"
+    },
+    "require-dev": {
+        "phpunit\/phpunit": "^10.0"
+    },
+    "autoload": {
+        "psr-4": {
+            "Acme\\Blog\\": "src\/"
+        }
+    },
+    "x-custom": {"keep": [1, 2,   3]},
+    "minimum-stability": "stable",
+    "repositories": {
+        "local": {
+            "type": "path",
+            "url": "..\/lib"
+        },
+        "acme": {
+            "type": "composer",
+            "url": "https:\/\/repo.acme.test"
+        }
+    }
+}
+`,
+		},
+		{
+			name: `SetExtra`,
+			edit: func(e *ManifestEditor) error {
+				return e.SetExtra("branch-alias.dev-main", "1.0.x-dev")
+			},
+			output: `{
+    "name": "acme\/blog",
+    "type": "project",
+    "description": "The Acme blog",
+    "license": "MIT",
+    "require": {
+        "php": "^8.1",
+        "symfony\/console": "^6.3",
+        "monolog\/monolog": "^3.0"
+    },
+    "require-dev": {
+        "phpunit\/phpunit": "^10.0"
+    },
+    "autoload": {
+        "psr-4": {
+            "Acme\\Blog\\": "src\/"
+        }
+    },
+    "x-custom": {"keep": [1, 2,   3]},
+    "minimum-stability": "stable",
+    "extra": {
+        "branch-alias": {
+            "dev-main": "1.0.x-dev"
+        }
+    }
+}
+`,
+		},
+		{
+			name: `AddAutoloadNamespace`,
+			edit: func(e *ManifestEditor) error {
+				if err := e.AddAutoloadNamespace("psr-4", `Acme\Blog\`, "lib/", false); err != nil {
+					return err
+				}
+				return e.AddAutoloadNamespace("psr-4", `Acme\Blog\Tests\`, "tests/", true)
+			},
+			output: `{
+    "name": "acme\/blog",
+    "type": "project",
+    "description": "The Acme blog",
+    "license": "MIT",
+    "require": {
+        "php": "^8.1",
+        "symfony\/console": "^6.3",
+        "monolog\/monolog": "^3.0"
+    },
+    "require-dev": {
+        "phpunit\/phpunit": "^10.0"
+    },
+    "autoload": {
+        "psr-4": {
+            "Acme\\Blog\\": [
+                "src\/",
+                "lib\/"
+            ]
+        }
+    },
+    "x-custom": {"keep": [1, 2,   3]},
+    "minimum-stability": "stable",
+    "autoload-dev": {
+        "psr-4": {
+            "Acme\\Blog\\Tests\\": "tests\/"
+        }
+    }
+}
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			e, err := LoadManifestEditor("testdata/manifest/composer.json")
+			is.NoErr(err)
+			is.NoErr(test.edit(e))
+			is.Equal(string(e.Bytes()), test.output)
+
+			_, err = e.ComposerJSON()
+			is.NoErr(err)
+		})
+	}
+}
+
+func TestManifestEditor_Repositories(t *testing.T) {
+	is := is2.New(t)
+
+	e, err := NewManifestEditor([]byte(`{
+  "repositories": [
+    {"type": "vcs", "url": "https://github.com/acme/http"},
+    {"packagist.org": false}
+  ]
+}
+`))
+	is.NoErr(err)
+
+	removed, err := e.RemoveRepository("acme")
+	is.NoErr(err)
+	is.True(!removed)
+
+	// A list of repositories becomes an object when a named repository is added.
+	local := Repository{Type: TypePath, Path: PathRepository{Type: "path", URL: "../lib"}}
+	is.NoErr(e.AddRepository("local", local, false))
+	is.Equal(string(e.Bytes()), `{
+  "repositories": {
+    "0": {
+      "type": "vcs",
+      "url": "https://github.com/acme/http"
+    },
+    "packagist.org": false,
+    "local": {
+      "type": "path",
+      "url": "../lib"
+    }
+  }
+}
+`)
+	c, err := e.ComposerJSON()
+	is.NoErr(err)
+	repo, ok := c.Repositories.GetRepo("local")
+	is.True(ok)
+	is.Equal(repo.Path.URL, "../lib")
+
+	removed, err = e.RemoveRepository("0")
+	is.NoErr(err)
+	is.True(removed)
+	removed, err = e.RemoveRepository("packagist.org")
+	is.NoErr(err)
+	is.True(removed)
+	is.Equal(string(e.Bytes()), `{
+  "repositories": {
+    "local": {
+      "type": "path",
+      "url": "../lib"
+    }
+  }
+}
+`)
+
+	// Repositories in a list are removed by index.
+	e, err = NewManifestEditor([]byte(`{"repositories": [{"type": "vcs", "url": "https://github.com/acme/http"}, {"packagist.org": false}]}`))
+	is.NoErr(err)
+	removed, err = e.RemoveRepository("1")
+	is.NoErr(err)
+	is.True(removed)
+	is.Equal(string(e.Bytes()), `{"repositories": [
+        {
+            "type": "vcs",
+            "url": "https://github.com/acme/http"
+        }
+    ]}`)
+}
+
+func TestManifestEditor_Errors(t *testing.T) {
+	is := is2.New(t)
+
+	e, err := LoadManifestEditor("testdata/manifest/composer.json")
+	is.NoErr(err)
+	original := e.Bytes()
+
+	err = e.AddRequire("psr/log", "^3.0 ||| foo", false)
+	is.True(err != nil)
+	err = e.MoveToDev("psr/log")
+	is.Equal(err.Error(), "psr/log is not required in require")
+	err = e.AddAutoloadNamespace("psr-4", `Acme\Blog`, "src/", false)
+	is.Equal(err.Error(), `a non-empty PSR-4 prefix must end with a namespace separator: Acme\Blog`)
+	err = e.AddAutoloadNamespace("classmap", "", "src/", false)
+	is.Equal(err.Error(), `autoload type "classmap" is not a namespace type, must be psr-4 or psr-0`)
+	err = e.SetExtra("x", make(chan int))
+	is.True(err != nil)
+
+	// Removing options that do not exist does not add the sections.
+	is.NoErr(e.SetConfig("platform.php", nil))
+	is.NoErr(e.SetExtra("branch-alias.dev-main", nil))
+	is.Equal(string(e.Bytes()), string(original))
+
+	_, err = NewManifestEditor([]byte(`["not", "an", "object"]`))
+	is.True(err != nil)
+}
+
+func TestManifestEditor_Save(t *testing.T) {
+	is := is2.New(t)
+
+	c, err := Load("testdata/manifest/composer.json")
+	is.NoErr(err)
+	c.Description = "The Acme blog/news site"
+	e, err := c.Editor()
+	is.NoErr(err)
+	is.NoErr(e.SetConfig("sort-packages", true))
+	is.NoErr(e.AddRequire("psr/log", "^3.0", false))
+
+	path := filepath.Join(t.TempDir(), "composer.json")
+	is.NoErr(e.Save(path))
+	data, err := os.ReadFile(path)
+	is.NoErr(err)
+	is.Equal(data, e.Bytes())
+
+	saved, err := Load(path)
+	is.NoErr(err)
+	is.Equal(saved.Description, "The Acme blog/news site")
+	is.Equal(saved.Require["psr/log"], "^3.0")
+	is.True(saved.Config.GetSortPackages())
+}
@@ -0,0 +1,468 @@
+package gocomposer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// ExtraMember is a key of Extra and its value.
+type ExtraMember struct {
+	Key   string
+	Value interface{}
+}
+
+// Extra is the extra section of a package, which holds arbitrary data for scripts and
+// plugins. Plugins such as composer/installers and cweagans/composer-patches depend on
+// the order of keys, so Extra is a list of members in the order of the file and nested
+// objects are decoded into Extra too. Other values are decoded into []interface{},
+// string, json.Number, bool and nil, so every key and number is kept as it was when
+// the package is encoded again. The methods read the keys of well-known plugins.
+type Extra []ExtraMember
+
+func (e *Extra) UnmarshalJSON(data []byte) error {
+	decoded, err := decodeOrdered(data)
+	if err != nil || decoded == nil {
+		return err
+	}
+	extra, ok := extraObject(extraValue(decoded))
+	if !ok {
+		return fmt.Errorf("extra must be an object")
+	}
+	*e = extra
+	return nil
+}
+
+func (e Extra) MarshalJSON() ([]byte, error) {
+	if e == nil {
+		return []byte("null"), nil
+	}
+	buf := bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, member := range e {
+		key, err := json.Marshal(member.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(member.Value)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// extraValue converts a value produced by decodeOrdered into the values Extra holds.
+func extraValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case *orderedObject:
+		extra := make(Extra, 0, len(value.keys))
+		for _, key := range value.keys {
+			extra = append(extra, ExtraMember{Key: key, Value: extraValue(value.values[key])})
+		}
+		return extra
+	case []interface{}:
+		list := make([]interface{}, len(value))
+		for i, item := range value {
+			list[i] = extraValue(item)
+		}
+		return list
+	}
+	return value
+}
+
+// extraObject returns a value of Extra as an object. Like PHP, an empty list is an
+// empty object.
+func extraObject(value interface{}) (Extra, bool) {
+	switch value := value.(type) {
+	case Extra:
+		return value, true
+	case []interface{}:
+		if len(value) == 0 {
+			return Extra{}, true
+		}
+	}
+	return nil, false
+}
+
+// Get returns the value of key. It returns false if key is not set.
+func (e Extra) Get(key string) (interface{}, bool) {
+	for _, member := range e {
+		if member.Key == key {
+			return member.Value, true
+		}
+	}
+	return nil, false
+}
+
+// decode decodes the value of key into v. It returns false if key is not set or its
+// value does not fit v.
+func (e Extra) decode(key string, v interface{}) bool {
+	value, ok := e.Get(key)
+	if !ok || value == nil {
+		return false
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
+
+// Set sets key to value, which is converted to the values Extra holds, so typed values
+// such as LaravelExtra can be stored. A key that is already set keeps its position,
+// new keys are added to the end.
+func (e *Extra) Set(key string, value interface{}) error {
+	decoded, err := toOrdered(value)
+	if err != nil {
+		return err
+	}
+	for i, member := range *e {
+		if member.Key == key {
+			(*e)[i].Value = extraValue(decoded)
+			return nil
+		}
+	}
+	*e = append(*e, ExtraMember{Key: key, Value: extraValue(decoded)})
+	return nil
+}
+
+// BranchAliases returns the branches (keys) and the versions they are aliased to
+// (values) of branch-alias, e.g. "dev-main": "2.x-dev". Entries that are not strings
+// are left out.
+func (e Extra) BranchAliases() map[string]string {
+	value, _ := e.Get("branch-alias")
+	branches, ok := extraObject(value)
+	if !ok {
+		return nil
+	}
+	aliases := make(map[string]string, len(branches))
+	for _, branch := range branches {
+		if alias, ok := branch.Value.(string); ok {
+			aliases[branch.Key] = alias
+		}
+	}
+	return aliases
+}
+
+// PluginClass returns the class key of a composer-plugin package, which names the
+// classes that implement the plugin. It returns false if it is not set.
+func (e Extra) PluginClass() ([]string, bool) {
+	var class StringOrSlice
+	if !e.decode("class", &class) || len(class) == 0 {
+		return nil, false
+	}
+	return class, true
+}
+
+// InstallerPaths returns the installer-paths of composer/installers in the order of
+// the file, which is the order composer/installers tries them in. It returns false if
+// it is not set or is not an object of install paths and package names.
+func (e Extra) InstallerPaths() ([]InstallerPath, bool) {
+	value, _ := e.Get("installer-paths")
+	object, ok := extraObject(value)
+	if !ok {
+		return nil, false
+	}
+	paths := make([]InstallerPath, 0, len(object))
+	for _, member := range object {
+		names := make([]string, 0)
+		switch value := member.Value.(type) {
+		case string:
+			names = append(names, value)
+		case []interface{}:
+			for _, name := range value {
+				name, ok := name.(string)
+				if !ok {
+					return nil, false
+				}
+				names = append(names, name)
+			}
+		default:
+			return nil, false
+		}
+		paths = append(paths, InstallerPath{Path: member.Key, Names: names})
+	}
+	return paths, true
+}
+
+// InstallerName returns the installer-name of composer/installers, which replaces the
+// name of the package in its install path. It returns false if it is not set.
+func (e Extra) InstallerName() (string, bool) {
+	var name string
+	if !e.decode("installer-name", &name) || name == "" {
+		return "", false
+	}
+	return name, true
+}
+
+// LaravelExtra is the laravel key, which Laravel's package discovery reads.
+type LaravelExtra struct {
+	// Service provider classes registered by the package.
+	Providers []string `json:"providers,omitempty"`
+
+	// Facade aliases (keys) and the classes they alias (values).
+	Aliases map[string]string `json:"aliases,omitempty"`
+
+	// Packages whose providers and aliases are not discovered, or "*" for all of them.
+	// Only used by the root package.
+	DontDiscover []string `json:"dont-discover,omitempty"`
+
+	// Keys that are not modeled by this struct, see decodeUnknown.
+	Unknown map[string]json.RawMessage `json:"-"`
+}
+
+func (l *LaravelExtra) UnmarshalJSON(data []byte) error {
+	type plain LaravelExtra
+	if err := json.Unmarshal(data, (*plain)(l)); err != nil {
+		return err
+	}
+	unknown, err := decodeUnknown(data, l)
+	if err != nil {
+		return err
+	}
+	l.Unknown = unknown
+	return nil
+}
+
+func (l LaravelExtra) MarshalJSON() ([]byte, error) {
+	type plain LaravelExtra
+	data, err := json.Marshal(plain(l))
+	if err != nil {
+		return nil, err
+	}
+	return encodeUnknown(data, l.Unknown, l)
+}
+
+// Laravel returns the laravel key. It returns false if it is not set.
+func (e Extra) Laravel() (LaravelExtra, bool) {
+	laravel := LaravelExtra{}
+	if !e.decode("laravel", &laravel) {
+		return LaravelExtra{}, false
+	}
+	return laravel, true
+}
+
+// SymfonyExtra is the symfony key, which Symfony Flex reads from the root package.
+type SymfonyExtra struct {
+	// Whether recipes of the contrib repository are installed without asking.
+	AllowContrib bool `json:"allow-contrib,omitempty"`
+
+	// A version constraint all symfony/* packages are restricted to, e.g. "6.4.*".
+	Require string `json:"require,omitempty"`
+
+	// Whether recipes add Docker configuration. Flex asks if it is not set.
+	Docker *bool `json:"docker,omitempty"`
+
+	// The recipe endpoints. "flex://defaults" stands for the default endpoints.
+	Endpoint StringOrSlice `json:"endpoint,omitempty"`
+
+	// Keys that are not modeled by this struct, see decodeUnknown.
+	Unknown map[string]json.RawMessage `json:"-"`
+}
+
+func (s *SymfonyExtra) UnmarshalJSON(data []byte) error {
+	type plain SymfonyExtra
+	if err := json.Unmarshal(data, (*plain)(s)); err != nil {
+		return err
+	}
+	unknown, err := decodeUnknown(data, s)
+	if err != nil {
+		return err
+	}
+	s.Unknown = unknown
+	return nil
+}
+
+func (s SymfonyExtra) MarshalJSON() ([]byte, error) {
+	type plain SymfonyExtra
+	data, err := json.Marshal(plain(s))
+	if err != nil {
+		return nil, err
+	}
+	return encodeUnknown(data, s.Unknown, s)
+}
+
+// Symfony returns the symfony key. It returns false if it is not set.
+func (e Extra) Symfony() (SymfonyExtra, bool) {
+	symfony := SymfonyExtra{}
+	if !e.decode("symfony", &symfony) {
+		return SymfonyExtra{}, false
+	}
+	return symfony, true
+}
+
+// Patch is a patch cweagans/composer-patches applies to a package.
+type Patch struct {
+	// What the patch does.
+	Description string `json:"description"`
+
+	// URL or path of the patch file.
+	URL string `json:"url"`
+
+	// Optional SHA-256 checksum of the patch file.
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// Patches returns the patches of cweagans/composer-patches by package name. Patches
+// can be given as an object of descriptions (keys) and URLs (values) or as a list of
+// patch objects. Either way they are returned in the order of the file, which is the
+// order they are applied in. It returns nil if patches is not set, and an error with
+// the JSON path of the first malformed entry.
+func (e Extra) Patches() (map[string][]Patch, error) {
+	value, ok := e.Get("patches")
+	if !ok {
+		return nil, nil
+	}
+	packages, ok := extraObject(value)
+	if !ok {
+		return nil, fmt.Errorf("extra.patches must be an object")
+	}
+	patches := make(map[string][]Patch, len(packages))
+	for _, member := range packages {
+		list, err := decodePatches("extra.patches."+member.Key, member.Value)
+		if err != nil {
+			return nil, err
+		}
+		patches[member.Key] = list
+	}
+	return patches, nil
+}
+
+// decodePatches decodes the patches of a package found at path.
+func decodePatches(path string, value interface{}) ([]Patch, error) {
+	patches := make([]Patch, 0)
+	switch value := value.(type) {
+	case Extra:
+		for _, member := range value {
+			url, ok := member.Value.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s[%q] must be a string", path, member.Key)
+			}
+			patches = append(patches, Patch{Description: member.Key, URL: url})
+		}
+	case []interface{}:
+		for i, item := range value {
+			patch, ok := item.(Extra)
+			if !ok {
+				return nil, fmt.Errorf("%s[%d] must be an object", path, i)
+			}
+			fields := make(map[string]string, 3)
+			for _, key := range []string{"description", "url", "sha256"} {
+				field, exists := patch.Get(key)
+				if !exists {
+					continue
+				}
+				if fields[key], ok = field.(string); !ok {
+					return nil, fmt.Errorf("%s[%d].%s must be a string", path, i, key)
+				}
+			}
+			if fields["url"] == "" {
+				return nil, fmt.Errorf("%s[%d].url must be set", path, i)
+			}
+			patches = append(patches, Patch{Description: fields["description"], URL: fields["url"], SHA256: fields["sha256"]})
+		}
+	default:
+		return nil, fmt.Errorf("%s must be an object or a list", path)
+	}
+	return patches, nil
+}
+
+// PatchesFile returns the patches-file of cweagans/composer-patches, a JSON file with
+// the patches in its patches key. It returns false if it is not set.
+func (e Extra) PatchesFile() (string, bool) {
+	var file string
+	if !e.decode("patches-file", &file) || file == "" {
+		return "", false
+	}
+	return file, true
+}
+
+// MergePluginExtra is the merge-plugin key of wikimedia/composer-merge-plugin.
+type MergePluginExtra struct {
+	// Glob patterns of composer.json files whose settings are merged.
+	Include StringOrSlice `json:"include,omitempty"`
+
+	// Glob patterns of composer.json files that must exist and are merged.
+	Require StringOrSlice `json:"require,omitempty"`
+
+	// Whether include and require of merged files are merged too. Defaults to true.
+	Recurse *bool `json:"recurse,omitempty"`
+
+	// Whether requirements of merged files replace the ones already defined.
+	Replace bool `json:"replace,omitempty"`
+
+	// Whether duplicate requirements are ignored instead of merged.
+	IgnoreDuplicates bool `json:"ignore-duplicates,omitempty"`
+
+	// Whether require-dev of merged files is merged. Defaults to true.
+	MergeDev *bool `json:"merge-dev,omitempty"`
+
+	// Whether extra of merged files is merged.
+	MergeExtra bool `json:"merge-extra,omitempty"`
+
+	// Whether extra of merged files is merged recursively.
+	MergeExtraDeep bool `json:"merge-extra-deep,omitempty"`
+
+	// Whether replace of merged files is merged. Defaults to true.
+	MergeReplace *bool `json:"merge-replace,omitempty"`
+
+	// Whether scripts of merged files are merged.
+	MergeScripts bool `json:"merge-scripts,omitempty"`
+
+	// Keys that are not modeled by this struct, see decodeUnknown.
+	Unknown map[string]json.RawMessage `json:"-"`
+}
+
+func (m *MergePluginExtra) UnmarshalJSON(data []byte) error {
+	type plain MergePluginExtra
+	if err := json.Unmarshal(data, (*plain)(m)); err != nil {
+		return err
+	}
+	unknown, err := decodeUnknown(data, m)
+	if err != nil {
+		return err
+	}
+	m.Unknown = unknown
+	return nil
+}
+
+func (m MergePluginExtra) MarshalJSON() ([]byte, error) {
+	type plain MergePluginExtra
+	data, err := json.Marshal(plain(m))
+	if err != nil {
+		return nil, err
+	}
+	return encodeUnknown(data, m.Unknown, m)
+}
+
+// GetRecurse returns the recurse option.
+func (m MergePluginExtra) GetRecurse() bool {
+	return boolOrDefault(m.Recurse, true)
+}
+
+// GetMergeDev returns the merge-dev option.
+func (m MergePluginExtra) GetMergeDev() bool {
+	return boolOrDefault(m.MergeDev, true)
+}
+
+// GetMergeReplace returns the merge-replace option.
+func (m MergePluginExtra) GetMergeReplace() bool {
+	return boolOrDefault(m.MergeReplace, true)
+}
+
+// MergePlugin returns the merge-plugin key. It returns false if it is not set.
+func (e Extra) MergePlugin() (MergePluginExtra, bool) {
+	merge := MergePluginExtra{}
+	if !e.decode("merge-plugin", &merge) {
+		return MergePluginExtra{}, false
+	}
+	return merge, true
+}
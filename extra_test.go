@@ -0,0 +1,211 @@
+package gocomposer
+
+import (
+	"bytes"
+	"encoding/json"
+	is2 "github.com/matryer/is"
+	"testing"
+)
+
+const extraJSON = `{
+  "branch-alias": {"dev-main": "2.x-dev", "dev-next": 3},
+  "class": "Acme\\Plugin",
+  "installer-paths": {
+    "web/modules/contrib/{$name}": ["type:drupal-module"],
+    "web/libraries/{$name}": "vendor:npm-asset"
+  },
+  "installer-name": "acme-theme",
+  "laravel": {
+    "providers": ["Acme\\ServiceProvider"],
+    "aliases": {"Acme": "Acme\\Facade"},
+    "x-acme": true
+  },
+  "symfony": {
+    "allow-contrib": true,
+    "require": "6.4.*",
+    "docker": false,
+    "endpoint": ["https://recipes.acme.test/index.json", "flex://defaults"]
+  },
+  "patches": {
+    "acme/log": {
+      "Fix the timezone": "patches/timezone.patch",
+      "Add a handler": "https://patches.acme.test/handler.patch"
+    },
+    "acme/http": [
+      {"description": "Retry requests", "url": "patches/retry.patch", "sha256": "abc123"}
+    ]
+  },
+  "patches-file": "composer.patches.json",
+  "merge-plugin": {
+    "include": "composer.local.json",
+    "require": ["modules/*/composer.json"],
+    "merge-dev": false,
+    "merge-extra": true
+  },
+  "acme": {"arbitrary": [1, 2]}
+}`
+
+func TestExtra(t *testing.T) {
+	is := is2.New(t)
+
+	extra := Extra{}
+	is.NoErr(json.Unmarshal([]byte(extraJSON), &extra))
+
+	is.Equal(extra.BranchAliases(), map[string]string{"dev-main": "2.x-dev"})
+
+	class, ok := extra.PluginClass()
+	is.True(ok)
+	is.Equal(class, []string{`Acme\Plugin`})
+
+	paths, ok := extra.InstallerPaths()
+	is.True(ok)
+	is.Equal(paths, []InstallerPath{
+		{Path: "web/modules/contrib/{$name}", Names: []string{"type:drupal-module"}},
+		{Path: "web/libraries/{$name}", Names: []string{"vendor:npm-asset"}},
+	})
+
+	name, ok := extra.InstallerName()
+	is.True(ok)
+	is.Equal(name, "acme-theme")
+
+	laravel, ok := extra.Laravel()
+	is.True(ok)
+	is.Equal(laravel.Providers, []string{`Acme\ServiceProvider`})
+	is.Equal(laravel.Aliases, map[string]string{"Acme": `Acme\Facade`})
+	is.Equal(laravel.Unknown, map[string]json.RawMessage{"x-acme": json.RawMessage(`true`)})
+
+	symfony, ok := extra.Symfony()
+	is.True(ok)
+	is.True(symfony.AllowContrib)
+	is.Equal(symfony.Require, "6.4.*")
+	is.True(symfony.Docker != nil && !*symfony.Docker)
+	is.Equal(symfony.Endpoint, StringOrSlice{"https://recipes.acme.test/index.json", "flex://defaults"})
+
+	patches, err := extra.Patches()
+	is.NoErr(err)
+	is.Equal(patches, map[string][]Patch{
+		"acme/log": {
+			{Description: "Fix the timezone", URL: "patches/timezone.patch"},
+			{Description: "Add a handler", URL: "https://patches.acme.test/handler.patch"},
+		},
+		"acme/http": {
+			{Description: "Retry requests", URL: "patches/retry.patch", SHA256: "abc123"},
+		},
+	})
+
+	file, ok := extra.PatchesFile()
+	is.True(ok)
+	is.Equal(file, "composer.patches.json")
+
+	merge, ok := extra.MergePlugin()
+	is.True(ok)
+	is.Equal(merge.Include, StringOrSlice{"composer.local.json"})
+	is.Equal(merge.Require, StringOrSlice{"modules/*/composer.json"})
+	is.True(merge.GetRecurse())
+	is.True(!merge.GetMergeDev())
+	is.True(merge.GetMergeReplace())
+	is.True(merge.MergeExtra)
+
+	// Every key is written again in the order of the file.
+	data, err := json.Marshal(extra)
+	is.NoErr(err)
+	expected := bytes.Buffer{}
+	is.NoErr(json.Compact(&expected, []byte(extraJSON)))
+	is.Equal(string(data), expected.String())
+}
+
+func TestExtra_Missing(t *testing.T) {
+	is := is2.New(t)
+
+	extra := Extra{{"class", 42}, {"laravel", "providers"}, {"installer-paths", Extra{{"web/{$name}", 42}}}}
+
+	is.Equal(extra.BranchAliases(), nil)
+	_, ok := extra.PluginClass()
+	is.True(!ok)
+	_, ok = extra.InstallerPaths()
+	is.True(!ok)
+	_, ok = extra.InstallerName()
+	is.True(!ok)
+	_, ok = extra.Laravel()
+	is.True(!ok)
+	_, ok = extra.Symfony()
+	is.True(!ok)
+	patches, err := extra.Patches()
+	is.NoErr(err)
+	is.Equal(patches, nil)
+	_, ok = extra.PatchesFile()
+	is.True(!ok)
+	_, ok = extra.MergePlugin()
+	is.True(!ok)
+}
+
+func TestExtra_Set(t *testing.T) {
+	is := is2.New(t)
+
+	c, err := Parse([]byte(`{"name": "acme/app", "extra": {"acme": {"arbitrary": [1, 2]}, "laravel": {"dont-discover": ["*"], "x-acme": true}}}`))
+	is.NoErr(err)
+
+	laravel, ok := c.Extra.Laravel()
+	is.True(ok)
+	laravel.Providers = []string{`Acme\ServiceProvider`}
+	is.NoErr(c.Extra.Set("laravel", laravel))
+
+	var empty Extra
+	is.NoErr(empty.Set("installer-name", "acme-theme"))
+	is.Equal(empty, Extra{{"installer-name", "acme-theme"}})
+
+	data, err := json.Marshal(c.Extra)
+	is.NoErr(err)
+	is.Equal(string(data), `{"acme":{"arbitrary":[1,2]},"laravel":{"providers":["Acme\\ServiceProvider"],"dont-discover":["*"],"x-acme":true}}`)
+}
+
+func TestExtra_Patches(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{
+			name:  `NotAnObject`,
+			input: `{"patches": "patches.json"}`,
+			err:   `extra.patches must be an object`,
+		},
+		{
+			name:  `Package`,
+			input: `{"patches": {"acme/http": [], "acme/cache": "patches/cache.patch"}}`,
+			err:   `extra.patches.acme/cache must be an object or a list`,
+		},
+		{
+			name:  `URL`,
+			input: `{"patches": {"acme/log": {"Fix the timezone": 1}}}`,
+			err:   `extra.patches.acme/log["Fix the timezone"] must be a string`,
+		},
+		{
+			name:  `Patch`,
+			input: `{"patches": {"acme/http": ["patches/retry.patch"]}}`,
+			err:   `extra.patches.acme/http[0] must be an object`,
+		},
+		{
+			name:  `MissingURL`,
+			input: `{"patches": {"acme/http": [{"description": "Retry requests"}]}}`,
+			err:   `extra.patches.acme/http[0].url must be set`,
+		},
+		{
+			name:  `Field`,
+			input: `{"patches": {"acme/queue": [{"url": "patches/queue.patch"}, {"url": "patches/retry.patch", "sha256": 2}]}}`,
+			err:   `extra.patches.acme/queue[1].sha256 must be a string`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			extra := Extra{}
+			is.NoErr(json.Unmarshal([]byte(test.input), &extra))
+
+			_, err := extra.Patches()
+			is.Equal(err.Error(), test.err)
+		})
+	}
+}
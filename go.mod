@@ -2,4 +2,4 @@ module github.com/danielmorell/gocomposer
 
 go 1.18
 
-require github.com/matryer/is v1.4.0 // indirect
+require github.com/matryer/is v1.4.0
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -139,7 +140,7 @@ type ComposerJSON struct {
 	// autoloading.
 	//
 	// Deprecated: Not used with Composer 2.0
-	IncludePath string `json:"include-path,omitempty"`
+	IncludePath IncludePaths `json:"include-path,omitempty"`
 
 	// A set of files, or a single file, that should be treated as binaries and
 	// symlinked into bin-dir (from config).
@@ -151,7 +152,49 @@ type ComposerJSON struct {
 	// Composer options.
 	Config Config `json:"config,omitempty"`
 
-	Extra map[string]interface{} `json:"extra,omitempty"`
+	// This is an object of event or custom script names (keys) and the commands to
+	// run (values).
+	Scripts Scripts `json:"scripts,omitempty"`
+
+	// This is an object of custom script names (keys) and the descriptions shown for
+	// them by `composer list` (values).
+	ScriptsDescriptions map[string]string `json:"scripts-descriptions,omitempty"`
+
+	// This is an object of custom script names (keys) and lists of aliases the script
+	// can also be run with (values).
+	ScriptsAliases map[string][]string `json:"scripts-aliases,omitempty"`
+
+	// Arbitrary extra data for consumption by scripts and plugins.
+	Extra Extra `json:"extra,omitempty"`
+
+	// Keys that are not modeled by this struct, see decodeUnknown.
+	Unknown map[string]json.RawMessage `json:"-"`
+
+	// The file the struct was loaded from, used by Encode and Save to keep the
+	// original formatting.
+	manifest *manifest
+}
+
+func (c *ComposerJSON) UnmarshalJSON(data []byte) error {
+	type plain ComposerJSON
+	if err := json.Unmarshal(data, (*plain)(c)); err != nil {
+		return err
+	}
+	unknown, err := decodeUnknown(data, c)
+	if err != nil {
+		return err
+	}
+	c.Unknown = unknown
+	return nil
+}
+
+func (c ComposerJSON) MarshalJSON() ([]byte, error) {
+	type plain ComposerJSON
+	data, err := json.Marshal(plain(c))
+	if err != nil {
+		return nil, err
+	}
+	return encodeUnknown(data, c.Unknown, c)
 }
 
 // List of authors that contributed to the package. This is typically the main
@@ -187,6 +230,31 @@ type Autoload struct {
 	// This is an array of patterns to exclude from autoload classmap generation.
 	// (e.g. "exclude-from-classmap": ["/test/", "/tests/", "/Tests/"]
 	ExcludeFromClassMap []string `json:"exclude-from-classmap,omitempty"`
+
+	// Keys that are not modeled by this struct, see decodeUnknown.
+	Unknown map[string]json.RawMessage `json:"-"`
+}
+
+func (a *Autoload) UnmarshalJSON(data []byte) error {
+	type plain Autoload
+	if err := json.Unmarshal(data, (*plain)(a)); err != nil {
+		return err
+	}
+	unknown, err := decodeUnknown(data, a)
+	if err != nil {
+		return err
+	}
+	a.Unknown = unknown
+	return nil
+}
+
+func (a Autoload) MarshalJSON() ([]byte, error) {
+	type plain Autoload
+	data, err := json.Marshal(plain(a))
+	if err != nil {
+		return nil, err
+	}
+	return encodeUnknown(data, a.Unknown, a)
 }
 
 // Description of additional autoload rules for development purpose (eg. a test suite).
@@ -205,6 +273,34 @@ type AutoloadDev struct {
 	// This is an array of paths that contain classes to be included in the class-map
 	// generation process.
 	ClassMap []string `json:"classmap,omitempty"`
+
+	// This is an array of patterns to exclude from autoload classmap generation.
+	ExcludeFromClassMap []string `json:"exclude-from-classmap,omitempty"`
+
+	// Keys that are not modeled by this struct, see decodeUnknown.
+	Unknown map[string]json.RawMessage `json:"-"`
+}
+
+func (a *AutoloadDev) UnmarshalJSON(data []byte) error {
+	type plain AutoloadDev
+	if err := json.Unmarshal(data, (*plain)(a)); err != nil {
+		return err
+	}
+	unknown, err := decodeUnknown(data, a)
+	if err != nil {
+		return err
+	}
+	a.Unknown = unknown
+	return nil
+}
+
+func (a AutoloadDev) MarshalJSON() ([]byte, error) {
+	type plain AutoloadDev
+	data, err := json.Marshal(plain(a))
+	if err != nil {
+		return nil, err
+	}
+	return encodeUnknown(data, a.Unknown, a)
 }
 
 // Options for creating package archives for distribution.
@@ -215,6 +311,31 @@ type Archive struct {
 	// A list of patterns for paths to exclude or include if prefixed with an
 	// exclamation mark. Use like you would in a .gitignore file.
 	Exclude []string `json:"exclude,omitempty"`
+
+	// Keys that are not modeled by this struct, see decodeUnknown.
+	Unknown map[string]json.RawMessage `json:"-"`
+}
+
+func (a *Archive) UnmarshalJSON(data []byte) error {
+	type plain Archive
+	if err := json.Unmarshal(data, (*plain)(a)); err != nil {
+		return err
+	}
+	unknown, err := decodeUnknown(data, a)
+	if err != nil {
+		return err
+	}
+	a.Unknown = unknown
+	return nil
+}
+
+func (a Archive) MarshalJSON() ([]byte, error) {
+	type plain Archive
+	data, err := json.Marshal(plain(a))
+	if err != nil {
+		return nil, err
+	}
+	return encodeUnknown(data, a.Unknown, a)
 }
 
 // Composer options.
@@ -225,35 +346,236 @@ type Config struct {
 	Platform map[string]StringOrBool `json:"platform,omitempty"`
 
 	// This is an object of {"pattern": true|false} with packages which are allowed to
-	// be loaded as plugins, or true to allow all, false to allow none. Defaults to {}
-	// which prompts when an unknown plugin is added.
-	AllowPlugins map[string]bool `json:"allow-plugins,omitempty"`
+	// be loaded as plugins, or true to allow all, false to allow none. When not set
+	// Composer prompts when an unknown plugin is added.
+	AllowPlugins *AllowPlugins `json:"allow-plugins,omitempty"`
 
-	// The timeout in seconds for process executions, defaults to 300 (5mins).
-	ProcessTimeout int `json:"process-timeout,omitempty"`
+	// The timeout in seconds for process executions, defaults to 300 (5mins). 0
+	// disables the timeout.
+	ProcessTimeout *int `json:"process-timeout,omitempty"`
 
 	// If true, the Composer autoloader will also look for classes in the PHP include
 	// path.
-	UseIncludePath bool `json:"use-include-path,omitempty"`
+	UseIncludePath *bool `json:"use-include-path,omitempty"`
 
 	// When running Composer in a directory where there is no composer.json, if there is
 	// one present in a directory above Composer will by default ask you whether you
 	// want to use that directory's composer.json instead. One of: true (always use
 	// parent if needed), false (never ask or use it) or "prompt" (ask every time),
 	// defaults to prompt.
-	UseParentDir StringOrBool `json:"use-parent-dir,omitempty"`
+	UseParentDir *StringOrBool `json:"use-parent-dir,omitempty"`
 
-	// The install method Composer will prefer to use, defaults to auto and can be any
+	// The install method Composer will prefer to use, defaults to dist and can be any
 	// of source, dist, auto, or an object of {"pattern": "preference"}.
 	PreferredInstall PreferredInstall `json:"preferred-install,omitempty"`
+
+	// Security audit configuration options.
+	Audit *AuditConfig `json:"audit,omitempty"`
+
+	// What to do after prompting for authentication, one of: true (always store),
+	// false (do not store) and "prompt" (ask every time), defaults to prompt.
+	StoreAuths *StringOrBool `json:"store-auths,omitempty"`
+
+	// Protocols to use when cloning from github.com, in priority order. Defaults to
+	// ["https", "ssh", "git"].
+	GithubProtocols []string `json:"github-protocols,omitempty"`
+
+	// This is an object of domain names (keys) and OAuth tokens (values) used to access
+	// GitHub.
+	GithubOAuth map[string]string `json:"github-oauth,omitempty"`
+
+	// List of domains of GitLab servers, defaults to ["gitlab.com"].
+	GitlabDomains []string `json:"gitlab-domains,omitempty"`
+
+	// This is an object of domain names (keys) and OAuth tokens (values) used to access
+	// GitLab.
+	GitlabOAuth map[string]string `json:"gitlab-oauth,omitempty"`
+
+	// This is an object of domain names (keys) and private tokens (values) used to
+	// access GitLab. The value can also be an object with a username and token.
+	GitlabToken map[string]GitlabToken `json:"gitlab-token,omitempty"`
+
+	// A protocol to force use of when creating a repository URL for the source value
+	// of the package metadata. One of git or http. (https is treated as a synonym for
+	// http.)
+	GitlabProtocol string `json:"gitlab-protocol,omitempty"`
+
+	// If true, all HTTPS URLs will be tried with HTTP instead and no network level
+	// encryption is performed.
+	DisableTLS *bool `json:"disable-tls,omitempty"`
+
+	// If true, only HTTPS URLs are allowed to be downloaded via Composer. Defaults to
+	// true.
+	SecureHTTP *bool `json:"secure-http,omitempty"`
+
+	// This is an object of domain names (keys) and OAuth consumers (values) used to
+	// access Bitbucket.
+	BitbucketOAuth map[string]BitbucketOAuth `json:"bitbucket-oauth,omitempty"`
+
+	// Location of Certificate Authority file on local filesystem.
+	CAFile string `json:"cafile,omitempty"`
+
+	// If cafile is not specified or if the certificate is not found there, the
+	// directory pointed to by capath is searched for a suitable certificate.
+	CAPath string `json:"capath,omitempty"`
+
+	// This is an object of domain names (keys) and username/password credentials
+	// (values) used for HTTP basic authentication.
+	HTTPBasic map[string]HTTPBasicAuth `json:"http-basic,omitempty"`
+
+	// This is an object of domain names (keys) and tokens (values) used for bearer
+	// authentication.
+	Bearer map[string]string `json:"bearer,omitempty"`
+
+	// This is an object of domain names (keys) and TLS client certificates (values)
+	// used to authenticate.
+	ClientCertificate map[string]ClientCertificate `json:"client-certificate,omitempty"`
+
+	// The directory dependencies are installed in, defaults to vendor.
+	VendorDir string `json:"vendor-dir,omitempty"`
+
+	// The directory binaries are symlinked into, defaults to vendor/bin. "{$vendor-dir}"
+	// is replaced by the vendor-dir.
+	BinDir string `json:"bin-dir,omitempty"`
+
+	// The directory Composer stores data in that is not a cache, defaults to
+	// COMPOSER_HOME.
+	DataDir string `json:"data-dir,omitempty"`
+
+	// The directory all caches are stored in, defaults to $COMPOSER_HOME/cache.
+	CacheDir string `json:"cache-dir,omitempty"`
+
+	// The directory package archives are cached in, defaults to $cache-dir/files.
+	CacheFilesDir string `json:"cache-files-dir,omitempty"`
+
+	// The directory repository metadata is cached in, defaults to $cache-dir/repo.
+	CacheRepoDir string `json:"cache-repo-dir,omitempty"`
+
+	// The directory VCS clones are cached in, defaults to $cache-dir/vcs.
+	CacheVCSDir string `json:"cache-vcs-dir,omitempty"`
+
+	// The number of seconds unused cache entries are kept for, defaults to 15552000
+	// (6 months).
+	CacheTTL *int `json:"cache-ttl,omitempty"`
+
+	// The number of seconds unused package archives are kept for, defaults to the value
+	// of cache-ttl.
+	CacheFilesTTL *int `json:"cache-files-ttl,omitempty"`
+
+	// The maximum size of the package archive cache, defaults to 300MiB. It can be a
+	// number of bytes or a string with a K, M or G unit.
+	CacheFilesMaxSize *StringOrInt `json:"cache-files-maxsize,omitempty"`
+
+	// If true, the Composer cache is used in read-only mode.
+	CacheReadOnly *bool `json:"cache-read-only,omitempty"`
+
+	// The type of binaries to install, one of auto, full or proxy. Defaults to auto.
+	BinCompat string `json:"bin-compat,omitempty"`
+
+	// If false, the Composer autoloader will not be prepended to existing autoloaders.
+	// Defaults to true.
+	PrependAutoloader *bool `json:"prepend-autoloader,omitempty"`
+
+	// String to be used as a suffix for the generated Composer autoloader. When not set
+	// the content-hash of the lock file is used.
+	AutoloaderSuffix string `json:"autoloader-suffix,omitempty"`
+
+	// If true, always optimize when dumping the autoloader.
+	OptimizeAutoloader *bool `json:"optimize-autoloader,omitempty"`
+
+	// If true, the require command keeps packages sorted by name when adding a new
+	// package.
+	SortPackages *bool `json:"sort-packages,omitempty"`
+
+	// If true, the Composer autoloader will only load classes from the classmap.
+	// Implies optimize-autoloader.
+	ClassmapAuthoritative *bool `json:"classmap-authoritative,omitempty"`
+
+	// If true, the Composer autoloader will check for APCu and use it to cache found or
+	// not found classes when the extension is enabled.
+	APCuAutoloader *bool `json:"apcu-autoloader,omitempty"`
+
+	// A list of domains to use in github mode. Defaults to ["github.com"].
+	GithubDomains []string `json:"github-domains,omitempty"`
+
+	// If false, the OAuth tokens created to access the GitHub API will have a date
+	// instead of the machine hostname. Defaults to true.
+	GithubExposeHostname *bool `json:"github-expose-hostname,omitempty"`
+
+	// If false, GitHub repositories are cloned with git instead of using the GitHub API.
+	// Defaults to true.
+	UseGithubAPI *bool `json:"use-github-api,omitempty"`
+
+	// If false, Composer will not notify repositories about installed packages.
+	// Defaults to true.
+	NotifyOnInstall *bool `json:"notify-on-install,omitempty"`
+
+	// The default style of handling dirty updates when in non-interactive mode, one of
+	// true (always discard), false (always fail) or "stash" (try to stash and reapply).
+	// Defaults to false.
+	DiscardChanges *StringOrBool `json:"discard-changes,omitempty"`
+
+	// The default format of the archive command, defaults to tar.
+	ArchiveFormat string `json:"archive-format,omitempty"`
+
+	// The default destination of the archive command, defaults to ".".
+	ArchiveDir string `json:"archive-dir,omitempty"`
+
+	// If false, Composer will not create .htaccess files in the Composer home, cache,
+	// and data directories. Defaults to true.
+	HtaccessProtect *bool `json:"htaccess-protect,omitempty"`
+
+	// If false, Composer will not create a composer.lock file. Defaults to true.
+	Lock *bool `json:"lock,omitempty"`
+
+	// If true, the autoloader checks the platform requirements at runtime. One of true,
+	// false or "php-only" (only check the PHP version). Defaults to php-only.
+	PlatformCheck *StringOrBool `json:"platform-check,omitempty"`
+
+	// List of domains which should be trusted/marked as using a secure Subversion/SVN
+	// transport.
+	SecureSVNDomains []string `json:"secure-svn-domains,omitempty"`
+
+	// If true, the bump command runs after the update command. One of true, false,
+	// "dev" or "no-dev". Defaults to false.
+	BumpAfterUpdate *StringOrBool `json:"bump-after-update,omitempty"`
+
+	// If true, the install command will not fail when the lock file does not fulfil
+	// the requirements in composer.json. Defaults to false.
+	AllowMissingRequirements *bool `json:"allow-missing-requirements,omitempty"`
+
+	// Keys that are not modeled by this struct, see decodeUnknown.
+	Unknown map[string]json.RawMessage `json:"-"`
+}
+
+func (c *Config) UnmarshalJSON(data []byte) error {
+	type plain Config
+	if err := json.Unmarshal(data, (*plain)(c)); err != nil {
+		return err
+	}
+	unknown, err := decodeUnknown(data, c)
+	if err != nil {
+		return err
+	}
+	c.Unknown = unknown
+	return nil
+}
+
+func (c Config) MarshalJSON() ([]byte, error) {
+	type plain Config
+	data, err := json.Marshal(plain(c))
+	if err != nil {
+		return nil, err
+	}
+	return encodeUnknown(data, c.Unknown, c)
 }
 
 type Dist struct {
-	URL       string `json:"url"`
-	Type      string `json:"type"`
-	Reference string `json:"reference,omitempty"`
-	ShaSum    string `json:"shasum,omitempty"`
-	Mirrors   string `json:"mirrors,omitempty"`
+	URL       string   `json:"url"`
+	Type      string   `json:"type"`
+	Reference string   `json:"reference,omitempty"`
+	ShaSum    string   `json:"shasum,omitempty"`
+	Mirrors   []Mirror `json:"mirrors,omitempty"`
 }
 
 // Funding method to support the development and maintenance of the package.
@@ -486,6 +808,9 @@ type PackageRepository struct {
 	Exclude   []string       `json:"exclude,omitempty"`
 }
 
+// PackageOrSlice is the package definition of a package repository, which can be a
+// single package object or an array of packages. A single package is marshaled as an
+// object.
 type PackageOrSlice []InlinePackage
 
 func (p *PackageOrSlice) UnmarshalJSON(data []byte) error {
@@ -502,14 +827,17 @@ func (p *PackageOrSlice) UnmarshalJSON(data []byte) error {
 		return nil
 	}
 	if isArray(data) {
-		err := json.Unmarshal(data, &p)
+		err := json.Unmarshal(data, (*[]InlinePackage)(p))
 		return err
 	}
 	return nil
 }
 
 func (p PackageOrSlice) MarshalJSON() ([]byte, error) {
-	return json.Marshal(p)
+	if len(p) == 1 {
+		return json.Marshal(p[0])
+	}
+	return json.Marshal([]InlinePackage(p))
 }
 
 // PreferredInstall for the project and its dependencies. Because order matters this is
@@ -529,30 +857,33 @@ func (p *PreferredInstall) UnmarshalJSON(data []byte) error {
 		}
 
 		(*p) = append((*p), [2]string{"", value})
+		return nil
 	}
 	if isObject(data) {
-		temp := make(map[string]string)
-		err := json.Unmarshal(data, &temp)
+		// Decode the object in order, the first matching pattern wins.
+		temp, err := decodeOrdered(data)
 		if err != nil {
 			return err
 		}
-		for pattern, install := range temp {
+		obj := temp.(*orderedObject)
+		for _, pattern := range obj.keys {
+			install, ok := obj.values[pattern].(string)
+			if !ok {
+				return errors.New("preferred-install patterns must have a string value")
+			}
 			(*p) = append((*p), [2]string{pattern, install})
 		}
+		return nil
 	}
 	return errors.New("preferred-install must be a string or an object")
 }
 
 func (p PreferredInstall) MarshalJSON() ([]byte, error) {
 	if len(p) == 0 {
-		return []byte{}, nil
+		return []byte("null"), nil
 	}
 	if len(p) == 1 && p[0][0] == "" {
-		value := ""
-		for _, value = range p {
-			break
-		}
-		return json.Marshal(value)
+		return json.Marshal(p[0][1])
 	}
 
 	// Order matters so we serialize the JSON ourselves instead of transforming to a
@@ -560,17 +891,17 @@ func (p PreferredInstall) MarshalJSON() ([]byte, error) {
 	buf := strings.Builder{}
 	buf.WriteRune('{')
 	for i, v := range p {
-		pattern, err := json.Marshal([]byte(v[0]))
+		pattern, err := json.Marshal(v[0])
 		if err != nil {
 			return []byte{}, err
 		}
-		install, err := json.Marshal([]byte(v[1]))
+		install, err := json.Marshal(v[1])
 		if err != nil {
 			return []byte{}, err
 		}
 
 		buf.WriteString(fmt.Sprintf(`%s:%s`, pattern, install))
-		if i < len(p) {
+		if i < len(p)-1 {
 			buf.WriteRune(',')
 		}
 	}
@@ -578,15 +909,146 @@ func (p PreferredInstall) MarshalJSON() ([]byte, error) {
 	return []byte(buf.String()), nil
 }
 
+// InlinePackage is a complete package definition of a package repository. It has
+// the same shape as the composer.json file of the package with a version and where to
+// download it from.
 type InlinePackage struct {
-	// TODO fill this out
+	// Package name, including 'vendor-name/' prefix.
+	Name string `json:"name"`
+
+	// The version of the package.
+	Version string `json:"version"`
+
+	// Package type, e.g. 'library' or 'composer-plugin'.
+	Type string `json:"type,omitempty"`
+
+	// Short package description.
+	Description string `json:"description,omitempty"`
+
+	// A tag/keyword that this package relates to.
+	Keywords []string `json:"keywords,omitempty"`
+
+	// Homepage URL for the project.
+	Homepage string `json:"homepage,omitempty"`
+
+	// Package release date, in 'YYYY-MM-DD', 'YYYY-MM-DD HH:MM:SS' or ISO 8601 format.
+	Time string `json:"time,omitempty"`
+
+	// License name. Or an array of license names.
+	License StringOrSlice `json:"license,omitempty"`
+
+	// List of authors that contributed to the package.
+	Authors []Authors `json:"authors,omitempty"`
+
+	// Support channels for the package
+	Support *Support `json:"support,omitempty"`
+
+	// A list of options to fund the development and maintenance of the package.
+	Funding []Funding `json:"funding,omitempty"`
+
+	// Archive details.
+	Dist *Dist `json:"dist,omitempty"`
+
+	// Source code details.
+	Source *Source `json:"source,omitempty"`
+
+	// This is an object of package name (keys) and version constraints (values) that
+	// are required to run this package.
+	Require map[string]string `json:"require,omitempty"`
+
+	// This is an object of package name (keys) and version constraints (values) that
+	// this package requires for developing it.
+	RequireDev map[string]string `json:"require-dev,omitempty"`
+
+	// This is an object of package name (keys) and version constraints (values) that
+	// conflict with this package.
+	Conflict map[string]string `json:"conflict,omitempty"`
+
+	// This is an object of package name (keys) and version constraints (values) that
+	// can be replaced by this package.
+	Replace map[string]string `json:"replace,omitempty"`
+
+	// This is an object of package name (keys) and version constraints (values) that
+	// this package provides in addition to this package's name.
+	Provide map[string]string `json:"provide,omitempty"`
+
+	// This is an object of package name (keys) and descriptions (values) that this
+	// package suggests work well with it.
+	Suggest map[string]string `json:"suggest,omitempty"`
+
+	// Description of how the package can be autoloaded.
+	Autoload *Autoload `json:"autoload,omitempty"`
+
+	// Description of additional autoload rules for development purpose.
+	AutoloadDev *AutoloadDev `json:"autoload-dev,omitempty"`
+
+	// Forces the package to be installed into the given subdirectory path.
+	//
+	// Deprecated: Not used with Composer 2.0
+	TargetDir string `json:"target-dir,omitempty"`
+
+	// A list of directories which should get added to PHP's include path.
+	//
+	// Deprecated: Not used with Composer 2.0
+	IncludePath IncludePaths `json:"include-path,omitempty"`
+
+	// A set of files that should be treated as binaries and symlinked into bin-dir.
+	Bin StringOrSlice `json:"bin,omitempty"`
+
+	// Options for creating package archives for distribution.
+	Archive *Archive `json:"archive,omitempty"`
+
+	// Arbitrary extra data for consumption by scripts and plugins.
+	Extra Extra `json:"extra,omitempty"`
+
+	// URL Composer notifies after the package is installed.
+	NotificationURL string `json:"notification-url,omitempty"`
+
+	// Indicates whether this package has been abandoned, it can be boolean or a package
+	// name/URL pointing to a recommended alternative.
+	Abandoned *StringOrBool `json:"abandoned,omitempty"`
+
+	// Indicates whether this version is the default branch of the linked VCS
+	// repository.
+	DefaultBranch bool `json:"default-branch,omitempty"`
+
+	// Keys that are not modeled by this struct, see decodeUnknown.
+	Unknown map[string]json.RawMessage `json:"-"`
 }
 
-type Source struct {
-	Type      string `json:"type"`
+func (i *InlinePackage) UnmarshalJSON(data []byte) error {
+	type plain InlinePackage
+	if err := json.Unmarshal(data, (*plain)(i)); err != nil {
+		return err
+	}
+	unknown, err := decodeUnknown(data, i)
+	if err != nil {
+		return err
+	}
+	i.Unknown = unknown
+	return nil
+}
+
+func (i InlinePackage) MarshalJSON() ([]byte, error) {
+	type plain InlinePackage
+	data, err := json.Marshal(plain(i))
+	if err != nil {
+		return nil, err
+	}
+	return encodeUnknown(data, i.Unknown, i)
+}
+
+// Mirror is an alternative URL a dist or source can be downloaded from.
+type Mirror struct {
 	URL       string `json:"url"`
-	Reference string `json:"reference"`
-	Mirrors   string `json:"mirrors,omitempty"`
+	Preferred bool   `json:"preferred"`
+}
+
+type Source struct {
+	Type      string   `json:"type"`
+	URL       string   `json:"url"`
+	Reference string   `json:"reference"`
+	Mirrors   []Mirror `json:"mirrors,omitempty"`
 }
 
 type StringOrSlice []string
@@ -596,11 +1058,16 @@ func (s *StringOrSlice) UnmarshalJSON(data []byte) error {
 		return nil
 	}
 	if isString(data) {
-		(*s) = []string{string(data[1 : len(data)-1])}
+		value := ""
+		err := json.Unmarshal(data, &value)
+		if err != nil {
+			return err
+		}
+		(*s) = []string{value}
 		return nil
 	}
 	if isArray(data) {
-		err := json.Unmarshal(data, &s)
+		err := json.Unmarshal(data, (*[]string)(s))
 		if err != nil {
 			return err
 		}
@@ -610,12 +1077,30 @@ func (s *StringOrSlice) UnmarshalJSON(data []byte) error {
 
 func (s StringOrSlice) MarshalJSON() ([]byte, error) {
 	if len(s) == 0 {
-		return []byte{}, nil
+		return []byte("[]"), nil
 	}
 	if len(s) == 1 {
 		return json.Marshal(s[0])
 	}
-	return json.Marshal(s)
+	return json.Marshal([]string(s))
+}
+
+// IncludePaths is the include-path of a package. Composer's schema defines it as an
+// array of directories, but a single directory written as a string is accepted too, so
+// manifests that still have the string form can be read. It is always encoded as an
+// array.
+type IncludePaths []string
+
+func (i *IncludePaths) UnmarshalJSON(data []byte) error {
+	if isString(data) {
+		value := ""
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		*i = IncludePaths{value}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(i))
 }
 
 type StringOrBool struct {
@@ -654,24 +1139,114 @@ func (s StringOrBool) MarshalJSON() ([]byte, error) {
 	return json.Marshal(s.stringValue)
 }
 
+// StringOrBoolFromString creates a StringOrBool holding a string.
+func StringOrBoolFromString(value string) StringOrBool {
+	return StringOrBool{stringValue: value}
+}
+
+// StringOrBoolFromBool creates a StringOrBool holding a boolean.
+func StringOrBoolFromBool(value bool) StringOrBool {
+	return StringOrBool{isBool: true, boolValue: value}
+}
+
+// IsBool returns true if the value is a boolean.
+func (s StringOrBool) IsBool() bool {
+	return s.isBool
+}
+
+// Bool returns the boolean value. A string is true when it is not empty, the same as
+// PHP would cast it.
+func (s StringOrBool) Bool() bool {
+	if s.isBool {
+		return s.boolValue
+	}
+	return s.stringValue != ""
+}
+
+// String returns the string value, or "true" or "false" for a boolean.
+func (s StringOrBool) String() string {
+	if s.isBool {
+		return strconv.FormatBool(s.boolValue)
+	}
+	return s.stringValue
+}
+
+// StringOrInt is a JSON value that can be either a string or an integer.
+type StringOrInt struct {
+	isInt       bool
+	intValue    int
+	stringValue string
+}
+
+func (s *StringOrInt) UnmarshalJSON(data []byte) error {
+	if isString(data) {
+		*s = StringOrInt{}
+		return json.Unmarshal(data, &s.stringValue)
+	}
+	value := 0
+	if err := json.Unmarshal(data, &value); err != nil {
+		return errors.New("invalid value, must be type string or int")
+	}
+	*s = StringOrInt{isInt: true, intValue: value}
+	return nil
+}
+
+func (s StringOrInt) MarshalJSON() ([]byte, error) {
+	if s.isInt {
+		return json.Marshal(s.intValue)
+	}
+	return json.Marshal(s.stringValue)
+}
+
+// StringOrIntFromString creates a StringOrInt holding a string.
+func StringOrIntFromString(value string) StringOrInt {
+	return StringOrInt{stringValue: value}
+}
+
+// StringOrIntFromInt creates a StringOrInt holding an integer.
+func StringOrIntFromInt(value int) StringOrInt {
+	return StringOrInt{isInt: true, intValue: value}
+}
+
+// IsInt returns true if the value is an integer.
+func (s StringOrInt) IsInt() bool {
+	return s.isInt
+}
+
+// Int returns the integer value, or the string parsed as an integer.
+func (s StringOrInt) Int() (int, error) {
+	if s.isInt {
+		return s.intValue, nil
+	}
+	return strconv.Atoi(s.stringValue)
+}
+
+// String returns the string value, or the integer formatted as a string.
+func (s StringOrInt) String() string {
+	if s.isInt {
+		return strconv.Itoa(s.intValue)
+	}
+	return s.stringValue
+}
+
 // Support channels available for the package.
 type Support struct {
 	// Email address for support.
-	Email string `json:"email"`
+	Email string `json:"email,omitempty"`
 	// Issues tracker URL.
-	Issues string `json:"issues"`
+	Issues string `json:"issues,omitempty"`
 	// Forum URL.
-	Forum string `json:"forum"`
+	Forum string `json:"forum,omitempty"`
 	// Wiki URL.
-	Wiki string `json:"wiki"`
+	Wiki string `json:"wiki,omitempty"`
 	// IRC chanel for support, as irc://server/channel.
-	IRC string `json:"irc"`
+	IRC string `json:"irc,omitempty"`
 	// Chat support URL.
-	Chat string `json:"chat"`
+	Chat string `json:"chat,omitempty"`
 	// Source code browse or download URL.
-	Source string `json:"source"`
+	Source string `json:"source,omitempty"`
 	// Docs URL.
-	Docs string `json:"docs"`
+	Docs string `json:"docs,omitempty"`
 	// RSS feed URL.
-	RSS string `json:"rss"`
+	RSS string `json:"rss,omitempty"`
 }
@@ -136,3 +136,245 @@ func TestStringOrBool_UnmarshalJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestIncludePaths_JSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		value    IncludePaths
+		expected string
+	}{
+		{
+			name:     `Array`,
+			input:    `{"name": "acme/legacy", "include-path": ["lib/", "src/"]}`,
+			value:    IncludePaths{"lib/", "src/"},
+			expected: `["lib/","src/"]`,
+		},
+		{
+			name:     `String`,
+			input:    `{"name": "acme/legacy", "include-path": "lib/"}`,
+			value:    IncludePaths{"lib/"},
+			expected: `["lib/"]`,
+		},
+		{
+			name:     `Empty`,
+			input:    `{"name": "acme/legacy", "include-path": []}`,
+			value:    IncludePaths{},
+			expected: `[]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			c, err := Parse([]byte(test.input))
+			is.NoErr(err)
+			is.Equal(c.IncludePath, test.value)
+
+			encoded, err := json.Marshal(c.IncludePath)
+			is.NoErr(err)
+			is.Equal(string(encoded), test.expected)
+		})
+	}
+}
+
+func TestPreferredInstall_JSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		output string
+		value  PreferredInstall
+	}{
+		{
+			name:   `String`,
+			input:  `"dist"`,
+			output: `"dist"`,
+			value:  PreferredInstall{{"", "dist"}},
+		},
+		{
+			name:   `ObjectKeepsOrder`,
+			input:  `{"my-org/*": "source", "partner/*": "auto", "*": "dist"}`,
+			output: `{"my-org/*":"source","partner/*":"auto","*":"dist"}`,
+			value:  PreferredInstall{{"my-org/*", "source"}, {"partner/*", "auto"}, {"*", "dist"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			result := PreferredInstall{}
+			err := json.Unmarshal([]byte(test.input), &result)
+			is.NoErr(err)
+			is.Equal(result, test.value)
+
+			data, err := json.Marshal(result)
+			is.NoErr(err)
+			is.Equal(string(data), test.output)
+		})
+	}
+}
+
+func TestPackageOrSlice_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		value PackageOrSlice
+	}{
+		{
+			name: `Object`,
+			input: `{
+				"name": "smarty/smarty",
+				"version": "3.1.7",
+				"dist": {
+					"url": "https://www.smarty.net/files/Smarty-3.1.7.zip",
+					"type": "zip"
+				},
+				"source": {
+					"url": "https://smarty-php.googlecode.com/svn/",
+					"type": "svn",
+					"reference": "tags/Smarty_3_1_7/distribution/"
+				},
+				"autoload": {
+					"classmap": ["libs/"]
+				}
+			}`,
+			value: PackageOrSlice{{
+				Name:    "smarty/smarty",
+				Version: "3.1.7",
+				Dist: &Dist{
+					URL:  "https://www.smarty.net/files/Smarty-3.1.7.zip",
+					Type: "zip",
+				},
+				Source: &Source{
+					URL:       "https://smarty-php.googlecode.com/svn/",
+					Type:      "svn",
+					Reference: "tags/Smarty_3_1_7/distribution/",
+				},
+				Autoload: &Autoload{ClassMap: []string{"libs/"}},
+			}},
+		},
+		{
+			name: `Array`,
+			input: `[
+				{
+					"name": "acme/tool",
+					"version": "1.0.0",
+					"type": "library",
+					"require": {"php": ">=7.4"},
+					"bin": ["bin/tool"],
+					"dist": {
+						"url": "https://acme.test/tool-1.0.0.zip",
+						"type": "zip",
+						"mirrors": [{"url": "https://mirror.acme.test/%package%/%version%.zip", "preferred": true}]
+					}
+				},
+				{
+					"name": "acme/tool",
+					"version": "2.0.0",
+					"license": "MIT",
+					"extra": {"branch-alias": {"dev-main": "2.x-dev"}},
+					"x-acme": true
+				}
+			]`,
+			value: PackageOrSlice{
+				{
+					Name:    "acme/tool",
+					Version: "1.0.0",
+					Type:    "library",
+					Require: map[string]string{"php": ">=7.4"},
+					Bin:     StringOrSlice{"bin/tool"},
+					Dist: &Dist{
+						URL:  "https://acme.test/tool-1.0.0.zip",
+						Type: "zip",
+						Mirrors: []Mirror{{
+							URL:       "https://mirror.acme.test/%package%/%version%.zip",
+							Preferred: true,
+						}},
+					},
+				},
+				{
+					Name:    "acme/tool",
+					Version: "2.0.0",
+					License: StringOrSlice{"MIT"},
+					Extra: Extra{
+						{"branch-alias", Extra{{"dev-main", "2.x-dev"}}},
+					},
+					Unknown: map[string]json.RawMessage{"x-acme": json.RawMessage(`true`)},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			result := PackageOrSlice{}
+			err := json.Unmarshal([]byte(test.input), &result)
+			is.NoErr(err)
+			is.Equal(result, test.value)
+
+			// Marshaling and unmarshaling again must not lose anything.
+			data, err := json.Marshal(result)
+			is.NoErr(err)
+			again := PackageOrSlice{}
+			is.NoErr(json.Unmarshal(data, &again))
+			is.Equal(again, result)
+		})
+	}
+}
+
+func TestPackageOrSlice_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  PackageOrSlice
+		output string
+	}{
+		{
+			name:   `Single`,
+			value:  PackageOrSlice{{Name: "acme/tool", Version: "1.0.0"}},
+			output: `{"name":"acme/tool","version":"1.0.0"}`,
+		},
+		{
+			name: `Multiple`,
+			value: PackageOrSlice{
+				{Name: "acme/tool", Version: "1.0.0"},
+				{Name: "acme/tool", Version: "2.0.0"},
+			},
+			output: `[{"name":"acme/tool","version":"1.0.0"},{"name":"acme/tool","version":"2.0.0"}]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			data, err := json.Marshal(test.value)
+			is.NoErr(err)
+			is.Equal(string(data), test.output)
+		})
+	}
+}
+
+func TestRepository_UnmarshalJSON_Package(t *testing.T) {
+	is := is2.New(t)
+
+	repo := Repository{}
+	err := json.Unmarshal([]byte(`{
+		"type": "package",
+		"package": {
+			"name": "acme/theme",
+			"version": "dev-main",
+			"type": "wordpress-theme",
+			"source": {"url": "https://github.com/acme/theme.git", "type": "git", "reference": "main"}
+		}
+	}`), &repo)
+	is.NoErr(err)
+	is.Equal(repo.Type, TypePackage)
+	is.Equal(len(repo.Package.Packages), 1)
+	is.Equal(repo.Package.Packages[0].Name, "acme/theme")
+	is.Equal(repo.Package.Packages[0].Type, "wordpress-theme")
+	is.Equal(repo.Package.Packages[0].Source.Reference, "main")
+}
@@ -0,0 +1,365 @@
+package gocomposer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+const (
+	// InstalledJSONFile is the file in the composer directory of the vendor directory
+	// that lists the installed packages.
+	InstalledJSONFile = "installed.json"
+
+	// InstalledPHPFile is the file in the composer directory of the vendor directory
+	// that Composer\InstalledVersions reads the installed versions from.
+	InstalledPHPFile = "installed.php"
+)
+
+// InstalledJSON is the data of the vendor/composer/installed.json file.
+type InstalledJSON struct {
+	// The installed packages.
+	Packages []InstalledPackage `json:"packages"`
+
+	// Whether the packages were installed with the development requirements. Composer
+	// 1 does not record it, so it is false for files written by Composer 1.
+	Dev bool `json:"dev"`
+
+	// The names of the installed packages that are only required for development.
+	DevPackageNames []string `json:"dev-package-names"`
+}
+
+// UnmarshalJSON decodes both the Composer 2 object and the list of packages Composer 1
+// writes.
+func (i *InstalledJSON) UnmarshalJSON(data []byte) error {
+	if isArray(data) {
+		*i = InstalledJSON{}
+		return json.Unmarshal(data, &i.Packages)
+	}
+	type installedJSON InstalledJSON
+	return json.Unmarshal(data, (*installedJSON)(i))
+}
+
+// InstalledPackage is a package entry of installed.json, which is a locked package
+// with the details of its installation.
+type InstalledPackage struct {
+	LockedPackage
+
+	// The normalized version of the package.
+	VersionNormalized string `json:"version_normalized,omitempty"`
+
+	// Whether the package was installed from "source" or "dist".
+	InstallationSource string `json:"installation-source,omitempty"`
+
+	// The install path relative to the directory of installed.json. Composer 1 does not
+	// record it.
+	InstallPath string `json:"install-path,omitempty"`
+}
+
+// ParseInstalledJSON parses the contents of an installed.json file.
+func ParseInstalledJSON(data []byte) (*InstalledJSON, error) {
+	installed := &InstalledJSON{}
+	err := json.Unmarshal(data, installed)
+	if err != nil {
+		return nil, err
+	}
+	return installed, nil
+}
+
+// LoadInstalledJSON reads and parses the installed.json file at path.
+func LoadInstalledJSON(path string) (*InstalledJSON, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseInstalledJSON(data)
+}
+
+// IsDevPackage returns true if the package is only installed for development.
+func (i InstalledJSON) IsDevPackage(name string) bool {
+	for _, devName := range i.DevPackageNames {
+		if devName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Repository returns the installed versions of the packages like the versions
+// Composer writes to installed.php. dir is the directory of installed.json, which
+// install paths are relative to. Packages without a recorded install path are
+// installed in the vendor directory under their name. Root is empty because
+// installed.json does not describe the root package.
+func (i InstalledJSON) Repository(dir string) *InstalledRepository {
+	dir = normalizePath(filepath.ToSlash(dir))
+	repo := &InstalledRepository{Versions: make(map[string]InstalledVersion)}
+	for _, pkg := range i.Packages {
+		isDev := i.IsDevPackage(pkg.Name)
+		version := InstalledVersion{
+			Name:           pkg.Name,
+			PrettyVersion:  pkg.Version,
+			Version:        pkg.VersionNormalized,
+			Reference:      installedReference(pkg),
+			Type:           pkg.Type,
+			DevRequirement: isDev,
+		}
+		if version.Type == "" {
+			version.Type = "library"
+		}
+		if pkg.Type != "metapackage" {
+			installPath := pkg.InstallPath
+			if installPath == "" {
+				installPath = "../" + pkg.Name
+			}
+			if !path.IsAbs(installPath) {
+				installPath = dir + "/" + installPath
+			}
+			version.InstallPath = normalizePath(installPath)
+		}
+		if existing, ok := repo.Versions[pkg.Name]; ok {
+			version.Provided = existing.Provided
+			version.Replaced = existing.Replaced
+		}
+		repo.Versions[pkg.Name] = version
+
+		for _, link := range []struct {
+			constraints map[string]string
+			replaced    bool
+		}{{pkg.Replace, true}, {pkg.Provide, false}} {
+			for _, name := range sortedKeys(link.constraints) {
+				constraint := link.constraints[name]
+				if constraint == "self.version" {
+					constraint = pkg.Version
+				}
+				provided, ok := repo.Versions[name]
+				if !ok {
+					provided = InstalledVersion{Name: name, DevRequirement: isDev}
+				} else {
+					provided.DevRequirement = provided.DevRequirement && isDev
+				}
+				if link.replaced {
+					provided.Replaced = append(provided.Replaced, constraint)
+				} else {
+					provided.Provided = append(provided.Provided, constraint)
+				}
+				repo.Versions[name] = provided
+			}
+		}
+	}
+	return repo
+}
+
+// installedReference returns the reference of the installed source or dist of a
+// package, falling back to the other one like Composer when it writes installed.php.
+func installedReference(pkg InstalledPackage) string {
+	var source, dist string
+	if pkg.Source != nil {
+		source = pkg.Source.Reference
+	}
+	if pkg.Dist != nil {
+		dist = pkg.Dist.Reference
+	}
+	switch {
+	case pkg.InstallationSource == "source" && source != "":
+		return source
+	case pkg.InstallationSource == "dist" && dist != "":
+		return dist
+	case source != "":
+		return source
+	}
+	return dist
+}
+
+// InstalledRepository holds the installed versions of the root package and its
+// dependencies, like Composer\InstalledVersions.
+type InstalledRepository struct {
+	// The root package.
+	Root InstalledVersion
+
+	// The installed, provided and replaced packages by name.
+	Versions map[string]InstalledVersion
+}
+
+// InstalledVersion is an entry of installed.php.
+type InstalledVersion struct {
+	// Package name, which is "__root__" for a root package without a name.
+	Name string
+
+	// The version as written in the package, e.g. "v1.2.0".
+	PrettyVersion string
+
+	// The normalized version, e.g. "1.2.0.0".
+	Version string
+
+	// The commit or dist reference of the installed package.
+	Reference string
+
+	// Package type, e.g. 'library' or 'composer-plugin'.
+	Type string
+
+	// The normalized absolute path the package is installed in, which is empty for
+	// metapackages and for packages that are only provided or replaced.
+	InstallPath string
+
+	// The aliases of the version, e.g. "1.0.x-dev".
+	Aliases []string
+
+	// Whether the package is only required for development.
+	DevRequirement bool
+
+	// Whether the packages were installed with the development requirements. This is
+	// only set on the root package.
+	Dev bool
+
+	// The constraints of the packages that replace this package.
+	Replaced []string
+
+	// The constraints of the packages that provide this package.
+	Provided []string
+}
+
+// ParseInstalledPHP parses the contents of an installed.php file. dir is the directory
+// of the file, which __DIR__ evaluates to in the install paths.
+func ParseInstalledPHP(data []byte, dir string) (*InstalledRepository, error) {
+	value, err := parsePHPReturn(data, normalizePath(filepath.ToSlash(dir)))
+	if err != nil {
+		return nil, err
+	}
+	array, ok := value.(phpArray)
+	if !ok {
+		return nil, errors.New("installed.php does not return an array")
+	}
+
+	repo := &InstalledRepository{
+		Root:     installedVersion(array.getArray("root")),
+		Versions: make(map[string]InstalledVersion),
+	}
+	for _, item := range array.getArray("versions") {
+		versionArray, ok := item.value.(phpArray)
+		if !ok {
+			return nil, fmt.Errorf("installed.php: the version of %s is not an array", item.key)
+		}
+		version := installedVersion(versionArray)
+		version.Name = item.key
+		repo.Versions[item.key] = version
+	}
+	return repo, nil
+}
+
+// installedVersion returns the installed version of an entry of installed.php.
+func installedVersion(array phpArray) InstalledVersion {
+	version := InstalledVersion{
+		Name:          array.getString("name"),
+		PrettyVersion: array.getString("pretty_version"),
+		Version:       array.getString("version"),
+		Reference:     array.getString("reference"),
+		Type:          array.getString("type"),
+		Aliases:       array.getArray("aliases").strings(),
+		Replaced:      array.getArray("replaced").strings(),
+		Provided:      array.getArray("provided").strings(),
+	}
+	if installPath := array.getString("install_path"); installPath != "" {
+		version.InstallPath = normalizePath(installPath)
+	}
+	devRequirement, _ := array.get("dev_requirement")
+	version.DevRequirement, _ = devRequirement.(bool)
+	dev, _ := array.get("dev")
+	version.Dev, _ = dev.(bool)
+	return version
+}
+
+// LoadInstalledPHP reads and parses the installed.php file at path.
+func LoadInstalledPHP(path string) (*InstalledRepository, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	return ParseInstalledPHP(data, dir)
+}
+
+// LoadInstalledRepository reads the installed packages of a vendor directory from
+// composer/installed.php, or from composer/installed.json if installed.php does not
+// exist, which is the case for projects installed with Composer 1.
+func LoadInstalledRepository(vendorDir string) (*InstalledRepository, error) {
+	dir, err := filepath.Abs(filepath.Join(vendorDir, "composer"))
+	if err != nil {
+		return nil, err
+	}
+	repo, err := LoadInstalledPHP(filepath.Join(dir, InstalledPHPFile))
+	if !errors.Is(err, os.ErrNotExist) {
+		return repo, err
+	}
+	installed, err := LoadInstalledJSON(filepath.Join(dir, InstalledJSONFile))
+	if err != nil {
+		return nil, err
+	}
+	return installed.Repository(dir), nil
+}
+
+// get returns the installed version of a package, including the root package.
+func (r InstalledRepository) get(name string) (InstalledVersion, bool) {
+	if version, ok := r.Versions[name]; ok {
+		return version, true
+	}
+	if r.Root.Name != "" && r.Root.Name == name {
+		return r.Root, true
+	}
+	return InstalledVersion{}, false
+}
+
+// IsInstalled returns true if a package is installed, provided or replaced, like
+// InstalledVersions::isInstalled. If includeDevRequirements is false, packages that
+// are only required for development are not considered installed.
+func (r InstalledRepository) IsInstalled(name string, includeDevRequirements bool) bool {
+	version, ok := r.get(name)
+	return ok && (includeDevRequirements || !version.DevRequirement)
+}
+
+// GetVersion returns the normalized version of an installed package. It returns false
+// if the package is not installed or is only provided or replaced.
+func (r InstalledRepository) GetVersion(name string) (string, bool) {
+	version, ok := r.get(name)
+	if !ok || version.Version == "" {
+		return "", false
+	}
+	return version.Version, true
+}
+
+// GetPrettyVersion returns the version of an installed package as written in the
+// package. It returns false if the package is not installed or is only provided or
+// replaced.
+func (r InstalledRepository) GetPrettyVersion(name string) (string, bool) {
+	version, ok := r.get(name)
+	if !ok || version.PrettyVersion == "" {
+		return "", false
+	}
+	return version.PrettyVersion, true
+}
+
+// GetReference returns the reference of an installed package. It returns false if the
+// package is not installed or has no reference.
+func (r InstalledRepository) GetReference(name string) (string, bool) {
+	version, ok := r.get(name)
+	if !ok || version.Reference == "" {
+		return "", false
+	}
+	return version.Reference, true
+}
+
+// GetInstallPath returns the path an installed package is installed in. It returns
+// false if the package is not installed or has no install path, like metapackages and
+// packages that are only provided or replaced.
+func (r InstalledRepository) GetInstallPath(name string) (string, bool) {
+	version, ok := r.get(name)
+	if !ok || version.InstallPath == "" {
+		return "", false
+	}
+	return version.InstallPath, true
+}
@@ -0,0 +1,202 @@
+package gocomposer
+
+import (
+	is2 "github.com/matryer/is"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadInstalledJSON(t *testing.T) {
+	is := is2.New(t)
+
+	installed, err := LoadInstalledJSON("testdata/installed/vendor/composer/installed.json")
+	is.NoErr(err)
+	is.True(installed.Dev)
+	is.Equal(installed.DevPackageNames, []string{"acme/testing"})
+	is.Equal(len(installed.Packages), 4)
+	is.True(installed.IsDevPackage("acme/testing"))
+	is.True(!installed.IsDevPackage("acme/http"))
+
+	http := installed.Packages[0]
+	is.Equal(http.Name, "acme/http")
+	is.Equal(http.Version, "v1.2.0")
+	is.Equal(http.VersionNormalized, "1.2.0.0")
+	is.Equal(http.InstallationSource, "dist")
+	is.Equal(http.InstallPath, "../acme/http")
+	is.Equal(http.Autoload.PSR4[`Acme\Http\`], StringOrSlice{"src/"})
+	is.Equal(installed.Packages[1].InstallPath, "")
+
+	// Composer 1 writes a list of packages.
+	installed, err = LoadInstalledJSON("testdata/installed/composer1/vendor/composer/installed.json")
+	is.NoErr(err)
+	is.True(!installed.Dev)
+	is.Equal(len(installed.DevPackageNames), 0)
+	is.Equal(len(installed.Packages), 1)
+	is.Equal(installed.Packages[0].Name, "psr/log")
+	is.Equal(installed.Packages[0].VersionNormalized, "1.1.4.0")
+}
+
+func TestInstalledJSON_Repository(t *testing.T) {
+	is := is2.New(t)
+
+	installed, err := LoadInstalledJSON("testdata/installed/vendor/composer/installed.json")
+	is.NoErr(err)
+	repo := installed.Repository("/app/vendor/composer")
+	is.Equal(sortedKeys(repo.Versions), []string{
+		"acme/http",
+		"acme/meta",
+		"acme/test-helpers",
+		"acme/testing",
+		"psr/http-client-implementation",
+		"psr/log",
+	})
+	is.Equal(repo.Versions["acme/http"], InstalledVersion{
+		Name:          "acme/http",
+		PrettyVersion: "v1.2.0",
+		Version:       "1.2.0.0",
+		Reference:     "5d9a1f0c2b3e4a5f6c7d8e9f0a1b2c3d4e5f6a7b",
+		Type:          "library",
+		InstallPath:   "/app/vendor/acme/http",
+	})
+	is.Equal(repo.Versions["acme/test-helpers"], InstalledVersion{
+		Name:           "acme/test-helpers",
+		DevRequirement: true,
+		Replaced:       []string{"dev-main"},
+	})
+	is.Equal(repo.Versions["psr/http-client-implementation"].Provided, []string{"1.0"})
+	is.Equal(repo.Versions["acme/testing"].Reference, "c0ffee1234567890c0ffee1234567890c0ffee12")
+
+	path, ok := repo.GetInstallPath("acme/meta")
+	is.Equal(path, "")
+	is.True(!ok)
+
+	// Composer 1 does not record install paths, so packages are in the vendor directory.
+	installed, err = LoadInstalledJSON("testdata/installed/composer1/vendor/composer/installed.json")
+	is.NoErr(err)
+	repo = installed.Repository("/app/vendor/composer")
+	path, ok = repo.GetInstallPath("psr/log")
+	is.Equal(path, "/app/vendor/psr/log")
+	is.True(ok)
+}
+
+func TestLoadInstalledPHP(t *testing.T) {
+	is := is2.New(t)
+
+	dir, err := filepath.Abs("testdata/installed")
+	is.NoErr(err)
+	dir = filepath.ToSlash(dir)
+	repo, err := LoadInstalledPHP("testdata/installed/vendor/composer/installed.php")
+	is.NoErr(err)
+	is.Equal(repo.Root, InstalledVersion{
+		Name:          "acme/app",
+		PrettyVersion: "dev-main",
+		Version:       "dev-main",
+		Reference:     "0123456789abcdef0123456789abcdef01234567",
+		Type:          "project",
+		InstallPath:   dir,
+		Dev:           true,
+	})
+	is.Equal(len(repo.Versions), 7)
+	is.Equal(repo.Versions["acme/testing"], InstalledVersion{
+		Name:           "acme/testing",
+		PrettyVersion:  "dev-main",
+		Version:        "dev-main",
+		Reference:      "c0ffee1234567890c0ffee1234567890c0ffee12",
+		Type:           "library",
+		InstallPath:    dir + "/vendor/acme/testing",
+		Aliases:        []string{"1.0.x-dev"},
+		DevRequirement: true,
+	})
+	is.Equal(repo.Versions["acme/test-helpers"].Replaced, []string{"dev-main"})
+	is.Equal(repo.Versions["psr/http-client-implementation"].Provided, []string{"1.0"})
+}
+
+func TestLoadInstalledRepository(t *testing.T) {
+	is := is2.New(t)
+
+	dir, err := filepath.Abs("testdata/installed")
+	is.NoErr(err)
+	dir = filepath.ToSlash(dir)
+
+	// installed.json is read if there is no installed.php.
+	repo, err := LoadInstalledRepository("testdata/installed/composer1/vendor")
+	is.NoErr(err)
+	version, ok := repo.GetVersion("psr/log")
+	is.Equal(version, "1.1.4.0")
+	is.True(ok)
+
+	repo, err = LoadInstalledRepository("testdata/installed/vendor")
+	is.NoErr(err)
+
+	// Values that are not recorded are returned as "" and false.
+	tests := []struct {
+		name           string
+		installed      bool
+		installedNoDev bool
+		version        string
+		prettyVersion  string
+		reference      string
+		installPath    string
+	}{
+		{
+			name:           "acme/http",
+			installed:      true,
+			installedNoDev: true,
+			version:        "1.2.0.0",
+			prettyVersion:  "v1.2.0",
+			reference:      "5d9a1f0c2b3e4a5f6c7d8e9f0a1b2c3d4e5f6a7b",
+			installPath:    dir + "/vendor/acme/http",
+		},
+		{
+			name:           "acme/app",
+			installed:      true,
+			installedNoDev: true,
+			version:        "dev-main",
+			prettyVersion:  "dev-main",
+			reference:      "0123456789abcdef0123456789abcdef01234567",
+			installPath:    dir,
+		},
+		{
+			name:           "acme/meta",
+			installed:      true,
+			installedNoDev: true,
+			version:        "1.0.0.0",
+			prettyVersion:  "1.0.0",
+		},
+		{
+			name:      "acme/test-helpers",
+			installed: true,
+		},
+		{
+			name:           "psr/http-client-implementation",
+			installed:      true,
+			installedNoDev: true,
+		},
+		{
+			name: "acme/missing",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+			is.Equal(repo.IsInstalled(test.name, true), test.installed)
+			is.Equal(repo.IsInstalled(test.name, false), test.installedNoDev)
+
+			version, ok := repo.GetVersion(test.name)
+			is.Equal(version, test.version)
+			is.Equal(ok, test.version != "")
+
+			prettyVersion, ok := repo.GetPrettyVersion(test.name)
+			is.Equal(prettyVersion, test.prettyVersion)
+			is.Equal(ok, test.prettyVersion != "")
+
+			reference, ok := repo.GetReference(test.name)
+			is.Equal(reference, test.reference)
+			is.Equal(ok, test.reference != "")
+
+			installPath, ok := repo.GetInstallPath(test.name)
+			is.Equal(installPath, test.installPath)
+			is.Equal(ok, test.installPath != "")
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package gocomposer
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// installerLocations holds the install paths of the package types composer/installers
+// supports for each framework, by the type without the framework prefix. The types
+// are the framework and the location joined with a dash, e.g. "drupal-module".
+var installerLocations = map[string]map[string]string{
+	"concrete5": {
+		"core":    "concrete/",
+		"block":   "application/blocks/{$name}/",
+		"package": "packages/{$name}/",
+		"theme":   "application/themes/{$name}/",
+		"update":  "updates/{$name}/",
+	},
+	"drupal": {
+		"core":             "core/",
+		"module":           "modules/{$name}/",
+		"theme":            "themes/{$name}/",
+		"library":          "libraries/{$name}/",
+		"profile":          "profiles/{$name}/",
+		"database-driver":  "drivers/lib/Drupal/Driver/Database/{$name}/",
+		"drush":            "drush/{$name}/",
+		"custom-theme":     "themes/custom/{$name}/",
+		"custom-module":    "modules/custom/{$name}/",
+		"custom-profile":   "profiles/custom/{$name}/",
+		"drupal-multisite": "sites/{$name}/",
+		"console":          "console/{$name}/",
+		"console-language": "console/language/{$name}/",
+		"config":           "config/sync/",
+		"recipe":           "recipes/{$name}",
+	},
+	"joomla": {
+		"component": "components/{$name}/",
+		"module":    "modules/{$name}/",
+		"template":  "templates/{$name}/",
+		"plugin":    "plugins/{$name}/",
+		"library":   "libraries/{$name}/",
+	},
+	"kirby": {
+		"plugin": "site/plugins/{$name}/",
+		"field":  "site/fields/{$name}/",
+		"tag":    "site/tags/{$name}/",
+	},
+	"kohana": {
+		"module": "modules/{$name}/",
+	},
+	"laravel": {
+		"library": "libraries/{$name}/",
+	},
+	"magento": {
+		"theme":   "app/design/frontend/{$name}/",
+		"skin":    "skin/frontend/default/{$name}/",
+		"library": "lib/{$name}/",
+	},
+	"mediawiki": {
+		"core":      "core/",
+		"extension": "extensions/{$name}/",
+		"skin":      "skins/{$name}/",
+	},
+	"phpbb": {
+		"extension": "ext/{$vendor}/{$name}/",
+		"language":  "language/{$name}/",
+		"style":     "styles/{$name}/",
+	},
+	"silverstripe": {
+		"module": "{$name}/",
+		"theme":  "themes/{$name}/",
+	},
+	"wordpress": {
+		"plugin":   "wp-content/plugins/{$name}/",
+		"theme":    "wp-content/themes/{$name}/",
+		"muplugin": "wp-content/mu-plugins/{$name}/",
+		"dropin":   "wp-content/{$name}/",
+	},
+	"zend": {
+		"library": "library/{$name}/",
+		"extra":   "extras/library/{$name}/",
+		"module":  "module/{$name}/",
+	},
+}
+
+var (
+	installerVarRegex          = regexp.MustCompile(`\{\$([A-Za-z0-9_]*)\}`)
+	mediawikiExtensionSuffixRe = regexp.MustCompile(`-extension$`)
+	mediawikiSkinSuffixRe      = regexp.MustCompile(`-skin$`)
+)
+
+// InstallerPath is an entry of installer-paths: an install path and the package names,
+// "type:" and "vendor:" selectors it applies to.
+type InstallerPath struct {
+	// The install path, which may contain the {$name}, {$vendor} and {$type}
+	// placeholders.
+	Path string
+
+	// Package names such as "acme/theme", "type:drupal-module" or "vendor:acme".
+	Names []string
+}
+
+// InstallerPathResolver computes the directory a package is installed in when the root
+// package uses composer/installers, which installs packages of framework specific
+// types such as "drupal-module" or "wordpress-plugin" outside the vendor directory.
+type InstallerPathResolver struct {
+	// The custom install paths of the root package in the order they are tried.
+	Paths []InstallerPath
+
+	// The frameworks whose installers are disabled with installer-disable, or "*" if
+	// all of them are.
+	Disabled []string
+
+	// The vendor directory packages are installed in if composer/installers does not
+	// support their type.
+	VendorDir string
+}
+
+// NewInstallerPathResolver returns the InstallerPathResolver of a root package. The
+// custom install paths are tried in the order of installer-paths like
+// composer/installers does.
+func NewInstallerPathResolver(root ComposerJSON) *InstallerPathResolver {
+	r := &InstallerPathResolver{VendorDir: root.Config.GetVendorDir()}
+	r.Paths, _ = root.Extra.InstallerPaths()
+
+	disable, _ := root.Extra.Get("installer-disable")
+	disabled, ok := disable.([]interface{})
+	if !ok {
+		disabled = []interface{}{disable}
+	}
+	for _, value := range disabled {
+		switch value := value.(type) {
+		case bool:
+			if value {
+				r.Disabled = []string{"*"}
+				return r
+			}
+		case string:
+			if value == "all" || value == "*" {
+				r.Disabled = []string{"*"}
+				return r
+			}
+			r.Disabled = append(r.Disabled, value)
+		}
+	}
+	return r
+}
+
+// ParseInstallerPathResolver returns the InstallerPathResolver of the root package in
+// the contents of a composer.json file, see NewInstallerPathResolver.
+func ParseInstallerPathResolver(data []byte) (*InstallerPathResolver, error) {
+	root, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if _, set := root.Extra.Get("installer-paths"); set {
+		if _, ok := root.Extra.InstallerPaths(); !ok {
+			return nil, fmt.Errorf("extra.installer-paths must be an object")
+		}
+	}
+	return NewInstallerPathResolver(*root), nil
+}
+
+// LoadInstallerPathResolver reads the composer.json file at path and returns the
+// InstallerPathResolver of its root package, see ParseInstallerPathResolver.
+func LoadInstallerPathResolver(path string) (*InstallerPathResolver, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseInstallerPathResolver(data)
+}
+
+// Framework returns the framework of a package type composer/installers supports,
+// e.g. "drupal" for "drupal-module". It returns false if composer/installers does not
+// support the type or its installer is disabled.
+func (r InstallerPathResolver) Framework(packageType string) (string, bool) {
+	if contains(r.Disabled, "*") {
+		return "", false
+	}
+	for _, framework := range reverseSortedKeys(installerLocations) {
+		if contains(r.Disabled, framework) || !strings.HasPrefix(packageType, framework) {
+			continue
+		}
+		_, ok := installerLocations[framework][strings.TrimPrefix(packageType, framework+"-")]
+		return framework, ok && strings.HasPrefix(packageType, framework+"-")
+	}
+	return "", false
+}
+
+// InstallPath returns the directory a package is installed in, relative to the
+// directory of the root package. Like composer/installers, the first custom install
+// path that lists the package name, its "type:" or its "vendor:" is used, otherwise
+// the install path of the package type. The installer-name of the package replaces
+// the {$name} placeholder. Packages whose type is not supported are installed in the
+// vendor directory.
+func (r InstallerPathResolver) InstallPath(name, packageType string, extra Extra) string {
+	framework, ok := r.Framework(packageType)
+	if !ok {
+		vendorDir := r.VendorDir
+		if vendorDir == "" {
+			vendorDir = DefaultVendorDir
+		}
+		return vendorDir + "/" + name
+	}
+
+	vendor, packageName, found := strings.Cut(name, "/")
+	if !found {
+		vendor, packageName = "", name
+	}
+	vars := inflectInstallerVars(map[string]string{
+		"name":   packageName,
+		"vendor": vendor,
+		"type":   packageType,
+	})
+	if installerName, ok := extra.InstallerName(); ok {
+		vars["name"] = installerName
+	}
+
+	path := installerLocations[framework][strings.TrimPrefix(packageType, framework+"-")]
+	for _, custom := range r.Paths {
+		if contains(custom.Names, name) || contains(custom.Names, "type:"+packageType) || contains(custom.Names, "vendor:"+vendor) {
+			path = custom.Path
+			break
+		}
+	}
+	return installerVarRegex.ReplaceAllStringFunc(path, func(match string) string {
+		return vars[installerVarRegex.FindStringSubmatch(match)[1]]
+	})
+}
+
+// inflectInstallerVars changes the placeholder values of the package types whose
+// installer rewrites them, which are the MediaWiki extensions and skins of the
+// supported frameworks.
+func inflectInstallerVars(vars map[string]string) map[string]string {
+	switch vars["type"] {
+	case "mediawiki-extension":
+		name := mediawikiExtensionSuffixRe.ReplaceAllString(vars["name"], "")
+		words := strings.Split(name, "-")
+		for i, word := range words {
+			if word != "" {
+				words[i] = strings.ToUpper(word[:1]) + word[1:]
+			}
+		}
+		vars["name"] = strings.Join(words, "")
+	case "mediawiki-skin":
+		vars["name"] = mediawikiSkinSuffixRe.ReplaceAllString(vars["name"], "")
+	}
+	return vars
+}
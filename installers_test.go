@@ -0,0 +1,85 @@
+package gocomposer
+
+import (
+	is2 "github.com/matryer/is"
+	"testing"
+)
+
+func TestInstallerPathResolver_InstallPath(t *testing.T) {
+	r, err := LoadInstallerPathResolver("testdata/installers/composer.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		packageType string
+		extra       Extra
+		expected    string
+	}{
+		{name: "drupal/core", packageType: "drupal-core", expected: "web/core"},
+		{name: "drupal/token", packageType: "drupal-module", expected: "web/modules/contrib/token"},
+		{name: "drupal/admin_toolbar", packageType: "drupal-module", expected: "web/modules/custom/admin_toolbar"},
+		{name: "acme/blog", packageType: "drupal-module", expected: "web/modules/custom/blog"},
+		{name: "drupal/olivero", packageType: "drupal-theme", extra: Extra{{"installer-name", "olivero2"}}, expected: "web/themes/contrib/olivero2"},
+		{name: "drupal/standard", packageType: "drupal-profile", expected: "profiles/standard/"},
+		{name: "drupal/site", packageType: "drupal-drupal-multisite", expected: "sites/site/"},
+		{name: "example/custom", packageType: "drupal-custom-module", expected: "modules/custom/custom/"},
+		{name: "wpackagist-plugin/akismet", packageType: "wordpress-plugin", expected: "web/app/wpackagist-plugin/wordpress-plugin/akismet"},
+		{name: "wpackagist-theme/twentytwenty", packageType: "wordpress-theme", expected: "wp-content/themes/twentytwenty/"},
+		{name: "example/forum", packageType: "phpbb-extension", expected: "ext/example/forum/"},
+		{name: "mediawiki/semantic-media-wiki-extension", packageType: "mediawiki-extension", expected: "extensions/SemanticMediaWiki/"},
+		{name: "mediawiki/vector-skin", packageType: "mediawiki-skin", expected: "skins/vector/"},
+		{name: "acme/theme", packageType: "magento-theme", expected: "lib/vendor/acme/theme"},
+		{name: "acme/http", packageType: "library", expected: "lib/vendor/acme/http"},
+		{name: "acme/unknown", packageType: "drupal-unknown", expected: "lib/vendor/acme/unknown"},
+		{name: "acme/plugin", packageType: "wordpressplugin", expected: "lib/vendor/acme/plugin"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name+" "+test.packageType, func(t *testing.T) {
+			is := is2.New(t)
+
+			is.Equal(r.InstallPath(test.name, test.packageType, test.extra), test.expected)
+		})
+	}
+}
+
+func TestParseInstallerPathResolver(t *testing.T) {
+	is := is2.New(t)
+
+	r, err := LoadInstallerPathResolver("testdata/installers/composer.json")
+	is.NoErr(err)
+	is.Equal(r.VendorDir, "lib/vendor")
+	is.Equal(r.Disabled, []string{"magento"})
+	is.Equal(r.Paths[0], InstallerPath{Path: "web/core", Names: []string{"type:drupal-core"}})
+
+	// The paths are tried in the order of the file, so the vendor path is tried before
+	// the type path.
+	is.Equal(r.InstallPath("acme/blog", "drupal-module", nil), "web/modules/custom/blog")
+
+	framework, ok := r.Framework("drupal-module")
+	is.True(ok)
+	is.Equal(framework, "drupal")
+	_, ok = r.Framework("magento-theme")
+	is.True(!ok)
+
+	root, err := Load("testdata/installers/composer.json")
+	is.NoErr(err)
+	is.Equal(NewInstallerPathResolver(*root).Paths, r.Paths)
+	for _, disable := range []interface{}{true, "all", []interface{}{"drupal", "*"}} {
+		is.NoErr(root.Extra.Set("installer-disable", disable))
+		r = NewInstallerPathResolver(*root)
+		is.Equal(r.Disabled, []string{"*"})
+		is.Equal(r.InstallPath("drupal/core", "drupal-core", nil), "lib/vendor/drupal/core")
+	}
+
+	r, err = ParseInstallerPathResolver([]byte(`{"name": "acme/app"}`))
+	is.NoErr(err)
+	is.Equal(len(r.Paths), 0)
+	is.Equal(r.InstallPath("drupal/core", "drupal-core", nil), "core/")
+	is.Equal(r.InstallPath("acme/http", "library", nil), "vendor/acme/http")
+
+	_, err = ParseInstallerPathResolver([]byte(`{"extra": {"installer-paths": "web/"}}`))
+	is.Equal(err.Error(), "extra.installer-paths must be an object")
+}
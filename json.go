@@ -0,0 +1,611 @@
+package gocomposer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// orderedObject is a decoded JSON object that remembers the order of its keys. The
+// encoding/json package always decodes objects into maps, which loses the key order
+// Composer relies on when hashing and writing files.
+type orderedObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newOrderedObject() *orderedObject {
+	return &orderedObject{values: make(map[string]interface{})}
+}
+
+// get returns the value stored under key.
+func (o *orderedObject) get(key string) (interface{}, bool) {
+	v, ok := o.values[key]
+	return v, ok
+}
+
+// set stores value under key. New keys are appended to the end of the object.
+func (o *orderedObject) set(key string, value interface{}) {
+	if _, exists := o.values[key]; !exists {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// remove deletes key from the object.
+func (o *orderedObject) remove(key string) {
+	if _, exists := o.values[key]; !exists {
+		return
+	}
+	delete(o.values, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i:i], o.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// rename replaces the key old with key, keeping its position and value.
+func (o *orderedObject) rename(old, key string) {
+	value, exists := o.values[old]
+	if !exists || old == key {
+		return
+	}
+	o.remove(key)
+	for i, k := range o.keys {
+		if k == old {
+			o.keys[i] = key
+		}
+	}
+	delete(o.values, old)
+	o.values[key] = value
+}
+
+// decodeOrdered decodes JSON data into a tree of *orderedObject, []interface{},
+// string, json.Number, bool and nil values.
+func decodeOrdered(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	v, err := decodeOrderedValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid JSON: unexpected data after top-level value")
+	}
+	return v, nil
+}
+
+func decodeOrderedValue(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			obj := newOrderedObject()
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key := keyTok.(string)
+				value, err := decodeOrderedValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				obj.set(key, value)
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+			return obj, nil
+		case '[':
+			arr := make([]interface{}, 0)
+			for dec.More() {
+				value, err := decodeOrderedValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				arr = append(arr, value)
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+			return arr, nil
+		}
+		return nil, fmt.Errorf("invalid JSON: unexpected %q", t)
+	default:
+		return t, nil
+	}
+}
+
+// encodePHP encodes a value produced by decodeOrdered the same way PHP's json_encode
+// does with no flags set. Slashes and non-ASCII characters are escaped, and because
+// PHP decodes objects into arrays, empty objects are written as [] and objects with
+// sequential integer keys are written as lists.
+func encodePHP(buf *bytes.Buffer, v interface{}) error {
+	switch t := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		if t {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+	case string:
+		encodePHPString(buf, t)
+	case json.Number:
+		return encodePHPNumber(buf, t)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, item := range t {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := encodePHP(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case *orderedObject:
+		if isPHPList(t) {
+			items := make([]interface{}, 0, len(t.keys))
+			for _, key := range t.keys {
+				items = append(items, t.values[key])
+			}
+			return encodePHP(buf, items)
+		}
+		buf.WriteByte('{')
+		for i, key := range t.keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			encodePHPString(buf, key)
+			buf.WriteByte(':')
+			if err := encodePHP(buf, t.values[key]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("unsupported JSON value of type %T", v)
+	}
+	return nil
+}
+
+// isPHPList returns true if PHP would treat the object as a list, meaning it is empty
+// or its keys are the integers 0 to n-1 in order.
+func isPHPList(o *orderedObject) bool {
+	for i, key := range o.keys {
+		if key != strconv.Itoa(i) {
+			return false
+		}
+	}
+	return true
+}
+
+func encodePHPString(buf *bytes.Buffer, s string) {
+	const hex = "0123456789abcdef"
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '/':
+			buf.WriteString(`\/`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r >= 0x20 && r < utf8.RuneSelf {
+				buf.WriteRune(r)
+				continue
+			}
+			units := []rune{r}
+			if r > 0xFFFF {
+				r -= 0x10000
+				units = []rune{0xD800 + (r>>10)&0x3FF, 0xDC00 + r&0x3FF}
+			}
+			for _, u := range units {
+				buf.WriteString(`\u`)
+				buf.WriteByte(hex[(u>>12)&0xF])
+				buf.WriteByte(hex[(u>>8)&0xF])
+				buf.WriteByte(hex[(u>>4)&0xF])
+				buf.WriteByte(hex[u&0xF])
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// encodePHPNumber encodes a number like PHP's json_encode. Numbers are integers if
+// they are written without a fraction or exponent and fit in 64 bits, otherwise they
+// are floats, like json_decode decodes them.
+func encodePHPNumber(buf *bytes.Buffer, n json.Number) error {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		buf.WriteString(strconv.FormatInt(i, 10))
+		return nil
+	}
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		return err
+	}
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return fmt.Errorf("unsupported JSON number %s", n)
+	}
+	// PHP uses the shortest representation that round trips, switching to scientific
+	// notation for very small and very large exponents.
+	exp := 0
+	if f != 0 {
+		exp = int(math.Floor(math.Log10(math.Abs(f))))
+	}
+	if exp < -4 || exp >= 17 {
+		s := strconv.FormatFloat(f, 'e', -1, 64)
+		i := strings.IndexByte(s, 'e')
+		mantissa, sign, digits := s[:i], s[i+1], strings.TrimLeft(s[i+2:], "0")
+		if !strings.Contains(mantissa, ".") {
+			mantissa += ".0"
+		}
+		buf.WriteString(mantissa + "e" + string(sign) + digits)
+		return nil
+	}
+	// Floats are written with a fractional part even if it is zero, so 1.0 stays 1.0.
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if !strings.Contains(s, ".") {
+		s += ".0"
+	}
+	buf.WriteString(s)
+	return nil
+}
+
+// jsonFormat describes how a JSON document is laid out, so that edited values can be
+// written in the same style as the rest of the file.
+type jsonFormat struct {
+	indent        string
+	newline       string
+	escapeSlashes bool
+}
+
+// defaultJSONFormat is the format Composer writes composer.json files in.
+var defaultJSONFormat = jsonFormat{indent: "    ", newline: "\n"}
+
+// encodePretty writes v as indented JSON in the same style as PHP's JSON_PRETTY_PRINT
+// with JSON_UNESCAPED_UNICODE. Slashes are only escaped if the format asks for it.
+// depth is the indentation level of the line v starts on.
+func encodePretty(buf *bytes.Buffer, v interface{}, format jsonFormat, depth int) error {
+	switch t := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		if t {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+	case string:
+		encodeString(buf, t, format.escapeSlashes)
+	case json.Number:
+		buf.WriteString(string(t))
+	case []interface{}:
+		if len(t) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		buf.WriteByte('[')
+		for i, item := range t {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(format.newline + strings.Repeat(format.indent, depth+1))
+			if err := encodePretty(buf, item, format, depth+1); err != nil {
+				return err
+			}
+		}
+		buf.WriteString(format.newline + strings.Repeat(format.indent, depth) + "]")
+	case *orderedObject:
+		if len(t.keys) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		buf.WriteByte('{')
+		for i, key := range t.keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(format.newline + strings.Repeat(format.indent, depth+1))
+			encodeString(buf, key, format.escapeSlashes)
+			buf.WriteString(": ")
+			if err := encodePretty(buf, t.values[key], format, depth+1); err != nil {
+				return err
+			}
+		}
+		buf.WriteString(format.newline + strings.Repeat(format.indent, depth) + "}")
+	default:
+		return fmt.Errorf("unsupported JSON value of type %T", v)
+	}
+	return nil
+}
+
+// encodeString writes s as a JSON string without escaping HTML or unicode characters.
+func encodeString(buf *bytes.Buffer, s string, escapeSlashes bool) {
+	const hex = "0123456789abcdef"
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '/':
+			if escapeSlashes {
+				buf.WriteString(`\/`)
+			} else {
+				buf.WriteByte('/')
+			}
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		case ' ', ' ':
+			buf.WriteString(`\u202` + string(hex[r&0xF]))
+		default:
+			if r < 0x20 {
+				buf.WriteString(`\u00` + string(hex[r>>4]) + string(hex[r&0xF]))
+				continue
+			}
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// keepOrder returns a copy of value where the keys of every object follow the order of
+// the matching object in original. Keys that are not in original are added at the end
+// in their existing order.
+func keepOrder(value, original interface{}) interface{} {
+	switch v := value.(type) {
+	case *orderedObject:
+		o, ok := original.(*orderedObject)
+		if !ok {
+			return v
+		}
+		result := newOrderedObject()
+		for _, key := range o.keys {
+			if item, exists := v.values[key]; exists {
+				result.set(key, keepOrder(item, o.values[key]))
+			}
+		}
+		for _, key := range v.keys {
+			if _, exists := result.values[key]; !exists {
+				result.set(key, v.values[key])
+			}
+		}
+		return result
+	case []interface{}:
+		o, ok := original.([]interface{})
+		if !ok {
+			return v
+		}
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			if i < len(o) {
+				result[i] = keepOrder(item, o[i])
+			} else {
+				result[i] = item
+			}
+		}
+		return result
+	}
+	return value
+}
+
+// jsonMember is a key/value pair of the top level object in a JSON document, with the
+// byte offsets of where it was found.
+type jsonMember struct {
+	key        string
+	keyStart   int
+	valueStart int
+	valueEnd   int
+}
+
+// jsonDocument is a JSON document whose top level value is an object, split up into
+// its members so that they can be individually replaced while keeping the rest of the
+// document untouched.
+type jsonDocument struct {
+	data    []byte
+	open    int
+	close   int
+	members []jsonMember
+}
+
+// scanJSONDocument finds the position of every top level member in data.
+func scanJSONDocument(data []byte) (*jsonDocument, error) {
+	doc := &jsonDocument{data: data}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("invalid JSON: the top level value must be an object")
+	}
+	doc.open = int(dec.InputOffset()) - 1
+
+	for dec.More() {
+		keyStart := skipJSONSpace(data, int(dec.InputOffset()))
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key := tok.(string)
+		valueStart := skipJSONSpace(data, int(dec.InputOffset()))
+		raw := json.RawMessage{}
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		doc.members = append(doc.members, jsonMember{
+			key:        key,
+			keyStart:   keyStart,
+			valueStart: valueStart,
+			valueEnd:   int(dec.InputOffset()),
+		})
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	doc.close = int(dec.InputOffset()) - 1
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid JSON: unexpected data after top-level value")
+	}
+	return doc, nil
+}
+
+// skipJSONSpace returns the offset of the first byte at or after i that is not
+// whitespace, a comma or a colon.
+func skipJSONSpace(data []byte, i int) int {
+	for i < len(data) {
+		switch data[i] {
+		case ' ', '\t', '\n', '\r', ',', ':':
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// format detects the indentation, line endings and slash escaping of the document.
+func (d *jsonDocument) format() jsonFormat {
+	format := defaultJSONFormat
+	if bytes.Contains(d.data, []byte(`\/`)) {
+		format.escapeSlashes = true
+	}
+	if bytes.Contains(d.data, []byte("\r\n")) {
+		format.newline = "\r\n"
+	}
+	if len(d.members) > 0 {
+		leading := string(d.data[d.open+1 : d.members[0].keyStart])
+		if i := strings.LastIndexAny(leading, "\n"); i >= 0 && i < len(leading)-1 {
+			format.indent = leading[i+1:]
+		}
+	}
+	return format
+}
+
+// rawValue returns the value of the member with the given key exactly as it is written
+// in the document.
+func (d *jsonDocument) rawValue(key string) ([]byte, bool) {
+	for _, m := range d.members {
+		if m.key == key {
+			return d.data[m.valueStart:m.valueEnd], true
+		}
+	}
+	return nil, false
+}
+
+// jsonEdit replaces, removes or adds a top level member of a jsonDocument. A nil value
+// removes the member.
+type jsonEdit struct {
+	key   string
+	value []byte
+}
+
+// apply returns a copy of the document with the edits applied. Members that are not
+// edited keep their original formatting, edited members keep their position and new
+// members are appended to the end of the object.
+func (d *jsonDocument) apply(edits []jsonEdit) []byte {
+	format := d.format()
+	replaced := make(map[string][]byte)
+	removed := make(map[string]bool)
+	added := make([]jsonEdit, 0)
+	for _, edit := range edits {
+		_, exists := d.rawValue(edit.key)
+		switch {
+		case edit.value == nil:
+			removed[edit.key] = true
+		case exists:
+			replaced[edit.key] = edit.value
+		default:
+			added = append(added, edit)
+		}
+	}
+
+	separator := "," + format.newline + format.indent
+	leading := format.newline + format.indent
+	trailing := format.newline
+	if len(d.members) > 0 {
+		leading = string(d.data[d.open+1 : d.members[0].keyStart])
+		trailing = string(d.data[d.members[len(d.members)-1].valueEnd:d.close])
+		if len(d.members) > 1 {
+			separator = string(d.data[d.members[0].valueEnd:d.members[1].keyStart])
+		}
+	}
+
+	buf := bytes.Buffer{}
+	buf.Write(d.data[:d.open+1])
+	first := true
+	for i, m := range d.members {
+		if removed[m.key] {
+			continue
+		}
+		switch {
+		case first:
+			buf.WriteString(leading)
+		case i > 0:
+			buf.Write(d.data[d.members[i-1].valueEnd:m.keyStart])
+		default:
+			buf.WriteString(separator)
+		}
+		first = false
+		buf.Write(d.data[m.keyStart:m.valueStart])
+		if value, ok := replaced[m.key]; ok {
+			buf.Write(value)
+		} else {
+			buf.Write(d.data[m.valueStart:m.valueEnd])
+		}
+	}
+	for _, edit := range added {
+		if first {
+			buf.WriteString(leading)
+		} else {
+			buf.WriteString(separator)
+		}
+		first = false
+		encodeString(&buf, edit.key, format.escapeSlashes)
+		buf.WriteString(": ")
+		buf.Write(edit.value)
+	}
+	if first {
+		// Every member was removed, so there is nothing to indent.
+		trailing = ""
+	}
+	buf.WriteString(trailing)
+	buf.Write(d.data[d.close:])
+	return buf.Bytes()
+}
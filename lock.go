@@ -0,0 +1,247 @@
+package gocomposer
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// ComposerLock is a complete representation of the composer.lock file data.
+type ComposerLock struct {
+	// Informational message Composer writes at the top of every lock file.
+	Readme []string `json:"_readme,omitempty"`
+
+	// MD5 hash of the relevant parts of composer.json, used to detect when the lock
+	// file is out of date.
+	ContentHash string `json:"content-hash"`
+
+	// Legacy hash of the whole composer.json file written by Composer 1.
+	//
+	// Deprecated: Not written by Composer 2.0
+	Hash string `json:"hash,omitempty"`
+
+	// The packages locked for production.
+	Packages []LockedPackage `json:"packages"`
+
+	// The packages locked for development only.
+	PackagesDev []LockedPackage `json:"packages-dev"`
+
+	// Inline aliases defined by the root package requirements (e.g. "dev-main as
+	// 1.0.x-dev").
+	Aliases []LockAlias `json:"aliases"`
+
+	// The root package minimum-stability at the time the lock file was written.
+	MinimumStability string `json:"minimum-stability"`
+
+	// This is an object of package name (keys) and stability (values) for root
+	// requirements with an explicit stability flag such as "@dev".
+	StabilityFlags StabilityFlags `json:"stability-flags"`
+
+	// The root package prefer-stable setting at the time the lock file was written.
+	PreferStable bool `json:"prefer-stable"`
+
+	// Whether the lock file was generated with --prefer-lowest.
+	PreferLowest bool `json:"prefer-lowest"`
+
+	// This is an object of platform package name (keys) and version constraints
+	// (values) required by the root package.
+	Platform PlatformMap `json:"platform"`
+
+	// This is an object of platform package name (keys) and version constraints
+	// (values) required by the root package for development.
+	PlatformDev PlatformMap `json:"platform-dev"`
+
+	// The config.platform values in use when the lock file was written.
+	PlatformOverrides map[string]StringOrBool `json:"platform-overrides,omitempty"`
+
+	// The Composer plugin API version used to write the lock file.
+	PluginAPIVersion string `json:"plugin-api-version,omitempty"`
+}
+
+// GetPackage looks for a LockedPackage with a given name in both the packages and
+// packages-dev sections of the lock file.
+func (l ComposerLock) GetPackage(name string) (LockedPackage, bool) {
+	for _, p := range l.Packages {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	for _, p := range l.PackagesDev {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return LockedPackage{}, false
+}
+
+// LockedPackage is a single package entry in the packages or packages-dev section of
+// the lock file.
+type LockedPackage struct {
+	// Package name, including 'vendor-name/' prefix.
+	Name string `json:"name"`
+
+	// The exact version that was locked.
+	Version string `json:"version"`
+
+	// Forces the package to be installed into the given subdirectory path.
+	//
+	// Deprecated: Not used with Composer 2.0
+	TargetDir string `json:"target-dir,omitempty"`
+
+	// Source code details.
+	Source *Source `json:"source,omitempty"`
+
+	// Archive details.
+	Dist *Dist `json:"dist,omitempty"`
+
+	// This is an object of package name (keys) and version constraints (values) that
+	// are required to run this package.
+	Require map[string]string `json:"require,omitempty"`
+
+	// This is an object of package name (keys) and version constraints (values) that
+	// conflict with this package.
+	Conflict map[string]string `json:"conflict,omitempty"`
+
+	// This is an object of package name (keys) and version constraints (values) that
+	// this package provides in addition to this package's name.
+	Provide map[string]string `json:"provide,omitempty"`
+
+	// This is an object of package name (keys) and version constraints (values) that
+	// can be replaced by this package.
+	Replace map[string]string `json:"replace,omitempty"`
+
+	// This is an object of package name (keys) and version constraints (values) that
+	// this package requires for developing it.
+	RequireDev map[string]string `json:"require-dev,omitempty"`
+
+	// This is an object of package name (keys) and descriptions (values) that this
+	// package suggests work well with it.
+	Suggest map[string]string `json:"suggest,omitempty"`
+
+	// A set of files that should be treated as binaries and symlinked into bin-dir.
+	Bin StringOrSlice `json:"bin,omitempty"`
+
+	// Package type, e.g. 'library' or 'composer-plugin'.
+	Type string `json:"type,omitempty"`
+
+	// Arbitrary extra data for consumption by scripts and plugins.
+	Extra Extra `json:"extra,omitempty"`
+
+	// Description of how the package can be autoloaded.
+	Autoload *Autoload `json:"autoload,omitempty"`
+
+	// Description of additional autoload rules for development purpose.
+	AutoloadDev *AutoloadDev `json:"autoload-dev,omitempty"`
+
+	// URL Composer notifies after the package is installed.
+	NotificationURL string `json:"notification-url,omitempty"`
+
+	// A list of directories which should get added to PHP's include path.
+	//
+	// Deprecated: Not used with Composer 2.0
+	IncludePath IncludePaths `json:"include-path,omitempty"`
+
+	// License name. Or an array of license names.
+	License StringOrSlice `json:"license,omitempty"`
+
+	// List of authors that contributed to the package.
+	Authors []Authors `json:"authors,omitempty"`
+
+	// Short package description.
+	Description string `json:"description,omitempty"`
+
+	// Homepage URL for the project.
+	Homepage string `json:"homepage,omitempty"`
+
+	// A tag/keyword that this package relates to.
+	Keywords []string `json:"keywords,omitempty"`
+
+	// Support channels for the package
+	Support *Support `json:"support,omitempty"`
+
+	// A list of options to fund the development and maintenance of the package.
+	Funding []Funding `json:"funding,omitempty"`
+
+	// Indicates whether this package has been abandoned, it can be boolean or a package
+	// name/URL pointing to a recommended alternative.
+	Abandoned *StringOrBool `json:"abandoned,omitempty"`
+
+	// Indicates whether this version is the default branch of the linked VCS
+	// repository.
+	DefaultBranch bool `json:"default-branch,omitempty"`
+
+	// Package release date. This is kept as the raw string because Composer 1 and
+	// Composer 2 lock files use different date formats.
+	Time string `json:"time,omitempty"`
+}
+
+// LockAlias is an inline alias recorded in the lock file.
+type LockAlias struct {
+	// Name of the aliased package.
+	Package string `json:"package"`
+	// Normalized version of the package that is being aliased.
+	Version string `json:"version"`
+	// The alias version, e.g. "1.0.x-dev".
+	Alias string `json:"alias"`
+	// Normalized alias version, e.g. "1.0.9999999.9999999-dev".
+	AliasNormalized string `json:"alias_normalized"`
+}
+
+// StabilityFlags is an object of package name (keys) and stability (values) as stored
+// in the lock file. Composer encodes the stabilities as integers: 0 for stable, 5 for
+// RC, 10 for beta, 15 for alpha and 20 for dev.
+type StabilityFlags map[string]int
+
+func (s *StabilityFlags) UnmarshalJSON(data []byte) error {
+	// PHP encodes an empty associative array as [] so an empty array is valid here.
+	if len(data) == 0 || isArray(data) || string(data) == "null" {
+		*s = StabilityFlags{}
+		return nil
+	}
+	return json.Unmarshal(data, (*map[string]int)(s))
+}
+
+func (s StabilityFlags) MarshalJSON() ([]byte, error) {
+	if len(s) == 0 {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(map[string]int(s))
+}
+
+// PlatformMap is an object of platform package name (keys) and version constraints
+// (values) as stored in the lock file.
+type PlatformMap map[string]string
+
+func (p *PlatformMap) UnmarshalJSON(data []byte) error {
+	// PHP encodes an empty associative array as [] so an empty array is valid here.
+	if len(data) == 0 || isArray(data) || string(data) == "null" {
+		*p = PlatformMap{}
+		return nil
+	}
+	return json.Unmarshal(data, (*map[string]string)(p))
+}
+
+func (p PlatformMap) MarshalJSON() ([]byte, error) {
+	if len(p) == 0 {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(map[string]string(p))
+}
+
+// ParseLockFile parses the contents of a composer.lock file.
+func ParseLockFile(data []byte) (*ComposerLock, error) {
+	lock := &ComposerLock{}
+	err := json.Unmarshal(data, lock)
+	if err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+// LoadLock reads and parses the composer.lock file at path.
+func LoadLock(path string) (*ComposerLock, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseLockFile(data)
+}
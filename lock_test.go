@@ -0,0 +1,186 @@
+package gocomposer
+
+import (
+	"encoding/json"
+	is2 "github.com/matryer/is"
+	"testing"
+)
+
+func TestLoadLock(t *testing.T) {
+	is := is2.New(t)
+
+	lock, err := LoadLock("testdata/composer.lock")
+
+	is.NoErr(err)
+	is.Equal(lock.ContentHash, "2e3a6d5f4b1c8e0a7d9f6b3c1e2a4d5f")
+	is.Equal(len(lock.Readme), 3)
+	is.Equal(len(lock.Packages), 2)
+	is.Equal(len(lock.PackagesDev), 1)
+	is.Equal(lock.MinimumStability, "stable")
+	is.Equal(lock.StabilityFlags, StabilityFlags{"doctrine/instantiator": 20})
+	is.Equal(lock.Platform, PlatformMap{"php": ">=7.4", "ext-json": "*"})
+	is.Equal(lock.PlatformDev, PlatformMap{})
+	is.Equal(lock.PluginAPIVersion, "2.3.0")
+	is.Equal(lock.Aliases, []LockAlias{{
+		Package:         "doctrine/instantiator",
+		Version:         "dev-main",
+		Alias:           "2.0.x-dev",
+		AliasNormalized: "2.0.9999999.9999999-dev",
+	}})
+
+	monolog := lock.Packages[0]
+	is.Equal(monolog.Name, "monolog/monolog")
+	is.Equal(monolog.Version, "2.9.1")
+	is.Equal(monolog.Source.Reference, "f259e2b15fb95494c83f52d3caad003bbf5ffaa1")
+	is.Equal(monolog.Dist.Type, "zip")
+	is.Equal(monolog.Require["psr/log"], "^1.0.1 || ^2.0 || ^3.0")
+	is.Equal(monolog.Provide["psr/log-implementation"], "1.0.0 || 2.0.0 || 3.0.0")
+	is.Equal(monolog.Autoload.PSR4["Monolog\\"], StringOrSlice{"src/Monolog"})
+	is.Equal(monolog.License, StringOrSlice{"MIT"})
+	is.Equal(monolog.Authors[0].Name, "Jordi Boggiano")
+	is.Equal(monolog.Support.Issues, "https://github.com/Seldaek/monolog/issues")
+	is.Equal(monolog.Funding[0].Type, "github")
+	is.Equal(monolog.Time, "2023-02-06T13:44:46+00:00")
+
+	instantiator := lock.PackagesDev[0]
+	is.True(instantiator.DefaultBranch)
+	is.True(instantiator.Dist == nil)
+	is.Equal(instantiator.Autoload.ClassMap, []string{"lib/", "legacy/"})
+	is.Equal(*instantiator.Abandoned, StringOrBool{stringValue: "symfony/var-exporter"})
+}
+
+func TestComposerLock_RoundTrip(t *testing.T) {
+	is := is2.New(t)
+
+	lock, err := LoadLock("testdata/composer.lock")
+	is.NoErr(err)
+
+	data, err := json.Marshal(lock)
+	is.NoErr(err)
+
+	result, err := ParseLockFile(data)
+	is.NoErr(err)
+	is.Equal(result, lock)
+}
+
+func TestComposerLock_GetPackage(t *testing.T) {
+	tests := []struct {
+		name   string
+		pkg    string
+		exists bool
+	}{
+		{
+			name:   `Packages`,
+			pkg:    `psr/log`,
+			exists: true,
+		},
+		{
+			name:   `PackagesDev`,
+			pkg:    `doctrine/instantiator`,
+			exists: true,
+		},
+		{
+			name:   `Missing`,
+			pkg:    `symfony/console`,
+			exists: false,
+		},
+	}
+
+	lock, err := LoadLock("testdata/composer.lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			pkg, exists := lock.GetPackage(test.pkg)
+
+			is.Equal(exists, test.exists)
+			if test.exists {
+				is.Equal(pkg.Name, test.pkg)
+			}
+		})
+	}
+}
+
+func TestPlatformMap_JSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		output string
+		value  PlatformMap
+	}{
+		{
+			name:   `EmptyArray`,
+			input:  `[]`,
+			output: `[]`,
+			value:  PlatformMap{},
+		},
+		{
+			name:   `EmptyObject`,
+			input:  `{}`,
+			output: `[]`,
+			value:  PlatformMap{},
+		},
+		{
+			name:   `Object`,
+			input:  `{"php": "^8.1"}`,
+			output: `{"php":"^8.1"}`,
+			value:  PlatformMap{"php": "^8.1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			result := PlatformMap{}
+			err := json.Unmarshal([]byte(test.input), &result)
+			is.NoErr(err)
+			is.Equal(result, test.value)
+
+			data, err := json.Marshal(result)
+			is.NoErr(err)
+			is.Equal(string(data), test.output)
+		})
+	}
+}
+
+func TestStabilityFlags_JSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		output string
+		value  StabilityFlags
+	}{
+		{
+			name:   `EmptyArray`,
+			input:  `[]`,
+			output: `[]`,
+			value:  StabilityFlags{},
+		},
+		{
+			name:   `Object`,
+			input:  `{"foo/bar": 20, "baz/qux": 10}`,
+			output: `{"baz/qux":10,"foo/bar":20}`,
+			value:  StabilityFlags{"foo/bar": 20, "baz/qux": 10},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			result := StabilityFlags{}
+			err := json.Unmarshal([]byte(test.input), &result)
+			is.NoErr(err)
+			is.Equal(result, test.value)
+
+			data, err := json.Marshal(result)
+			is.NoErr(err)
+			is.Equal(string(data), test.output)
+		})
+	}
+}
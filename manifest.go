@@ -0,0 +1,178 @@
+package gocomposer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+)
+
+// manifest remembers the contents of a composer.json file as it was read, along with
+// the struct values decoded from it, so that only the parts that changed are written
+// back.
+type manifest struct {
+	data     []byte
+	baseline *orderedObject
+}
+
+// newManifest creates a manifest for data, which c was decoded from.
+func newManifest(data []byte, c *ComposerJSON) (*manifest, error) {
+	baseline, err := marshalOrdered(c)
+	if err != nil {
+		return nil, err
+	}
+	return &manifest{data: data, baseline: baseline}, nil
+}
+
+// marshalOrdered encodes c and decodes the result again as an orderedObject.
+func marshalOrdered(c *ComposerJSON) (*orderedObject, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	value, err := decodeOrdered(data)
+	if err != nil {
+		return nil, err
+	}
+	return value.(*orderedObject), nil
+}
+
+// Parse parses the contents of a composer.json file. The original contents are kept
+// so that Encode and Save only rewrite the values that were changed.
+func Parse(data []byte) (*ComposerJSON, error) {
+	if _, err := scanJSONDocument(data); err != nil {
+		return nil, err
+	}
+	c := &ComposerJSON{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	m, err := newManifest(data, c)
+	if err != nil {
+		return nil, err
+	}
+	c.manifest = m
+	return c, nil
+}
+
+// Load reads and parses the composer.json file at path.
+func Load(path string) (*ComposerJSON, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Encode returns the composer.json file contents for c.
+//
+// When c was created with Load or Parse, the original file is kept as it was and only
+// the top level keys whose values changed are rewritten. Unknown keys, key order,
+// indentation, line endings and the escaping of slashes are all preserved, so the
+// result produces a minimal diff the same way `composer config` does. Otherwise, the
+// non-empty values are written in the format Composer uses for new files.
+func (c *ComposerJSON) Encode() ([]byte, error) {
+	m := c.manifest
+	if m == nil {
+		var err error
+		m, err = newManifest([]byte("{\n}\n"), &ComposerJSON{})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	doc, err := scanJSONDocument(m.data)
+	if err != nil {
+		return nil, err
+	}
+	current, err := marshalOrdered(c)
+	if err != nil {
+		return nil, err
+	}
+	empty, err := marshalOrdered(&ComposerJSON{})
+	if err != nil {
+		return nil, err
+	}
+	format := doc.format()
+
+	keys := append([]string{}, current.keys...)
+	for _, key := range m.baseline.keys {
+		if _, exists := current.get(key); !exists {
+			keys = append(keys, key)
+		}
+	}
+
+	edits := make([]jsonEdit, 0)
+	for _, key := range keys {
+		before, _ := m.baseline.get(key)
+		value, exists := current.get(key)
+		if exists && canonicalJSON(value) == canonicalJSON(before) {
+			continue
+		}
+
+		zero, _ := empty.get(key)
+		if !exists || canonicalJSON(value) == canonicalJSON(zero) {
+			if _, inFile := doc.rawValue(key); inFile {
+				edits = append(edits, jsonEdit{key: key})
+			}
+			continue
+		}
+
+		if raw, inFile := doc.rawValue(key); inFile {
+			if original, err := decodeOrdered(raw); err == nil {
+				value = keepOrder(value, original)
+			}
+		}
+		buf := bytes.Buffer{}
+		if err := encodePretty(&buf, value, format, 1); err != nil {
+			return nil, err
+		}
+		edits = append(edits, jsonEdit{key: key, value: buf.Bytes()})
+	}
+
+	return doc.apply(edits), nil
+}
+
+// Save writes c to the composer.json file at path. See Encode for how the original
+// formatting is preserved. An existing file keeps its permissions, new files are
+// created with 0644.
+func (c *ComposerJSON) Save(path string) error {
+	data, err := c.Encode()
+	if err != nil {
+		return err
+	}
+	if err := writeManifest(path, data); err != nil {
+		return err
+	}
+
+	m, err := newManifest(data, c)
+	if err != nil {
+		return err
+	}
+	c.manifest = m
+	return nil
+}
+
+// writeManifest writes data to the file at path. An existing file keeps its
+// permissions, new files are created with 0644.
+func writeManifest(path string, data []byte) error {
+	mode := fs.FileMode(0644)
+	info, err := os.Stat(path)
+	if err == nil {
+		mode = info.Mode().Perm()
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return os.WriteFile(path, data, mode)
+}
+
+// canonicalJSON returns a compact encoding of a decoded JSON value that can be used to
+// compare values.
+func canonicalJSON(v interface{}) string {
+	buf := bytes.Buffer{}
+	if err := encodePHP(&buf, v); err != nil {
+		return ""
+	}
+	return buf.String()
+}
@@ -0,0 +1,251 @@
+package gocomposer
+
+import (
+	is2 "github.com/matryer/is"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestComposerJSON_Encode_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{
+			name: `EscapedSlashes`,
+			path: `testdata/manifest/composer.json`,
+		},
+		{
+			name: `CRLF`,
+			path: `testdata/manifest/crlf.json`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			data, err := os.ReadFile(test.path)
+			is.NoErr(err)
+
+			c, err := Parse(data)
+			is.NoErr(err)
+
+			result, err := c.Encode()
+			is.NoErr(err)
+			is.Equal(string(result), string(data))
+		})
+	}
+}
+
+func TestComposerJSON_Encode_Edit(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		edit   func(c *ComposerJSON)
+		output string
+	}{
+		{
+			name: `ChangeValue`,
+			path: `testdata/manifest/composer.json`,
+			edit: func(c *ComposerJSON) {
+				c.Description = "The Acme blog/news site"
+			},
+			output: `{
+    "name": "acme\/blog",
+    "type": "project",
+    "description": "The Acme blog\/news site",
+    "license": "MIT",
+    "require": {
+        "php": "^8.1",
+        "symfony\/console": "^6.3",
+        "monolog\/monolog": "^3.0"
+    },
+    "require-dev": {
+        "phpunit\/phpunit": "^10.0"
+    },
+    "autoload": {
+        "psr-4": {
+            "Acme\\Blog\\": "src\/"
+        }
+    },
+    "x-custom": {"keep": [1, 2,   3]},
+    "minimum-stability": "stable"
+}
+`,
+		},
+		{
+			name: `AddToMapKeepsOrder`,
+			path: `testdata/manifest/composer.json`,
+			edit: func(c *ComposerJSON) {
+				c.Require["psr/log"] = "^3.0"
+			},
+			output: `{
+    "name": "acme\/blog",
+    "type": "project",
+    "description": "The Acme blog",
+    "license": "MIT",
+    "require": {
+        "php": "^8.1",
+        "symfony\/console": "^6.3",
+        "monolog\/monolog": "^3.0",
+        "psr\/log": "^3.0"
+    },
+    "require-dev": {
+        "phpunit\/phpunit": "^10.0"
+    },
+    "autoload": {
+        "psr-4": {
+            "Acme\\Blog\\": "src\/"
+        }
+    },
+    "x-custom": {"keep": [1, 2,   3]},
+    "minimum-stability": "stable"
+}
+`,
+		},
+		{
+			name: `RemoveAndAdd`,
+			path: `testdata/manifest/composer.json`,
+			edit: func(c *ComposerJSON) {
+				c.RequireDev = nil
+				c.MinimumStability = ""
+				c.PreferStable = true
+			},
+			output: `{
+    "name": "acme\/blog",
+    "type": "project",
+    "description": "The Acme blog",
+    "license": "MIT",
+    "require": {
+        "php": "^8.1",
+        "symfony\/console": "^6.3",
+        "monolog\/monolog": "^3.0"
+    },
+    "autoload": {
+        "psr-4": {
+            "Acme\\Blog\\": "src\/"
+        }
+    },
+    "x-custom": {"keep": [1, 2,   3]},
+    "prefer-stable": true
+}
+`,
+		},
+		{
+			name: `CRLF`,
+			path: `testdata/manifest/crlf.json`,
+			edit: func(c *ComposerJSON) {
+				c.Require["ext-json"] = "*"
+			},
+			output: "{\r\n  \"name\": \"acme/tabs\",\r\n  \"require\": {\r\n    \"php\": \"^8.1\",\r\n" +
+				"    \"ext-json\": \"*\"\r\n  }\r\n}",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			c, err := Load(test.path)
+			is.NoErr(err)
+
+			test.edit(c)
+			result, err := c.Encode()
+			is.NoErr(err)
+			is.Equal(string(result), test.output)
+		})
+	}
+}
+
+func TestComposerJSON_Encode_New(t *testing.T) {
+	is := is2.New(t)
+
+	c := ComposerJSON{
+		Name:    "acme/new",
+		Type:    "library",
+		License: StringOrSlice{"MIT"},
+		Require: map[string]string{"php": "^8.1"},
+		Autoload: Autoload{
+			PSR4: map[string]StringOrSlice{"Acme\\New\\": {"src/"}},
+		},
+	}
+
+	result, err := c.Encode()
+	is.NoErr(err)
+	is.Equal(string(result), `{
+    "name": "acme/new",
+    "license": "MIT",
+    "type": "library",
+    "require": {
+        "php": "^8.1"
+    },
+    "autoload": {
+        "psr-4": {
+            "Acme\\New\\": "src/"
+        }
+    }
+}
+`)
+}
+
+func TestComposerJSON_Save(t *testing.T) {
+	is := is2.New(t)
+
+	data, err := os.ReadFile("testdata/manifest/composer.json")
+	is.NoErr(err)
+
+	path := filepath.Join(t.TempDir(), "composer.json")
+	is.NoErr(os.WriteFile(path, data, 0600))
+
+	c, err := Load(path)
+	is.NoErr(err)
+	c.Require["psr/log"] = "^3.0"
+	is.NoErr(c.Save(path))
+
+	info, err := os.Stat(path)
+	is.NoErr(err)
+	is.Equal(info.Mode().Perm(), os.FileMode(0600))
+
+	saved, err := Load(path)
+	is.NoErr(err)
+	is.Equal(saved.Require["psr/log"], "^3.0")
+
+	// Saving again without changes must not touch the file contents.
+	before, err := os.ReadFile(path)
+	is.NoErr(err)
+	is.NoErr(c.Save(path))
+	after, err := os.ReadFile(path)
+	is.NoErr(err)
+	is.Equal(string(after), string(before))
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  `Array`,
+			input: `[]`,
+		},
+		{
+			name:  `TrailingData`,
+			input: `{"name": "acme/blog"} {}`,
+		},
+		{
+			name:  `Syntax`,
+			input: `{"name": }`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			_, err := Parse([]byte(test.input))
+			is.True(err != nil)
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package gocomposer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// MinifiedFormat is the value of "minified" in Composer 2 metadata where every version
+// only lists the keys that changed from the version before it.
+const MinifiedFormat = "composer/2.0"
+
+// unsetValue is the value that removes an inherited key from a minified version.
+const unsetValue = `"__unset"`
+
+// DecodePackageMetadata decodes the Composer 2 metadata of a package as served from a
+// metadata-url such as https://repo.packagist.org/p2/monolog/monolog.json. Every name
+// has an array of versions, which are expanded if the metadata is minified. The
+// Composer 1 format, where every name has an object of versions, is accepted too.
+// Package names are lower cased.
+func DecodePackageMetadata(data []byte) (map[string][]InlinePackage, error) {
+	temp := struct {
+		Packages map[string]json.RawMessage `json:"packages"`
+		Minified string                     `json:"minified"`
+	}{}
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return nil, err
+	}
+	if temp.Minified != "" && temp.Minified != MinifiedFormat {
+		return nil, fmt.Errorf(`unsupported minified format "%s"`, temp.Minified)
+	}
+
+	packages := make(map[string][]InlinePackage, len(temp.Packages))
+	for name, raw := range temp.Packages {
+		name = strings.ToLower(name)
+		if isObject(raw) {
+			versions, err := decodeVersions(raw)
+			if err != nil {
+				return nil, fmt.Errorf("versions of %s: %w", name, err)
+			}
+			packages[name] = versions
+			continue
+		}
+
+		versions := make([]map[string]json.RawMessage, 0)
+		if err := json.Unmarshal(raw, &versions); err != nil {
+			return nil, fmt.Errorf("versions of %s: %w", name, err)
+		}
+		if temp.Minified == MinifiedFormat {
+			versions = ExpandVersions(versions)
+		}
+		for _, version := range versions {
+			encoded, err := json.Marshal(version)
+			if err != nil {
+				return nil, err
+			}
+			pkg := InlinePackage{}
+			if err := json.Unmarshal(encoded, &pkg); err != nil {
+				return nil, fmt.Errorf("versions of %s: %w", name, err)
+			}
+			packages[name] = append(packages[name], pkg)
+		}
+	}
+	return packages, nil
+}
+
+// EncodePackageMetadata encodes packages as minified Composer 2 metadata, the format
+// DecodePackageMetadata reads. The versions of every package are written in the order
+// they are given, which is usually from newest to oldest.
+func EncodePackageMetadata(packages map[string][]InlinePackage) ([]byte, error) {
+	minified := make(map[string][]map[string]json.RawMessage, len(packages))
+	for name, versions := range packages {
+		records := make([]map[string]json.RawMessage, 0, len(versions))
+		for _, version := range versions {
+			encoded, err := json.Marshal(version)
+			if err != nil {
+				return nil, err
+			}
+			record := make(map[string]json.RawMessage)
+			if err := json.Unmarshal(encoded, &record); err != nil {
+				return nil, err
+			}
+			records = append(records, record)
+		}
+		minified[name] = MinifyVersions(records)
+	}
+
+	return json.Marshal(struct {
+		Packages map[string][]map[string]json.RawMessage `json:"packages"`
+		Minified string                                  `json:"minified"`
+	}{minified, MinifiedFormat})
+}
+
+// ExpandVersions undoes the minification of Composer 2 metadata. Each version inherits
+// the keys of the expanded version before it, the keys it lists replace the inherited
+// ones and the value "__unset" removes an inherited key. This is a port of Composer's
+// MetadataMinifier::expand.
+func ExpandVersions(versions []map[string]json.RawMessage) []map[string]json.RawMessage {
+	expanded := make([]map[string]json.RawMessage, 0, len(versions))
+	var previous map[string]json.RawMessage
+	for _, version := range versions {
+		current := make(map[string]json.RawMessage, len(previous)+len(version))
+		for key, value := range previous {
+			current[key] = value
+		}
+		for key, value := range version {
+			if string(value) == unsetValue {
+				delete(current, key)
+			} else {
+				current[key] = value
+			}
+		}
+		expanded = append(expanded, current)
+		previous = current
+	}
+	return expanded
+}
+
+// MinifyVersions is the reverse of ExpandVersions. The first version is kept as it is
+// and every following version only lists the keys whose values changed from the
+// version before it, with "__unset" for the keys it does not have. Values are compared
+// without insignificant whitespace. This is a port of Composer's
+// MetadataMinifier::minify.
+func MinifyVersions(versions []map[string]json.RawMessage) []map[string]json.RawMessage {
+	minified := make([]map[string]json.RawMessage, 0, len(versions))
+	var previous map[string]json.RawMessage
+	for i, version := range versions {
+		if i == 0 {
+			minified = append(minified, version)
+			previous = version
+			continue
+		}
+
+		current := make(map[string]json.RawMessage)
+		for key, value := range version {
+			if before, exists := previous[key]; !exists || !equalJSON(before, value) {
+				current[key] = value
+			}
+		}
+		for key := range previous {
+			if _, exists := version[key]; !exists {
+				current[key] = json.RawMessage(unsetValue)
+			}
+		}
+		minified = append(minified, current)
+		previous = version
+	}
+	return minified
+}
+
+// equalJSON returns true if two JSON values are the same apart from insignificant
+// whitespace.
+func equalJSON(a, b json.RawMessage) bool {
+	ca, cb := bytes.Buffer{}, bytes.Buffer{}
+	if json.Compact(&ca, a) != nil || json.Compact(&cb, b) != nil {
+		return bytes.Equal(a, b)
+	}
+	return bytes.Equal(ca.Bytes(), cb.Bytes())
+}
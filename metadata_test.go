@@ -0,0 +1,144 @@
+package gocomposer
+
+import (
+	"encoding/json"
+	is2 "github.com/matryer/is"
+	"os"
+	"testing"
+)
+
+func TestExpandVersions(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions string
+		expected string
+	}{
+		{
+			name:     `Empty`,
+			versions: `[]`,
+			expected: `[]`,
+		},
+		{
+			name:     `Inherit`,
+			versions: `[{"name":"acme/log","version":"2.0.0","license":["MIT"]},{"version":"1.0.0"}]`,
+			expected: `[{"license":["MIT"],"name":"acme/log","version":"2.0.0"},{"license":["MIT"],"name":"acme/log","version":"1.0.0"}]`,
+		},
+		{
+			name:     `Unset`,
+			versions: `[{"name":"acme/log","version":"2.0.0","license":["MIT"]},{"version":"1.0.0","license":"__unset"},{"version":"0.1.0"}]`,
+			expected: `[{"license":["MIT"],"name":"acme/log","version":"2.0.0"},{"name":"acme/log","version":"1.0.0"},{"name":"acme/log","version":"0.1.0"}]`,
+		},
+		{
+			name:     `Replace`,
+			versions: `[{"name":"acme/log","version":"2.0.0","require":{"php":"^8.1"}},{"version":"1.0.0","require":{"php":"^7.4","psr/log":"^1.0"}}]`,
+			expected: `[{"name":"acme/log","require":{"php":"^8.1"},"version":"2.0.0"},{"name":"acme/log","require":{"php":"^7.4","psr/log":"^1.0"},"version":"1.0.0"}]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			versions := make([]map[string]json.RawMessage, 0)
+			is.NoErr(json.Unmarshal([]byte(test.versions), &versions))
+			expanded, err := json.Marshal(ExpandVersions(versions))
+			is.NoErr(err)
+			is.Equal(string(expanded), test.expected)
+		})
+	}
+}
+
+func TestMinifyVersions(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions string
+		expected string
+	}{
+		{
+			name:     `Empty`,
+			versions: `[]`,
+			expected: `[]`,
+		},
+		{
+			name:     `Unchanged`,
+			versions: `[{"name":"acme/log","version":"2.0.0","license":["MIT"]},{"name":"acme/log","version":"1.0.0","license": [ "MIT" ]}]`,
+			expected: `[{"license":["MIT"],"name":"acme/log","version":"2.0.0"},{"version":"1.0.0"}]`,
+		},
+		{
+			name:     `Unset`,
+			versions: `[{"name":"acme/log","version":"2.0.0","license":["MIT"]},{"name":"acme/log","version":"1.0.0"},{"name":"acme/log","version":"0.1.0","license":["MIT"]}]`,
+			expected: `[{"license":["MIT"],"name":"acme/log","version":"2.0.0"},{"license":"__unset","version":"1.0.0"},{"license":["MIT"],"version":"0.1.0"}]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			versions := make([]map[string]json.RawMessage, 0)
+			is.NoErr(json.Unmarshal([]byte(test.versions), &versions))
+			minified, err := json.Marshal(MinifyVersions(versions))
+			is.NoErr(err)
+			is.Equal(string(minified), test.expected)
+
+			// Expanding the minified versions gives back the original versions.
+			expanded, err := json.Marshal(ExpandVersions(MinifyVersions(versions)))
+			is.NoErr(err)
+			original, err := json.Marshal(versions)
+			is.NoErr(err)
+			is.Equal(canonicalJSONBytes(t, expanded), canonicalJSONBytes(t, original))
+		})
+	}
+}
+
+// canonicalJSONBytes decodes and encodes JSON data to remove insignificant whitespace.
+func canonicalJSONBytes(t *testing.T, data []byte) string {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(encoded)
+}
+
+func TestPackageMetadata_RoundTrip(t *testing.T) {
+	is := is2.New(t)
+
+	data, err := os.ReadFile("testdata/repository/p2/acme/log.json")
+	is.NoErr(err)
+	packages, err := DecodePackageMetadata(data)
+	is.NoErr(err)
+	is.Equal(resolvedVersions(packages["acme/log"]), []string{"acme/log 2.0.0", "acme/log 1.1.0", "acme/log 1.0.0"})
+
+	encoded, err := EncodePackageMetadata(packages)
+	is.NoErr(err)
+
+	// The encoded metadata is minified the same way as the original.
+	minified := struct {
+		Packages map[string][]map[string]json.RawMessage `json:"packages"`
+		Minified string                                  `json:"minified"`
+	}{}
+	is.NoErr(json.Unmarshal(encoded, &minified))
+	is.Equal(minified.Minified, MinifiedFormat)
+	is.Equal(string(minified.Packages["acme/log"][1]["provide"]), `"__unset"`)
+	is.Equal(string(minified.Packages["acme/log"][2]["license"]), `"__unset"`)
+	_, hasName := minified.Packages["acme/log"][1]["name"]
+	is.True(!hasName)
+
+	decoded, err := DecodePackageMetadata(encoded)
+	is.NoErr(err)
+	is.Equal(decoded, packages)
+}
+
+func TestDecodePackageMetadata_Errors(t *testing.T) {
+	is := is2.New(t)
+
+	_, err := DecodePackageMetadata([]byte(`{"packages":{},"minified":"composer/3.0"}`))
+	is.Equal(err.Error(), `unsupported minified format "composer/3.0"`)
+
+	_, err = DecodePackageMetadata([]byte(`{"packages":{"acme/log":[{"name":"acme/log","require":[1]}]}}`))
+	is.True(err != nil)
+}
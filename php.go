@@ -0,0 +1,477 @@
+package gocomposer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// phpArray is an ordered PHP array that is written with var_export.
+type phpArray []phpArrayItem
+
+// phpArrayItem is a key and value of a phpArray. The value is a string, an int or a
+// nested phpArray.
+type phpArrayItem struct {
+	key   string
+	value interface{}
+}
+
+// phpList returns a phpArray with the values keyed 0, 1, 2...
+func phpList(values ...string) phpArray {
+	list := make(phpArray, 0, len(values))
+	for i, value := range values {
+		list = append(list, phpArrayItem{strconv.Itoa(i), value})
+	}
+	return list
+}
+
+// varExport formats a value like PHP's var_export.
+func varExport(value interface{}) string {
+	b := strings.Builder{}
+	writeVarExport(&b, value, 1)
+	return b.String()
+}
+
+func writeVarExport(b *strings.Builder, value interface{}, level int) {
+	switch value := value.(type) {
+	case string:
+		b.WriteString(phpString(value))
+	case int:
+		b.WriteString(strconv.Itoa(value))
+	case phpArray:
+		if level > 1 {
+			b.WriteString("\n")
+			b.WriteString(strings.Repeat(" ", level-1))
+		}
+		b.WriteString("array (\n")
+		for _, item := range value {
+			b.WriteString(strings.Repeat(" ", level+1))
+			b.WriteString(phpKey(item.key))
+			b.WriteString(" => ")
+			writeVarExport(b, item.value, level+2)
+			b.WriteString(",\n")
+		}
+		if level > 1 {
+			b.WriteString(strings.Repeat(" ", level-1))
+		}
+		b.WriteString(")")
+	}
+}
+
+// phpString returns a PHP single quoted string literal like var_export does.
+func phpString(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s)
+	s = strings.ReplaceAll(s, "\x00", `' . "\0" . '`)
+	return "'" + s + "'"
+}
+
+// phpIntKeyRegex matches the strings PHP turns into integers when they are used as
+// array keys.
+var phpIntKeyRegex = regexp.MustCompile(`^(0|-?[1-9][0-9]*)$`)
+
+// phpKey returns the literal of an array key. Decimal integer strings are integer
+// keys in PHP, so they are written without quotes.
+func phpKey(key string) string {
+	if phpIntKeyRegex.MatchString(key) {
+		if _, err := strconv.ParseInt(key, 10, 64); err == nil {
+			return key
+		}
+	}
+	return phpString(key)
+}
+
+// phpStrtr replaces the keys of pairs in s like PHP's strtr, which tries the longest
+// keys first and does not replace text that was already replaced.
+func phpStrtr(s string, pairs map[string]string) string {
+	keys := make([]string, 0, len(pairs))
+	for key := range pairs {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return len(keys[i]) > len(keys[j])
+	})
+
+	b := strings.Builder{}
+	for i := 0; i < len(s); {
+		replaced := false
+		for _, key := range keys {
+			if strings.HasPrefix(s[i:], key) {
+				b.WriteString(pairs[key])
+				i += len(key)
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			b.WriteByte(s[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+// strnatcasecmp compares two strings in natural order ignoring case like PHP's
+// strnatcasecmp, so "lib2" comes before "lib10".
+func strnatcasecmp(a, b string) int {
+	return strnatcmp(strings.ToLower(a), strings.ToLower(b))
+}
+
+// strnatcmp compares two strings in natural order like PHP's strnatcmp.
+func strnatcmp(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			startA, startB := i, j
+			for i < len(a) && isDigit(a[i]) {
+				i++
+			}
+			for j < len(b) && isDigit(b[j]) {
+				j++
+			}
+			numA := strings.TrimLeft(a[startA:i], "0")
+			numB := strings.TrimLeft(b[startB:j], "0")
+			if len(numA) != len(numB) {
+				return sign(len(numA) - len(numB))
+			}
+			if c := strings.Compare(numA, numB); c != 0 {
+				return c
+			}
+			continue
+		}
+		if a[i] != b[j] {
+			return sign(int(a[i]) - int(b[j]))
+		}
+		i++
+		j++
+	}
+	return sign((len(a) - i) - (len(b) - j))
+}
+
+// phpSubstr returns s from start like PHP's substr, which returns an empty string
+// instead of failing if start is past the end of s.
+func phpSubstr(s string, start int) string {
+	if start >= len(s) {
+		return ""
+	}
+	return s[start:]
+}
+
+// get returns the value of a key of the array.
+func (a phpArray) get(key string) (interface{}, bool) {
+	for _, item := range a {
+		if item.key == key {
+			return item.value, true
+		}
+	}
+	return nil, false
+}
+
+// getString returns the value of a key of the array if it is a string.
+func (a phpArray) getString(key string) string {
+	value, _ := a.get(key)
+	s, _ := value.(string)
+	return s
+}
+
+// getArray returns the value of a key of the array if it is an array.
+func (a phpArray) getArray(key string) phpArray {
+	value, _ := a.get(key)
+	array, _ := value.(phpArray)
+	return array
+}
+
+// strings returns the string values of the array, or nil if there are none.
+func (a phpArray) strings() []string {
+	var values []string
+	for _, item := range a {
+		if s, ok := item.value.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+// parsePHPReturn parses a PHP file that returns a constant expression, like the
+// installed.php file Composer writes. Arrays are returned as a phpArray, strings as a
+// string, integers as an int, booleans as a bool and null as nil. Strings can be
+// concatenated with "." and the __DIR__ constant is replaced by dir.
+func parsePHPReturn(data []byte, dir string) (interface{}, error) {
+	p := &phpParser{s: string(data), dir: dir}
+	p.skip()
+	if !p.consume("<?php") {
+		return nil, p.errorf("expected <?php")
+	}
+	p.skip()
+	if !p.consumeWord("return") {
+		return nil, p.errorf("expected return")
+	}
+	value, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	p.skip()
+	if !p.consume(";") {
+		return nil, p.errorf("expected ;")
+	}
+	return value, nil
+}
+
+// phpParser parses constant PHP expressions.
+type phpParser struct {
+	s   string
+	pos int
+	dir string
+}
+
+func (p *phpParser) errorf(format string, args ...interface{}) error {
+	line := strings.Count(p.s[:p.pos], "\n") + 1
+	return fmt.Errorf("parse error on line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+// skip skips whitespace and comments.
+func (p *phpParser) skip() {
+	for p.pos < len(p.s) {
+		switch {
+		case strings.ContainsRune(" \t\r\n", rune(p.s[p.pos])):
+			p.pos++
+		case strings.HasPrefix(p.s[p.pos:], "//") || p.s[p.pos] == '#':
+			end := strings.IndexByte(p.s[p.pos:], '\n')
+			if end < 0 {
+				p.pos = len(p.s)
+			} else {
+				p.pos += end + 1
+			}
+		case strings.HasPrefix(p.s[p.pos:], "/*"):
+			end := strings.Index(p.s[p.pos+2:], "*/")
+			if end < 0 {
+				p.pos = len(p.s)
+			} else {
+				p.pos += end + 4
+			}
+		default:
+			return
+		}
+	}
+}
+
+// consume skips s if the input continues with it.
+func (p *phpParser) consume(s string) bool {
+	if strings.HasPrefix(p.s[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+// consumeWord skips a keyword, ignoring case, if the input continues with it.
+func (p *phpParser) consumeWord(word string) bool {
+	end := p.pos + len(word)
+	if end > len(p.s) || !strings.EqualFold(p.s[p.pos:end], word) {
+		return false
+	}
+	if isIdentifierByte(p.s, end) {
+		return false
+	}
+	p.pos = end
+	return true
+}
+
+// expression parses values concatenated with ".".
+func (p *phpParser) expression() (interface{}, error) {
+	value, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skip()
+		if !p.consume(".") {
+			return value, nil
+		}
+		next, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		left, ok := phpConcatOperand(value)
+		if !ok {
+			return nil, p.errorf("cannot concatenate an array")
+		}
+		right, ok := phpConcatOperand(next)
+		if !ok {
+			return nil, p.errorf("cannot concatenate an array")
+		}
+		value = left + right
+	}
+}
+
+// phpConcatOperand returns the string a scalar is converted to when it is concatenated.
+func phpConcatOperand(value interface{}) (string, bool) {
+	switch value := value.(type) {
+	case string:
+		return value, true
+	case int:
+		return strconv.Itoa(value), true
+	case bool:
+		if value {
+			return "1", true
+		}
+		return "", true
+	case nil:
+		return "", true
+	}
+	return "", false
+}
+
+// value parses a literal, an array or the __DIR__ constant.
+func (p *phpParser) value() (interface{}, error) {
+	p.skip()
+	if p.pos >= len(p.s) {
+		return nil, p.errorf("unexpected end of file")
+	}
+	switch c := p.s[p.pos]; {
+	case c == '\'':
+		return p.singleQuoted()
+	case c == '"':
+		return p.doubleQuoted()
+	case c == '[':
+		p.pos++
+		return p.array("]")
+	case c == '-' || isDigit(c):
+		start := p.pos
+		p.pos++
+		for p.pos < len(p.s) && isDigit(p.s[p.pos]) {
+			p.pos++
+		}
+		n, err := strconv.Atoi(p.s[start:p.pos])
+		if err != nil {
+			return nil, p.errorf("invalid integer %s", p.s[start:p.pos])
+		}
+		return n, nil
+	case p.consumeWord("array"):
+		p.skip()
+		if !p.consume("(") {
+			return nil, p.errorf("expected (")
+		}
+		return p.array(")")
+	case p.consumeWord("__DIR__"):
+		return p.dir, nil
+	case p.consumeWord("null"):
+		return nil, nil
+	case p.consumeWord("true"):
+		return true, nil
+	case p.consumeWord("false"):
+		return false, nil
+	}
+	return nil, p.errorf("unexpected %q", p.s[p.pos])
+}
+
+// array parses the items of an array up to the closing bracket. Items without a key
+// get the next integer key like in PHP.
+func (p *phpParser) array(closing string) (phpArray, error) {
+	array := make(phpArray, 0)
+	next := 0
+	for {
+		p.skip()
+		if p.consume(closing) {
+			return array, nil
+		}
+		value, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		key := ""
+		p.skip()
+		if p.consume("=>") {
+			switch k := value.(type) {
+			case string:
+				key = k
+			case int:
+				key = strconv.Itoa(k)
+			default:
+				return nil, p.errorf("invalid array key")
+			}
+			if value, err = p.expression(); err != nil {
+				return nil, err
+			}
+		} else {
+			key = strconv.Itoa(next)
+		}
+		if n, err := strconv.Atoi(key); err == nil && phpIntKeyRegex.MatchString(key) && n >= next {
+			next = n + 1
+		}
+
+		replaced := false
+		for i := range array {
+			if array[i].key == key {
+				array[i].value = value
+				replaced = true
+			}
+		}
+		if !replaced {
+			array = append(array, phpArrayItem{key, value})
+		}
+
+		p.skip()
+		if !p.consume(",") {
+			p.skip()
+			if !p.consume(closing) {
+				return nil, p.errorf("expected , or %s", closing)
+			}
+			return array, nil
+		}
+	}
+}
+
+// singleQuoted parses a single quoted string, where only \' and \\ are escapes.
+func (p *phpParser) singleQuoted() (string, error) {
+	b := strings.Builder{}
+	for p.pos++; p.pos < len(p.s); p.pos++ {
+		c := p.s[p.pos]
+		switch {
+		case c == '\'':
+			p.pos++
+			return b.String(), nil
+		case c == '\\' && p.pos+1 < len(p.s) && (p.s[p.pos+1] == '\'' || p.s[p.pos+1] == '\\'):
+			p.pos++
+			b.WriteByte(p.s[p.pos])
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+// phpEscapes are the escape sequences of double quoted strings without variables.
+var phpEscapes = map[byte]byte{
+	'n': '\n', 't': '\t', 'r': '\r', 'v': '\v', 'e': 0x1b, 'f': '\f', '0': 0,
+	'\\': '\\', '$': '$', '"': '"',
+}
+
+// doubleQuoted parses a double quoted string with simple escape sequences. Variables
+// are not supported.
+func (p *phpParser) doubleQuoted() (string, error) {
+	b := strings.Builder{}
+	for p.pos++; p.pos < len(p.s); p.pos++ {
+		c := p.s[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			return b.String(), nil
+		case c == '$':
+			return "", p.errorf("variables are not supported")
+		case c == '\\' && p.pos+1 < len(p.s):
+			if escaped, ok := phpEscapes[p.s[p.pos+1]]; ok {
+				p.pos++
+				b.WriteByte(escaped)
+			} else {
+				b.WriteByte(c)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
@@ -0,0 +1,149 @@
+package gocomposer
+
+import (
+	is2 "github.com/matryer/is"
+	"testing"
+)
+
+func Test_varExport(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected string
+	}{
+		{
+			name:     `String`,
+			value:    `it's a \ path`,
+			expected: `'it\'s a \\ path'`,
+		},
+		{
+			name:     `NUL`,
+			value:    "a\x00b",
+			expected: `'a' . "\0" . 'b'`,
+		},
+		{
+			name:     `Int`,
+			value:    26,
+			expected: `26`,
+		},
+		{
+			name:     `List`,
+			value:    phpList("a", "b"),
+			expected: "array (\n  0 => 'a',\n  1 => 'b',\n)",
+		},
+		{
+			name: `Nested`,
+			value: phpArray{
+				{"S", phpArray{{"Symfony\\", 8}}},
+				{"10", "ten"},
+				{"010", "leading zero"},
+			},
+			expected: "array (\n  'S' => \n  array (\n    'Symfony\\\\' => 8,\n  ),\n  10 => 'ten',\n  '010' => 'leading zero',\n)",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+			is.Equal(varExport(test.value), test.expected)
+		})
+	}
+}
+
+func Test_phpStrtr(t *testing.T) {
+	is := is2.New(t)
+
+	pairs := map[string]string{
+		"/app/":        "BASE/",
+		"/app/vendor/": "VENDOR/",
+		"BASE":         "never",
+	}
+	is.Equal(phpStrtr("/app/src /app/vendor/acme /other", pairs), "BASE/src VENDOR/acme /other")
+	is.Equal(phpStrtr("", pairs), "")
+}
+
+func Test_strnatcasecmp(t *testing.T) {
+	tests := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{"acme/lib2", "acme/lib10", -1},
+		{"acme/lib10", "acme/lib2", 1},
+		{"Acme/Log", "acme/log", 0},
+		{"acme/log", "acme/log-bridge", -1},
+		{"psr/log", "acme/log", 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.a+" "+test.b, func(t *testing.T) {
+			is := is2.New(t)
+			is.Equal(strnatcasecmp(test.a, test.b), test.expected)
+		})
+	}
+}
+
+func Test_parsePHPReturn(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected interface{}
+	}{
+		{
+			name:     `Scalars`,
+			data:     `<?php return array(1, -2, 'it\'s', "tab\t\\", NULL, true, FALSE);`,
+			expected: phpArray{{"0", 1}, {"1", -2}, {"2", "it's"}, {"3", "tab\t\\"}, {"4", nil}, {"5", true}, {"6", false}},
+		},
+		{
+			name: `Nested`,
+			data: "<?php\n// installed.php\nreturn [\n    'a' => ['b' => __DIR__ . '/../x', /* list */ 'c', 'd',],\n    5 => 'e',\n    'f',\n];\n",
+			expected: phpArray{
+				{"a", phpArray{{"b", "/app/vendor/composer/../x"}, {"0", "c"}, {"1", "d"}}},
+				{"5", "e"},
+				{"6", "f"},
+			},
+		},
+		{
+			name:     `Concatenation`,
+			data:     `<?php return 'a' . "\0" . 'b' . 1;`,
+			expected: "a\x00b1",
+		},
+		{
+			name:     `Duplicate keys`,
+			data:     `<?php return array('a' => 1, 'b' => 2, 'a' => 3);`,
+			expected: phpArray{{"a", 3}, {"b", 2}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+			value, err := parsePHPReturn([]byte(test.data), "/app/vendor/composer")
+			is.NoErr(err)
+			is.Equal(value, test.expected)
+		})
+	}
+}
+
+func Test_parsePHPReturn_Errors(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected string
+	}{
+		{`return 1;`, `parse error on line 1: expected <?php`},
+		{`<?php echo 1;`, `parse error on line 1: expected return`},
+		{"<?php return array(\n'a' => 1\n'b');", `parse error on line 3: expected , or )`},
+		{`<?php return "$a";`, `parse error on line 1: variables are not supported`},
+		{`<?php return 'a`, `parse error on line 1: unterminated string`},
+		{`<?php return array() . 'a';`, `parse error on line 1: cannot concatenate an array`},
+		{`<?php return 1`, `parse error on line 1: expected ;`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.data, func(t *testing.T) {
+			is := is2.New(t)
+			_, err := parsePHPReturn([]byte(test.data), "")
+			is.Equal(err.Error(), test.expected)
+		})
+	}
+}
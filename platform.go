@@ -0,0 +1,514 @@
+package gocomposer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PlatformCheckFile is the file in the composer directory of the vendor directory that
+// checks the platform requirements when the autoloader is included.
+const PlatformCheckFile = "platform_check.php"
+
+// Statuses of a PlatformCheckResult, as shown by `composer check-platform-reqs`.
+const (
+	PlatformCheckSuccess = "success"
+	PlatformCheckFailed  = "failed"
+	PlatformCheckMissing = "missing"
+)
+
+// PlatformRequirement is a requirement of a package on a platform package like php,
+// ext-json or composer-plugin-api.
+type PlatformRequirement struct {
+	// The lowercase name of the platform package.
+	Name string
+
+	// The version constraint, e.g. "^8.1".
+	Constraint string
+
+	// The name of the package with the requirement.
+	Source string
+
+	// Whether the requirement is only needed for development, because it is in the
+	// require-dev section of the root package or of a package in packages-dev.
+	Dev bool
+}
+
+// PlatformCheckResult is the result of checking the requirements on a platform
+// package.
+type PlatformCheckResult struct {
+	// The platform package, or the package that provides or replaces it.
+	Name string
+
+	// The version of the platform package, the constraint the package provides it
+	// with, or "n/a" if it is missing.
+	Version string
+
+	// The requirement that failed, or the first requirement on the platform package.
+	Requirement PlatformRequirement
+
+	// One of PlatformCheckSuccess, PlatformCheckFailed or PlatformCheckMissing.
+	Status string
+
+	// The package that provides or replaces the platform package, empty if the
+	// platform package itself was checked.
+	Provider string
+}
+
+// SplitPlatformRequirements separates the platform packages of a require section from
+// the other packages.
+func SplitPlatformRequirements(require map[string]string) (platform map[string]string, packages map[string]string) {
+	platform = make(map[string]string)
+	packages = make(map[string]string)
+	for name, constraint := range require {
+		if isPlatformPackage(name) {
+			platform[name] = constraint
+		} else {
+			packages[name] = constraint
+		}
+	}
+	return platform, packages
+}
+
+// ApplyPlatformOverrides returns the platform packages (keys) and their versions
+// (values) with the config.platform overrides applied. An override with a version
+// replaces the version of the platform package or adds it, and an override that is
+// false removes the platform package.
+func ApplyPlatformOverrides(platform map[string]string, overrides map[string]StringOrBool) map[string]string {
+	applied := make(map[string]string, len(platform))
+	for name, version := range platform {
+		applied[strings.ToLower(name)] = version
+	}
+	for name, override := range overrides {
+		name = strings.ToLower(name)
+		if override.IsBool() {
+			if !override.Bool() {
+				delete(applied, name)
+			}
+			continue
+		}
+		applied[name] = override.String()
+	}
+	return applied
+}
+
+// PlatformChecker checks the platform requirements of the root package and the
+// installed packages, like `composer check-platform-reqs`, and generates the
+// platform_check.php file of the autoloader.
+type PlatformChecker struct {
+	// The root package. Its config.platform overrides are applied to Platform.
+	Root ComposerJSON
+
+	// The installed packages, usually the packages of the lock file.
+	Packages []LockedPackage
+
+	// The installed development packages, usually the packages-dev of the lock file.
+	PackagesDev []LockedPackage
+
+	// If true, the requirements of require-dev and of PackagesDev are not checked.
+	NoDev bool
+
+	// Platform packages (keys) and their versions (values) of the machine, e.g. "php":
+	// "8.2.4" or "ext-json": "8.2.4".
+	Platform map[string]string
+}
+
+// Requirements returns the platform requirements of the root package and the
+// installed packages, sorted by platform package and then by source.
+func (c PlatformChecker) Requirements() []PlatformRequirement {
+	requirements := make([]PlatformRequirement, 0)
+	add := func(source string, require map[string]string, dev bool) {
+		for name, constraint := range require {
+			if isPlatformPackage(name) {
+				requirements = append(requirements, PlatformRequirement{
+					Name:       strings.ToLower(name),
+					Constraint: constraint,
+					Source:     source,
+					Dev:        dev,
+				})
+			}
+		}
+	}
+
+	rootName, _ := rootPackageIdentity(c.Root)
+	add(rootName, c.Root.Require, false)
+	for _, pkg := range c.Packages {
+		add(pkg.Name, pkg.Require, false)
+	}
+	if !c.NoDev {
+		add(rootName, c.Root.RequireDev, true)
+		for _, pkg := range c.PackagesDev {
+			add(pkg.Name, pkg.Require, true)
+		}
+	}
+
+	sort.SliceStable(requirements, func(i, j int) bool {
+		if requirements[i].Name != requirements[j].Name {
+			return requirements[i].Name < requirements[j].Name
+		}
+		return requirements[i].Source < requirements[j].Source
+	})
+	return requirements
+}
+
+// EffectivePlatform returns the Platform with the config.platform overrides of the root
+// package applied.
+func (c PlatformChecker) EffectivePlatform() map[string]string {
+	return ApplyPlatformOverrides(c.Platform, c.Root.Config.Platform)
+}
+
+// platformProvider is a package that provides or replaces a platform package, or the
+// platform package itself.
+type platformProvider struct {
+	name       string
+	constraint Constraint
+	pretty     string
+	provided   bool
+}
+
+// Check checks every platform requirement against the effective platform and the
+// packages that provide or replace platform packages, like `composer
+// check-platform-reqs`. It returns one result per platform package unless a platform
+// package fails for several providers.
+func (c PlatformChecker) Check() ([]PlatformCheckResult, error) {
+	requirements := make(map[string][]PlatformRequirement)
+	for _, requirement := range c.Requirements() {
+		requirements[requirement.Name] = append(requirements[requirement.Name], requirement)
+	}
+
+	providers, err := c.providers()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]PlatformCheckResult, 0, len(requirements))
+	for _, name := range sortedKeys(requirements) {
+		links := requirements[name]
+		candidates := providers[name]
+		if len(candidates) == 0 {
+			results = append(results, PlatformCheckResult{
+				Name:        name,
+				Version:     "n/a",
+				Requirement: links[0],
+				Status:      PlatformCheckMissing,
+			})
+			continue
+		}
+
+		failed := make([]PlatformCheckResult, 0)
+		var success *PlatformCheckResult
+		for _, candidate := range candidates {
+			result := PlatformCheckResult{
+				Name:        name,
+				Version:     candidate.pretty,
+				Requirement: links[0],
+				Status:      PlatformCheckSuccess,
+			}
+			if candidate.provided {
+				result.Provider = candidate.name
+			}
+			for _, link := range links {
+				constraint, err := ParseConstraint(link.Constraint)
+				if err != nil {
+					return nil, fmt.Errorf("%s requires %s: %w", link.Source, link.Name, err)
+				}
+				if !constraint.Intersects(candidate.constraint) {
+					result.Requirement = link
+					result.Status = PlatformCheckFailed
+					break
+				}
+			}
+			if result.Status == PlatformCheckSuccess {
+				success = &result
+				break
+			}
+			failed = append(failed, result)
+		}
+		if success != nil {
+			results = append(results, *success)
+		} else {
+			results = append(results, failed...)
+		}
+	}
+	return results, nil
+}
+
+// providers returns the candidates of each platform package: the installed packages
+// that provide or replace it, followed by the platform package of the effective
+// platform.
+func (c PlatformChecker) providers() (map[string][]platformProvider, error) {
+	providers := make(map[string][]platformProvider)
+	rootName, rootVersion := rootPackageIdentity(c.Root)
+	packages := []LockedPackage{{
+		Name:    rootName,
+		Version: rootVersion,
+		Provide: c.Root.Provide,
+		Replace: c.Root.Replace,
+	}}
+	packages = append(packages, c.Packages...)
+	if !c.NoDev {
+		packages = append(packages, c.PackagesDev...)
+	}
+	for _, pkg := range packages {
+		for _, links := range []map[string]string{pkg.Provide, pkg.Replace} {
+			for _, name := range sortedKeys(links) {
+				if !isPlatformPackage(name) {
+					continue
+				}
+				pretty := links[name]
+				constraint := pretty
+				if constraint == "self.version" {
+					normalized, err := NormalizeVersion(pkg.Version)
+					if err != nil {
+						return nil, fmt.Errorf("package %s: %w", pkg.Name, err)
+					}
+					constraint = normalized
+				}
+				parsed, err := ParseConstraint(constraint)
+				if err != nil {
+					return nil, fmt.Errorf("package %s provides %s: %w", pkg.Name, name, err)
+				}
+				name = strings.ToLower(name)
+				providers[name] = append(providers[name], platformProvider{
+					name:       pkg.Name,
+					constraint: parsed,
+					pretty:     pretty,
+					provided:   true,
+				})
+			}
+		}
+	}
+
+	platform := c.EffectivePlatform()
+	for _, name := range sortedKeys(platform) {
+		normalized, err := NormalizeVersion(platform[name])
+		if err != nil {
+			return nil, fmt.Errorf("platform package %s: %w", name, err)
+		}
+		providers[name] = append(providers[name], platformProvider{
+			name:       name,
+			constraint: &SingleConstraint{Operator: OpEqual, Version: normalized},
+			pretty:     platform[name],
+		})
+	}
+	return providers, nil
+}
+
+// PlatformCheck returns the contents of platform_check.php for a platform-check mode,
+// like Composer's AutoloadGenerator::getPlatformCheck. The mode is one of the values
+// of config.platform-check: true checks the PHP version and the extensions,
+// "php-only" only checks the PHP version and false disables the check. It returns
+// nil if there is nothing to check, in which case Composer removes the file. Like
+// Composer, the requirements of development packages and of require-dev are not
+// checked.
+func (c PlatformChecker) PlatformCheck(mode StringOrBool) ([]byte, error) {
+	if !mode.Bool() {
+		return nil, nil
+	}
+	checkExtensions := mode.IsBool()
+
+	extensionProviders := make(map[string][]Constraint)
+	providers, err := c.providers()
+	if err != nil {
+		return nil, err
+	}
+	for name, candidates := range providers {
+		if !strings.HasPrefix(name, "ext-") {
+			continue
+		}
+		for _, candidate := range candidates {
+			if candidate.provided {
+				extensionProviders[name[len("ext-"):]] = append(extensionProviders[name[len("ext-"):]], candidate.constraint)
+			}
+		}
+	}
+
+	lowestPHPVersion := zeroBound()
+	requiredPHP64bit := false
+	requiredExtensions := make(map[string]string)
+	for _, requirement := range c.Requirements() {
+		if requirement.Dev {
+			continue
+		}
+		constraint, err := ParseConstraint(requirement.Constraint)
+		if err != nil {
+			return nil, fmt.Errorf("%s requires %s: %w", requirement.Source, requirement.Name, err)
+		}
+		if requirement.Name == "php" || requirement.Name == "php-64bit" {
+			if bound := lowerBound(constraint); bound.compareTo(lowestPHPVersion, ">") {
+				lowestPHPVersion = bound
+			}
+		}
+		if requirement.Name == "php-64bit" {
+			requiredPHP64bit = true
+		}
+		if !checkExtensions || !strings.HasPrefix(requirement.Name, "ext-") {
+			continue
+		}
+
+		// Extensions that are provided or replaced by a package are not checked.
+		extension := requirement.Name[len("ext-"):]
+		provided := false
+		for _, provider := range extensionProviders[extension] {
+			if provider.Intersects(constraint) {
+				provided = true
+				break
+			}
+		}
+		if provided {
+			continue
+		}
+
+		if extension == "zend-opcache" {
+			extension = "zend opcache"
+		}
+		code := phpString(extension)
+		if extension == "pcntl" || extension == "readline" {
+			requiredExtensions[code] = fmt.Sprintf("PHP_SAPI !== 'cli' || extension_loaded(%s) || $missingExtensions[] = %s;\n", code, code)
+		} else {
+			requiredExtensions[code] = fmt.Sprintf("extension_loaded(%s) || $missingExtensions[] = %s;\n", code, code)
+		}
+	}
+
+	requiredPHP := ""
+	if !lowestPHPVersion.isZero() {
+		operator := ">"
+		if lowestPHPVersion.inclusive {
+			operator = ">="
+		}
+		requiredPHP = fmt.Sprintf("\nif (!(PHP_VERSION_ID %s %d)) {\n"+
+			"    $issues[] = 'Your Composer dependencies require a PHP version \"%s %s\". You are running ' . PHP_VERSION . '.';\n"+
+			"}\n", operator, lowestPHPVersion.phpVersionID(), operator, lowestPHPVersion.humanReadable())
+	}
+	if requiredPHP64bit {
+		requiredPHP += "\nif (PHP_INT_SIZE !== 8) {\n" +
+			"    $issues[] = 'Your Composer dependencies require a 64-bit build of PHP.';\n" +
+			"}\n"
+	}
+
+	extensionChecks := ""
+	if len(requiredExtensions) > 0 {
+		b := strings.Builder{}
+		b.WriteString("\n$missingExtensions = array();\n")
+		for _, code := range sortedKeys(requiredExtensions) {
+			b.WriteString(requiredExtensions[code])
+		}
+		b.WriteString("\nif ($missingExtensions) {\n" +
+			"    $issues[] = 'Your Composer dependencies require the following PHP extensions to be installed: ' . implode(', ', $missingExtensions) . '.';\n" +
+			"}\n")
+		extensionChecks = b.String()
+	}
+
+	if requiredPHP == "" && extensionChecks == "" {
+		return nil, nil
+	}
+	return []byte("<?php\n\n// platform_check.php @generated by Composer\n\n$issues = array();\n" +
+		requiredPHP + extensionChecks + `
+if ($issues) {
+    if (!headers_sent()) {
+        header('HTTP/1.1 500 Internal Server Error');
+    }
+    if (!ini_get('display_errors')) {
+        if (PHP_SAPI === 'cli' || PHP_SAPI === 'phpdbg') {
+            fwrite(STDERR, 'Composer detected issues in your platform:' . PHP_EOL.PHP_EOL . implode(PHP_EOL, $issues) . PHP_EOL.PHP_EOL);
+        } elseif (!headers_sent()) {
+            echo 'Composer detected issues in your platform:' . PHP_EOL.PHP_EOL . str_replace('You are running '.PHP_VERSION.'.', '', implode(PHP_EOL, $issues)) . PHP_EOL.PHP_EOL;
+        }
+    }
+    trigger_error(
+        'Composer detected issues in your platform: ' . implode(' ', $issues),
+        E_USER_ERROR
+    );
+}
+`), nil
+}
+
+// versionBound is the lowest or highest version a constraint allows, a port of
+// Composer's Bound.
+type versionBound struct {
+	version   string
+	inclusive bool
+}
+
+// zeroBound returns the bound of constraints without a lower bound.
+func zeroBound() versionBound {
+	return versionBound{version: "0.0.0.0-dev", inclusive: true}
+}
+
+func (b versionBound) isZero() bool {
+	return b == zeroBound()
+}
+
+// compareTo returns true if b is higher than other for the ">" operator, or lower
+// for the "<" operator.
+func (b versionBound) compareTo(other versionBound, operator string) bool {
+	if b == other {
+		return false
+	}
+	if c := phpVersionCompare(b.version, other.version); c != 0 {
+		return (operator == ">") == (c > 0)
+	}
+	if operator == ">" {
+		return other.inclusive
+	}
+	return !other.inclusive
+}
+
+// phpVersionID returns the bound like PHP's PHP_VERSION_ID constant, e.g. 80100 for
+// 8.1.0.
+func (b versionBound) phpVersionID() int {
+	chunks := strings.Split(strings.ReplaceAll(b.version, "-", "."), ".")
+	id := 0
+	for i, factor := range []int{10000, 100, 1} {
+		if i < len(chunks) {
+			n, _ := strconv.Atoi(chunks[i])
+			id += n * factor
+		}
+	}
+	return id
+}
+
+// humanReadable returns the first three parts of the version of the bound.
+func (b versionBound) humanReadable() string {
+	chunks := strings.Split(strings.ReplaceAll(b.version, "-", "."), ".")
+	if len(chunks) > 3 {
+		chunks = chunks[:3]
+	}
+	return strings.Join(chunks, ".")
+}
+
+// lowerBound returns the lowest version a constraint allows, like Composer's
+// ConstraintInterface::getLowerBound.
+func lowerBound(constraint Constraint) versionBound {
+	switch constraint := constraint.(type) {
+	case *SingleConstraint:
+		if strings.HasPrefix(constraint.Version, "dev-") {
+			return zeroBound()
+		}
+		switch constraint.Operator {
+		case OpEqual, OpGreaterEqual:
+			return versionBound{version: constraint.Version, inclusive: true}
+		case OpGreater:
+			return versionBound{version: constraint.Version, inclusive: false}
+		}
+	case *MultiConstraint:
+		operator := "<"
+		if constraint.Conjunctive {
+			operator = ">"
+		}
+		var bound *versionBound
+		for _, c := range constraint.Constraints {
+			cBound := lowerBound(c)
+			if bound == nil || cBound.compareTo(*bound, operator) {
+				bound = &cBound
+			}
+		}
+		if bound != nil {
+			return *bound
+		}
+	case MatchNoneConstraint, *MatchNoneConstraint:
+		return versionBound{version: "0.0.0.0-dev", inclusive: false}
+	}
+	return zeroBound()
+}
@@ -0,0 +1,255 @@
+package gocomposer
+
+import (
+	is2 "github.com/matryer/is"
+	"os"
+	"strings"
+	"testing"
+)
+
+// newPlatformChecker returns a checker for an application whose dependencies require
+// several platform packages.
+func newPlatformChecker() PlatformChecker {
+	return PlatformChecker{
+		Root: ComposerJSON{
+			Name: "acme/app",
+			Require: map[string]string{
+				"php":       "^8.1",
+				"ext-json":  "*",
+				"acme/http": "^1.0",
+			},
+			RequireDev: map[string]string{
+				"ext-xdebug": "^3.0",
+			},
+			Config: Config{
+				Platform: map[string]StringOrBool{
+					"php":       StringOrBoolFromString("8.0.30"),
+					"ext-pcntl": StringOrBoolFromBool(false),
+				},
+			},
+		},
+		Packages: []LockedPackage{
+			{
+				Name:    "acme/http",
+				Version: "1.0.0",
+				Require: map[string]string{
+					"php":              ">=7.4",
+					"ext-mbstring":     "*",
+					"ext-pcntl":        "*",
+					"ext-zend-opcache": "*",
+				},
+			},
+			{
+				Name:    "acme/meta",
+				Version: "1.0.0",
+				Type:    "metapackage",
+				Require: map[string]string{"php-64bit": ">=8.0"},
+			},
+			{
+				Name:    "symfony/polyfill-mbstring",
+				Version: "1.28.0",
+				Provide: map[string]string{"ext-mbstring": "*"},
+			},
+		},
+		PackagesDev: []LockedPackage{
+			{
+				Name:    "acme/testing",
+				Version: "1.0.0",
+				Require: map[string]string{"ext-sodium": "*", "php": ">=8.2"},
+			},
+		},
+		Platform: map[string]string{
+			"php":        "8.2.4",
+			"ext-json":   "8.2.4",
+			"ext-pcntl":  "8.2.4",
+			"ext-xdebug": "3.2.0",
+		},
+	}
+}
+
+func TestSplitPlatformRequirements(t *testing.T) {
+	is := is2.New(t)
+
+	platform, packages := SplitPlatformRequirements(map[string]string{
+		"php":                 "^8.1",
+		"ext-json":            "*",
+		"lib-icu":             ">=70",
+		"composer-plugin-api": "^2.0",
+		"acme/http":           "^1.0",
+		"phpunit/phpunit":     "^10.0",
+	})
+	is.Equal(platform, map[string]string{
+		"php":                 "^8.1",
+		"ext-json":            "*",
+		"lib-icu":             ">=70",
+		"composer-plugin-api": "^2.0",
+	})
+	is.Equal(packages, map[string]string{
+		"acme/http":       "^1.0",
+		"phpunit/phpunit": "^10.0",
+	})
+}
+
+func TestApplyPlatformOverrides(t *testing.T) {
+	is := is2.New(t)
+
+	platform := map[string]string{"php": "8.2.4", "ext-json": "8.2.4", "ext-pcntl": "8.2.4"}
+	applied := ApplyPlatformOverrides(platform, map[string]StringOrBool{
+		"PHP":       StringOrBoolFromString("8.1.0"),
+		"ext-intl":  StringOrBoolFromString("8.1.0"),
+		"ext-pcntl": StringOrBoolFromBool(false),
+	})
+	is.Equal(applied, map[string]string{"php": "8.1.0", "ext-json": "8.2.4", "ext-intl": "8.1.0"})
+	is.Equal(platform, map[string]string{"php": "8.2.4", "ext-json": "8.2.4", "ext-pcntl": "8.2.4"})
+}
+
+func TestPlatformChecker_Requirements(t *testing.T) {
+	is := is2.New(t)
+
+	checker := newPlatformChecker()
+	is.Equal(checker.Requirements(), []PlatformRequirement{
+		{Name: "ext-json", Constraint: "*", Source: "acme/app"},
+		{Name: "ext-mbstring", Constraint: "*", Source: "acme/http"},
+		{Name: "ext-pcntl", Constraint: "*", Source: "acme/http"},
+		{Name: "ext-sodium", Constraint: "*", Source: "acme/testing", Dev: true},
+		{Name: "ext-xdebug", Constraint: "^3.0", Source: "acme/app", Dev: true},
+		{Name: "ext-zend-opcache", Constraint: "*", Source: "acme/http"},
+		{Name: "php", Constraint: "^8.1", Source: "acme/app"},
+		{Name: "php", Constraint: ">=7.4", Source: "acme/http"},
+		{Name: "php", Constraint: ">=8.2", Source: "acme/testing", Dev: true},
+		{Name: "php-64bit", Constraint: ">=8.0", Source: "acme/meta"},
+	})
+
+	checker.NoDev = true
+	is.Equal(len(checker.Requirements()), 7)
+}
+
+func TestPlatformChecker_Check(t *testing.T) {
+	is := is2.New(t)
+
+	checker := newPlatformChecker()
+	is.Equal(checker.EffectivePlatform(), map[string]string{"php": "8.0.30", "ext-json": "8.2.4", "ext-xdebug": "3.2.0"})
+
+	results, err := checker.Check()
+	is.NoErr(err)
+	is.Equal(results, []PlatformCheckResult{
+		{
+			Name:        "ext-json",
+			Version:     "8.2.4",
+			Requirement: PlatformRequirement{Name: "ext-json", Constraint: "*", Source: "acme/app"},
+			Status:      PlatformCheckSuccess,
+		},
+		{
+			Name:        "ext-mbstring",
+			Version:     "*",
+			Requirement: PlatformRequirement{Name: "ext-mbstring", Constraint: "*", Source: "acme/http"},
+			Status:      PlatformCheckSuccess,
+			Provider:    "symfony/polyfill-mbstring",
+		},
+		{
+			Name:        "ext-pcntl",
+			Version:     "n/a",
+			Requirement: PlatformRequirement{Name: "ext-pcntl", Constraint: "*", Source: "acme/http"},
+			Status:      PlatformCheckMissing,
+		},
+		{
+			Name:        "ext-sodium",
+			Version:     "n/a",
+			Requirement: PlatformRequirement{Name: "ext-sodium", Constraint: "*", Source: "acme/testing", Dev: true},
+			Status:      PlatformCheckMissing,
+		},
+		{
+			Name:        "ext-xdebug",
+			Version:     "3.2.0",
+			Requirement: PlatformRequirement{Name: "ext-xdebug", Constraint: "^3.0", Source: "acme/app", Dev: true},
+			Status:      PlatformCheckSuccess,
+		},
+		{
+			Name:        "ext-zend-opcache",
+			Version:     "n/a",
+			Requirement: PlatformRequirement{Name: "ext-zend-opcache", Constraint: "*", Source: "acme/http"},
+			Status:      PlatformCheckMissing,
+		},
+		{
+			Name:        "php",
+			Version:     "8.0.30",
+			Requirement: PlatformRequirement{Name: "php", Constraint: "^8.1", Source: "acme/app"},
+			Status:      PlatformCheckFailed,
+		},
+		{
+			Name:        "php-64bit",
+			Version:     "n/a",
+			Requirement: PlatformRequirement{Name: "php-64bit", Constraint: ">=8.0", Source: "acme/meta"},
+			Status:      PlatformCheckMissing,
+		},
+	})
+
+	// Without the overrides, the PHP version of the machine is checked.
+	checker.Root.Config.Platform = nil
+	checker.NoDev = true
+	results, err = checker.Check()
+	is.NoErr(err)
+	is.Equal(len(results), 6)
+	is.Equal(results[2].Status, PlatformCheckSuccess)
+	is.Equal(results[4].Name, "php")
+	is.Equal(results[4].Status, PlatformCheckSuccess)
+}
+
+func TestPlatformChecker_PlatformCheck(t *testing.T) {
+	is := is2.New(t)
+
+	checker := newPlatformChecker()
+	content, err := checker.PlatformCheck(StringOrBoolFromBool(true))
+	is.NoErr(err)
+	expected, err := os.ReadFile("testdata/platform/platform_check.php")
+	is.NoErr(err)
+	is.Equal(string(content), string(expected))
+
+	// php-only does not check the extensions.
+	content, err = checker.PlatformCheck(StringOrBoolFromString("php-only"))
+	is.NoErr(err)
+	is.True(strings.Contains(string(content), "if (!(PHP_VERSION_ID >= 80100)) {"))
+	is.True(strings.Contains(string(content), "if (PHP_INT_SIZE !== 8) {"))
+	is.True(!strings.Contains(string(content), "$missingExtensions"))
+
+	// The lowest PHP version can be exclusive.
+	checker.Root.Require["php"] = ">8.1.2"
+	content, err = checker.PlatformCheck(StringOrBoolFromString("php-only"))
+	is.NoErr(err)
+	is.True(strings.Contains(string(content), `if (!(PHP_VERSION_ID > 80102)) {
+    $issues[] = 'Your Composer dependencies require a PHP version "> 8.1.2". You are running ' . PHP_VERSION . '.';
+}`))
+
+	content, err = checker.PlatformCheck(StringOrBoolFromBool(false))
+	is.NoErr(err)
+	is.Equal(content, nil)
+
+	// Nothing is checked if no PHP version or extension is required.
+	checker = PlatformChecker{Root: ComposerJSON{Require: map[string]string{"acme/http": "^1.0"}}}
+	content, err = checker.PlatformCheck(StringOrBoolFromBool(true))
+	is.NoErr(err)
+	is.Equal(content, nil)
+}
+
+func Test_lowerBound(t *testing.T) {
+	tests := []struct {
+		constraint string
+		expected   versionBound
+	}{
+		{"*", zeroBound()},
+		{"<8.0", zeroBound()},
+		{"dev-main", zeroBound()},
+		{"8.1.2", versionBound{"8.1.2.0", true}},
+		{">8.1", versionBound{"8.1.0.0", false}},
+		{"^7.4 || ^8.0", versionBound{"7.4.0.0-dev", true}},
+		{">=7.4 >7.4", versionBound{"7.4.0.0", false}},
+		{">=7.4 <8.0 || >=8.1", versionBound{"7.4.0.0-dev", true}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.constraint, func(t *testing.T) {
+			is := is2.New(t)
+			is.Equal(lowerBound(MustParseConstraint(test.constraint)), test.expected)
+		})
+	}
+}
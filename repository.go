@@ -0,0 +1,371 @@
+package gocomposer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// RepositoryMetadata is the packages.json file at the root of a Composer repository.
+type RepositoryMetadata struct {
+	// URL template of the Composer 2 package metadata, e.g. "/p2/%package%.json".
+	MetadataURL string `json:"metadata-url,omitempty"`
+
+	// URL template of the Composer 1 metadata of a single package, e.g.
+	// "/p/%package%.json".
+	ProvidersLazyURL string `json:"providers-lazy-url,omitempty"`
+
+	// If set, the repository only has the packages listed here or matching one of
+	// AvailablePackagePatterns.
+	AvailablePackages []string `json:"available-packages,omitempty"`
+
+	// Package name patterns where "*" matches any sequence of characters.
+	AvailablePackagePatterns []string `json:"available-package-patterns,omitempty"`
+
+	// Packages defined in packages.json itself, by package name.
+	Packages map[string][]InlinePackage `json:"-"`
+
+	// URL used to search the repository, with "%query%" and "%type%" placeholders.
+	Search string `json:"search,omitempty"`
+
+	// URL used to list the package names of the repository.
+	List string `json:"list,omitempty"`
+
+	// URL Composer notifies after packages were installed.
+	NotifyBatch string `json:"notify-batch,omitempty"`
+
+	// Where the security advisories of the repository can be found.
+	SecurityAdvisories *SecurityAdvisoriesMetadata `json:"security-advisories,omitempty"`
+
+	// Keys that are not modeled by this struct, e.g. the Composer 1 "providers-url" and
+	// "provider-includes", see decodeUnknown.
+	Unknown map[string]json.RawMessage `json:"-"`
+}
+
+// SecurityAdvisoriesMetadata describes the security advisory API of a repository.
+type SecurityAdvisoriesMetadata struct {
+	// If true, the package metadata includes the advisories of each package.
+	Metadata bool `json:"metadata,omitempty"`
+
+	// URL of the security advisories API.
+	APIURL string `json:"api-url,omitempty"`
+}
+
+func (m *RepositoryMetadata) UnmarshalJSON(data []byte) error {
+	type plain RepositoryMetadata
+	if err := json.Unmarshal(data, (*plain)(m)); err != nil {
+		return err
+	}
+
+	temp := struct {
+		Packages json.RawMessage `json:"packages"`
+	}{}
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return err
+	}
+	packages, err := decodeVersionMap(temp.Packages)
+	if err != nil {
+		return err
+	}
+	m.Packages = packages
+
+	unknown, err := decodeUnknown(data, m)
+	if err != nil {
+		return err
+	}
+	delete(unknown, "packages")
+	if len(unknown) == 0 {
+		unknown = nil
+	}
+	m.Unknown = unknown
+	return nil
+}
+
+func (m RepositoryMetadata) MarshalJSON() ([]byte, error) {
+	type plain RepositoryMetadata
+	data, err := json.Marshal(plain(m))
+	if err != nil {
+		return nil, err
+	}
+
+	unknown := make(map[string]json.RawMessage, len(m.Unknown)+1)
+	for key, value := range m.Unknown {
+		unknown[key] = value
+	}
+	if m.Packages != nil {
+		packages := make(map[string]map[string]InlinePackage, len(m.Packages))
+		for name, versions := range m.Packages {
+			packages[name] = make(map[string]InlinePackage, len(versions))
+			for _, version := range versions {
+				packages[name][version.Version] = version
+			}
+		}
+		if unknown["packages"], err = json.Marshal(packages); err != nil {
+			return nil, err
+		}
+	}
+	return encodeUnknown(data, unknown, m)
+}
+
+// decodeVersionMap decodes the Composer 1 "packages" object of package names and their
+// versions. PHP encodes an empty object as [], so that is accepted too.
+func decodeVersionMap(data json.RawMessage) (map[string][]InlinePackage, error) {
+	if len(data) == 0 || isArray(data) || string(data) == "null" {
+		return nil, nil
+	}
+	raw := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	packages := make(map[string][]InlinePackage, len(raw))
+	for name, versions := range raw {
+		decoded, err := decodeVersions(versions)
+		if err != nil {
+			return nil, fmt.Errorf("versions of %s: %w", name, err)
+		}
+		packages[name] = decoded
+	}
+	return packages, nil
+}
+
+// decodeVersions decodes an object of versions (keys) and packages (values), sorted
+// by version key.
+func decodeVersions(data json.RawMessage) ([]InlinePackage, error) {
+	if isArray(data) {
+		return []InlinePackage{}, nil
+	}
+	versions := make(map[string]InlinePackage)
+	if err := json.Unmarshal(data, &versions); err != nil {
+		return nil, err
+	}
+	packages := make([]InlinePackage, 0, len(versions))
+	for _, version := range sortedKeys(versions) {
+		pkg := versions[version]
+		if pkg.Version == "" {
+			pkg.Version = version
+		}
+		packages = append(packages, pkg)
+	}
+	return packages, nil
+}
+
+// RepositoryClient reads packages from a Composer repository, such as Packagist or a
+// Private Packagist or Satis instance.
+type RepositoryClient struct {
+	// URL of the repository, e.g. "https://repo.packagist.org". It can also be the URL
+	// of the packages.json file.
+	URL string
+
+	// Client used for requests. http.DefaultClient is used if it is nil.
+	HTTPClient *http.Client
+
+	mu       sync.Mutex
+	metadata *RepositoryMetadata
+}
+
+// NewRepositoryClient creates a RepositoryClient for a composer repository of
+// composer.json.
+func NewRepositoryClient(repo ComposerRepository) *RepositoryClient {
+	return &RepositoryClient{URL: repo.URL}
+}
+
+// metadataURL returns the URL of packages.json.
+func (c *RepositoryClient) metadataURL() string {
+	if strings.HasSuffix(c.URL, ".json") {
+		return c.URL
+	}
+	return strings.TrimRight(c.URL, "/") + "/packages.json"
+}
+
+// Metadata returns the packages.json file of the repository. It is only downloaded
+// once.
+func (c *RepositoryClient) Metadata(ctx context.Context) (*RepositoryMetadata, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.metadata != nil {
+		return c.metadata, nil
+	}
+
+	data, err := c.get(ctx, c.metadataURL())
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("%s was not found", c.metadataURL())
+	}
+	metadata := &RepositoryMetadata{}
+	if err := json.Unmarshal(data, metadata); err != nil {
+		return nil, fmt.Errorf("%s: %w", c.metadataURL(), err)
+	}
+	c.metadata = metadata
+	return metadata, nil
+}
+
+// HasPackage returns false if the available-packages or available-package-patterns of
+// the repository show that it does not have the named package. If the repository
+// lists neither, it may have any package.
+func (m RepositoryMetadata) HasPackage(name string) bool {
+	if m.AvailablePackages == nil && m.AvailablePackagePatterns == nil {
+		return true
+	}
+	for _, available := range m.AvailablePackages {
+		if strings.EqualFold(available, name) {
+			return true
+		}
+	}
+	for _, pattern := range m.AvailablePackagePatterns {
+		if matchPackagePattern(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetPackageVersions returns every version of the named package in the repository.
+// The Composer 2 metadata is used if the repository has it, including the dev
+// versions, otherwise the Composer 1 metadata. No versions are returned if the
+// repository does not have the package.
+func (c *RepositoryClient) GetPackageVersions(ctx context.Context, name string) ([]InlinePackage, error) {
+	metadata, err := c.Metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.ToLower(name)
+	if !metadata.HasPackage(name) {
+		return nil, nil
+	}
+
+	versions := make([]InlinePackage, 0)
+	for inlineName, inline := range metadata.Packages {
+		if strings.ToLower(inlineName) == name {
+			versions = append(versions, inline...)
+		}
+	}
+
+	switch {
+	case metadata.MetadataURL != "":
+		for _, file := range []string{name, name + "~dev"} {
+			packages, err := c.getPackages(ctx, metadata.MetadataURL, file)
+			if err != nil {
+				return nil, err
+			}
+			versions = append(versions, packages[name]...)
+		}
+	case metadata.ProvidersLazyURL != "":
+		packages, err := c.getPackages(ctx, metadata.ProvidersLazyURL, name)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, packages[name]...)
+	}
+	return versions, nil
+}
+
+// LoadPackages returns every version of the named packages and of all packages they
+// require, which can be used as the packages of a Resolver. Platform packages are
+// skipped.
+func (c *RepositoryClient) LoadPackages(ctx context.Context, names ...string) ([]InlinePackage, error) {
+	packages := make([]InlinePackage, 0)
+	loaded := make(map[string]bool)
+	queue := append([]string{}, names...)
+	for len(queue) > 0 {
+		name := strings.ToLower(queue[0])
+		queue = queue[1:]
+		if loaded[name] || isPlatformPackage(name) {
+			continue
+		}
+		loaded[name] = true
+
+		versions, err := c.GetPackageVersions(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, version := range versions {
+			queue = append(queue, sortedKeys(version.Require)...)
+		}
+		packages = append(packages, versions...)
+	}
+	return packages, nil
+}
+
+// getPackages downloads the metadata of a package from a URL template with a
+// "%package%" placeholder and returns the versions by package name. Nothing is
+// returned if the file does not exist.
+func (c *RepositoryClient) getPackages(ctx context.Context, template string, name string) (map[string][]InlinePackage, error) {
+	fileURL, err := c.resolve(strings.ReplaceAll(template, "%package%", name))
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.get(ctx, fileURL)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	packages, err := DecodePackageMetadata(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fileURL, err)
+	}
+	return packages, nil
+}
+
+// resolve turns a URL from packages.json, which may be relative, into an absolute URL.
+func (c *RepositoryClient) resolve(ref string) (string, error) {
+	base, err := url.Parse(c.metadataURL())
+	if err != nil {
+		return "", err
+	}
+	target, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(target).String(), nil
+}
+
+// get downloads a file from the repository. It returns nil data if the file does not
+// exist.
+func (c *RepositoryClient) get(ctx context.Context, fileURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(req)
+}
+
+// post sends a form to the repository and returns the response. It returns nil data if
+// the URL does not exist.
+func (c *RepositoryClient) post(ctx context.Context, apiURL string, form url.Values) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return c.do(req)
+}
+
+// do sends a request and returns the JSON response. It returns nil data if the server
+// responds with 404 Not Found.
+func (c *RepositoryClient) do(req *http.Request) ([]byte, error) {
+	req.Header.Set("Accept", "application/json")
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("%s %s: %s", req.Method, req.URL, resp.Status)
+}
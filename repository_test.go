@@ -0,0 +1,176 @@
+package gocomposer
+
+import (
+	"context"
+	"encoding/json"
+	is2 "github.com/matryer/is"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+)
+
+// fakeRepository serves the files of a directory in testdata like a Composer
+// repository and records the paths that were requested.
+type fakeRepository struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []string
+}
+
+func newFakeRepository(t *testing.T, dir string) *fakeRepository {
+	repo := &fakeRepository{}
+	files := http.FileServer(http.Dir(dir))
+	repo.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		repo.mu.Lock()
+		repo.requests = append(repo.requests, r.URL.Path)
+		repo.mu.Unlock()
+		files.ServeHTTP(w, r)
+	}))
+	t.Cleanup(repo.Close)
+	return repo
+}
+
+func TestRepositoryClient_GetPackageVersions(t *testing.T) {
+	is := is2.New(t)
+	repo := newFakeRepository(t, "testdata/repository")
+	client := NewRepositoryClient(ComposerRepository{Type: TypeComposer, URL: repo.URL})
+
+	versions, err := client.GetPackageVersions(context.Background(), "Acme/Log")
+	is.NoErr(err)
+	is.Equal(len(versions), 4)
+	is.Equal(repo.requests, []string{"/packages.json", "/p2/acme/log.json", "/p2/acme/log~dev.json"})
+
+	// Every version inherits the keys of the version before it.
+	is.Equal(versions[0].Version, "2.0.0")
+	is.Equal(versions[0].License, StringOrSlice{"MIT"})
+	is.Equal(versions[0].Provide, map[string]string{"psr/log-implementation": "3.0"})
+	is.Equal(versions[0].Unknown, map[string]json.RawMessage{"version_normalized": json.RawMessage(`"2.0.0.0"`)})
+
+	is.Equal(versions[1].Name, "acme/log")
+	is.Equal(versions[1].Version, "1.1.0")
+	is.Equal(versions[1].Description, "Logging for Acme applications")
+	is.Equal(versions[1].Require, map[string]string{"php": ">=7.4"})
+	is.Equal(versions[1].Provide, nil)
+	is.Equal(versions[1].Dist.Reference, "a1c2d3f4")
+
+	is.Equal(versions[2].Version, "1.0.0")
+	is.Equal(versions[2].License, nil)
+	is.Equal(versions[2].Require, map[string]string{"php": ">=7.4"})
+
+	is.Equal(versions[3].Version, "dev-main")
+	is.Equal(versions[3].Source.Reference, "c0ffee12")
+	is.True(versions[3].DefaultBranch)
+	is.Equal(versions[3].Dist, nil)
+}
+
+func TestRepositoryClient_GetPackageVersions_Unavailable(t *testing.T) {
+	is := is2.New(t)
+	repo := newFakeRepository(t, "testdata/repository")
+	client := &RepositoryClient{URL: repo.URL + "/packages.json"}
+
+	// acme/missing matches the available package patterns but does not exist.
+	versions, err := client.GetPackageVersions(context.Background(), "acme/missing")
+	is.NoErr(err)
+	is.Equal(len(versions), 0)
+
+	// other/package does not match the patterns so it is not requested.
+	versions, err = client.GetPackageVersions(context.Background(), "other/package")
+	is.NoErr(err)
+	is.Equal(len(versions), 0)
+	is.Equal(repo.requests, []string{"/packages.json", "/p2/acme/missing.json", "/p2/acme/missing~dev.json"})
+}
+
+func TestRepositoryClient_GetPackageVersions_ProvidersLazyURL(t *testing.T) {
+	is := is2.New(t)
+	repo := newFakeRepository(t, "testdata/repository-lazy")
+	client := &RepositoryClient{URL: repo.URL + "/"}
+
+	versions, err := client.GetPackageVersions(context.Background(), "acme/log")
+	is.NoErr(err)
+	is.Equal(resolvedVersions(versions), []string{"acme/log 1.0.0", "acme/log dev-main"})
+
+	versions, err = client.GetPackageVersions(context.Background(), "acme/inline")
+	is.NoErr(err)
+	is.Equal(resolvedVersions(versions), []string{"acme/inline 1.0.0"})
+	is.Equal(versions[0].Dist.URL, "https://repo.acme.test/dist/acme/inline/1.0.0.zip")
+
+	versions, err = client.GetPackageVersions(context.Background(), "acme/http")
+	is.NoErr(err)
+	is.Equal(len(versions), 0)
+	is.Equal(repo.requests, []string{"/packages.json", "/p/acme/log.json", "/p/acme/inline.json"})
+}
+
+func TestRepositoryClient_LoadPackages(t *testing.T) {
+	is := is2.New(t)
+	repo := newFakeRepository(t, "testdata/repository")
+	client := &RepositoryClient{URL: repo.URL}
+
+	packages, err := client.LoadPackages(context.Background(), "acme/http")
+	is.NoErr(err)
+	is.Equal(resolvedVersions(packages), []string{
+		"acme/http 1.0.0",
+		"acme/log 2.0.0",
+		"acme/log 1.1.0",
+		"acme/log 1.0.0",
+		"acme/log dev-main",
+	})
+
+	resolution, err := Resolver{
+		Root:     ComposerJSON{Require: map[string]string{"acme/http": "^1.0"}},
+		Packages: packages,
+		Platform: map[string]string{"php": "8.2.0"},
+	}.Resolve()
+	is.NoErr(err)
+	is.Equal(resolvedVersions(resolution.Packages), []string{"acme/http 1.0.0", "acme/log 2.0.0"})
+}
+
+func TestRepositoryClient_Metadata(t *testing.T) {
+	is := is2.New(t)
+	repo := newFakeRepository(t, "testdata/repository")
+	client := &RepositoryClient{URL: repo.URL}
+
+	metadata, err := client.Metadata(context.Background())
+	is.NoErr(err)
+	is.Equal(metadata.MetadataURL, "/p2/%package%.json")
+	is.Equal(metadata.AvailablePackagePatterns, []string{"acme/*"})
+	is.Equal(metadata.SecurityAdvisories.APIURL, "https://repo.acme.test/api/security-advisories/")
+	is.Equal(len(metadata.Packages), 0)
+	is.Equal(metadata.Unknown, map[string]json.RawMessage{"providers-url": json.RawMessage(`"/p/%package%$%hash%.json"`)})
+
+	_, err = client.Metadata(context.Background())
+	is.NoErr(err)
+	is.Equal(repo.requests, []string{"/packages.json"})
+}
+
+func TestRepositoryClient_Metadata_Errors(t *testing.T) {
+	is := is2.New(t)
+	repo := newFakeRepository(t, "testdata/repository")
+
+	_, err := (&RepositoryClient{URL: repo.URL + "/p2"}).Metadata(context.Background())
+	is.Equal(err.Error(), repo.URL+"/p2/packages.json was not found")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	_, err = (&RepositoryClient{URL: server.URL}).Metadata(context.Background())
+	is.Equal(err.Error(), "GET "+server.URL+"/packages.json: 500 Internal Server Error")
+}
+
+func TestRepositoryMetadata_JSON(t *testing.T) {
+	is := is2.New(t)
+
+	data, err := os.ReadFile("testdata/repository-lazy/packages.json")
+	is.NoErr(err)
+	metadata := RepositoryMetadata{}
+	is.NoErr(json.Unmarshal(data, &metadata))
+	is.Equal(resolvedVersions(metadata.Packages["acme/inline"]), []string{"acme/inline 1.0.0"})
+	is.True(metadata.HasPackage("Acme/Log"))
+	is.True(!metadata.HasPackage("acme/http"))
+
+	encoded, err := json.Marshal(metadata)
+	is.NoErr(err)
+	is.Equal(string(encoded), `{"providers-lazy-url":"p/%package%.json","available-packages":["acme/inline","acme/log"],"packages":{"acme/inline":{"1.0.0":{"name":"acme/inline","version":"1.0.0","dist":{"url":"https://repo.acme.test/dist/acme/inline/1.0.0.zip","type":"zip"}}}}}`)
+}
@@ -0,0 +1,734 @@
+package gocomposer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Resolver predicts which package versions `composer update` installs for a root
+// package. It only works on the packages it is given, nothing is downloaded.
+type Resolver struct {
+	// The root package. Its require, require-dev, conflict, replace and provide links
+	// are used along with minimum-stability and prefer-stable.
+	Root ComposerJSON
+
+	// Every version of every package that can be installed.
+	Packages []InlinePackage
+
+	// Platform packages (keys) and their versions (values), e.g. "php": "8.2.4" or
+	// "ext-json": "8.2.4". A requirement on a platform package that is not listed
+	// cannot be satisfied.
+	Platform map[string]string
+
+	// If true, requirements on platform packages are ignored, like
+	// `composer update --ignore-platform-reqs`.
+	IgnorePlatformRequirements bool
+
+	// If true, the require-dev section of the root package is ignored.
+	NoDev bool
+}
+
+// Resolution is the set of package versions chosen by a Resolver.
+type Resolution struct {
+	// Packages needed by the require section of the root package, sorted by name.
+	Packages []InlinePackage
+
+	// Packages only needed by the require-dev section of the root package, sorted by
+	// name.
+	PackagesDev []InlinePackage
+}
+
+// ResolveError is returned by Resolver.Resolve if the requirements cannot be
+// satisfied. Each problem is a list of the rules that cannot all be met at once.
+type ResolveError struct {
+	Problems [][]string
+}
+
+func (e *ResolveError) Error() string {
+	buf := strings.Builder{}
+	buf.WriteString("Your requirements could not be resolved to an installable set of packages.\n")
+	for i, problem := range e.Problems {
+		buf.WriteString(fmt.Sprintf("\n  Problem %d", i+1))
+		for _, message := range problem {
+			buf.WriteString("\n    - " + message)
+		}
+	}
+	return buf.String()
+}
+
+// Resolve chooses a version for every package needed by the root package. Like
+// Composer, it prefers the highest version of each package and, if prefer-stable is
+// set, the most stable one. Packages less stable than minimum-stability, or than the
+// stability flag of a root requirement such as "^2.1@beta", are never chosen.
+// Versions with a branch alias, and the versions of root inline aliases such as
+// "dev-main as 1.0.x-dev", also satisfy constraints on their alias version. A
+// *ResolveError is returned if the requirements cannot be satisfied.
+func (r Resolver) Resolve() (Resolution, error) {
+	p, err := r.newPool()
+	if err != nil {
+		return Resolution{}, err
+	}
+	g := ruleGenerator{resolver: r, pool: p, added: make(map[*poolPackage]bool)}
+	g.generate()
+
+	problems := g.problems
+	for {
+		s := newSolver(p, g.rules, r.Root.PreferStable)
+		conflict := s.solve()
+		if conflict == nil {
+			if len(problems) > 0 {
+				break
+			}
+			return r.resolution(p, s), nil
+		}
+
+		rules := s.explain(conflict)
+		problems = append(problems, p.messages(rules))
+
+		// Like Composer, disable a root requirement of the problem and solve again to
+		// find any other problems.
+		disabled := false
+		for _, rule := range rules {
+			if rule.kind == ruleRootRequire {
+				rule.disabled = true
+				disabled = true
+				break
+			}
+		}
+		if !disabled {
+			break
+		}
+	}
+	return Resolution{}, &ResolveError{Problems: problems}
+}
+
+// resolution collects the packages installed by a solver and splits them into the
+// ones needed by require and the ones only needed by require-dev.
+func (r Resolver) resolution(p *pool, s *solver) Resolution {
+	installed := make(map[string][]*poolPackage)
+	for _, pkg := range p.packages {
+		if s.value[pkg.id] != 1 || pkg.fixed {
+			continue
+		}
+		for _, name := range pkg.names() {
+			installed[name] = append(installed[name], pkg)
+		}
+	}
+
+	required := make(map[*poolPackage]bool)
+	queue := make([]string, 0)
+	for _, name := range sortedKeys(r.Root.Require) {
+		queue = append(queue, strings.ToLower(name))
+	}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, pkg := range installed[name] {
+			if required[pkg] {
+				continue
+			}
+			required[pkg] = true
+			for _, link := range pkg.requires {
+				queue = append(queue, link.target)
+			}
+		}
+	}
+
+	result := Resolution{}
+	for _, pkg := range p.packages {
+		if s.value[pkg.id] != 1 || pkg.fixed || pkg.aliasOf != nil {
+			continue
+		}
+		if required[pkg] {
+			result.Packages = append(result.Packages, pkg.pkg)
+		} else {
+			result.PackagesDev = append(result.PackagesDev, pkg.pkg)
+		}
+	}
+	sortInlinePackages(result.Packages)
+	sortInlinePackages(result.PackagesDev)
+	return result
+}
+
+func sortInlinePackages(packages []InlinePackage) {
+	sort.SliceStable(packages, func(i, j int) bool {
+		return strings.ToLower(packages[i].Name) < strings.ToLower(packages[j].Name)
+	})
+}
+
+// poolPackage is a package version that can take part in a resolution. Its id is the
+// variable of the package in the rules given to the solver.
+type poolPackage struct {
+	id      int
+	pkg     InlinePackage
+	name    string
+	version Version
+
+	requires  []poolLink
+	conflicts []poolLink
+	replaces  []poolLink
+	provides  []poolLink
+
+	// stability is the rank of the stability of the version in stabilityRanks.
+	stability int
+
+	// order is the position of the version when every version in the pool is sorted
+	// in ascending order, which is faster to compare than the version itself.
+	order int
+
+	// fixed packages are always installed, these are the root package and the platform
+	// packages.
+	fixed    bool
+	root     bool
+	platform bool
+
+	// aliasOf is the package an alias package is the alias of. An alias package has the
+	// name and links of the aliased package with the alias version, and can only be
+	// installed along with the aliased package.
+	aliasOf *poolPackage
+}
+
+// poolLink is a require, conflict, replace or provide link of a poolPackage.
+type poolLink struct {
+	target           string
+	prettyTarget     string
+	constraint       Constraint
+	prettyConstraint string
+}
+
+// names returns the name of the package and the names it replaces or provides.
+func (p *poolPackage) names() []string {
+	names := []string{p.name}
+	for _, link := range p.replaces {
+		names = append(names, link.target)
+	}
+	for _, link := range p.provides {
+		names = append(names, link.target)
+	}
+	return names
+}
+
+func (p *poolPackage) String() string {
+	if p.root {
+		return "Root composer.json"
+	}
+	return p.pkg.Name + " " + p.version.Pretty
+}
+
+// pool holds every package known to a Resolver.
+type pool struct {
+	packages []*poolPackage
+
+	// providers maps a package name to the packages with that name or which replace or
+	// provide it.
+	providers map[string][]*poolPackage
+
+	// unacceptable maps a package name to the versions that were left out because they
+	// are less stable than the StabilityPolicy of the root package allows.
+	unacceptable map[string][]*poolPackage
+
+	platform map[string]*poolPackage
+}
+
+func (r Resolver) newPool() (*pool, error) {
+	p := &pool{
+		providers:    make(map[string][]*poolPackage),
+		unacceptable: make(map[string][]*poolPackage),
+		platform:     make(map[string]*poolPackage),
+	}
+
+	policy, err := NewStabilityPolicy(r.Root)
+	if err != nil {
+		return nil, err
+	}
+	rootAliases, err := r.Root.InlineAliases()
+	if err != nil {
+		return nil, err
+	}
+
+	rootName, rootVersion := rootPackageIdentity(r.Root)
+	root, err := newPoolPackage(InlinePackage{
+		Name:     rootName,
+		Version:  rootVersion,
+		Conflict: r.Root.Conflict,
+		Replace:  r.Root.Replace,
+		Provide:  r.Root.Provide,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("root package: %w", err)
+	}
+	root.fixed = true
+	root.root = true
+	p.add(root)
+
+	for _, name := range sortedKeys(r.Platform) {
+		pkg, err := newPoolPackage(InlinePackage{Name: name, Version: r.Platform[name]})
+		if err != nil {
+			return nil, fmt.Errorf("platform package %s: %w", name, err)
+		}
+		pkg.fixed = true
+		pkg.platform = true
+		p.add(pkg)
+		p.platform[pkg.name] = pkg
+	}
+
+	for _, inline := range r.Packages {
+		pkg, err := newPoolPackage(inline)
+		if err != nil {
+			return nil, fmt.Errorf("package %s: %w", inline.Name, err)
+		}
+
+		// Like Composer, a package version that has a branch alias or a root inline
+		// alias is also available as the alias version.
+		aliases := make([]Version, 0)
+		if alias, ok := inline.BranchAlias(); ok {
+			aliases = append(aliases, alias)
+		}
+		for _, alias := range rootAliases {
+			if alias.Package == pkg.name && alias.Version == pkg.version.Normalized {
+				aliases = append(aliases, Version{Pretty: alias.Alias, Normalized: alias.AliasNormalized})
+			}
+		}
+		packages := []*poolPackage{pkg}
+		for _, alias := range aliases {
+			aliasPkg, err := newAliasPoolPackage(pkg, alias)
+			if err != nil {
+				return nil, fmt.Errorf("package %s: %w", inline.Name, err)
+			}
+			packages = append(packages, aliasPkg)
+		}
+
+		if !policy.acceptable(pkg.name, pkg.stability) {
+			p.unacceptable[pkg.name] = append(p.unacceptable[pkg.name], packages...)
+			continue
+		}
+		for _, pkg := range packages {
+			p.add(pkg)
+		}
+	}
+
+	sorted := append([]*poolPackage{}, p.packages...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].version.Less(sorted[j].version)
+	})
+	for i, pkg := range sorted {
+		if i > 0 && pkg.version.Compare(sorted[i-1].version) == 0 {
+			pkg.order = sorted[i-1].order
+		} else {
+			pkg.order = i
+		}
+	}
+	return p, nil
+}
+
+func newPoolPackage(inline InlinePackage) (*poolPackage, error) {
+	version, err := ParseVersion(inline.Version)
+	if err != nil {
+		return nil, err
+	}
+	return newVersionPoolPackage(inline, version)
+}
+
+// newAliasPoolPackage creates the alias of a package with the alias version. Links
+// with the self.version constraint refer to the alias version, like in Composer's
+// AliasPackage.
+func newAliasPoolPackage(aliasOf *poolPackage, alias Version) (*poolPackage, error) {
+	pkg, err := newVersionPoolPackage(aliasOf.pkg, alias)
+	if err != nil {
+		return nil, err
+	}
+	pkg.aliasOf = aliasOf
+	return pkg, nil
+}
+
+func newVersionPoolPackage(inline InlinePackage, version Version) (*poolPackage, error) {
+	pkg := &poolPackage{
+		pkg:       inline,
+		name:      strings.ToLower(inline.Name),
+		version:   version,
+		stability: stabilityRanks[version.Stability()],
+	}
+
+	sections := []struct {
+		links map[string]string
+		dest  *[]poolLink
+	}{
+		{inline.Require, &pkg.requires},
+		{inline.Conflict, &pkg.conflicts},
+		{inline.Replace, &pkg.replaces},
+		{inline.Provide, &pkg.provides},
+	}
+	for _, section := range sections {
+		for _, target := range sortedKeys(section.links) {
+			pretty := section.links[target]
+			constraint := pretty
+			if constraint == "self.version" {
+				constraint = version.Normalized
+			}
+			parsed, err := ParseConstraint(constraint)
+			if err != nil {
+				return nil, fmt.Errorf("link to %s: %w", target, err)
+			}
+			*section.dest = append(*section.dest, poolLink{
+				target:           strings.ToLower(target),
+				prettyTarget:     target,
+				constraint:       parsed,
+				prettyConstraint: pretty,
+			})
+		}
+	}
+	return pkg, nil
+}
+
+func (p *pool) add(pkg *poolPackage) {
+	pkg.id = len(p.packages) + 1
+	p.packages = append(p.packages, pkg)
+	for _, name := range pkg.names() {
+		p.providers[name] = append(p.providers[name], pkg)
+	}
+}
+
+// get returns the package with the given id.
+func (p *pool) get(id int) *poolPackage {
+	return p.packages[id-1]
+}
+
+// whatProvides returns the packages named name with a version matching the constraint,
+// and the packages which replace or provide name with a constraint that intersects it.
+func (p *pool) whatProvides(name string, constraint Constraint) []*poolPackage {
+	matches := make([]*poolPackage, 0)
+	for _, pkg := range p.providers[name] {
+		if pkg.name == name {
+			if pkg.version.Satisfies(constraint) {
+				matches = append(matches, pkg)
+			}
+			continue
+		}
+		for _, link := range append(pkg.replaces, pkg.provides...) {
+			if link.target == name && link.constraint.Intersects(constraint) {
+				matches = append(matches, pkg)
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// ruleGenerator creates the rules for every package that can be reached from the
+// requirements of the root package, like Composer's RuleSetGenerator.
+type ruleGenerator struct {
+	resolver Resolver
+	pool     *pool
+	rules    []*rule
+	added    map[*poolPackage]bool
+	queue    []*poolPackage
+
+	// problems found while generating the rules, which are root requirements that no
+	// package can satisfy.
+	problems [][]string
+}
+
+func (g *ruleGenerator) addRule(r *rule) {
+	r.id = len(g.rules)
+	g.rules = append(g.rules, r)
+}
+
+func (g *ruleGenerator) generate() {
+	root := g.pool.get(1)
+	g.added[root] = true
+	for _, pkg := range g.pool.packages {
+		if pkg.fixed {
+			g.addRule(&rule{kind: ruleFixed, literals: []int{pkg.id}, pkg: pkg})
+		}
+	}
+
+	requires := []map[string]string{g.resolver.Root.Require}
+	if !g.resolver.NoDev {
+		requires = append(requires, g.resolver.Root.RequireDev)
+	}
+	for _, links := range requires {
+		for _, target := range sortedKeys(links) {
+			link, err := rootLink(target, links[target])
+			if err != nil {
+				g.problems = append(g.problems, []string{fmt.Sprintf("Root composer.json requires %s %s, %s.", target, links[target], err)})
+				continue
+			}
+			if g.ignored(link) {
+				continue
+			}
+			providers := g.pool.whatProvides(link.target, link.constraint)
+			if len(providers) == 0 {
+				g.problems = append(g.problems, []string{g.pool.rootMissingMessage(link)})
+				continue
+			}
+			g.addRule(&rule{kind: ruleRootRequire, literals: packageIDs(providers, 1), pkg: root, link: link})
+			g.queue = append(g.queue, providers...)
+		}
+	}
+	for _, link := range root.conflicts {
+		for _, pkg := range g.pool.whatProvides(link.target, link.constraint) {
+			if pkg != root {
+				g.addRule(&rule{kind: ruleRootConflict, literals: []int{-pkg.id}, pkg: root, link: link})
+			}
+		}
+	}
+
+	for len(g.queue) > 0 {
+		pkg := g.queue[0]
+		g.queue = g.queue[1:]
+		g.addPackage(pkg)
+	}
+	g.addSameNameRules()
+}
+
+// rootLink parses a requirement of the root package.
+func rootLink(target, constraint string) (poolLink, error) {
+	parsed, err := ParseConstraint(constraint)
+	if err != nil {
+		return poolLink{}, err
+	}
+	return poolLink{
+		target:           strings.ToLower(target),
+		prettyTarget:     target,
+		constraint:       parsed,
+		prettyConstraint: constraint,
+	}, nil
+}
+
+// ignored returns true if the link is a platform requirement and platform requirements
+// are ignored.
+func (g *ruleGenerator) ignored(link poolLink) bool {
+	return g.resolver.IgnorePlatformRequirements && isPlatformPackage(link.target)
+}
+
+func (g *ruleGenerator) addPackage(pkg *poolPackage) {
+	if g.added[pkg] {
+		return
+	}
+	g.added[pkg] = true
+	if pkg.fixed {
+		return
+	}
+
+	if pkg.aliasOf != nil {
+		g.addRule(&rule{kind: rulePackageAlias, literals: []int{-pkg.id, pkg.aliasOf.id}, pkg: pkg})
+		g.queue = append(g.queue, pkg.aliasOf)
+	}
+	for _, link := range pkg.requires {
+		if g.ignored(link) {
+			continue
+		}
+		providers := g.pool.whatProvides(link.target, link.constraint)
+		literals := append([]int{-pkg.id}, packageIDs(providers, 1)...)
+		g.addRule(&rule{kind: rulePackageRequire, literals: literals, pkg: pkg, link: link})
+		g.queue = append(g.queue, providers...)
+	}
+	for _, link := range pkg.conflicts {
+		for _, other := range g.pool.whatProvides(link.target, link.constraint) {
+			if other != pkg {
+				g.addRule(&rule{kind: rulePackageConflict, literals: []int{-pkg.id, -other.id}, pkg: pkg, link: link})
+			}
+		}
+	}
+}
+
+// addSameNameRules makes sure only one package with a name is installed. A package
+// that replaces another one counts as having the name of the replaced package.
+func (g *ruleGenerator) addSameNameRules() {
+	byName := make(map[string][]*poolPackage)
+	for _, pkg := range g.pool.packages {
+		// An alias is installed along with the package it is the alias of, so it
+		// does not count as another package with the name.
+		if !g.added[pkg] || pkg.aliasOf != nil {
+			continue
+		}
+		byName[pkg.name] = append(byName[pkg.name], pkg)
+		for _, link := range pkg.replaces {
+			byName[link.target] = append(byName[link.target], pkg)
+		}
+	}
+	for _, name := range sortedKeys(byName) {
+		if len(byName[name]) > 1 {
+			g.addRule(&rule{kind: ruleSameName, literals: packageIDs(byName[name], -1), name: name})
+		}
+	}
+}
+
+// packageIDs returns the ids of the packages multiplied by direction, which turns them
+// into literals that install (1) or remove (-1) the packages.
+func packageIDs(packages []*poolPackage, direction int) []int {
+	ids := make([]int, len(packages))
+	for i, pkg := range packages {
+		ids[i] = pkg.id * direction
+	}
+	return ids
+}
+
+// messages describes the rules of a problem the same way Composer does.
+func (p *pool) messages(rules []*rule) []string {
+	messages := make([]string, 0, len(rules))
+	seen := make(map[string]bool)
+	for _, r := range rules {
+		message := p.message(r)
+		if message == "" || seen[message] {
+			continue
+		}
+		seen[message] = true
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+func (p *pool) message(r *rule) string {
+	switch r.kind {
+	case ruleRootRequire:
+		return fmt.Sprintf("Root composer.json requires %s %s -> satisfiable by %s.", r.link.prettyTarget, r.link.prettyConstraint, p.formatPackages(r.literals))
+	case rulePackageRequire:
+		if len(r.literals) == 1 {
+			return fmt.Sprintf("%s requires %s %s -> %s", r.pkg, r.link.prettyTarget, r.link.prettyConstraint, p.missingReason(r.link))
+		}
+		return fmt.Sprintf("%s requires %s %s -> satisfiable by %s.", r.pkg, r.link.prettyTarget, r.link.prettyConstraint, p.formatPackages(r.literals[1:]))
+	case rulePackageConflict:
+		return fmt.Sprintf("%s conflicts with %s.", r.pkg, p.get(-r.literals[1]))
+	case rulePackageAlias:
+		// Like Composer, leave out the alias of the default branch, which says nothing
+		// useful.
+		if r.pkg.version.Normalized == DefaultBranchAlias {
+			return ""
+		}
+		return fmt.Sprintf("%s is an alias of %s and thus requires it to be installed too.", r.pkg, r.pkg.aliasOf)
+	case ruleRootConflict:
+		return fmt.Sprintf("Root composer.json conflicts with %s.", p.get(-r.literals[0]))
+	case ruleSameName:
+		message := fmt.Sprintf("Only one of these can be installed: %s.", p.formatPackages(r.literals))
+		replacers := make([]string, 0)
+		for _, literal := range r.literals {
+			pkg := p.get(-literal)
+			if pkg.name != r.name && !contains(replacers, pkg.pkg.Name) {
+				replacers = append(replacers, pkg.pkg.Name)
+			}
+		}
+		for _, replacer := range replacers {
+			message += fmt.Sprintf(" %s replaces %s and thus cannot coexist with it.", replacer, r.name)
+		}
+		return message
+	}
+	return ""
+}
+
+// rootMissingMessage describes a root requirement that no package can satisfy.
+func (p *pool) rootMissingMessage(link poolLink) string {
+	requirement := "Root composer.json requires " + link.prettyTarget + " " + link.prettyConstraint
+	if isPlatformPackage(link.target) {
+		return requirement + " but " + p.missingReason(link)
+	}
+	return requirement + ", " + p.missingReason(link)
+}
+
+// missingReason explains why no package can satisfy a link.
+func (p *pool) missingReason(link poolLink) string {
+	if isPlatformPackage(link.target) {
+		pkg, ok := p.platform[link.target]
+		switch {
+		case ok && strings.HasPrefix(link.target, "php"):
+			return fmt.Sprintf("your %s version (%s) does not satisfy that requirement.", link.target, pkg.version.Pretty)
+		case ok:
+			return fmt.Sprintf("it has the wrong version installed (%s).", pkg.version.Pretty)
+		case strings.HasPrefix(link.target, "ext-"):
+			return fmt.Sprintf("it is missing from your system. Install or enable PHP's %s extension.", link.target[len("ext-"):])
+		}
+		return "it is missing from your system."
+	}
+
+	unstable := make([]*poolPackage, 0)
+	for _, pkg := range p.unacceptable[link.target] {
+		if pkg.version.Satisfies(link.constraint) {
+			unstable = append(unstable, pkg)
+		}
+	}
+	if len(unstable) > 0 {
+		return fmt.Sprintf("found %s but it does not match your minimum-stability.", formatPackages(unstable))
+	}
+
+	found := make([]*poolPackage, 0)
+	for _, pkg := range p.providers[link.target] {
+		if pkg.name == link.target {
+			found = append(found, pkg)
+		}
+	}
+	found = append(found, p.unacceptable[link.target]...)
+	if len(found) > 0 {
+		return fmt.Sprintf("found %s but it does not match the constraint.", formatPackages(found))
+	}
+	return "could not be found in any version, there may be a typo in the package name."
+}
+
+// formatPackages lists the packages of literals grouped by name, e.g.
+// "acme/a[1.0.0, 1.1.0], acme/b[2.0.0]". The sign of a literal is ignored.
+func (p *pool) formatPackages(literals []int) string {
+	packages := make([]*poolPackage, len(literals))
+	for i, literal := range literals {
+		if literal < 0 {
+			literal = -literal
+		}
+		packages[i] = p.get(literal)
+	}
+	return formatPackages(packages)
+}
+
+// formatPackages lists packages grouped by name, e.g. "acme/a[1.0.0, 1.1.0],
+// acme/b[2.0.0]". Aliases are listed with the version they are the alias of, e.g.
+// "2.x-dev (alias of dev-main)".
+func formatPackages(packages []*poolPackage) string {
+	names := make([]string, 0)
+	byName := make(map[string][]*poolPackage)
+	for _, pkg := range packages {
+		if _, exists := byName[pkg.pkg.Name]; !exists {
+			names = append(names, pkg.pkg.Name)
+		}
+		byName[pkg.pkg.Name] = append(byName[pkg.pkg.Name], pkg)
+	}
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		sort.SliceStable(byName[name], func(i, j int) bool {
+			return byName[name][i].version.Less(byName[name][j].version)
+		})
+		pretty := make([]string, 0, len(byName[name]))
+		for _, pkg := range byName[name] {
+			version := pkg.version.Pretty
+			if pkg.aliasOf != nil {
+				version += " (alias of " + pkg.aliasOf.version.Pretty + ")"
+			}
+			if !contains(pretty, version) {
+				pretty = append(pretty, version)
+			}
+		}
+		parts = append(parts, name+"["+strings.Join(pretty, ", ")+"]")
+	}
+	return strings.Join(parts, ", ")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// rootPackageIdentity returns the name and version of the root package, which default
+// to "__root__" and "1.0.0+no-version-set" like in Composer.
+func rootPackageIdentity(root ComposerJSON) (string, string) {
+	name := root.Name
+	if name == "" {
+		name = rootPackageName
+	}
+	version := root.Version
+	if version == "" {
+		version = "1.0.0+no-version-set"
+	}
+	return name, version
+}
@@ -0,0 +1,370 @@
+package gocomposer
+
+import (
+	"errors"
+	is2 "github.com/matryer/is"
+	"strings"
+	"testing"
+)
+
+// resolverPackage creates an InlinePackage with links given as "type:name constraint",
+// e.g. "require:acme/b ^1.0" or "replace:acme/c self.version".
+func resolverPackage(name, version string, links ...string) InlinePackage {
+	pkg := InlinePackage{Name: name, Version: version}
+	for _, link := range links {
+		kind, rest, _ := strings.Cut(link, ":")
+		target, constraint, _ := strings.Cut(rest, " ")
+		var dest *map[string]string
+		switch kind {
+		case "require":
+			dest = &pkg.Require
+		case "conflict":
+			dest = &pkg.Conflict
+		case "replace":
+			dest = &pkg.Replace
+		case "provide":
+			dest = &pkg.Provide
+		}
+		if *dest == nil {
+			*dest = map[string]string{}
+		}
+		(*dest)[target] = constraint
+	}
+	return pkg
+}
+
+// resolvedVersions returns the packages of a Resolution as "name version" strings.
+func resolvedVersions(packages []InlinePackage) []string {
+	versions := make([]string, 0, len(packages))
+	for _, pkg := range packages {
+		versions = append(versions, pkg.Name+" "+pkg.Version)
+	}
+	return versions
+}
+
+var resolverPool = []InlinePackage{
+	resolverPackage("acme/log", "1.0.0"),
+	resolverPackage("acme/log", "1.1.0"),
+	resolverPackage("acme/log", "2.0.0"),
+	resolverPackage("acme/log", "2.1.0-beta1"),
+	resolverPackage("acme/log", "dev-main"),
+	resolverPackage("acme/http", "1.0.0", "require:acme/log ^1.0"),
+	resolverPackage("acme/http", "2.0.0", "require:acme/log ^2.0", "require:php >=8.1"),
+	resolverPackage("acme/cache", "1.0.0", "require:acme/log ^1.0 || ^2.0", "conflict:acme/http <2.0"),
+	resolverPackage("acme/framework", "3.0.0", "replace:acme/http self.version", "require:acme/log ^2.0"),
+	resolverPackage("acme/log-impl", "1.0.0", "provide:psr/log-implementation 1.0"),
+	resolverPackage("acme/debug", "1.0.0", "require:acme/http ^1.0"),
+	resolverPackage("acme/phpunit", "9.0.0", "require:ext-dom *"),
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	tests := []struct {
+		name        string
+		root        ComposerJSON
+		platform    map[string]string
+		packages    []string
+		packagesDev []string
+	}{
+		{
+			name:     `HighestVersion`,
+			root:     ComposerJSON{Require: map[string]string{"acme/log": "*"}},
+			packages: []string{"acme/log 2.0.0"},
+		},
+		{
+			name:     `Transitive`,
+			root:     ComposerJSON{Require: map[string]string{"acme/http": "^2.0"}},
+			platform: map[string]string{"php": "8.2.0"},
+			packages: []string{"acme/http 2.0.0", "acme/log 2.0.0"},
+		},
+		{
+			name:     `PlatformForcesLowerVersion`,
+			root:     ComposerJSON{Require: map[string]string{"acme/http": "*"}},
+			platform: map[string]string{"php": "8.0.30"},
+			packages: []string{"acme/http 1.0.0", "acme/log 1.1.0"},
+		},
+		{
+			name:     `Backtrack`,
+			root:     ComposerJSON{Require: map[string]string{"acme/log": "*", "acme/debug": "*"}},
+			packages: []string{"acme/debug 1.0.0", "acme/http 1.0.0", "acme/log 1.1.0"},
+		},
+		{
+			name:     `Conflict`,
+			root:     ComposerJSON{Require: map[string]string{"acme/cache": "*", "acme/http": "*"}},
+			platform: map[string]string{"php": "8.2.0"},
+			packages: []string{"acme/cache 1.0.0", "acme/http 2.0.0", "acme/log 2.0.0"},
+		},
+		{
+			name:     `Replace`,
+			root:     ComposerJSON{Require: map[string]string{"acme/framework": "^3.0", "acme/http": "^3.0"}},
+			packages: []string{"acme/framework 3.0.0", "acme/log 2.0.0"},
+		},
+		{
+			name:     `Provide`,
+			root:     ComposerJSON{Require: map[string]string{"psr/log-implementation": "^1.0"}},
+			packages: []string{"acme/log-impl 1.0.0"},
+		},
+		{
+			name:     `RootReplace`,
+			root:     ComposerJSON{Require: map[string]string{"acme/debug": "*"}, Replace: map[string]string{"acme/http": "1.0.0"}},
+			packages: []string{"acme/debug 1.0.0"},
+		},
+		{
+			name:     `MinimumStability`,
+			root:     ComposerJSON{Require: map[string]string{"acme/log": ">=2.0"}, MinimumStability: "beta"},
+			packages: []string{"acme/log 2.1.0-beta1"},
+		},
+		{
+			name:     `PreferStable`,
+			root:     ComposerJSON{Require: map[string]string{"acme/log": ">=2.0"}, MinimumStability: "dev", PreferStable: true},
+			packages: []string{"acme/log 2.0.0"},
+		},
+		{
+			name:     `DevBranch`,
+			root:     ComposerJSON{Require: map[string]string{"acme/log": "dev-main"}, MinimumStability: "dev"},
+			packages: []string{"acme/log dev-main"},
+		},
+		{
+			name:     `StabilityFlag`,
+			root:     ComposerJSON{Require: map[string]string{"acme/log": "^2.0@beta"}},
+			packages: []string{"acme/log 2.1.0-beta1"},
+		},
+		{
+			name:     `InferredStabilityFlag`,
+			root:     ComposerJSON{Require: map[string]string{"acme/log": "dev-main"}},
+			packages: []string{"acme/log dev-main"},
+		},
+		{
+			name:     `StabilityFlagOnlyAppliesToPackage`,
+			root:     ComposerJSON{Require: map[string]string{"acme/http": "^1.0@dev"}},
+			packages: []string{"acme/http 1.0.0", "acme/log 1.1.0"},
+		},
+		{
+			name: `RequireDev`,
+			root: ComposerJSON{
+				Require:    map[string]string{"acme/log": "^1.0"},
+				RequireDev: map[string]string{"acme/debug": "*", "acme/phpunit": "^9.0"},
+			},
+			platform:    map[string]string{"ext-dom": "20031129"},
+			packages:    []string{"acme/log 1.1.0"},
+			packagesDev: []string{"acme/debug 1.0.0", "acme/http 1.0.0", "acme/phpunit 9.0.0"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			resolution, err := Resolver{Root: test.root, Packages: resolverPool, Platform: test.platform}.Resolve()
+			is.NoErr(err)
+			is.Equal(resolvedVersions(resolution.Packages), test.packages)
+			is.Equal(resolvedVersions(resolution.PackagesDev), append([]string{}, test.packagesDev...))
+		})
+	}
+}
+
+func TestResolver_Resolve_Options(t *testing.T) {
+	is := is2.New(t)
+
+	root := ComposerJSON{
+		Require:    map[string]string{"acme/http": "^2.0"},
+		RequireDev: map[string]string{"acme/phpunit": "*"},
+	}
+	resolution, err := Resolver{Root: root, Packages: resolverPool, IgnorePlatformRequirements: true, NoDev: true}.Resolve()
+	is.NoErr(err)
+	is.Equal(resolvedVersions(resolution.Packages), []string{"acme/http 2.0.0", "acme/log 2.0.0"})
+	is.Equal(len(resolution.PackagesDev), 0)
+}
+
+func TestResolver_Resolve_Problems(t *testing.T) {
+	tests := []struct {
+		name     string
+		root     ComposerJSON
+		platform map[string]string
+		problems [][]string
+	}{
+		{
+			name: `NotFound`,
+			root: ComposerJSON{Require: map[string]string{"acme/lgo": "^1.0"}},
+			problems: [][]string{{
+				"Root composer.json requires acme/lgo ^1.0, could not be found in any version, there may be a typo in the package name.",
+			}},
+		},
+		{
+			name: `NoMatchingVersion`,
+			root: ComposerJSON{Require: map[string]string{"acme/log": "^3.0"}},
+			problems: [][]string{{
+				"Root composer.json requires acme/log ^3.0, found acme/log[dev-main, 1.0.0, 1.1.0, 2.0.0, 2.1.0-beta1] but it does not match the constraint.",
+			}},
+		},
+		{
+			name: `MinimumStability`,
+			root: ComposerJSON{Require: map[string]string{"acme/log": "^2.1"}},
+			problems: [][]string{{
+				"Root composer.json requires acme/log ^2.1, found acme/log[2.1.0-beta1] but it does not match your minimum-stability.",
+			}},
+		},
+		{
+			name: `PlatformMissing`,
+			root: ComposerJSON{Require: map[string]string{"php": "^8.1", "ext-intl": "*"}},
+			problems: [][]string{
+				{"Root composer.json requires ext-intl * but it is missing from your system. Install or enable PHP's intl extension."},
+				{"Root composer.json requires php ^8.1 but it is missing from your system."},
+			},
+		},
+		{
+			name:     `PlatformVersion`,
+			root:     ComposerJSON{Require: map[string]string{"php": "^8.1"}},
+			platform: map[string]string{"php": "7.4.33"},
+			problems: [][]string{{
+				"Root composer.json requires php ^8.1 but your php version (7.4.33) does not satisfy that requirement.",
+			}},
+		},
+		{
+			name:     `Transitive`,
+			root:     ComposerJSON{Require: map[string]string{"acme/http": "^2.0"}},
+			platform: map[string]string{"php": "8.0.30"},
+			problems: [][]string{{
+				"Root composer.json requires acme/http ^2.0 -> satisfiable by acme/http[2.0.0].",
+				"acme/http 2.0.0 requires php >=8.1 -> your php version (8.0.30) does not satisfy that requirement.",
+			}},
+		},
+		{
+			name:     `SameName`,
+			root:     ComposerJSON{Require: map[string]string{"acme/log": "^2.0", "acme/http": "^1.0"}},
+			platform: map[string]string{"php": "8.2.0"},
+			problems: [][]string{{
+				"Root composer.json requires acme/http ^1.0 -> satisfiable by acme/http[1.0.0].",
+				"Root composer.json requires acme/log ^2.0 -> satisfiable by acme/log[2.0.0].",
+				"acme/http 1.0.0 requires acme/log ^1.0 -> satisfiable by acme/log[1.0.0, 1.1.0].",
+				"Only one of these can be installed: acme/log[1.0.0, 1.1.0, 2.0.0].",
+			}},
+		},
+		{
+			name:     `Conflict`,
+			root:     ComposerJSON{Require: map[string]string{"acme/cache": "*", "acme/debug": "*"}},
+			platform: map[string]string{"php": "8.2.0"},
+			problems: [][]string{{
+				"Root composer.json requires acme/cache * -> satisfiable by acme/cache[1.0.0].",
+				"Root composer.json requires acme/debug * -> satisfiable by acme/debug[1.0.0].",
+				"acme/cache 1.0.0 conflicts with acme/http 1.0.0.",
+				"acme/debug 1.0.0 requires acme/http ^1.0 -> satisfiable by acme/http[1.0.0].",
+			}},
+		},
+		{
+			name: `Replace`,
+			root: ComposerJSON{Require: map[string]string{"acme/framework": "*", "acme/debug": "*"}},
+			problems: [][]string{{
+				"Root composer.json requires acme/debug * -> satisfiable by acme/debug[1.0.0].",
+				"Root composer.json requires acme/framework * -> satisfiable by acme/framework[3.0.0].",
+				"acme/debug 1.0.0 requires acme/http ^1.0 -> satisfiable by acme/http[1.0.0].",
+				"Only one of these can be installed: acme/http[1.0.0], acme/framework[3.0.0]. acme/framework replaces acme/http and thus cannot coexist with it.",
+			}},
+		},
+		{
+			name: `RootConflict`,
+			root: ComposerJSON{Require: map[string]string{"acme/log": "^1.0"}, Conflict: map[string]string{"acme/log": "<2.0"}},
+			problems: [][]string{{
+				"Root composer.json requires acme/log ^1.0 -> satisfiable by acme/log[1.0.0, 1.1.0].",
+				"Root composer.json conflicts with acme/log 1.0.0.",
+				"Root composer.json conflicts with acme/log 1.1.0.",
+			}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			_, err := Resolver{Root: test.root, Packages: resolverPool, Platform: test.platform}.Resolve()
+			resolveErr := &ResolveError{}
+			is.True(errors.As(err, &resolveErr))
+			is.Equal(resolveErr.Problems, test.problems)
+		})
+	}
+}
+
+func TestResolveError_Error(t *testing.T) {
+	is := is2.New(t)
+
+	err := &ResolveError{Problems: [][]string{{"first", "second"}, {"third"}}}
+	is.Equal(err.Error(), `Your requirements could not be resolved to an installable set of packages.
+
+  Problem 1
+    - first
+    - second
+  Problem 2
+    - third`)
+}
+
+func TestResolver_Resolve_InvalidVersion(t *testing.T) {
+	is := is2.New(t)
+
+	_, err := Resolver{Packages: []InlinePackage{resolverPackage("acme/log", "latest")}}.Resolve()
+	is.Equal(err.Error(), `package acme/log: invalid version string "latest"`)
+}
+
+func TestResolver_Resolve_Aliases(t *testing.T) {
+	queue := resolverPackage("acme/queue", "dev-main")
+	queue.Extra = Extra{{"branch-alias", Extra{{"dev-main", "2.x-dev"}}}}
+	pool := []InlinePackage{
+		queue,
+		resolverPackage("acme/queue", "dev-legacy", "require:acme/log ^1.5"),
+		resolverPackage("acme/queue", "1.0.0"),
+		resolverPackage("acme/log", "1.5.0"),
+		resolverPackage("acme/worker", "1.0.0", "require:acme/queue ^2.0"),
+		resolverPackage("acme/mailer", "1.0.0", "require:acme/queue ^1.5"),
+	}
+
+	tests := []struct {
+		name     string
+		root     ComposerJSON
+		packages []string
+		problems [][]string
+	}{
+		{
+			name:     `BranchAlias`,
+			root:     ComposerJSON{Require: map[string]string{"acme/worker": "*"}, MinimumStability: "dev"},
+			packages: []string{"acme/queue dev-main", "acme/worker 1.0.0"},
+		},
+		{
+			name:     `InlineAlias`,
+			root:     ComposerJSON{Require: map[string]string{"acme/mailer": "*", "acme/queue": "dev-legacy as 1.5.0"}},
+			packages: []string{"acme/log 1.5.0", "acme/mailer 1.0.0", "acme/queue dev-legacy"},
+		},
+		{
+			name: `BranchAliasUnstable`,
+			root: ComposerJSON{Require: map[string]string{"acme/worker": "*"}},
+			problems: [][]string{{
+				"Root composer.json requires acme/worker * -> satisfiable by acme/worker[1.0.0].",
+				"acme/worker 1.0.0 requires acme/queue ^2.0 -> found acme/queue[2.x-dev (alias of dev-main)] but it does not match your minimum-stability.",
+			}},
+		},
+		{
+			name: `AliasOutsideConstraint`,
+			root: ComposerJSON{Require: map[string]string{"acme/mailer": "*"}, MinimumStability: "dev"},
+			problems: [][]string{{
+				"Root composer.json requires acme/mailer * -> satisfiable by acme/mailer[1.0.0].",
+				"acme/mailer 1.0.0 requires acme/queue ^1.5 -> found acme/queue[dev-legacy, dev-main, 1.0.0, 2.x-dev (alias of dev-main)] but it does not match the constraint.",
+			}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			resolution, err := Resolver{Root: test.root, Packages: pool}.Resolve()
+			if test.problems != nil {
+				var resolveErr *ResolveError
+				is.True(errors.As(err, &resolveErr))
+				is.Equal(resolveErr.Problems, test.problems)
+				return
+			}
+			is.NoErr(err)
+			is.Equal(resolvedVersions(resolution.Packages), test.packages)
+		})
+	}
+
+	_, err := Resolver{Root: ComposerJSON{Require: map[string]string{"acme/queue": "dev-main as"}}, Packages: pool}.Resolve()
+	is2.New(t).True(err != nil)
+}
@@ -0,0 +1,282 @@
+package gocomposer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// ScriptShell is a command executed by the shell, e.g. "phpunit --colors".
+	ScriptShell ScriptKind = "shell"
+	// ScriptCallback is a static PHP method, e.g. "Vendor\\Class::method".
+	ScriptCallback ScriptKind = "callback"
+	// ScriptReference runs another script, e.g. "@test".
+	ScriptReference ScriptKind = "reference"
+	// ScriptPHP runs a PHP script with the same PHP binary as Composer, e.g.
+	// "@php artisan migrate".
+	ScriptPHP ScriptKind = "php"
+	// ScriptComposer runs a Composer command with the same Composer binary, e.g.
+	// "@composer dump-autoload".
+	ScriptComposer ScriptKind = "composer"
+	// ScriptPutenv sets an environment variable for the following commands, e.g.
+	// "@putenv COMPOSER_PROCESS_TIMEOUT=0".
+	ScriptPutenv ScriptKind = "putenv"
+)
+
+// ScriptKind is the way Composer runs a ScriptCommand.
+type ScriptKind string
+
+// ScriptCommand is a single command of a script.
+type ScriptCommand string
+
+// Kind returns how Composer runs the command. The checks are done in the same order
+// as Composer's event dispatcher.
+func (c ScriptCommand) Kind() ScriptKind {
+	command := string(c)
+	switch {
+	case !strings.Contains(command, " ") && strings.Contains(command, "::"):
+		return ScriptCallback
+	case strings.HasPrefix(command, "@php "):
+		return ScriptPHP
+	case strings.HasPrefix(command, "@putenv "):
+		return ScriptPutenv
+	case strings.HasPrefix(command, "@composer "):
+		return ScriptComposer
+	case strings.HasPrefix(command, "@"):
+		return ScriptReference
+	}
+	return ScriptShell
+}
+
+// Callback returns the class and method of a ScriptCallback command.
+func (c ScriptCommand) Callback() (class string, method string, ok bool) {
+	if c.Kind() != ScriptCallback {
+		return "", "", false
+	}
+	class, method, _ = strings.Cut(string(c), "::")
+	return class, method, true
+}
+
+// Reference returns the name of the script a ScriptReference command runs, along with
+// the arguments passed to it.
+func (c ScriptCommand) Reference() (name string, args string, ok bool) {
+	if c.Kind() != ScriptReference {
+		return "", "", false
+	}
+	name, args, _ = strings.Cut(string(c)[1:], " ")
+	return name, strings.TrimSpace(args), true
+}
+
+// withArgs returns the command with args appended, the way Composer passes the
+// arguments given to a script reference on to the commands it runs.
+func (c ScriptCommand) withArgs(args string) ScriptCommand {
+	if args == "" {
+		return c
+	}
+	switch c.Kind() {
+	case ScriptShell, ScriptPHP, ScriptComposer, ScriptReference:
+		return c + ScriptCommand(" "+args)
+	}
+	return c
+}
+
+// Script is the list of commands run for an event or custom script. In composer.json
+// it can be a single command string or an array of commands.
+type Script struct {
+	Commands []ScriptCommand
+
+	// isString is true when the script was a single string in composer.json.
+	isString bool
+
+	// keys holds the object keys of a script written as an object, which is used by
+	// Symfony Flex for its "auto-scripts". Composer only uses the values.
+	keys []string
+}
+
+// NewScript creates a Script with the given commands.
+func NewScript(commands ...string) Script {
+	s := Script{}
+	for _, command := range commands {
+		s.Commands = append(s.Commands, ScriptCommand(command))
+	}
+	return s
+}
+
+func (s *Script) UnmarshalJSON(data []byte) error {
+	*s = Script{}
+	if isString(data) {
+		command := ""
+		if err := json.Unmarshal(data, &command); err != nil {
+			return err
+		}
+		s.Commands = []ScriptCommand{ScriptCommand(command)}
+		s.isString = true
+		return nil
+	}
+	if isObject(data) {
+		temp, err := decodeOrdered(data)
+		if err != nil {
+			return err
+		}
+		obj := temp.(*orderedObject)
+		for _, key := range obj.keys {
+			command, ok := obj.values[key].(string)
+			if !ok {
+				return fmt.Errorf("script command %q must be a string", key)
+			}
+			s.keys = append(s.keys, key)
+			s.Commands = append(s.Commands, ScriptCommand(command))
+		}
+		return nil
+	}
+	if isArray(data) {
+		return json.Unmarshal(data, &s.Commands)
+	}
+	return errors.New("script must be a string or an array of strings")
+}
+
+func (s Script) MarshalJSON() ([]byte, error) {
+	if s.isString && len(s.Commands) == 1 {
+		return json.Marshal(string(s.Commands[0]))
+	}
+	if s.keys != nil && len(s.keys) == len(s.Commands) {
+		// Keep the original key order.
+		buf := strings.Builder{}
+		buf.WriteRune('{')
+		for i, key := range s.keys {
+			name, err := json.Marshal(key)
+			if err != nil {
+				return nil, err
+			}
+			command, err := json.Marshal(string(s.Commands[i]))
+			if err != nil {
+				return nil, err
+			}
+			buf.WriteString(fmt.Sprintf(`%s:%s`, name, command))
+			if i < len(s.keys)-1 {
+				buf.WriteRune(',')
+			}
+		}
+		buf.WriteRune('}')
+		return []byte(buf.String()), nil
+	}
+	if s.Commands == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(s.Commands)
+}
+
+// Scripts is an object of event or custom script names (keys) and the commands to run
+// (values).
+type Scripts map[string]Script
+
+// Resolve returns the complete list of commands run by the named script, with every
+// reference to another script replaced by the commands of that script. Arguments given
+// to a reference are appended to the commands it expands to. An error is returned if
+// a referenced script does not exist or if the references form a cycle.
+func (s Scripts) Resolve(name string) ([]ScriptCommand, error) {
+	if _, exists := s[name]; !exists {
+		return nil, fmt.Errorf("script %q is not defined", name)
+	}
+	return s.resolve(name, "", []string{})
+}
+
+func (s Scripts) resolve(name string, args string, stack []string) ([]ScriptCommand, error) {
+	for _, parent := range stack {
+		if parent == name {
+			return nil, fmt.Errorf("script reference cycle: %s", strings.Join(append(stack, name), " -> "))
+		}
+	}
+	script, exists := s[name]
+	if !exists {
+		return nil, fmt.Errorf("script %q references the undefined script %q", stack[len(stack)-1], name)
+	}
+	stack = append(stack, name)
+
+	commands := make([]ScriptCommand, 0, len(script.Commands))
+	for _, command := range script.Commands {
+		command = command.withArgs(args)
+		ref, refArgs, ok := command.Reference()
+		if !ok {
+			commands = append(commands, command)
+			continue
+		}
+		resolved, err := s.resolve(ref, refArgs, stack)
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, resolved...)
+	}
+	return commands, nil
+}
+
+// Cycles returns every cycle of script references. Each cycle is listed once as the
+// script names in the order they reference each other, starting with the name that
+// sorts first. References to undefined scripts are ignored.
+func (s Scripts) Cycles() [][]string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cycles := make([][]string, 0)
+	found := make(map[string]bool)
+	var visit func(name string, stack []string)
+	visit = func(name string, stack []string) {
+		for i, parent := range stack {
+			if parent != name {
+				continue
+			}
+			cycle := rotateToSmallest(stack[i:])
+			key := strings.Join(cycle, "\x00")
+			if !found[key] {
+				found[key] = true
+				cycles = append(cycles, cycle)
+			}
+			return
+		}
+		stack = append(stack, name)
+		for _, command := range s[name].Commands {
+			if ref, _, ok := command.Reference(); ok {
+				if _, exists := s[ref]; exists {
+					visit(ref, stack)
+				}
+			}
+		}
+	}
+	for _, name := range names {
+		visit(name, []string{})
+	}
+	return cycles
+}
+
+// rotateToSmallest returns a copy of cycle rotated so that it starts with the name
+// that sorts first.
+func rotateToSmallest(cycle []string) []string {
+	start := 0
+	for i, name := range cycle {
+		if name < cycle[start] {
+			start = i
+		}
+	}
+	return append(append([]string{}, cycle[start:]...), cycle[:start]...)
+}
+
+// ResolveScript returns the complete list of commands run by the named script, which
+// can also be one of the aliases defined in scripts-aliases. See Scripts.Resolve.
+func (c ComposerJSON) ResolveScript(name string) ([]ScriptCommand, error) {
+	if _, exists := c.Scripts[name]; !exists {
+		for script, aliases := range c.ScriptsAliases {
+			for _, alias := range aliases {
+				if alias == name {
+					return c.Scripts.Resolve(script)
+				}
+			}
+		}
+	}
+	return c.Scripts.Resolve(name)
+}
@@ -0,0 +1,253 @@
+package gocomposer
+
+import (
+	"encoding/json"
+	is2 "github.com/matryer/is"
+	"testing"
+)
+
+func TestScriptCommand_Kind(t *testing.T) {
+	tests := []struct {
+		name    string
+		command ScriptCommand
+		kind    ScriptKind
+	}{
+		{
+			name:    `Shell`,
+			command: `phpunit --colors=always`,
+			kind:    ScriptShell,
+		},
+		{
+			name:    `Callback`,
+			command: `Illuminate\Foundation\ComposerScripts::postAutoloadDump`,
+			kind:    ScriptCallback,
+		},
+		{
+			name:    `ShellWithDoubleColon`,
+			command: `php bin/console cache:clear --env=prod foo::bar`,
+			kind:    ScriptShell,
+		},
+		{
+			name:    `Reference`,
+			command: `@test`,
+			kind:    ScriptReference,
+		},
+		{
+			name:    `PHP`,
+			command: `@php artisan package:discover --ansi`,
+			kind:    ScriptPHP,
+		},
+		{
+			name:    `Composer`,
+			command: `@composer dump-autoload`,
+			kind:    ScriptComposer,
+		},
+		{
+			name:    `Putenv`,
+			command: `@putenv COMPOSER_PROCESS_TIMEOUT=0`,
+			kind:    ScriptPutenv,
+		},
+		{
+			name:    `ReferenceNamedLikeDirective`,
+			command: `@phpstan`,
+			kind:    ScriptReference,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+			is.Equal(test.command.Kind(), test.kind)
+		})
+	}
+}
+
+func TestScriptCommand_Callback(t *testing.T) {
+	is := is2.New(t)
+
+	class, method, ok := ScriptCommand(`Acme\Installer::postInstall`).Callback()
+	is.True(ok)
+	is.Equal(class, `Acme\Installer`)
+	is.Equal(method, `postInstall`)
+
+	_, _, ok = ScriptCommand(`@test`).Callback()
+	is.True(!ok)
+}
+
+func TestScriptCommand_Reference(t *testing.T) {
+	is := is2.New(t)
+
+	name, args, ok := ScriptCommand(`@test --filter=Foo`).Reference()
+	is.True(ok)
+	is.Equal(name, `test`)
+	is.Equal(args, `--filter=Foo`)
+
+	_, _, ok = ScriptCommand(`@php artisan`).Reference()
+	is.True(!ok)
+}
+
+func TestScript_JSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		output string
+		value  []ScriptCommand
+	}{
+		{
+			name:   `String`,
+			input:  `"phpunit"`,
+			output: `"phpunit"`,
+			value:  []ScriptCommand{"phpunit"},
+		},
+		{
+			name:   `SingleItemArray`,
+			input:  `["phpunit"]`,
+			output: `["phpunit"]`,
+			value:  []ScriptCommand{"phpunit"},
+		},
+		{
+			name:   `Array`,
+			input:  `["@lint", "@test"]`,
+			output: `["@lint","@test"]`,
+			value:  []ScriptCommand{"@lint", "@test"},
+		},
+		{
+			name:   `ObjectKeepsOrder`,
+			input:  `{"cache:clear": "symfony-cmd", "assets:install %PUBLIC_DIR%": "symfony-cmd"}`,
+			output: `{"cache:clear":"symfony-cmd","assets:install %PUBLIC_DIR%":"symfony-cmd"}`,
+			value:  []ScriptCommand{"symfony-cmd", "symfony-cmd"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			result := Script{}
+			err := json.Unmarshal([]byte(test.input), &result)
+			is.NoErr(err)
+			is.Equal(result.Commands, test.value)
+
+			data, err := json.Marshal(result)
+			is.NoErr(err)
+			is.Equal(string(data), test.output)
+		})
+	}
+}
+
+func TestScript_UnmarshalJSON_Invalid(t *testing.T) {
+	is := is2.New(t)
+
+	result := Script{}
+	is.True(json.Unmarshal([]byte(`true`), &result) != nil)
+	is.True(json.Unmarshal([]byte(`{"a": 1}`), &result) != nil)
+}
+
+func TestScripts_Resolve(t *testing.T) {
+	scripts := Scripts{
+		"lint":  NewScript("phpcs src"),
+		"test":  NewScript("@putenv XDEBUG_MODE=coverage", "phpunit"),
+		"check": NewScript("@lint", "@test --filter=Unit", "Acme\\Checks::run"),
+		"ci":    NewScript("@check", "@composer validate"),
+		"a":     NewScript("@b"),
+		"b":     NewScript("echo b", "@a"),
+		"bad":   NewScript("@missing"),
+	}
+
+	tests := []struct {
+		name     string
+		script   string
+		commands []ScriptCommand
+		err      string
+	}{
+		{
+			name:     `Simple`,
+			script:   `lint`,
+			commands: []ScriptCommand{"phpcs src"},
+		},
+		{
+			name:   `Nested`,
+			script: `ci`,
+			commands: []ScriptCommand{
+				"phpcs src",
+				"@putenv XDEBUG_MODE=coverage",
+				"phpunit --filter=Unit",
+				"Acme\\Checks::run",
+				"@composer validate",
+			},
+		},
+		{
+			name:   `Cycle`,
+			script: `a`,
+			err:    `script reference cycle: a -> b -> a`,
+		},
+		{
+			name:   `UndefinedReference`,
+			script: `bad`,
+			err:    `script "bad" references the undefined script "missing"`,
+		},
+		{
+			name:   `Undefined`,
+			script: `missing`,
+			err:    `script "missing" is not defined`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			commands, err := scripts.Resolve(test.script)
+			if test.err != "" {
+				is.True(err != nil)
+				is.Equal(err.Error(), test.err)
+				return
+			}
+			is.NoErr(err)
+			is.Equal(commands, test.commands)
+		})
+	}
+}
+
+func TestScripts_Cycles(t *testing.T) {
+	is := is2.New(t)
+
+	scripts := Scripts{
+		"a":    NewScript("@b"),
+		"b":    NewScript("@c"),
+		"c":    NewScript("@a", "@self"),
+		"self": NewScript("echo", "@self"),
+		"ok":   NewScript("@a", "@missing"),
+	}
+
+	is.Equal(scripts.Cycles(), [][]string{{"a", "b", "c"}, {"self"}})
+	is.Equal(Scripts{"ok": NewScript("@other"), "other": NewScript("ls")}.Cycles(), [][]string{})
+}
+
+func TestComposerJSON_ResolveScript(t *testing.T) {
+	is := is2.New(t)
+
+	c := ComposerJSON{}
+	err := json.Unmarshal([]byte(`{
+		"scripts": {
+			"test": "phpunit",
+			"check": ["@test"]
+		},
+		"scripts-descriptions": {
+			"test": "Run the test suite."
+		},
+		"scripts-aliases": {
+			"check": ["c", "verify"]
+		}
+	}`), &c)
+	is.NoErr(err)
+	is.Equal(c.ScriptsDescriptions["test"], "Run the test suite.")
+
+	commands, err := c.ResolveScript("verify")
+	is.NoErr(err)
+	is.Equal(commands, []ScriptCommand{"phpunit"})
+
+	commands, err = c.ResolveScript("test")
+	is.NoErr(err)
+	is.Equal(commands, []ScriptCommand{"phpunit"})
+}
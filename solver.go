@@ -0,0 +1,427 @@
+package gocomposer
+
+import "sort"
+
+// ruleKind is the reason a rule exists, which is used to describe problems.
+type ruleKind int
+
+const (
+	// ruleFixed installs the root package or a platform package.
+	ruleFixed ruleKind = iota
+	// ruleRootRequire installs one of the packages that satisfy a root requirement.
+	ruleRootRequire
+	// ruleRootConflict removes a package the root package conflicts with.
+	ruleRootConflict
+	// rulePackageRequire installs one of the packages that satisfy a requirement of an
+	// installed package.
+	rulePackageRequire
+	// rulePackageConflict removes one of two conflicting packages.
+	rulePackageConflict
+	// rulePackageAlias installs the aliased package of an installed alias.
+	rulePackageAlias
+	// ruleSameName installs at most one of the packages with the same name.
+	ruleSameName
+	// ruleLearned is derived from other rules while solving.
+	ruleLearned
+)
+
+// rule is a clause of package literals, one of which must be true. A positive literal
+// installs the package with that id and a negative literal removes it. Rules of the
+// ruleSameName kind are the exception: they hold negative literals of which all but
+// one must be true, i.e. at most one of the packages is installed.
+type rule struct {
+	id       int
+	kind     ruleKind
+	literals []int
+	disabled bool
+
+	// The package and link the rule was created for.
+	pkg  *poolPackage
+	link poolLink
+
+	// The package name of a ruleSameName rule.
+	name string
+
+	// parent is the ruleSameName rule a two literal rule was taken from.
+	parent *rule
+
+	// The rules a ruleLearned rule was derived from.
+	sources []*rule
+}
+
+// solver is a CDCL SAT solver over the rules of a pool, modeled on Composer's Solver.
+type solver struct {
+	pool         *pool
+	rules        []*rule
+	preferStable bool
+
+	// watches maps a literal to the clauses watching it. A clause watches its first
+	// two literals and is visited when one of them becomes false.
+	watches map[int][]*clause
+
+	// atMostOne maps a package id to the ruleSameName rules that contain it.
+	atMostOne map[int][]*rule
+
+	// value, level and reason are indexed by package id. A value is 1 if the package
+	// is installed, -1 if it is not and 0 if it has not been decided yet.
+	value  []int
+	level  []int
+	reason []*rule
+
+	// trail holds the true literals in the order they were assigned.
+	trail      []int
+	propagated int
+	decisions  int
+}
+
+// clause is the solver's copy of the literals of a rule, which are reordered so the
+// watched literals come first.
+type clause struct {
+	rule     *rule
+	literals []int
+}
+
+func newSolver(p *pool, rules []*rule, preferStable bool) *solver {
+	s := &solver{
+		pool:         p,
+		preferStable: preferStable,
+		watches:      make(map[int][]*clause),
+		atMostOne:    make(map[int][]*rule),
+		value:        make([]int, len(p.packages)+1),
+		level:        make([]int, len(p.packages)+1),
+		reason:       make([]*rule, len(p.packages)+1),
+	}
+	for _, r := range rules {
+		if !r.disabled {
+			s.rules = append(s.rules, r)
+		}
+	}
+	return s
+}
+
+// watch adds a rule with at least two literals to the watches.
+func (s *solver) watch(r *rule) {
+	if r.kind == ruleSameName {
+		for _, literal := range r.literals {
+			s.atMostOne[-literal] = append(s.atMostOne[-literal], r)
+		}
+		return
+	}
+	c := &clause{rule: r, literals: append([]int{}, r.literals...)}
+	s.watches[c.literals[0]] = append(s.watches[c.literals[0]], c)
+	s.watches[c.literals[1]] = append(s.watches[c.literals[1]], c)
+}
+
+// literalValue returns 1 if the literal is true, -1 if it is false and 0 if its
+// package has not been decided yet.
+func (s *solver) literalValue(literal int) int {
+	if literal < 0 {
+		return -s.value[-literal]
+	}
+	return s.value[literal]
+}
+
+func (s *solver) assign(literal int, reason *rule) {
+	id := abs(literal)
+	s.value[id] = sign(literal)
+	s.level[id] = s.decisions
+	s.reason[id] = reason
+	s.trail = append(s.trail, literal)
+}
+
+// solve decides every package. It returns nil if the rules are satisfiable, otherwise
+// it returns the rule that failed at the top decision level.
+func (s *solver) solve() *rule {
+	for _, r := range s.rules {
+		if len(r.literals) > 1 {
+			s.watch(r)
+			continue
+		}
+		switch s.literalValue(r.literals[0]) {
+		case -1:
+			return r
+		case 0:
+			s.assign(r.literals[0], r)
+		}
+	}
+
+	for {
+		if conflict := s.propagate(); conflict != nil {
+			if s.decisions == 0 {
+				return conflict
+			}
+			learned, level := s.analyze(conflict)
+			s.backtrack(level)
+			if len(learned.literals) > 1 {
+				s.watch(learned)
+			}
+			s.assign(learned.literals[0], learned)
+			continue
+		}
+
+		literal := s.selectLiteral()
+		if literal == 0 {
+			break
+		}
+		s.decisions++
+		s.assign(literal, nil)
+	}
+
+	// Packages that are still undecided are not needed.
+	for id := 1; id < len(s.value); id++ {
+		if s.value[id] == 0 {
+			s.value[id] = -1
+		}
+	}
+	return nil
+}
+
+// propagate assigns the literals implied by the literals on the trail. It returns the
+// rule that cannot be satisfied if there is a conflict.
+func (s *solver) propagate() *rule {
+	for s.propagated < len(s.trail) {
+		literal := s.trail[s.propagated]
+		s.propagated++
+
+		if literal > 0 {
+			for _, r := range s.atMostOne[literal] {
+				if conflict := s.propagateAtMostOne(r, literal); conflict != nil {
+					return conflict
+				}
+			}
+		}
+		if conflict := s.propagateWatches(-literal); conflict != nil {
+			return conflict
+		}
+	}
+	return nil
+}
+
+// propagateAtMostOne removes the other packages of a ruleSameName rule after the
+// package with the given id was installed.
+func (s *solver) propagateAtMostOne(r *rule, id int) *rule {
+	for _, literal := range r.literals {
+		other := -literal
+		switch {
+		case other == id:
+		case s.value[other] == 1:
+			return s.pair(r, id, other)
+		case s.value[other] == 0:
+			s.assign(literal, s.pair(r, id, other))
+		}
+	}
+	return nil
+}
+
+// propagateWatches visits the clauses watching a literal that just became false. Each
+// clause either finds another literal to watch, assigns its other watched literal or
+// fails.
+func (s *solver) propagateWatches(falseLiteral int) *rule {
+	watching := s.watches[falseLiteral]
+	kept := watching[:0]
+	for i, c := range watching {
+		if c.literals[0] == falseLiteral {
+			c.literals[0], c.literals[1] = c.literals[1], c.literals[0]
+		}
+		if s.literalValue(c.literals[0]) == 1 {
+			kept = append(kept, c)
+			continue
+		}
+
+		moved := false
+		for k := 2; k < len(c.literals); k++ {
+			if s.literalValue(c.literals[k]) != -1 {
+				c.literals[1], c.literals[k] = c.literals[k], c.literals[1]
+				s.watches[c.literals[1]] = append(s.watches[c.literals[1]], c)
+				moved = true
+				break
+			}
+		}
+		if moved {
+			continue
+		}
+
+		kept = append(kept, c)
+		if s.literalValue(c.literals[0]) == -1 {
+			s.watches[falseLiteral] = append(kept, watching[i+1:]...)
+			return c.rule
+		}
+		s.assign(c.literals[0], c.rule)
+	}
+	s.watches[falseLiteral] = kept
+	return nil
+}
+
+// pair returns the clause of two packages taken from a ruleSameName rule, which says
+// that they cannot both be installed.
+func (s *solver) pair(r *rule, a, b int) *rule {
+	return &rule{id: r.id, kind: ruleSameName, literals: []int{-a, -b}, parent: r}
+}
+
+// analyze finds the first unique implication point of a conflict and returns the rule
+// learned from it and the decision level to go back to. The first literal of the
+// learned rule is the one it asserts and the second one has the highest level of the
+// others, so they can be watched.
+func (s *solver) analyze(conflict *rule) (*rule, int) {
+	seen := make([]bool, len(s.value))
+	learned := &rule{kind: ruleLearned, literals: []int{0}}
+	backtrackLevel := 0
+	pending := 0
+
+	r := conflict
+	i := len(s.trail) - 1
+	for {
+		learned.sources = append(learned.sources, r)
+		for _, literal := range r.literals {
+			id := abs(literal)
+			if seen[id] || s.level[id] == 0 {
+				continue
+			}
+			seen[id] = true
+			if s.level[id] == s.decisions {
+				pending++
+				continue
+			}
+			// The literal is false, so it can be added to the learned rule as it is.
+			learned.literals = append(learned.literals, literal)
+			if s.level[id] > backtrackLevel {
+				backtrackLevel = s.level[id]
+				last := len(learned.literals) - 1
+				learned.literals[1], learned.literals[last] = learned.literals[last], learned.literals[1]
+			}
+		}
+
+		for !seen[abs(s.trail[i])] {
+			i--
+		}
+		literal := s.trail[i]
+		i--
+		pending--
+		if pending == 0 {
+			learned.literals[0] = -literal
+			return learned, backtrackLevel
+		}
+		r = s.reason[abs(literal)]
+	}
+}
+
+// backtrack undoes every assignment made above the given decision level.
+func (s *solver) backtrack(level int) {
+	for len(s.trail) > 0 {
+		id := abs(s.trail[len(s.trail)-1])
+		if s.level[id] <= level {
+			break
+		}
+		s.value[id] = 0
+		s.level[id] = 0
+		s.reason[id] = nil
+		s.trail = s.trail[:len(s.trail)-1]
+	}
+	s.propagated = len(s.trail)
+	s.decisions = level
+}
+
+// selectLiteral returns the package to install next, or 0 if every requirement is
+// satisfied. Root requirements are handled first, then the requirements of installed
+// packages in the order they were added.
+func (s *solver) selectLiteral() int {
+	for _, r := range s.rules {
+		if r.kind != ruleRootRequire && r.kind != rulePackageRequire {
+			continue
+		}
+		candidates := make([]*poolPackage, 0)
+		satisfied := false
+		for _, literal := range r.literals {
+			value := s.literalValue(literal)
+			if value == 1 {
+				satisfied = true
+				break
+			}
+			if literal > 0 && value == 0 {
+				candidates = append(candidates, s.pool.get(literal))
+			}
+		}
+		// Requirements of packages that are not installed yet are skipped, they are
+		// false if the package is not decided.
+		if satisfied || len(candidates) == 0 || (r.kind == rulePackageRequire && s.value[r.pkg.id] != 1) {
+			continue
+		}
+		return s.best(r.link.target, candidates).id
+	}
+	return 0
+}
+
+// best picks a package to satisfy a requirement on name like Composer's DefaultPolicy:
+// packages named name are preferred over packages that replace or provide it, then
+// stable packages if prefer-stable is set and finally the highest version.
+func (s *solver) best(name string, candidates []*poolPackage) *poolPackage {
+	best := candidates[0]
+	for _, pkg := range candidates[1:] {
+		if s.prefer(name, pkg, best) {
+			best = pkg
+		}
+	}
+	return best
+}
+
+// prefer returns true if a is a better choice than b for a requirement on name.
+func (s *solver) prefer(name string, a, b *poolPackage) bool {
+	if (a.name == name) != (b.name == name) {
+		return a.name == name
+	}
+	if s.preferStable && a.stability != b.stability {
+		return a.stability < b.stability
+	}
+	if a.order != b.order {
+		return a.order > b.order
+	}
+	return a.id < b.id
+}
+
+// explain returns the rules that caused a conflict at the top decision level, ordered
+// by the time they were created. Learned rules are replaced by the rules they were
+// derived from.
+func (s *solver) explain(conflict *rule) []*rule {
+	found := make(map[*rule]bool)
+	visited := make(map[*rule]bool)
+
+	var visit func(r *rule)
+	visit = func(r *rule) {
+		if visited[r] {
+			return
+		}
+		visited[r] = true
+		if r.kind == ruleLearned {
+			for _, source := range r.sources {
+				visit(source)
+			}
+		} else if r.parent != nil {
+			found[r.parent] = true
+		} else {
+			found[r] = true
+		}
+		for _, literal := range r.literals {
+			id := abs(literal)
+			if s.value[id] != 0 && s.level[id] == 0 && s.reason[id] != nil {
+				visit(s.reason[id])
+			}
+		}
+	}
+	visit(conflict)
+
+	rules := make([]*rule, 0, len(found))
+	for r := range found {
+		rules = append(rules, r)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].id < rules[j].id
+	})
+	return rules
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
@@ -0,0 +1,129 @@
+package gocomposer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// spdxIdentifier is an entry of the SPDX license or exception list.
+type spdxIdentifier struct {
+	id         string
+	deprecated bool
+}
+
+// spdxLicenseRefRegex matches user defined license references like
+// "LicenseRef-Acme" or "DocumentRef-spdx-tool-1.2:LicenseRef-MIT-Style-2".
+var spdxLicenseRefRegex = regexp.MustCompile(`^(?:DocumentRef-[A-Za-z0-9.-]+:)?LicenseRef-[A-Za-z0-9.-]+$`)
+
+// getSPDXLicense returns the SPDX license with the given identifier, which is matched
+// case-insensitively.
+func getSPDXLicense(id string) (spdxIdentifier, bool) {
+	license, ok := spdxLicenses[strings.ToLower(id)]
+	return license, ok
+}
+
+// isValidSPDXLicense returns true if license is a valid SPDX license expression, e.g.
+// "MIT", "GPL-2.0-or-later", "(LGPL-2.1-only or GPL-3.0-or-later)" or
+// "GPL-2.0-only WITH Classpath-exception-2.0". This follows the rules of the
+// composer/spdx-licenses package, so "NONE" and "NOASSERTION" are valid too.
+func isValidSPDXLicense(license string) bool {
+	if license == "NONE" || license == "NOASSERTION" {
+		return true
+	}
+	tokens := tokenizeSPDX(license)
+	if len(tokens) == 0 {
+		return false
+	}
+	p := spdxParser{tokens: tokens}
+	return p.parseExpression() && p.pos == len(p.tokens)
+}
+
+// tokenizeSPDX splits a license expression into parentheses and words. Any whitespace
+// separates words, but the expression must not start or end with whitespace.
+func tokenizeSPDX(license string) []string {
+	if strings.TrimSpace(license) != license {
+		return nil
+	}
+	tokens := make([]string, 0)
+	for _, field := range strings.Fields(license) {
+		for field != "" {
+			switch {
+			case field[0] == '(' || field[0] == ')':
+				tokens = append(tokens, field[:1])
+				field = field[1:]
+			default:
+				end := strings.IndexAny(field, "()")
+				if end < 0 {
+					end = len(field)
+				}
+				tokens = append(tokens, field[:end])
+				field = field[end:]
+			}
+		}
+	}
+	return tokens
+}
+
+// spdxParser is a recursive descent parser of SPDX license expressions.
+type spdxParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *spdxParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+// parseExpression parses terms joined by "and" or "or".
+func (p *spdxParser) parseExpression() bool {
+	if !p.parseTerm() {
+		return false
+	}
+	for {
+		switch strings.ToLower(p.peek()) {
+		case "and", "or":
+			p.pos++
+			if !p.parseTerm() {
+				return false
+			}
+		default:
+			return true
+		}
+	}
+}
+
+// parseTerm parses a parenthesized expression or a license with an optional
+// exception.
+func (p *spdxParser) parseTerm() bool {
+	if p.peek() == "(" {
+		p.pos++
+		if !p.parseExpression() || p.peek() != ")" {
+			return false
+		}
+		p.pos++
+		return true
+	}
+
+	token := p.peek()
+	if token == "" || token == ")" {
+		return false
+	}
+	p.pos++
+	if spdxLicenseRefRegex.MatchString(token) {
+		return true
+	}
+	if _, ok := getSPDXLicense(strings.TrimSuffix(token, "+")); !ok {
+		return false
+	}
+	if strings.ToLower(p.peek()) == "with" {
+		p.pos++
+		if _, ok := spdxExceptions[strings.ToLower(p.peek())]; !ok {
+			return false
+		}
+		p.pos++
+	}
+	return true
+}
@@ -0,0 +1,751 @@
+package gocomposer
+
+// The identifiers below are taken from version 3.25.0 of the SPDX license list.
+
+// spdxLicenses holds every SPDX license identifier, keyed by the lower-case identifier
+// because SPDX identifiers are matched case-insensitively.
+var spdxLicenses = map[string]spdxIdentifier{
+	"0bsd":                                 {"0BSD", false},
+	"3d-slicer-1.0":                        {"3D-Slicer-1.0", false},
+	"aal":                                  {"AAL", false},
+	"abstyles":                             {"Abstyles", false},
+	"adacore-doc":                          {"AdaCore-doc", false},
+	"adobe-2006":                           {"Adobe-2006", false},
+	"adobe-display-postscript":             {"Adobe-Display-PostScript", false},
+	"adobe-glyph":                          {"Adobe-Glyph", false},
+	"adobe-utopia":                         {"Adobe-Utopia", false},
+	"adsl":                                 {"ADSL", false},
+	"afl-1.1":                              {"AFL-1.1", false},
+	"afl-1.2":                              {"AFL-1.2", false},
+	"afl-2.0":                              {"AFL-2.0", false},
+	"afl-2.1":                              {"AFL-2.1", false},
+	"afl-3.0":                              {"AFL-3.0", false},
+	"afmparse":                             {"Afmparse", false},
+	"agpl-1.0":                             {"AGPL-1.0", true},
+	"agpl-1.0-only":                        {"AGPL-1.0-only", false},
+	"agpl-1.0-or-later":                    {"AGPL-1.0-or-later", false},
+	"agpl-3.0":                             {"AGPL-3.0", true},
+	"agpl-3.0-only":                        {"AGPL-3.0-only", false},
+	"agpl-3.0-or-later":                    {"AGPL-3.0-or-later", false},
+	"aladdin":                              {"Aladdin", false},
+	"amd-newlib":                           {"AMD-newlib", false},
+	"amdplpa":                              {"AMDPLPA", false},
+	"aml":                                  {"AML", false},
+	"aml-glslang":                          {"AML-glslang", false},
+	"ampas":                                {"AMPAS", false},
+	"antlr-pd":                             {"ANTLR-PD", false},
+	"antlr-pd-fallback":                    {"ANTLR-PD-fallback", false},
+	"any-osi":                              {"any-OSI", false},
+	"apache-1.0":                           {"Apache-1.0", false},
+	"apache-1.1":                           {"Apache-1.1", false},
+	"apache-2.0":                           {"Apache-2.0", false},
+	"apafml":                               {"APAFML", false},
+	"apl-1.0":                              {"APL-1.0", false},
+	"app-s2p":                              {"App-s2p", false},
+	"apsl-1.0":                             {"APSL-1.0", false},
+	"apsl-1.1":                             {"APSL-1.1", false},
+	"apsl-1.2":                             {"APSL-1.2", false},
+	"apsl-2.0":                             {"APSL-2.0", false},
+	"arphic-1999":                          {"Arphic-1999", false},
+	"artistic-1.0":                         {"Artistic-1.0", false},
+	"artistic-1.0-cl8":                     {"Artistic-1.0-cl8", false},
+	"artistic-1.0-perl":                    {"Artistic-1.0-Perl", false},
+	"artistic-2.0":                         {"Artistic-2.0", false},
+	"aswf-digital-assets-1.0":              {"ASWF-Digital-Assets-1.0", false},
+	"aswf-digital-assets-1.1":              {"ASWF-Digital-Assets-1.1", false},
+	"baekmuk":                              {"Baekmuk", false},
+	"bahyph":                               {"Bahyph", false},
+	"barr":                                 {"Barr", false},
+	"bcrypt-solar-designer":                {"bcrypt-Solar-Designer", false},
+	"beerware":                             {"Beerware", false},
+	"bitstream-charter":                    {"Bitstream-Charter", false},
+	"bitstream-vera":                       {"Bitstream-Vera", false},
+	"bittorrent-1.0":                       {"BitTorrent-1.0", false},
+	"bittorrent-1.1":                       {"BitTorrent-1.1", false},
+	"blessing":                             {"blessing", false},
+	"blueoak-1.0.0":                        {"BlueOak-1.0.0", false},
+	"boehm-gc":                             {"Boehm-GC", false},
+	"borceux":                              {"Borceux", false},
+	"brian-gladman-2-clause":               {"Brian-Gladman-2-Clause", false},
+	"brian-gladman-3-clause":               {"Brian-Gladman-3-Clause", false},
+	"bsd-1-clause":                         {"BSD-1-Clause", false},
+	"bsd-2-clause":                         {"BSD-2-Clause", false},
+	"bsd-2-clause-darwin":                  {"BSD-2-Clause-Darwin", false},
+	"bsd-2-clause-first-lines":             {"BSD-2-Clause-first-lines", false},
+	"bsd-2-clause-freebsd":                 {"BSD-2-Clause-FreeBSD", true},
+	"bsd-2-clause-netbsd":                  {"BSD-2-Clause-NetBSD", true},
+	"bsd-2-clause-patent":                  {"BSD-2-Clause-Patent", false},
+	"bsd-2-clause-views":                   {"BSD-2-Clause-Views", false},
+	"bsd-3-clause":                         {"BSD-3-Clause", false},
+	"bsd-3-clause-acpica":                  {"BSD-3-Clause-acpica", false},
+	"bsd-3-clause-attribution":             {"BSD-3-Clause-Attribution", false},
+	"bsd-3-clause-clear":                   {"BSD-3-Clause-Clear", false},
+	"bsd-3-clause-flex":                    {"BSD-3-Clause-flex", false},
+	"bsd-3-clause-hp":                      {"BSD-3-Clause-HP", false},
+	"bsd-3-clause-lbnl":                    {"BSD-3-Clause-LBNL", false},
+	"bsd-3-clause-modification":            {"BSD-3-Clause-Modification", false},
+	"bsd-3-clause-no-military-license":     {"BSD-3-Clause-No-Military-License", false},
+	"bsd-3-clause-no-nuclear-license":      {"BSD-3-Clause-No-Nuclear-License", false},
+	"bsd-3-clause-no-nuclear-license-2014": {"BSD-3-Clause-No-Nuclear-License-2014", false},
+	"bsd-3-clause-no-nuclear-warranty":     {"BSD-3-Clause-No-Nuclear-Warranty", false},
+	"bsd-3-clause-open-mpi":                {"BSD-3-Clause-Open-MPI", false},
+	"bsd-3-clause-sun":                     {"BSD-3-Clause-Sun", false},
+	"bsd-4-clause":                         {"BSD-4-Clause", false},
+	"bsd-4-clause-shortened":               {"BSD-4-Clause-Shortened", false},
+	"bsd-4-clause-uc":                      {"BSD-4-Clause-UC", false},
+	"bsd-4.3reno":                          {"BSD-4.3RENO", false},
+	"bsd-4.3tahoe":                         {"BSD-4.3TAHOE", false},
+	"bsd-advertising-acknowledgement":      {"BSD-Advertising-Acknowledgement", false},
+	"bsd-attribution-hpnd-disclaimer":      {"BSD-Attribution-HPND-disclaimer", false},
+	"bsd-inferno-nettverk":                 {"BSD-Inferno-Nettverk", false},
+	"bsd-protection":                       {"BSD-Protection", false},
+	"bsd-source-beginning-file":            {"BSD-Source-beginning-file", false},
+	"bsd-source-code":                      {"BSD-Source-Code", false},
+	"bsd-systemics":                        {"BSD-Systemics", false},
+	"bsd-systemics-w3works":                {"BSD-Systemics-W3Works", false},
+	"bsl-1.0":                              {"BSL-1.0", false},
+	"busl-1.1":                             {"BUSL-1.1", false},
+	"bzip2-1.0.5":                          {"bzip2-1.0.5", true},
+	"bzip2-1.0.6":                          {"bzip2-1.0.6", false},
+	"c-uda-1.0":                            {"C-UDA-1.0", false},
+	"cal-1.0":                              {"CAL-1.0", false},
+	"cal-1.0-combined-work-exception":      {"CAL-1.0-Combined-Work-Exception", false},
+	"caldera":                              {"Caldera", false},
+	"caldera-no-preamble":                  {"Caldera-no-preamble", false},
+	"catharon":                             {"Catharon", false},
+	"catosl-1.1":                           {"CATOSL-1.1", false},
+	"cc-by-1.0":                            {"CC-BY-1.0", false},
+	"cc-by-2.0":                            {"CC-BY-2.0", false},
+	"cc-by-2.5":                            {"CC-BY-2.5", false},
+	"cc-by-2.5-au":                         {"CC-BY-2.5-AU", false},
+	"cc-by-3.0":                            {"CC-BY-3.0", false},
+	"cc-by-3.0-at":                         {"CC-BY-3.0-AT", false},
+	"cc-by-3.0-au":                         {"CC-BY-3.0-AU", false},
+	"cc-by-3.0-de":                         {"CC-BY-3.0-DE", false},
+	"cc-by-3.0-igo":                        {"CC-BY-3.0-IGO", false},
+	"cc-by-3.0-nl":                         {"CC-BY-3.0-NL", false},
+	"cc-by-3.0-us":                         {"CC-BY-3.0-US", false},
+	"cc-by-4.0":                            {"CC-BY-4.0", false},
+	"cc-by-nc-1.0":                         {"CC-BY-NC-1.0", false},
+	"cc-by-nc-2.0":                         {"CC-BY-NC-2.0", false},
+	"cc-by-nc-2.5":                         {"CC-BY-NC-2.5", false},
+	"cc-by-nc-3.0":                         {"CC-BY-NC-3.0", false},
+	"cc-by-nc-3.0-de":                      {"CC-BY-NC-3.0-DE", false},
+	"cc-by-nc-4.0":                         {"CC-BY-NC-4.0", false},
+	"cc-by-nc-nd-1.0":                      {"CC-BY-NC-ND-1.0", false},
+	"cc-by-nc-nd-2.0":                      {"CC-BY-NC-ND-2.0", false},
+	"cc-by-nc-nd-2.5":                      {"CC-BY-NC-ND-2.5", false},
+	"cc-by-nc-nd-3.0":                      {"CC-BY-NC-ND-3.0", false},
+	"cc-by-nc-nd-3.0-de":                   {"CC-BY-NC-ND-3.0-DE", false},
+	"cc-by-nc-nd-3.0-igo":                  {"CC-BY-NC-ND-3.0-IGO", false},
+	"cc-by-nc-nd-4.0":                      {"CC-BY-NC-ND-4.0", false},
+	"cc-by-nc-sa-1.0":                      {"CC-BY-NC-SA-1.0", false},
+	"cc-by-nc-sa-2.0":                      {"CC-BY-NC-SA-2.0", false},
+	"cc-by-nc-sa-2.0-de":                   {"CC-BY-NC-SA-2.0-DE", false},
+	"cc-by-nc-sa-2.0-fr":                   {"CC-BY-NC-SA-2.0-FR", false},
+	"cc-by-nc-sa-2.0-uk":                   {"CC-BY-NC-SA-2.0-UK", false},
+	"cc-by-nc-sa-2.5":                      {"CC-BY-NC-SA-2.5", false},
+	"cc-by-nc-sa-3.0":                      {"CC-BY-NC-SA-3.0", false},
+	"cc-by-nc-sa-3.0-de":                   {"CC-BY-NC-SA-3.0-DE", false},
+	"cc-by-nc-sa-3.0-igo":                  {"CC-BY-NC-SA-3.0-IGO", false},
+	"cc-by-nc-sa-4.0":                      {"CC-BY-NC-SA-4.0", false},
+	"cc-by-nd-1.0":                         {"CC-BY-ND-1.0", false},
+	"cc-by-nd-2.0":                         {"CC-BY-ND-2.0", false},
+	"cc-by-nd-2.5":                         {"CC-BY-ND-2.5", false},
+	"cc-by-nd-3.0":                         {"CC-BY-ND-3.0", false},
+	"cc-by-nd-3.0-de":                      {"CC-BY-ND-3.0-DE", false},
+	"cc-by-nd-4.0":                         {"CC-BY-ND-4.0", false},
+	"cc-by-sa-1.0":                         {"CC-BY-SA-1.0", false},
+	"cc-by-sa-2.0":                         {"CC-BY-SA-2.0", false},
+	"cc-by-sa-2.0-uk":                      {"CC-BY-SA-2.0-UK", false},
+	"cc-by-sa-2.1-jp":                      {"CC-BY-SA-2.1-JP", false},
+	"cc-by-sa-2.5":                         {"CC-BY-SA-2.5", false},
+	"cc-by-sa-3.0":                         {"CC-BY-SA-3.0", false},
+	"cc-by-sa-3.0-at":                      {"CC-BY-SA-3.0-AT", false},
+	"cc-by-sa-3.0-de":                      {"CC-BY-SA-3.0-DE", false},
+	"cc-by-sa-3.0-igo":                     {"CC-BY-SA-3.0-IGO", false},
+	"cc-by-sa-4.0":                         {"CC-BY-SA-4.0", false},
+	"cc-pddc":                              {"CC-PDDC", false},
+	"cc0-1.0":                              {"CC0-1.0", false},
+	"cddl-1.0":                             {"CDDL-1.0", false},
+	"cddl-1.1":                             {"CDDL-1.1", false},
+	"cdl-1.0":                              {"CDL-1.0", false},
+	"cdla-permissive-1.0":                  {"CDLA-Permissive-1.0", false},
+	"cdla-permissive-2.0":                  {"CDLA-Permissive-2.0", false},
+	"cdla-sharing-1.0":                     {"CDLA-Sharing-1.0", false},
+	"cecill-1.0":                           {"CECILL-1.0", false},
+	"cecill-1.1":                           {"CECILL-1.1", false},
+	"cecill-2.0":                           {"CECILL-2.0", false},
+	"cecill-2.1":                           {"CECILL-2.1", false},
+	"cecill-b":                             {"CECILL-B", false},
+	"cecill-c":                             {"CECILL-C", false},
+	"cern-ohl-1.1":                         {"CERN-OHL-1.1", false},
+	"cern-ohl-1.2":                         {"CERN-OHL-1.2", false},
+	"cern-ohl-p-2.0":                       {"CERN-OHL-P-2.0", false},
+	"cern-ohl-s-2.0":                       {"CERN-OHL-S-2.0", false},
+	"cern-ohl-w-2.0":                       {"CERN-OHL-W-2.0", false},
+	"cfitsio":                              {"CFITSIO", false},
+	"check-cvs":                            {"check-cvs", false},
+	"checkmk":                              {"checkmk", false},
+	"clartistic":                           {"ClArtistic", false},
+	"clips":                                {"Clips", false},
+	"cmu-mach":                             {"CMU-Mach", false},
+	"cmu-mach-nodoc":                       {"CMU-Mach-nodoc", false},
+	"cnri-jython":                          {"CNRI-Jython", false},
+	"cnri-python":                          {"CNRI-Python", false},
+	"cnri-python-gpl-compatible":           {"CNRI-Python-GPL-Compatible", false},
+	"coil-1.0":                             {"COIL-1.0", false},
+	"community-spec-1.0":                   {"Community-Spec-1.0", false},
+	"condor-1.1":                           {"Condor-1.1", false},
+	"copyleft-next-0.3.0":                  {"copyleft-next-0.3.0", false},
+	"copyleft-next-0.3.1":                  {"copyleft-next-0.3.1", false},
+	"cornell-lossless-jpeg":                {"Cornell-Lossless-JPEG", false},
+	"cpal-1.0":                             {"CPAL-1.0", false},
+	"cpl-1.0":                              {"CPL-1.0", false},
+	"cpol-1.02":                            {"CPOL-1.02", false},
+	"cronyx":                               {"Cronyx", false},
+	"crossword":                            {"Crossword", false},
+	"crystalstacker":                       {"CrystalStacker", false},
+	"cua-opl-1.0":                          {"CUA-OPL-1.0", false},
+	"cube":                                 {"Cube", false},
+	"curl":                                 {"curl", false},
+	"cve-tou":                              {"cve-tou", false},
+	"d-fsl-1.0":                            {"D-FSL-1.0", false},
+	"dec-3-clause":                         {"DEC-3-Clause", false},
+	"diffmark":                             {"diffmark", false},
+	"dl-de-by-2.0":                         {"DL-DE-BY-2.0", false},
+	"dl-de-zero-2.0":                       {"DL-DE-ZERO-2.0", false},
+	"doc":                                  {"DOC", false},
+	"docbook-schema":                       {"DocBook-Schema", false},
+	"docbook-xml":                          {"DocBook-XML", false},
+	"dotseqn":                              {"Dotseqn", false},
+	"drl-1.0":                              {"DRL-1.0", false},
+	"drl-1.1":                              {"DRL-1.1", false},
+	"dsdp":                                 {"DSDP", false},
+	"dtoa":                                 {"dtoa", false},
+	"dvipdfm":                              {"dvipdfm", false},
+	"ecl-1.0":                              {"ECL-1.0", false},
+	"ecl-2.0":                              {"ECL-2.0", false},
+	"ecos-2.0":                             {"eCos-2.0", true},
+	"efl-1.0":                              {"EFL-1.0", false},
+	"efl-2.0":                              {"EFL-2.0", false},
+	"egenix":                               {"eGenix", false},
+	"elastic-2.0":                          {"Elastic-2.0", false},
+	"entessa":                              {"Entessa", false},
+	"epics":                                {"EPICS", false},
+	"epl-1.0":                              {"EPL-1.0", false},
+	"epl-2.0":                              {"EPL-2.0", false},
+	"erlpl-1.1":                            {"ErlPL-1.1", false},
+	"etalab-2.0":                           {"etalab-2.0", false},
+	"eudatagrid":                           {"EUDatagrid", false},
+	"eupl-1.0":                             {"EUPL-1.0", false},
+	"eupl-1.1":                             {"EUPL-1.1", false},
+	"eupl-1.2":                             {"EUPL-1.2", false},
+	"eurosym":                              {"Eurosym", false},
+	"fair":                                 {"Fair", false},
+	"fbm":                                  {"FBM", false},
+	"fdk-aac":                              {"FDK-AAC", false},
+	"ferguson-twofish":                     {"Ferguson-Twofish", false},
+	"frameworx-1.0":                        {"Frameworx-1.0", false},
+	"freebsd-doc":                          {"FreeBSD-DOC", false},
+	"freeimage":                            {"FreeImage", false},
+	"fsfap":                                {"FSFAP", false},
+	"fsfap-no-warranty-disclaimer":         {"FSFAP-no-warranty-disclaimer", false},
+	"fsful":                                {"FSFUL", false},
+	"fsfullr":                              {"FSFULLR", false},
+	"fsfullrwd":                            {"FSFULLRWD", false},
+	"ftl":                                  {"FTL", false},
+	"furuseth":                             {"Furuseth", false},
+	"fwlw":                                 {"fwlw", false},
+	"gcr-docs":                             {"GCR-docs", false},
+	"gd":                                   {"GD", false},
+	"gfdl-1.1":                             {"GFDL-1.1", true},
+	"gfdl-1.1-invariants-only":             {"GFDL-1.1-invariants-only", false},
+	"gfdl-1.1-invariants-or-later":         {"GFDL-1.1-invariants-or-later", false},
+	"gfdl-1.1-no-invariants-only":          {"GFDL-1.1-no-invariants-only", false},
+	"gfdl-1.1-no-invariants-or-later":      {"GFDL-1.1-no-invariants-or-later", false},
+	"gfdl-1.1-only":                        {"GFDL-1.1-only", false},
+	"gfdl-1.1-or-later":                    {"GFDL-1.1-or-later", false},
+	"gfdl-1.2":                             {"GFDL-1.2", true},
+	"gfdl-1.2-invariants-only":             {"GFDL-1.2-invariants-only", false},
+	"gfdl-1.2-invariants-or-later":         {"GFDL-1.2-invariants-or-later", false},
+	"gfdl-1.2-no-invariants-only":          {"GFDL-1.2-no-invariants-only", false},
+	"gfdl-1.2-no-invariants-or-later":      {"GFDL-1.2-no-invariants-or-later", false},
+	"gfdl-1.2-only":                        {"GFDL-1.2-only", false},
+	"gfdl-1.2-or-later":                    {"GFDL-1.2-or-later", false},
+	"gfdl-1.3":                             {"GFDL-1.3", true},
+	"gfdl-1.3-invariants-only":             {"GFDL-1.3-invariants-only", false},
+	"gfdl-1.3-invariants-or-later":         {"GFDL-1.3-invariants-or-later", false},
+	"gfdl-1.3-no-invariants-only":          {"GFDL-1.3-no-invariants-only", false},
+	"gfdl-1.3-no-invariants-or-later":      {"GFDL-1.3-no-invariants-or-later", false},
+	"gfdl-1.3-only":                        {"GFDL-1.3-only", false},
+	"gfdl-1.3-or-later":                    {"GFDL-1.3-or-later", false},
+	"giftware":                             {"Giftware", false},
+	"gl2ps":                                {"GL2PS", false},
+	"glide":                                {"Glide", false},
+	"glulxe":                               {"Glulxe", false},
+	"glwtpl":                               {"GLWTPL", false},
+	"gnuplot":                              {"gnuplot", false},
+	"gpl-1.0":                              {"GPL-1.0", true},
+	"gpl-1.0+":                             {"GPL-1.0+", true},
+	"gpl-1.0-only":                         {"GPL-1.0-only", false},
+	"gpl-1.0-or-later":                     {"GPL-1.0-or-later", false},
+	"gpl-2.0":                              {"GPL-2.0", true},
+	"gpl-2.0+":                             {"GPL-2.0+", true},
+	"gpl-2.0-only":                         {"GPL-2.0-only", false},
+	"gpl-2.0-or-later":                     {"GPL-2.0-or-later", false},
+	"gpl-2.0-with-autoconf-exception":      {"GPL-2.0-with-autoconf-exception", true},
+	"gpl-2.0-with-bison-exception":         {"GPL-2.0-with-bison-exception", true},
+	"gpl-2.0-with-classpath-exception":     {"GPL-2.0-with-classpath-exception", true},
+	"gpl-2.0-with-font-exception":          {"GPL-2.0-with-font-exception", true},
+	"gpl-2.0-with-gcc-exception":           {"GPL-2.0-with-GCC-exception", true},
+	"gpl-3.0":                              {"GPL-3.0", true},
+	"gpl-3.0+":                             {"GPL-3.0+", true},
+	"gpl-3.0-only":                         {"GPL-3.0-only", false},
+	"gpl-3.0-or-later":                     {"GPL-3.0-or-later", false},
+	"gpl-3.0-with-autoconf-exception":      {"GPL-3.0-with-autoconf-exception", true},
+	"gpl-3.0-with-gcc-exception":           {"GPL-3.0-with-GCC-exception", true},
+	"graphics-gems":                        {"Graphics-Gems", false},
+	"gsoap-1.3b":                           {"gSOAP-1.3b", false},
+	"gtkbook":                              {"gtkbook", false},
+	"gutmann":                              {"Gutmann", false},
+	"haskellreport":                        {"HaskellReport", false},
+	"hdparm":                               {"hdparm", false},
+	"hidapi":                               {"HIDAPI", false},
+	"hippocratic-2.1":                      {"Hippocratic-2.1", false},
+	"hp-1986":                              {"HP-1986", false},
+	"hp-1989":                              {"HP-1989", false},
+	"hpnd":                                 {"HPND", false},
+	"hpnd-dec":                             {"HPND-DEC", false},
+	"hpnd-doc":                             {"HPND-doc", false},
+	"hpnd-doc-sell":                        {"HPND-doc-sell", false},
+	"hpnd-export-us":                       {"HPND-export-US", false},
+	"hpnd-export-us-acknowledgement":       {"HPND-export-US-acknowledgement", false},
+	"hpnd-export-us-modify":                {"HPND-export-US-modify", false},
+	"hpnd-export2-us":                      {"HPND-export2-US", false},
+	"hpnd-fenneberg-livingston":            {"HPND-Fenneberg-Livingston", false},
+	"hpnd-inria-imag":                      {"HPND-INRIA-IMAG", false},
+	"hpnd-intel":                           {"HPND-Intel", false},
+	"hpnd-kevlin-henney":                   {"HPND-Kevlin-Henney", false},
+	"hpnd-markus-kuhn":                     {"HPND-Markus-Kuhn", false},
+	"hpnd-merchantability-variant":         {"HPND-merchantability-variant", false},
+	"hpnd-mit-disclaimer":                  {"HPND-MIT-disclaimer", false},
+	"hpnd-netrek":                          {"HPND-Netrek", false},
+	"hpnd-pbmplus":                         {"HPND-Pbmplus", false},
+	"hpnd-sell-mit-disclaimer-xserver":     {"HPND-sell-MIT-disclaimer-xserver", false},
+	"hpnd-sell-regexpr":                    {"HPND-sell-regexpr", false},
+	"hpnd-sell-variant":                    {"HPND-sell-variant", false},
+	"hpnd-sell-variant-mit-disclaimer":     {"HPND-sell-variant-MIT-disclaimer", false},
+	"hpnd-sell-variant-mit-disclaimer-rev": {"HPND-sell-variant-MIT-disclaimer-rev", false},
+	"hpnd-uc":                              {"HPND-UC", false},
+	"hpnd-uc-export-us":                    {"HPND-UC-export-US", false},
+	"htmltidy":                             {"HTMLTIDY", false},
+	"ibm-pibs":                             {"IBM-pibs", false},
+	"icu":                                  {"ICU", false},
+	"iec-code-components-eula":             {"IEC-Code-Components-EULA", false},
+	"ijg":                                  {"IJG", false},
+	"ijg-short":                            {"IJG-short", false},
+	"imagemagick":                          {"ImageMagick", false},
+	"imatix":                               {"iMatix", false},
+	"imlib2":                               {"Imlib2", false},
+	"info-zip":                             {"Info-ZIP", false},
+	"inner-net-2.0":                        {"Inner-Net-2.0", false},
+	"intel":                                {"Intel", false},
+	"intel-acpi":                           {"Intel-ACPI", false},
+	"interbase-1.0":                        {"Interbase-1.0", false},
+	"ipa":                                  {"IPA", false},
+	"ipl-1.0":                              {"IPL-1.0", false},
+	"isc":                                  {"ISC", false},
+	"isc-veillard":                         {"ISC-Veillard", false},
+	"jam":                                  {"Jam", false},
+	"jasper-2.0":                           {"JasPer-2.0", false},
+	"jpl-image":                            {"JPL-image", false},
+	"jpnic":                                {"JPNIC", false},
+	"json":                                 {"JSON", false},
+	"kastrup":                              {"Kastrup", false},
+	"kazlib":                               {"Kazlib", false},
+	"knuth-ctan":                           {"Knuth-CTAN", false},
+	"lal-1.2":                              {"LAL-1.2", false},
+	"lal-1.3":                              {"LAL-1.3", false},
+	"latex2e":                              {"Latex2e", false},
+	"latex2e-translated-notice":            {"Latex2e-translated-notice", false},
+	"leptonica":                            {"Leptonica", false},
+	"lgpl-2.0":                             {"LGPL-2.0", true},
+	"lgpl-2.0+":                            {"LGPL-2.0+", true},
+	"lgpl-2.0-only":                        {"LGPL-2.0-only", false},
+	"lgpl-2.0-or-later":                    {"LGPL-2.0-or-later", false},
+	"lgpl-2.1":                             {"LGPL-2.1", true},
+	"lgpl-2.1+":                            {"LGPL-2.1+", true},
+	"lgpl-2.1-only":                        {"LGPL-2.1-only", false},
+	"lgpl-2.1-or-later":                    {"LGPL-2.1-or-later", false},
+	"lgpl-3.0":                             {"LGPL-3.0", true},
+	"lgpl-3.0+":                            {"LGPL-3.0+", true},
+	"lgpl-3.0-only":                        {"LGPL-3.0-only", false},
+	"lgpl-3.0-or-later":                    {"LGPL-3.0-or-later", false},
+	"lgpllr":                               {"LGPLLR", false},
+	"libpng":                               {"Libpng", false},
+	"libpng-2.0":                           {"libpng-2.0", false},
+	"libselinux-1.0":                       {"libselinux-1.0", false},
+	"libtiff":                              {"libtiff", false},
+	"libutil-david-nugent":                 {"libutil-David-Nugent", false},
+	"liliq-p-1.1":                          {"LiLiQ-P-1.1", false},
+	"liliq-r-1.1":                          {"LiLiQ-R-1.1", false},
+	"liliq-rplus-1.1":                      {"LiLiQ-Rplus-1.1", false},
+	"linux-man-pages-1-para":               {"Linux-man-pages-1-para", false},
+	"linux-man-pages-copyleft":             {"Linux-man-pages-copyleft", false},
+	"linux-man-pages-copyleft-2-para":      {"Linux-man-pages-copyleft-2-para", false},
+	"linux-man-pages-copyleft-var":         {"Linux-man-pages-copyleft-var", false},
+	"linux-openib":                         {"Linux-OpenIB", false},
+	"loop":                                 {"LOOP", false},
+	"lpd-document":                         {"LPD-document", false},
+	"lpl-1.0":                              {"LPL-1.0", false},
+	"lpl-1.02":                             {"LPL-1.02", false},
+	"lppl-1.0":                             {"LPPL-1.0", false},
+	"lppl-1.1":                             {"LPPL-1.1", false},
+	"lppl-1.2":                             {"LPPL-1.2", false},
+	"lppl-1.3a":                            {"LPPL-1.3a", false},
+	"lppl-1.3c":                            {"LPPL-1.3c", false},
+	"lsof":                                 {"lsof", false},
+	"lucida-bitmap-fonts":                  {"Lucida-Bitmap-Fonts", false},
+	"lzma-sdk-9.11-to-9.20":                {"LZMA-SDK-9.11-to-9.20", false},
+	"lzma-sdk-9.22":                        {"LZMA-SDK-9.22", false},
+	"mackerras-3-clause":                   {"Mackerras-3-Clause", false},
+	"mackerras-3-clause-acknowledgment":    {"Mackerras-3-Clause-acknowledgment", false},
+	"magaz":                                {"magaz", false},
+	"mailprio":                             {"mailprio", false},
+	"makeindex":                            {"MakeIndex", false},
+	"martin-birgmeier":                     {"Martin-Birgmeier", false},
+	"mcphee-slideshow":                     {"McPhee-slideshow", false},
+	"metamail":                             {"metamail", false},
+	"minpack":                              {"Minpack", false},
+	"miros":                                {"MirOS", false},
+	"mit":                                  {"MIT", false},
+	"mit-0":                                {"MIT-0", false},
+	"mit-advertising":                      {"MIT-advertising", false},
+	"mit-cmu":                              {"MIT-CMU", false},
+	"mit-enna":                             {"MIT-enna", false},
+	"mit-feh":                              {"MIT-feh", false},
+	"mit-festival":                         {"MIT-Festival", false},
+	"mit-khronos-old":                      {"MIT-Khronos-old", false},
+	"mit-modern-variant":                   {"MIT-Modern-Variant", false},
+	"mit-open-group":                       {"MIT-open-group", false},
+	"mit-testregex":                        {"MIT-testregex", false},
+	"mit-wu":                               {"MIT-Wu", false},
+	"mitnfa":                               {"MITNFA", false},
+	"mmixware":                             {"MMIXware", false},
+	"motosoto":                             {"Motosoto", false},
+	"mpeg-ssg":                             {"MPEG-SSG", false},
+	"mpi-permissive":                       {"mpi-permissive", false},
+	"mpich2":                               {"mpich2", false},
+	"mpl-1.0":                              {"MPL-1.0", false},
+	"mpl-1.1":                              {"MPL-1.1", false},
+	"mpl-2.0":                              {"MPL-2.0", false},
+	"mpl-2.0-no-copyleft-exception":        {"MPL-2.0-no-copyleft-exception", false},
+	"mplus":                                {"mplus", false},
+	"ms-lpl":                               {"MS-LPL", false},
+	"ms-pl":                                {"MS-PL", false},
+	"ms-rl":                                {"MS-RL", false},
+	"mtll":                                 {"MTLL", false},
+	"mulanpsl-1.0":                         {"MulanPSL-1.0", false},
+	"mulanpsl-2.0":                         {"MulanPSL-2.0", false},
+	"multics":                              {"Multics", false},
+	"mup":                                  {"Mup", false},
+	"naist-2003":                           {"NAIST-2003", false},
+	"nasa-1.3":                             {"NASA-1.3", false},
+	"naumen":                               {"Naumen", false},
+	"nbpl-1.0":                             {"NBPL-1.0", false},
+	"ncbi-pd":                              {"NCBI-PD", false},
+	"ncgl-uk-2.0":                          {"NCGL-UK-2.0", false},
+	"ncl":                                  {"NCL", false},
+	"ncsa":                                 {"NCSA", false},
+	"net-snmp":                             {"Net-SNMP", true},
+	"netcdf":                               {"NetCDF", false},
+	"newsletr":                             {"Newsletr", false},
+	"ngpl":                                 {"NGPL", false},
+	"nicta-1.0":                            {"NICTA-1.0", false},
+	"nist-pd":                              {"NIST-PD", false},
+	"nist-pd-fallback":                     {"NIST-PD-fallback", false},
+	"nist-software":                        {"NIST-Software", false},
+	"nlod-1.0":                             {"NLOD-1.0", false},
+	"nlod-2.0":                             {"NLOD-2.0", false},
+	"nlpl":                                 {"NLPL", false},
+	"nokia":                                {"Nokia", false},
+	"nosl":                                 {"NOSL", false},
+	"noweb":                                {"Noweb", false},
+	"npl-1.0":                              {"NPL-1.0", false},
+	"npl-1.1":                              {"NPL-1.1", false},
+	"nposl-3.0":                            {"NPOSL-3.0", false},
+	"nrl":                                  {"NRL", false},
+	"ntp":                                  {"NTP", false},
+	"ntp-0":                                {"NTP-0", false},
+	"nunit":                                {"Nunit", true},
+	"o-uda-1.0":                            {"O-UDA-1.0", false},
+	"oar":                                  {"OAR", false},
+	"occt-pl":                              {"OCCT-PL", false},
+	"oclc-2.0":                             {"OCLC-2.0", false},
+	"odbl-1.0":                             {"ODbL-1.0", false},
+	"odc-by-1.0":                           {"ODC-By-1.0", false},
+	"offis":                                {"OFFIS", false},
+	"ofl-1.0":                              {"OFL-1.0", false},
+	"ofl-1.0-no-rfn":                       {"OFL-1.0-no-RFN", false},
+	"ofl-1.0-rfn":                          {"OFL-1.0-RFN", false},
+	"ofl-1.1":                              {"OFL-1.1", false},
+	"ofl-1.1-no-rfn":                       {"OFL-1.1-no-RFN", false},
+	"ofl-1.1-rfn":                          {"OFL-1.1-RFN", false},
+	"ogc-1.0":                              {"OGC-1.0", false},
+	"ogdl-taiwan-1.0":                      {"OGDL-Taiwan-1.0", false},
+	"ogl-canada-2.0":                       {"OGL-Canada-2.0", false},
+	"ogl-uk-1.0":                           {"OGL-UK-1.0", false},
+	"ogl-uk-2.0":                           {"OGL-UK-2.0", false},
+	"ogl-uk-3.0":                           {"OGL-UK-3.0", false},
+	"ogtsl":                                {"OGTSL", false},
+	"oldap-1.1":                            {"OLDAP-1.1", false},
+	"oldap-1.2":                            {"OLDAP-1.2", false},
+	"oldap-1.3":                            {"OLDAP-1.3", false},
+	"oldap-1.4":                            {"OLDAP-1.4", false},
+	"oldap-2.0":                            {"OLDAP-2.0", false},
+	"oldap-2.0.1":                          {"OLDAP-2.0.1", false},
+	"oldap-2.1":                            {"OLDAP-2.1", false},
+	"oldap-2.2":                            {"OLDAP-2.2", false},
+	"oldap-2.2.1":                          {"OLDAP-2.2.1", false},
+	"oldap-2.2.2":                          {"OLDAP-2.2.2", false},
+	"oldap-2.3":                            {"OLDAP-2.3", false},
+	"oldap-2.4":                            {"OLDAP-2.4", false},
+	"oldap-2.5":                            {"OLDAP-2.5", false},
+	"oldap-2.6":                            {"OLDAP-2.6", false},
+	"oldap-2.7":                            {"OLDAP-2.7", false},
+	"oldap-2.8":                            {"OLDAP-2.8", false},
+	"olfl-1.3":                             {"OLFL-1.3", false},
+	"oml":                                  {"OML", false},
+	"openpbs-2.3":                          {"OpenPBS-2.3", false},
+	"openssl":                              {"OpenSSL", false},
+	"openssl-standalone":                   {"OpenSSL-standalone", false},
+	"openvision":                           {"OpenVision", false},
+	"opl-1.0":                              {"OPL-1.0", false},
+	"opl-uk-3.0":                           {"OPL-UK-3.0", false},
+	"opubl-1.0":                            {"OPUBL-1.0", false},
+	"oset-pl-2.1":                          {"OSET-PL-2.1", false},
+	"osl-1.0":                              {"OSL-1.0", false},
+	"osl-1.1":                              {"OSL-1.1", false},
+	"osl-2.0":                              {"OSL-2.0", false},
+	"osl-2.1":                              {"OSL-2.1", false},
+	"osl-3.0":                              {"OSL-3.0", false},
+	"padl":                                 {"PADL", false},
+	"parity-6.0.0":                         {"Parity-6.0.0", false},
+	"parity-7.0.0":                         {"Parity-7.0.0", false},
+	"pddl-1.0":                             {"PDDL-1.0", false},
+	"php-3.0":                              {"PHP-3.0", false},
+	"php-3.01":                             {"PHP-3.01", false},
+	"pixar":                                {"Pixar", false},
+	"pkgconf":                              {"pkgconf", false},
+	"plexus":                               {"Plexus", false},
+	"pnmstitch":                            {"pnmstitch", false},
+	"polyform-noncommercial-1.0.0":         {"PolyForm-Noncommercial-1.0.0", false},
+	"polyform-small-business-1.0.0":        {"PolyForm-Small-Business-1.0.0", false},
+	"postgresql":                           {"PostgreSQL", false},
+	"ppl":                                  {"PPL", false},
+	"psf-2.0":                              {"PSF-2.0", false},
+	"psfrag":                               {"psfrag", false},
+	"psutils":                              {"psutils", false},
+	"python-2.0":                           {"Python-2.0", false},
+	"python-2.0.1":                         {"Python-2.0.1", false},
+	"python-ldap":                          {"python-ldap", false},
+	"qhull":                                {"Qhull", false},
+	"qpl-1.0":                              {"QPL-1.0", false},
+	"qpl-1.0-inria-2004":                   {"QPL-1.0-INRIA-2004", false},
+	"radvd":                                {"radvd", false},
+	"rdisc":                                {"Rdisc", false},
+	"rhecos-1.1":                           {"RHeCos-1.1", false},
+	"rpl-1.1":                              {"RPL-1.1", false},
+	"rpl-1.5":                              {"RPL-1.5", false},
+	"rpsl-1.0":                             {"RPSL-1.0", false},
+	"rsa-md":                               {"RSA-MD", false},
+	"rscpl":                                {"RSCPL", false},
+	"ruby":                                 {"Ruby", false},
+	"ruby-pty":                             {"Ruby-pty", false},
+	"sax-pd":                               {"SAX-PD", false},
+	"sax-pd-2.0":                           {"SAX-PD-2.0", false},
+	"saxpath":                              {"Saxpath", false},
+	"scea":                                 {"SCEA", false},
+	"schemereport":                         {"SchemeReport", false},
+	"sendmail":                             {"Sendmail", false},
+	"sendmail-8.23":                        {"Sendmail-8.23", false},
+	"sgi-b-1.0":                            {"SGI-B-1.0", false},
+	"sgi-b-1.1":                            {"SGI-B-1.1", false},
+	"sgi-b-2.0":                            {"SGI-B-2.0", false},
+	"sgi-opengl":                           {"SGI-OpenGL", false},
+	"sgp4":                                 {"SGP4", false},
+	"shl-0.5":                              {"SHL-0.5", false},
+	"shl-0.51":                             {"SHL-0.51", false},
+	"simpl-2.0":                            {"SimPL-2.0", false},
+	"sissl":                                {"SISSL", false},
+	"sissl-1.2":                            {"SISSL-1.2", false},
+	"sl":                                   {"SL", false},
+	"sleepycat":                            {"Sleepycat", false},
+	"smlnj":                                {"SMLNJ", false},
+	"smppl":                                {"SMPPL", false},
+	"snia":                                 {"SNIA", false},
+	"snprintf":                             {"snprintf", false},
+	"softsurfer":                           {"softSurfer", false},
+	"soundex":                              {"Soundex", false},
+	"spencer-86":                           {"Spencer-86", false},
+	"spencer-94":                           {"Spencer-94", false},
+	"spencer-99":                           {"Spencer-99", false},
+	"spl-1.0":                              {"SPL-1.0", false},
+	"ssh-keyscan":                          {"ssh-keyscan", false},
+	"ssh-openssh":                          {"SSH-OpenSSH", false},
+	"ssh-short":                            {"SSH-short", false},
+	"ssleay-standalone":                    {"SSLeay-standalone", false},
+	"sspl-1.0":                             {"SSPL-1.0", false},
+	"standardml-nj":                        {"StandardML-NJ", true},
+	"sugarcrm-1.1.3":                       {"SugarCRM-1.1.3", false},
+	"sun-ppp":                              {"Sun-PPP", false},
+	"sun-ppp-2000":                         {"Sun-PPP-2000", false},
+	"sunpro":                               {"SunPro", false},
+	"swl":                                  {"SWL", false},
+	"swrule":                               {"swrule", false},
+	"symlinks":                             {"Symlinks", false},
+	"tapr-ohl-1.0":                         {"TAPR-OHL-1.0", false},
+	"tcl":                                  {"TCL", false},
+	"tcp-wrappers":                         {"TCP-wrappers", false},
+	"termreadkey":                          {"TermReadKey", false},
+	"tgppl-1.0":                            {"TGPPL-1.0", false},
+	"threeparttable":                       {"threeparttable", false},
+	"tmate":                                {"TMate", false},
+	"torque-1.1":                           {"TORQUE-1.1", false},
+	"tosl":                                 {"TOSL", false},
+	"tpdl":                                 {"TPDL", false},
+	"tpl-1.0":                              {"TPL-1.0", false},
+	"ttwl":                                 {"TTWL", false},
+	"ttyp0":                                {"TTYP0", false},
+	"tu-berlin-1.0":                        {"TU-Berlin-1.0", false},
+	"tu-berlin-2.0":                        {"TU-Berlin-2.0", false},
+	"ubuntu-font-1.0":                      {"Ubuntu-font-1.0", false},
+	"ucar":                                 {"UCAR", false},
+	"ucl-1.0":                              {"UCL-1.0", false},
+	"ulem":                                 {"ulem", false},
+	"umich-merit":                          {"UMich-Merit", false},
+	"unicode-3.0":                          {"Unicode-3.0", false},
+	"unicode-dfs-2015":                     {"Unicode-DFS-2015", false},
+	"unicode-dfs-2016":                     {"Unicode-DFS-2016", false},
+	"unicode-tou":                          {"Unicode-TOU", false},
+	"unixcrypt":                            {"UnixCrypt", false},
+	"unlicense":                            {"Unlicense", false},
+	"upl-1.0":                              {"UPL-1.0", false},
+	"urt-rle":                              {"URT-RLE", false},
+	"vim":                                  {"Vim", false},
+	"vostrom":                              {"VOSTROM", false},
+	"vsl-1.0":                              {"VSL-1.0", false},
+	"w3c":                                  {"W3C", false},
+	"w3c-19980720":                         {"W3C-19980720", false},
+	"w3c-20150513":                         {"W3C-20150513", false},
+	"w3m":                                  {"w3m", false},
+	"watcom-1.0":                           {"Watcom-1.0", false},
+	"widget-workshop":                      {"Widget-Workshop", false},
+	"wsuipa":                               {"Wsuipa", false},
+	"wtfpl":                                {"WTFPL", false},
+	"wxwindows":                            {"wxWindows", true},
+	"x11":                                  {"X11", false},
+	"x11-distribute-modifications-variant": {"X11-distribute-modifications-variant", false},
+	"x11-swapped":                          {"X11-swapped", false},
+	"xdebug-1.03":                          {"Xdebug-1.03", false},
+	"xerox":                                {"Xerox", false},
+	"xfig":                                 {"Xfig", false},
+	"xfree86-1.1":                          {"XFree86-1.1", false},
+	"xinetd":                               {"xinetd", false},
+	"xkeyboard-config-zinoviev":            {"xkeyboard-config-Zinoviev", false},
+	"xlock":                                {"xlock", false},
+	"xnet":                                 {"Xnet", false},
+	"xpp":                                  {"xpp", false},
+	"xskat":                                {"XSkat", false},
+	"xzoom":                                {"xzoom", false},
+	"ypl-1.0":                              {"YPL-1.0", false},
+	"ypl-1.1":                              {"YPL-1.1", false},
+	"zed":                                  {"Zed", false},
+	"zeeff":                                {"Zeeff", false},
+	"zend-2.0":                             {"Zend-2.0", false},
+	"zimbra-1.3":                           {"Zimbra-1.3", false},
+	"zimbra-1.4":                           {"Zimbra-1.4", false},
+	"zlib":                                 {"Zlib", false},
+	"zlib-acknowledgement":                 {"zlib-acknowledgement", false},
+	"zpl-1.1":                              {"ZPL-1.1", false},
+	"zpl-2.0":                              {"ZPL-2.0", false},
+	"zpl-2.1":                              {"ZPL-2.1", false},
+}
+
+// spdxExceptions holds every SPDX license exception identifier, keyed by the
+// lower-case identifier.
+var spdxExceptions = map[string]spdxIdentifier{
+	"389-exception":                        {"389-exception", false},
+	"asterisk-exception":                   {"Asterisk-exception", false},
+	"asterisk-linking-protocols-exception": {"Asterisk-linking-protocols-exception", false},
+	"autoconf-exception-2.0":               {"Autoconf-exception-2.0", false},
+	"autoconf-exception-3.0":               {"Autoconf-exception-3.0", false},
+	"autoconf-exception-generic":           {"Autoconf-exception-generic", false},
+	"autoconf-exception-generic-3.0":       {"Autoconf-exception-generic-3.0", false},
+	"autoconf-exception-macro":             {"Autoconf-exception-macro", false},
+	"bison-exception-1.24":                 {"Bison-exception-1.24", false},
+	"bison-exception-2.2":                  {"Bison-exception-2.2", false},
+	"bootloader-exception":                 {"Bootloader-exception", false},
+	"classpath-exception-2.0":              {"Classpath-exception-2.0", false},
+	"clisp-exception-2.0":                  {"CLISP-exception-2.0", false},
+	"cryptsetup-openssl-exception":         {"cryptsetup-OpenSSL-exception", false},
+	"digirule-foss-exception":              {"DigiRule-FOSS-exception", false},
+	"ecos-exception-2.0":                   {"eCos-exception-2.0", false},
+	"erlang-otp-linking-exception":         {"erlang-otp-linking-exception", false},
+	"fawkes-runtime-exception":             {"Fawkes-Runtime-exception", false},
+	"fltk-exception":                       {"FLTK-exception", false},
+	"fmt-exception":                        {"fmt-exception", false},
+	"font-exception-2.0":                   {"Font-exception-2.0", false},
+	"freertos-exception-2.0":               {"freertos-exception-2.0", false},
+	"gcc-exception-2.0":                    {"GCC-exception-2.0", false},
+	"gcc-exception-2.0-note":               {"GCC-exception-2.0-note", false},
+	"gcc-exception-3.1":                    {"GCC-exception-3.1", false},
+	"gmsh-exception":                       {"Gmsh-exception", false},
+	"gnat-exception":                       {"GNAT-exception", false},
+	"gnome-examples-exception":             {"GNOME-examples-exception", false},
+	"gnu-compiler-exception":               {"GNU-compiler-exception", false},
+	"gnu-javamail-exception":               {"gnu-javamail-exception", false},
+	"gpl-3.0-interface-exception":          {"GPL-3.0-interface-exception", false},
+	"gpl-3.0-linking-exception":            {"GPL-3.0-linking-exception", false},
+	"gpl-3.0-linking-source-exception":     {"GPL-3.0-linking-source-exception", false},
+	"gpl-cc-1.0":                           {"GPL-CC-1.0", false},
+	"gstreamer-exception-2005":             {"GStreamer-exception-2005", false},
+	"gstreamer-exception-2008":             {"GStreamer-exception-2008", false},
+	"i2p-gpl-java-exception":               {"i2p-gpl-java-exception", false},
+	"kicad-libraries-exception":            {"KiCad-libraries-exception", false},
+	"lgpl-3.0-linking-exception":           {"LGPL-3.0-linking-exception", false},
+	"libpri-openh323-exception":            {"libpri-OpenH323-exception", false},
+	"libtool-exception":                    {"Libtool-exception", false},
+	"linux-syscall-note":                   {"Linux-syscall-note", false},
+	"llgpl":                                {"LLGPL", false},
+	"llvm-exception":                       {"LLVM-exception", false},
+	"lzma-exception":                       {"LZMA-exception", false},
+	"mif-exception":                        {"mif-exception", false},
+	"nokia-qt-exception-1.1":               {"Nokia-Qt-exception-1.1", true},
+	"ocaml-lgpl-linking-exception":         {"OCaml-LGPL-linking-exception", false},
+	"occt-exception-1.0":                   {"OCCT-exception-1.0", false},
+	"openjdk-assembly-exception-1.0":       {"OpenJDK-assembly-exception-1.0", false},
+	"openvpn-openssl-exception":            {"openvpn-openssl-exception", false},
+	"pcre2-exception":                      {"PCRE2-exception", false},
+	"ps-or-pdf-font-exception-20170817":    {"PS-or-PDF-font-exception-20170817", false},
+	"qpl-1.0-inria-2004-exception":         {"QPL-1.0-INRIA-2004-exception", false},
+	"qt-gpl-exception-1.0":                 {"Qt-GPL-exception-1.0", false},
+	"qt-lgpl-exception-1.1":                {"Qt-LGPL-exception-1.1", false},
+	"qwt-exception-1.0":                    {"Qwt-exception-1.0", false},
+	"romic-exception":                      {"romic-exception", false},
+	"rrdtool-floss-exception-2.0":          {"RRDtool-FLOSS-exception-2.0", false},
+	"sane-exception":                       {"SANE-exception", false},
+	"shl-2.0":                              {"SHL-2.0", false},
+	"shl-2.1":                              {"SHL-2.1", false},
+	"stunnel-exception":                    {"stunnel-exception", false},
+	"swi-exception":                        {"SWI-exception", false},
+	"swift-exception":                      {"Swift-exception", false},
+	"texinfo-exception":                    {"Texinfo-exception", false},
+	"u-boot-exception-2.0":                 {"u-boot-exception-2.0", false},
+	"ubdl-exception":                       {"UBDL-exception", false},
+	"universal-foss-exception-1.0":         {"Universal-FOSS-exception-1.0", false},
+	"vsftpd-openssl-exception":             {"vsftpd-openssl-exception", false},
+	"wxwindows-exception-3.1":              {"WxWindows-exception-3.1", false},
+	"x11vnc-openssl-exception":             {"x11vnc-openssl-exception", false},
+}
@@ -0,0 +1,42 @@
+package gocomposer
+
+import (
+	is2 "github.com/matryer/is"
+	"testing"
+)
+
+func Test_isValidSPDXLicense(t *testing.T) {
+	tests := []struct {
+		license string
+		valid   bool
+	}{
+		{`MIT`, true},
+		{`mit`, true},
+		{`GPL-2.0-or-later`, true},
+		{`GPL-2.0+`, true},
+		{`GPL-2.0`, true},
+		{`(LGPL-2.1-only or GPL-3.0-or-later)`, true},
+		{`MIT AND Apache-2.0`, true},
+		{`(MIT OR (Apache-2.0 AND BSD-3-Clause))`, true},
+		{`GPL-2.0-only WITH Classpath-exception-2.0`, true},
+		{`LicenseRef-Acme-Internal`, true},
+		{`DocumentRef-spdx-tool-1.2:LicenseRef-MIT-Style-2`, true},
+		{`NONE`, true},
+		{`NOASSERTION`, true},
+		{``, false},
+		{`Foo`, false},
+		{`MIT or`, false},
+		{`(MIT`, false},
+		{`MIT)`, false},
+		{`GPL-2.0-only WITH Foo-exception`, false},
+		{` MIT`, false},
+		{`MIT Apache-2.0`, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.license, func(t *testing.T) {
+			is := is2.New(t)
+			is.Equal(isValidSPDXLicense(test.license), test.valid)
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package gocomposer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// stabilityRanks orders the stabilities from most to least stable, the same way as
+// Composer's BasePackage::STABILITIES. The ranks are the values Composer writes to the
+// stability-flags of the lock file.
+var stabilityRanks = map[string]int{
+	StabilityStable: 0,
+	StabilityRC:     5,
+	StabilityBeta:   10,
+	StabilityAlpha:  15,
+	StabilityDev:    20,
+}
+
+var (
+	stabilityFlagRegex     = regexp.MustCompile(`(?i)^[^@]*?@(` + stabilitiesRegex + `)$`)
+	stabilityAliasRegex    = regexp.MustCompile(`^([^,\s@]+) as .+$`)
+	stabilityExplicitRegex = regexp.MustCompile(`^[^,\s@]+$`)
+)
+
+// StabilityPolicy decides which versions of a package may be installed and which one
+// is preferred, based on the minimum-stability and prefer-stable settings of the root
+// package and the stability flags of its requirements.
+type StabilityPolicy struct {
+	// The least stable stability that is accepted, one of the Stability constants.
+	// Empty means stable.
+	MinimumStability string
+
+	// The stabilities accepted for packages required by the root package, by lower
+	// case package name. These take precedence over MinimumStability.
+	StabilityFlags StabilityFlags
+
+	// If true, more stable versions are preferred over higher versions.
+	PreferStable bool
+}
+
+// NewStabilityPolicy returns the stability policy of a root package. The stability
+// flags are extracted from require and then require-dev, like Composer does when it
+// loads the root package.
+func NewStabilityPolicy(root ComposerJSON) (StabilityPolicy, error) {
+	minimum := StabilityStable
+	if root.MinimumStability != "" {
+		minimum = NormalizeStability(root.MinimumStability)
+		if _, ok := stabilityRanks[minimum]; !ok {
+			return StabilityPolicy{}, fmt.Errorf("invalid minimum-stability %q", root.MinimumStability)
+		}
+	}
+	flags := ExtractStabilityFlags(root.Require, minimum, nil)
+	flags = ExtractStabilityFlags(root.RequireDev, minimum, flags)
+	return StabilityPolicy{
+		MinimumStability: minimum,
+		StabilityFlags:   flags,
+		PreferStable:     root.PreferStable,
+	}, nil
+}
+
+// StabilityPolicy returns the stability policy the lock file was written with.
+func (l ComposerLock) StabilityPolicy() (StabilityPolicy, error) {
+	minimum := StabilityStable
+	if l.MinimumStability != "" {
+		minimum = NormalizeStability(l.MinimumStability)
+		if _, ok := stabilityRanks[minimum]; !ok {
+			return StabilityPolicy{}, fmt.Errorf("invalid minimum-stability %q", l.MinimumStability)
+		}
+	}
+	flags := make(StabilityFlags, len(l.StabilityFlags))
+	for name, rank := range l.StabilityFlags {
+		flags[name] = rank
+	}
+	return StabilityPolicy{
+		MinimumStability: minimum,
+		StabilityFlags:   flags,
+		PreferStable:     l.PreferStable,
+	}, nil
+}
+
+// ExtractStabilityFlags adds the stability flags of requirements to flags and returns
+// them, which is a port of RootPackageLoader::extractStabilityFlags. An explicit flag
+// such as "^1.0@beta" sets the stability of a package, keeping the least stable flag
+// if there are several. Otherwise, a requirement on an exact unstable version such as
+// "2.0.0-beta1" sets the stability of a package if it is less stable than both
+// minimumStability and the existing flag. flags is not modified.
+func ExtractStabilityFlags(requires map[string]string, minimumStability string, flags StabilityFlags) StabilityFlags {
+	result := make(StabilityFlags, len(flags))
+	for name, rank := range flags {
+		result[name] = rank
+	}
+	minimum := stabilityRanks[NormalizeStability(minimumStability)]
+
+	for _, target := range sortedKeys(requires) {
+		name := strings.ToLower(target)
+
+		// Extract all sub-constraints in case it is an OR/AND multi-constraint.
+		constraints := make([]string, 0)
+		for _, orConstraint := range constraintOrRegex.Split(strings.TrimSpace(requires[target]), -1) {
+			constraints = append(constraints, splitAndConstraints(orConstraint)...)
+		}
+
+		// Parse explicit stability flags to the most unstable.
+		matched := false
+		for _, constraint := range constraints {
+			m := stabilityFlagRegex.FindStringSubmatch(constraint)
+			if m == nil {
+				continue
+			}
+			rank := stabilityRanks[NormalizeStability(m[1])]
+			if existing, ok := result[name]; ok && existing > rank {
+				continue
+			}
+			result[name] = rank
+			matched = true
+		}
+		if matched {
+			continue
+		}
+
+		// Infer flags for requirements that have an explicit -dev or -beta version
+		// specified, but only for those that are more unstable than the minimum
+		// stability or existing flags.
+		for _, constraint := range constraints {
+			version := stabilityAliasRegex.ReplaceAllString(constraint, "$1")
+			if !stabilityExplicitRegex.MatchString(version) {
+				continue
+			}
+			stability := ParseStability(version)
+			if stability == StabilityStable {
+				continue
+			}
+			rank := stabilityRanks[stability]
+			if existing, ok := result[name]; (ok && existing > rank) || minimum > rank {
+				continue
+			}
+			result[name] = rank
+		}
+	}
+	return result
+}
+
+// IsAcceptable returns true if a version of a package is stable enough to be
+// installed, like Composer's PoolBuilder. The stability flag of the package is used if
+// it has one, otherwise the minimum stability.
+func (p StabilityPolicy) IsAcceptable(name string, version Version) bool {
+	return p.acceptable(strings.ToLower(name), stabilityRanks[version.Stability()])
+}
+
+func (p StabilityPolicy) acceptable(name string, rank int) bool {
+	if flag, ok := p.StabilityFlags[name]; ok {
+		return rank <= flag
+	}
+	minimum := StabilityStable
+	if p.MinimumStability != "" {
+		minimum = NormalizeStability(p.MinimumStability)
+	}
+	return rank <= stabilityRanks[minimum]
+}
+
+// Filter returns the versions of a package that are stable enough to be installed.
+func (p StabilityPolicy) Filter(name string, versions []Version) []Version {
+	accepted := make([]Version, 0, len(versions))
+	for _, version := range versions {
+		if p.IsAcceptable(name, version) {
+			accepted = append(accepted, version)
+		}
+	}
+	return accepted
+}
+
+// Compare returns 1 if a is preferred over b, -1 if b is preferred over a and 0 if
+// neither is, like Composer's DefaultPolicy: if PreferStable is set the more stable
+// version is preferred, otherwise or if both are as stable, the higher version.
+func (p StabilityPolicy) Compare(a, b Version) int {
+	if p.PreferStable {
+		rankA, rankB := stabilityRanks[a.Stability()], stabilityRanks[b.Stability()]
+		if rankA != rankB {
+			return sign(rankB - rankA)
+		}
+	}
+	return a.Compare(b)
+}
+
+// Sort sorts versions from the most to the least preferred.
+func (p StabilityPolicy) Sort(versions []Version) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		return p.Compare(versions[i], versions[j]) > 0
+	})
+}
+
+// Best returns the preferred version of a package among the versions that are stable
+// enough to be installed. It returns false if none of them is.
+func (p StabilityPolicy) Best(name string, versions []Version) (Version, bool) {
+	accepted := p.Filter(name, versions)
+	if len(accepted) == 0 {
+		return Version{}, false
+	}
+	p.Sort(accepted)
+	return accepted[0], true
+}
@@ -0,0 +1,166 @@
+package gocomposer
+
+import (
+	is2 "github.com/matryer/is"
+	"testing"
+)
+
+func TestExtractStabilityFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		requires map[string]string
+		minimum  string
+		flags    StabilityFlags
+		expected StabilityFlags
+	}{
+		{
+			name:     `ExplicitFlag`,
+			requires: map[string]string{"Acme/Log": "^1.0@beta", "acme/http": "^2.0"},
+			minimum:  StabilityStable,
+			expected: StabilityFlags{"acme/log": 10},
+		},
+		{
+			name:     `LeastStableFlag`,
+			requires: map[string]string{"acme/log": "^1.0@RC || ^2.0@alpha, <2.5@beta"},
+			minimum:  StabilityStable,
+			expected: StabilityFlags{"acme/log": 15},
+		},
+		{
+			name:     `StableFlag`,
+			requires: map[string]string{"acme/log": "^1.0@stable"},
+			minimum:  StabilityDev,
+			expected: StabilityFlags{"acme/log": 0},
+		},
+		{
+			name:     `InferredFromVersion`,
+			requires: map[string]string{"acme/log": "2.0.0-beta1", "acme/http": "dev-main as 1.0.x-dev", "acme/cache": ">=1.0-beta"},
+			minimum:  StabilityStable,
+			expected: StabilityFlags{"acme/log": 10, "acme/http": 20, "acme/cache": 10},
+		},
+		{
+			name:     `InferredBelowMinimumStability`,
+			requires: map[string]string{"acme/log": "2.0.0-beta1", "acme/http": "2.0.0-alpha1"},
+			minimum:  StabilityAlpha,
+			expected: StabilityFlags{"acme/http": 15},
+		},
+		{
+			name:     `ExistingFlags`,
+			requires: map[string]string{"acme/log": "^1.0@beta", "acme/http": "2.0.0-RC1"},
+			minimum:  StabilityStable,
+			flags:    StabilityFlags{"acme/log": 20, "acme/http": 15, "acme/cache": 5},
+			expected: StabilityFlags{"acme/log": 20, "acme/http": 15, "acme/cache": 5},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			flags := ExtractStabilityFlags(test.requires, test.minimum, test.flags)
+			is.Equal(flags, test.expected)
+		})
+	}
+}
+
+func TestNewStabilityPolicy(t *testing.T) {
+	is := is2.New(t)
+
+	policy, err := NewStabilityPolicy(ComposerJSON{
+		Require:          map[string]string{"acme/log": "^1.0@beta", "acme/http": "^2.0"},
+		RequireDev:       map[string]string{"acme/log": "^1.0@dev", "acme/debug": "1.0.0-alpha2"},
+		MinimumStability: "RC",
+		PreferStable:     true,
+	})
+	is.NoErr(err)
+	is.Equal(policy, StabilityPolicy{
+		MinimumStability: StabilityRC,
+		StabilityFlags:   StabilityFlags{"acme/log": 20, "acme/debug": 15},
+		PreferStable:     true,
+	})
+
+	_, err = NewStabilityPolicy(ComposerJSON{MinimumStability: "unstable"})
+	is.Equal(err.Error(), `invalid minimum-stability "unstable"`)
+
+	lock, err := LoadLock("testdata/composer.lock")
+	is.NoErr(err)
+	policy, err = lock.StabilityPolicy()
+	is.NoErr(err)
+	is.Equal(policy.StabilityFlags, StabilityFlags{"doctrine/instantiator": 20})
+	is.True(policy.IsAcceptable("doctrine/instantiator", MustParseVersion("dev-main")))
+}
+
+func TestStabilityPolicy(t *testing.T) {
+	is := is2.New(t)
+
+	versions := []Version{
+		MustParseVersion("1.0.0"),
+		MustParseVersion("1.1.0-RC1"),
+		MustParseVersion("1.1.0-beta2"),
+		MustParseVersion("dev-main"),
+	}
+	policy := StabilityPolicy{
+		MinimumStability: StabilityRC,
+		StabilityFlags:   StabilityFlags{"acme/log": 10},
+	}
+
+	tests := []struct {
+		name     string
+		pkg      string
+		policy   StabilityPolicy
+		accepted []string
+		best     string
+	}{
+		{
+			name:     `MinimumStability`,
+			pkg:      "acme/http",
+			policy:   policy,
+			accepted: []string{"1.0.0", "1.1.0-RC1"},
+			best:     "1.1.0-RC1",
+		},
+		{
+			name:     `StabilityFlag`,
+			pkg:      "Acme/Log",
+			policy:   policy,
+			accepted: []string{"1.0.0", "1.1.0-RC1", "1.1.0-beta2"},
+			best:     "1.1.0-RC1",
+		},
+		{
+			name:     `Stable`,
+			pkg:      "acme/http",
+			policy:   StabilityPolicy{},
+			accepted: []string{"1.0.0"},
+			best:     "1.0.0",
+		},
+		{
+			name:     `PreferStable`,
+			pkg:      "acme/http",
+			policy:   StabilityPolicy{MinimumStability: StabilityDev, PreferStable: true},
+			accepted: []string{"1.0.0", "1.1.0-RC1", "1.1.0-beta2", "dev-main"},
+			best:     "1.0.0",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			accepted := make([]string, 0)
+			for _, version := range test.policy.Filter(test.pkg, versions) {
+				accepted = append(accepted, version.Pretty)
+			}
+			is.Equal(accepted, test.accepted)
+			best, ok := test.policy.Best(test.pkg, versions)
+			is.True(ok)
+			is.Equal(best.Pretty, test.best)
+		})
+	}
+
+	_, ok := StabilityPolicy{}.Best("acme/http", versions[1:])
+	is.True(!ok)
+
+	sorted := append([]Version{}, versions...)
+	StabilityPolicy{PreferStable: true}.Sort(sorted)
+	is.Equal(sorted, []Version{versions[0], versions[1], versions[2], versions[3]})
+	StabilityPolicy{}.Sort(sorted)
+	is.Equal(sorted, []Version{versions[1], versions[2], versions[0], versions[3]})
+}
@@ -0,0 +1,20 @@
+<?php
+namespace Acme \ Http;
+
+trait RetriesRequests
+{
+}
+
+enum Method: string
+{
+    case Get = 'GET';
+}
+
+enum Status
+{
+    case Ok;
+}
+
+abstract class Client
+{
+}
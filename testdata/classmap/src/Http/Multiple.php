@@ -0,0 +1,15 @@
+<html><?php echo "class NotPhp {}"; ?>
+<p>class OutsidePhp {}</p>
+<?php
+
+namespace First {
+    class One {}
+}
+
+namespace Second {
+    class Two {}
+}
+
+namespace {
+    class GlobalClass {}
+}
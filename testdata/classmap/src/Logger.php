@@ -0,0 +1,33 @@
+<?php
+
+namespace Acme\Log;
+
+// class CommentedOut {}
+/*
+ * interface AlsoCommentedOut {}
+ */
+interface LoggerInterface
+{
+}
+
+final class Logger implements LoggerInterface
+{
+    private $message = 'class NotAClass {}';
+
+    public function type(): string
+    {
+        $class = new class extends \stdClass {};
+        $other = new class implements \Countable { public function count(): int { return 0; } };
+
+        return Logger::class . "class InString {}" . $this->message;
+    }
+
+    public function template(): string
+    {
+        return <<<EOT
+class InHeredoc {}
+EOT . <<<'NOW'
+    class InNowdoc {}
+    NOW;
+    }
+}
@@ -0,0 +1,5 @@
+<?php
+
+namespace Acme\Log;
+
+class Logger {}
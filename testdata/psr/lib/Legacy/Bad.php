@@ -0,0 +1,5 @@
+<?php
+
+class Legacy_Good
+{
+}
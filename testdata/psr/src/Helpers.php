@@ -0,0 +1,11 @@
+<?php
+
+namespace Acme;
+
+class Helpers
+{
+}
+
+class Extra
+{
+}
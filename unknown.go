@@ -0,0 +1,117 @@
+package gocomposer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// knownKeysCache holds the JSON keys of every struct type passed to knownKeys.
+var knownKeysCache sync.Map
+
+// knownKeys returns the set of JSON object keys that are mapped to a field of the
+// struct type t.
+func knownKeys(t reflect.Type) map[string]bool {
+	if keys, ok := knownKeysCache.Load(t); ok {
+		return keys.(map[string]bool)
+	}
+	keys := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if name == "" {
+			name = field.Name
+		}
+		keys[name] = true
+	}
+	knownKeysCache.Store(t, keys)
+	return keys
+}
+
+// decodeUnknown returns the members of the JSON object in data that do not belong to
+// a field of v, which must be a pointer to a struct. It returns nil if there are none.
+//
+// Together with encodeUnknown it keeps the keys a struct does not model, such as
+// vendor specific keys or options added in newer versions of Composer. Structs store
+// them as raw JSON in an Unknown field that is ignored by encoding/json. Their
+// UnmarshalJSON decodes the modeled keys through a plain copy of the type and stores
+// the rest with decodeUnknown, and their MarshalJSON appends them again with
+// encodeUnknown, so they are written back unchanged.
+func decodeUnknown(data []byte, v interface{}) (map[string]json.RawMessage, error) {
+	if !isObject(bytes.TrimSpace(data)) {
+		return nil, nil
+	}
+	members := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+
+	known := knownKeys(reflect.TypeOf(v).Elem())
+	var unknown map[string]json.RawMessage
+	for key, value := range members {
+		if known[key] {
+			continue
+		}
+		if unknown == nil {
+			unknown = make(map[string]json.RawMessage)
+		}
+		unknown[key] = value
+	}
+	return unknown, nil
+}
+
+// encodeUnknown appends the unknown members to the JSON object in data, which was
+// encoded from v. Members with the same key as a field of v are ignored so the result
+// never contains duplicate keys.
+func encodeUnknown(data []byte, unknown map[string]json.RawMessage, v interface{}) ([]byte, error) {
+	if len(unknown) == 0 {
+		return data, nil
+	}
+	data = bytes.TrimSpace(data)
+	if !isObject(data) {
+		return nil, fmt.Errorf("cannot add unknown keys to non-object JSON %s", data)
+	}
+
+	known := knownKeys(reflect.TypeOf(v))
+	keys := make([]string, 0, len(unknown))
+	for key := range unknown {
+		if !known[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	buf := bytes.Buffer{}
+	buf.Write(data[:len(data)-1])
+	empty := len(bytes.TrimSpace(data[1:len(data)-1])) == 0
+	for _, key := range keys {
+		if !empty {
+			buf.WriteByte(',')
+		}
+		empty = false
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		value := unknown[key]
+		if len(value) == 0 {
+			value = json.RawMessage("null")
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
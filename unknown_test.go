@@ -0,0 +1,138 @@
+package gocomposer
+
+import (
+	"encoding/json"
+	is2 "github.com/matryer/is"
+	"os"
+	"testing"
+)
+
+func TestComposerJSON_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{
+			name: `Laravel`,
+			path: `testdata/unknown/laravel.json`,
+		},
+		{
+			name: `Symfony`,
+			path: `testdata/unknown/symfony.json`,
+		},
+		{
+			name: `VendorKeys`,
+			path: `testdata/unknown/vendor.json`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			data, err := os.ReadFile(test.path)
+			is.NoErr(err)
+
+			c := ComposerJSON{}
+			is.NoErr(json.Unmarshal(data, &c))
+
+			output, err := json.Marshal(c)
+			is.NoErr(err)
+
+			// Every key of the original manifest must be written back with the same
+			// value. Struct fields without omitempty may add keys, which is fine.
+			expected := map[string]interface{}{}
+			is.NoErr(json.Unmarshal(data, &expected))
+			result := map[string]interface{}{}
+			is.NoErr(json.Unmarshal(output, &result))
+			for key, value := range expected {
+				is.Equal(result[key], value) // value of key must be unchanged
+			}
+
+			again := ComposerJSON{}
+			is.NoErr(json.Unmarshal(output, &again))
+			outputAgain, err := json.Marshal(again)
+			is.NoErr(err)
+			is.Equal(string(outputAgain), string(output))
+		})
+	}
+}
+
+func TestComposerJSON_Unknown(t *testing.T) {
+	is := is2.New(t)
+
+	data, err := os.ReadFile("testdata/unknown/vendor.json")
+	is.NoErr(err)
+
+	c := ComposerJSON{}
+	is.NoErr(json.Unmarshal(data, &c))
+
+	is.Equal(len(c.Unknown), 1)
+	is.Equal(string(c.Unknown["x-acme"]), `{
+        "channel": "beta",
+        "retries": 3
+    }`)
+	is.Equal(c.ScriptsAliases, map[string][]string{"test": {"t"}})
+	is.Equal(c.Autoload.PSR4["Acme\\Tool\\"], StringOrSlice{"src/"})
+	is.Equal(string(c.Autoload.Unknown["x-acme-preload"]), `["src/bootstrap.php"]`)
+	is.Equal(c.Archive.Exclude, []string{"/tests"})
+	is.Equal(string(c.Archive.Unknown["x-acme-format"]), `"tar"`)
+	is.Equal(c.Config.GetProcessTimeout(), 600)
+	is.Equal(string(c.Config.Unknown["x-acme-token"]), `null`)
+	is.Equal(c.AutoloadDev.Unknown, nil)
+}
+
+func TestConfig_MarshalJSON_Unknown(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  Config
+		output string
+	}{
+		{
+			name:   `NoUnknown`,
+			value:  Config{VendorDir: "lib"},
+			output: `{"vendor-dir":"lib"}`,
+		},
+		{
+			name: `SortedUnknown`,
+			value: Config{
+				VendorDir: "lib",
+				Unknown: map[string]json.RawMessage{
+					"z-option": json.RawMessage(`true`),
+					"a-option": json.RawMessage(`{"a": 1}`),
+				},
+			},
+			output: `{"vendor-dir":"lib","a-option":{"a":1},"z-option":true}`,
+		},
+		{
+			name: `KnownKeyIgnored`,
+			value: Config{
+				VendorDir: "lib",
+				Unknown: map[string]json.RawMessage{
+					"vendor-dir": json.RawMessage(`"src"`),
+				},
+			},
+			output: `{"vendor-dir":"lib"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			data, err := json.Marshal(test.value)
+			is.NoErr(err)
+			is.Equal(string(data), test.output)
+		})
+	}
+}
+
+func TestArchive_MarshalJSON_EmptyObject(t *testing.T) {
+	is := is2.New(t)
+
+	data, err := json.Marshal(Archive{
+		Unknown: map[string]json.RawMessage{"x-acme-format": json.RawMessage(`"tar"`)},
+	})
+	is.NoErr(err)
+	is.Equal(string(data), `{"x-acme-format":"tar"}`)
+}
@@ -1,5 +1,7 @@
 package gocomposer
 
+import "sort"
+
 // isObject returns true if the JSON data starts with '{' and ends with '}'.
 func isObject(data []byte) bool {
 	if len(data) == 0 {
@@ -32,3 +34,13 @@ func isArray(data []byte) bool {
 	}
 	return true
 }
+
+// sortedKeys returns the keys of a map with string keys in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,6 +1,9 @@
 package gocomposer
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func Test_isArray(t *testing.T) {
 	tests := []struct {
@@ -134,3 +137,29 @@ func Test_isString(t *testing.T) {
 		})
 	}
 }
+
+func Test_sortedKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		m    map[string]int
+		want []string
+	}{
+		{
+			name: "nil map",
+			m:    nil,
+			want: []string{},
+		},
+		{
+			name: "unsorted keys",
+			m:    map[string]int{"psr/log": 1, "acme/http": 2, "Acme/Log": 3},
+			want: []string{"Acme/Log", "acme/http", "psr/log"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sortedKeys(tt.m); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sortedKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,539 @@
+package gocomposer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// ValidationIssue is a single problem found by Validate.
+type ValidationIssue struct {
+	// JSON pointer (RFC 6901) to the value the issue is about, e.g.
+	// "/require/psr~1log". It is empty when the issue is about the whole file.
+	Pointer string
+
+	// Human readable description of the issue.
+	Message string
+}
+
+func (v ValidationIssue) String() string {
+	if v.Pointer == "" {
+		return v.Message
+	}
+	return v.Pointer + " : " + v.Message
+}
+
+// ValidationResult holds the issues found by Validate, grouped the same way as the
+// output of `composer validate`.
+type ValidationResult struct {
+	// Errors make the composer.json file invalid.
+	Errors []ValidationIssue
+
+	// PublishErrors do not stop Composer from using the file, but the package cannot be
+	// published on Packagist.
+	PublishErrors []ValidationIssue
+
+	// Warnings are about deprecated or discouraged usage.
+	Warnings []ValidationIssue
+}
+
+// IsValid returns true if there are no errors.
+func (r ValidationResult) IsValid() bool {
+	return len(r.Errors) == 0
+}
+
+// IsPublishable returns true if there are no errors or publish errors.
+func (r ValidationResult) IsPublishable() bool {
+	return len(r.Errors) == 0 && len(r.PublishErrors) == 0
+}
+
+func (r *ValidationResult) addError(pointer string, format string, args ...interface{}) {
+	r.Errors = append(r.Errors, ValidationIssue{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+}
+
+func (r *ValidationResult) addPublishError(pointer string, format string, args ...interface{}) {
+	r.PublishErrors = append(r.PublishErrors, ValidationIssue{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+}
+
+func (r *ValidationResult) addWarning(pointer string, format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, ValidationIssue{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+}
+
+var (
+	// looseNameRegex is the package name format Composer requires.
+	looseNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*/[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+	// strictNameRegex is the package name format Composer recommends.
+	strictNameRegex = regexp.MustCompile(`(?i)^[a-z0-9](?:[_.-]?[a-z0-9]+)*/[a-z0-9](?:(?:[_.]|-{1,2})?[a-z0-9]+)*$`)
+	// nameSuggestRegex finds the word boundaries of a camel-cased package name.
+	nameSuggestRegex = regexp.MustCompile(`([a-z])([A-Z])|([A-Z])([A-Z][a-z])`)
+	// typeRegex is the package type format.
+	typeRegex = regexp.MustCompile(`^[a-z0-9-]+$`)
+	// platformPackageRegex matches the names of platform packages like php or ext-json.
+	platformPackageRegex = regexp.MustCompile(`(?i)^(?:php(?:-64bit|-ipv6|-zts|-debug)?|hhvm|(?:ext|lib)-[a-z0-9](?:[_.-]?[a-z0-9]+)*|composer(?:-(?:plugin|runtime)-api)?)$`)
+	// upperRegex matches upper case letters.
+	upperRegex = regexp.MustCompile(`[A-Z]`)
+)
+
+// reservedNames are names that cannot be used as vendor or package names because they
+// are reserved file names on Windows.
+var reservedNames = []string{
+	"nul", "con", "prn", "aux",
+	"com1", "com2", "com3", "com4", "com5", "com6", "com7", "com8", "com9",
+	"lpt1", "lpt2", "lpt3", "lpt4", "lpt5", "lpt6", "lpt7", "lpt8", "lpt9",
+}
+
+// isPlatformPackage returns true if name is a platform package, like php, ext-json or
+// composer-plugin-api, which cannot be installed by Composer.
+func isPlatformPackage(name string) bool {
+	return platformPackageRegex.MatchString(name)
+}
+
+// Validate checks a ComposerJSON for the same problems as `composer validate`. Use
+// ValidateBytes to also detect problems that are lost when the file is decoded, like
+// duplicate keys or values of the wrong type.
+func Validate(c ComposerJSON) ValidationResult {
+	r := ValidationResult{}
+
+	validateName(&r, c.Name)
+	if c.Description == "" {
+		r.addPublishError("/description", "The property description is required")
+	}
+	validateType(&r, c.Type)
+	validateLicense(&r, c.License)
+
+	if c.Version != "" {
+		if _, err := NormalizeVersion(c.Version); err != nil {
+			r.addError("/version", "invalid value (%s): %s", c.Version, err)
+		}
+		r.addWarning("/version", "The version field is present, it is recommended to leave it out if the package is published on Packagist.")
+	}
+
+	if c.MinimumStability != "" {
+		switch strings.ToLower(c.MinimumStability) {
+		case StabilityStable, strings.ToLower(StabilityRC), StabilityBeta, StabilityAlpha, StabilityDev:
+		default:
+			r.addError("/minimum-stability", "invalid value (%s), must be one of stable, RC, beta, alpha, dev", c.MinimumStability)
+		}
+	}
+
+	validateURL(&r, "/homepage", c.Homepage)
+	for i, author := range c.Authors {
+		pointer := jsonPointer("authors", fmt.Sprint(i))
+		validateEmail(&r, pointer+"/email", author.Email)
+		validateURL(&r, pointer+"/homepage", author.Homepage)
+	}
+	validateSupport(&r, c.Support)
+
+	links := []struct {
+		key   string
+		links map[string]string
+	}{
+		{"require", c.Require},
+		{"require-dev", c.RequireDev},
+		{"conflict", c.Conflict},
+		{"replace", c.Replace},
+		{"provide", c.Provide},
+	}
+	for _, l := range links {
+		validateLinks(&r, l.key, l.links)
+	}
+	validateLinkOverlap(&r, c)
+
+	validateAutoload(&r, "autoload", c.Autoload.PSR4, c.Autoload.PSR0, c.Autoload.Unknown)
+	validateAutoload(&r, "autoload-dev", c.AutoloadDev.PSR4, c.AutoloadDev.PSR0, c.AutoloadDev.Unknown)
+
+	if c.TargetDir != "" {
+		r.addWarning("/target-dir", "The target-dir option is deprecated, use autoload.psr-4 instead.")
+	}
+	if len(c.IncludePath) > 0 {
+		r.addWarning("/include-path", "The include-path option is deprecated, use autoload instead.")
+	}
+
+	for _, name := range sortedKeys(c.ScriptsDescriptions) {
+		if _, exists := c.Scripts[name]; !exists {
+			r.addWarning(jsonPointer("scripts-descriptions", name), `Description for non-existent script "%s" found in "scripts-descriptions"`, name)
+		}
+	}
+	for _, name := range sortedKeys(c.ScriptsAliases) {
+		if _, exists := c.Scripts[name]; !exists {
+			r.addWarning(jsonPointer("scripts-aliases", name), `Aliases for non-existent script "%s" found in "scripts-aliases"`, name)
+		}
+	}
+
+	return r
+}
+
+// ValidateBytes checks the contents of a composer.json file. In addition to the checks
+// done by Validate, it reports duplicate keys and values of the wrong type. An error is
+// only returned if data is not valid JSON.
+func ValidateBytes(data []byte) (ValidationResult, error) {
+	if _, err := decodeOrdered(data); err != nil {
+		return ValidationResult{}, err
+	}
+
+	duplicates := ValidationResult{}
+	for _, pointer := range findDuplicateKeys(data) {
+		duplicates.addError(pointer, "Duplicate key")
+	}
+
+	c := ComposerJSON{}
+	if err := json.Unmarshal(data, &c); err != nil {
+		r := ValidationResult{}
+		typeErr := &json.UnmarshalTypeError{}
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			r.addError("/"+strings.ReplaceAll(typeErr.Field, ".", "/"), "invalid value, must be of type %s", jsonTypeName(typeErr.Type))
+		} else {
+			r.addError("", "%s", err)
+		}
+		r.Errors = append(duplicates.Errors, r.Errors...)
+		return r, nil
+	}
+
+	r := Validate(c)
+	r.Errors = append(duplicates.Errors, r.Errors...)
+	return r, nil
+}
+
+// validateName checks the package name the same way Composer's ValidatingArrayLoader
+// and ConfigValidator do.
+func validateName(r *ValidationResult, name string) {
+	if name == "" {
+		r.addPublishError("/name", "The property name is required")
+		return
+	}
+	if !looseNameRegex.MatchString(name) {
+		r.addError("/name", "invalid value (%s), must match %s", name, strings.Trim(looseNameRegex.String(), "^$"))
+		return
+	}
+	if err := packageNamingError(name, false); err != "" {
+		r.addWarning("/name", "Your package name %s", err)
+	}
+	if upperRegex.MatchString(name) {
+		r.addPublishError("/name", `Name "%s" does not match the best practice (e.g. lower-cased/with-dashes). We suggest using "%s" instead. As such you will not be able to submit it to Packagist.`, name, suggestName(name))
+	}
+}
+
+// packageNamingError returns why a package name does not follow the naming rules, or
+// an empty string if it does. This is a port of Composer's
+// ValidatingArrayLoader::hasPackageNamingError.
+func packageNamingError(name string, isLink bool) string {
+	if isPlatformPackage(name) {
+		return ""
+	}
+	if !strictNameRegex.MatchString(name) {
+		return name + ` is invalid, it should have a vendor name, a forward slash, and a package name. The vendor and package name can be words separated by -, . or _. The complete name should match "^[a-z0-9]([_.-]?[a-z0-9]+)*/[a-z0-9](([_.]?|-{0,2})[a-z0-9]+)*$".`
+	}
+	bits := strings.SplitN(strings.ToLower(name), "/", 2)
+	for _, reserved := range reservedNames {
+		if bits[0] == reserved || bits[1] == reserved {
+			return name + " is reserved, package and vendor names can not match any of: " + strings.Join(reservedNames, ", ") + "."
+		}
+	}
+	if strings.HasSuffix(name, ".json") {
+		return name + " is invalid, package names can not end in .json, consider renaming it or perhaps using a -json suffix instead."
+	}
+	if upperRegex.MatchString(name) {
+		if isLink {
+			return name + " is invalid, it should not contain uppercase characters. Please use " + strings.ToLower(name) + " instead."
+		}
+		return name + " is invalid, it should not contain uppercase characters. We suggest using " + suggestName(name) + " instead."
+	}
+	return ""
+}
+
+// suggestName turns a camel-cased package name into a lower-cased name with dashes.
+func suggestName(name string) string {
+	return strings.ToLower(nameSuggestRegex.ReplaceAllString(name, "$1$3-$2$4"))
+}
+
+func validateType(r *ValidationResult, t string) {
+	if t == "" {
+		return
+	}
+	if !typeRegex.MatchString(t) {
+		r.addError("/type", "invalid value (%s), must match [a-z0-9-]+", t)
+	}
+	if t == "composer-installer" {
+		r.addWarning("/type", "The package type 'composer-installer' is deprecated. Please distribute your custom installers as plugins from now on. See https://getcomposer.org/doc/articles/plugins.md for plugin documentation.")
+	}
+}
+
+// deprecatedGPLRegex matches deprecated GNU license identifiers like "GPL-2.0" or
+// "LGPL-3.0+".
+var deprecatedGPLRegex = regexp.MustCompile(`(?i)^([AL]?GPL-[123](?:\.[01])?)(\+?)$`)
+
+func validateLicense(r *ValidationResult, licenses StringOrSlice) {
+	if len(licenses) == 0 {
+		r.addWarning("/license", `No license specified, it is recommended to do so. For closed-source software you may use "proprietary" as license.`)
+		return
+	}
+
+	for i, license := range licenses {
+		pointer := "/license"
+		if len(licenses) > 1 {
+			pointer = jsonPointer("license", fmt.Sprint(i))
+		}
+		if license == "proprietary" {
+			continue
+		}
+		// "proprietary" can be used in an expression like "(MIT or proprietary)".
+		expr := strings.ReplaceAll(license, "proprietary", "MIT")
+		if !isValidSPDXLicense(expr) {
+			if isValidSPDXLicense(strings.TrimSpace(expr)) {
+				r.addWarning(pointer, "License %s must not contain extra spaces, make sure to trim it.", license)
+			} else {
+				r.addWarning(pointer, "License \"%s\" is not a valid SPDX license identifier, see https://spdx.org/licenses/ if you use an open license.\nIf the software is closed-source, you may use \"proprietary\" as license.", license)
+			}
+			continue
+		}
+
+		spdx, ok := getSPDXLicense(license)
+		if !ok || !spdx.deprecated {
+			continue
+		}
+		if matches := deprecatedGPLRegex.FindStringSubmatch(license); matches != nil {
+			if matches[2] == "+" {
+				r.addWarning(pointer, `License "%s" is a deprecated SPDX license identifier, use "%s-or-later" instead`, license, matches[1])
+			} else {
+				r.addWarning(pointer, `License "%s" is a deprecated SPDX license identifier, use "%s-only" or "%s-or-later" instead`, license, license, license)
+			}
+			continue
+		}
+		r.addWarning(pointer, `License "%s" is a deprecated SPDX license identifier, see https://spdx.org/licenses/`, license)
+	}
+}
+
+func validateSupport(r *ValidationResult, s Support) {
+	validateEmail(r, "/support/email", s.Email)
+	if s.IRC != "" && !isValidURL(s.IRC, "irc", "ircs") {
+		r.addWarning("/support/irc", "invalid value (%s), must be a irc://<server>/<channel> or ircs:// URL", s.IRC)
+	}
+	urls := []struct {
+		key   string
+		value string
+	}{
+		{"issues", s.Issues},
+		{"forum", s.Forum},
+		{"wiki", s.Wiki},
+		{"source", s.Source},
+		{"docs", s.Docs},
+		{"chat", s.Chat},
+		{"rss", s.RSS},
+	}
+	for _, u := range urls {
+		validateURL(r, "/support/"+u.key, u.value)
+	}
+}
+
+func validateURL(r *ValidationResult, pointer string, value string) {
+	if value != "" && !isValidURL(value, "http", "https") {
+		r.addWarning(pointer, "invalid value (%s), must be an http/https URL", value)
+	}
+}
+
+func validateEmail(r *ValidationResult, pointer string, value string) {
+	if value == "" {
+		return
+	}
+	address, err := mail.ParseAddress(value)
+	if err != nil || address.Address != value {
+		r.addWarning(pointer, "invalid value (%s), must be a valid email address", value)
+	}
+}
+
+// isValidURL returns true if value is an absolute URL with a host and one of the
+// given schemes.
+func isValidURL(value string, schemes ...string) bool {
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	for _, scheme := range schemes {
+		if strings.EqualFold(u.Scheme, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateLinks checks the package names and version constraints of a require,
+// require-dev, conflict, replace or provide section.
+func validateLinks(r *ValidationResult, key string, links map[string]string) {
+	unbound := &SingleConstraint{Operator: OpEqual, Version: "10000000-dev"}
+	stable := &SingleConstraint{Operator: OpGreaterEqual, Version: "1.0.0.0-dev"}
+
+	for _, pkg := range sortedKeys(links) {
+		pointer := jsonPointer(key, pkg)
+		if err := packageNamingError(pkg, true); err != "" {
+			r.addWarning(pointer, "%s", err)
+		}
+
+		constraint := links[pkg]
+		if constraint == "self.version" {
+			continue
+		}
+		parsed, err := ParseConstraint(constraint)
+		if err != nil {
+			r.addError(pointer, "invalid version constraint (%s)", err)
+			continue
+		}
+		if key != "require" {
+			continue
+		}
+		if single, ok := parsed.(*SingleConstraint); ok && single.Operator == OpEqual && stable.Intersects(single) {
+			r.addWarning(pointer, "exact version constraints (%s) should be avoided if the package follows semantic versioning", constraint)
+		}
+		if parsed.Intersects(unbound) && !isPlatformPackage(pkg) {
+			r.addWarning(pointer, "unbound version constraints (%s) should be avoided", constraint)
+		}
+	}
+}
+
+// validateLinkOverlap reports packages that are required twice, provided by the root
+// package itself or pointing to a commit reference.
+func validateLinkOverlap(r *ValidationResult, c ComposerJSON) {
+	overrides := make([]string, 0)
+	for _, pkg := range sortedKeys(c.Require) {
+		if _, exists := c.RequireDev[pkg]; exists {
+			overrides = append(overrides, pkg)
+		}
+	}
+	if len(overrides) == 1 {
+		r.addWarning(jsonPointer("require-dev", overrides[0]), "%s is required both in require and require-dev, this can lead to unexpected behavior", overrides[0])
+	} else if len(overrides) > 1 {
+		r.addWarning("/require-dev", "%s are required both in require and require-dev, this can lead to unexpected behavior", strings.Join(overrides, ", "))
+	}
+
+	for _, linkType := range []string{"provide", "replace"} {
+		links := c.Provide
+		if linkType == "replace" {
+			links = c.Replace
+		}
+		for _, requireType := range []string{"require", "require-dev"} {
+			require := c.Require
+			if requireType == "require-dev" {
+				require = c.RequireDev
+			}
+			for _, pkg := range sortedKeys(links) {
+				if _, exists := require[pkg]; exists {
+					r.addWarning(jsonPointer(linkType, pkg), "The package %s in %s is also listed in %s which satisfies the requirement. Remove it from %s if you wish to install it.", pkg, requireType, linkType, linkType)
+				}
+			}
+		}
+	}
+
+	for _, requireType := range []string{"require", "require-dev"} {
+		require := c.Require
+		if requireType == "require-dev" {
+			require = c.RequireDev
+		}
+		for _, pkg := range sortedKeys(require) {
+			if strings.Contains(require[pkg], "#") {
+				r.addWarning(jsonPointer(requireType, pkg), `The package "%s" is pointing to a commit-ref, this is bad practice and can cause unforeseen issues.`, pkg)
+			}
+		}
+	}
+}
+
+// validateAutoload checks the namespace prefixes and the keys of an autoload or
+// autoload-dev section.
+func validateAutoload(r *ValidationResult, key string, psr4, psr0 map[string]StringOrSlice, unknown map[string]json.RawMessage) {
+	for _, name := range sortedKeys(unknown) {
+		r.addError(jsonPointer(key, name), "invalid value (%s), must be one of psr-0, psr-4, classmap, files, exclude-from-classmap", name)
+	}
+
+	for _, namespace := range sortedKeys(psr4) {
+		if namespace != "" && !strings.HasSuffix(namespace, `\`) {
+			r.addError(jsonPointer(key, "psr-4", namespace), `invalid value (%s), namespaces must end with a namespace separator, should be %s\\`, namespace, namespace)
+		}
+	}
+	if _, exists := psr4[""]; exists && key == "autoload" {
+		r.addWarning(jsonPointer(key, "psr-4", ""), "Defining autoload.psr-4 with an empty namespace prefix is a bad idea for performance")
+	}
+	if _, exists := psr0[""]; exists && key == "autoload" {
+		r.addWarning(jsonPointer(key, "psr-0", ""), "Defining autoload.psr-0 with an empty namespace prefix is a bad idea for performance")
+	}
+}
+
+// findDuplicateKeys returns the JSON pointer of every object key that appears more
+// than once in the same object.
+func findDuplicateKeys(data []byte) []string {
+	duplicates := make([]string, 0)
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	var walk func(path []string) error
+	walk = func(path []string) error {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		delim, ok := tok.(json.Delim)
+		if !ok {
+			return nil
+		}
+		switch delim {
+		case '{':
+			seen := make(map[string]bool)
+			for dec.More() {
+				tok, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				key := tok.(string)
+				if seen[key] {
+					duplicates = append(duplicates, jsonPointer(append(path, key)...))
+				}
+				seen[key] = true
+				if err := walk(append(path[:len(path):len(path)], key)); err != nil {
+					return err
+				}
+			}
+		case '[':
+			for i := 0; dec.More(); i++ {
+				if err := walk(append(path[:len(path):len(path)], fmt.Sprint(i))); err != nil {
+					return err
+				}
+			}
+		}
+		_, err = dec.Token()
+		return err
+	}
+
+	if err := walk([]string{}); err != nil {
+		return nil
+	}
+	return duplicates
+}
+
+// jsonPointer creates a JSON pointer (RFC 6901) from a list of object keys and array
+// indexes.
+func jsonPointer(parts ...string) string {
+	buf := strings.Builder{}
+	for _, part := range parts {
+		buf.WriteByte('/')
+		part = strings.ReplaceAll(part, "~", "~0")
+		buf.WriteString(strings.ReplaceAll(part, "/", "~1"))
+	}
+	return buf.String()
+}
+
+// jsonTypeName returns the name of the JSON type a Go type is decoded from.
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Map, reflect.Struct:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	}
+	return "number"
+}
@@ -0,0 +1,273 @@
+package gocomposer
+
+import (
+	is2 "github.com/matryer/is"
+	"testing"
+)
+
+func TestValidateBytes(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		errors        []ValidationIssue
+		publishErrors []ValidationIssue
+		warnings      []ValidationIssue
+	}{
+		{
+			name: `Valid`,
+			input: `{
+				"name": "acme/blog",
+				"description": "The Acme blog",
+				"type": "project",
+				"license": "MIT",
+				"homepage": "https://acme.test",
+				"authors": [{"name": "Jane", "email": "jane@acme.test"}],
+				"require": {"php": ">=8.1", "monolog/monolog": "^3.0"},
+				"require-dev": {"phpunit/phpunit": "^10.0"},
+				"autoload": {"psr-4": {"Acme\\Blog\\": "src/"}},
+				"minimum-stability": "RC"
+			}`,
+		},
+		{
+			name:  `MissingPublishFields`,
+			input: `{"license": "proprietary"}`,
+			publishErrors: []ValidationIssue{
+				{"/name", "The property name is required"},
+				{"/description", "The property description is required"},
+			},
+		},
+		{
+			name:  `InvalidName`,
+			input: `{"name": "acme", "description": "x", "license": "MIT"}`,
+			errors: []ValidationIssue{
+				{"/name", "invalid value (acme), must match [A-Za-z0-9][A-Za-z0-9_.-]*/[A-Za-z0-9][A-Za-z0-9_.-]*"},
+			},
+		},
+		{
+			name:  `UppercaseName`,
+			input: `{"name": "Acme/BlogEngine", "description": "x", "license": "MIT"}`,
+			publishErrors: []ValidationIssue{
+				{"/name", `Name "Acme/BlogEngine" does not match the best practice (e.g. lower-cased/with-dashes). We suggest using "acme/blog-engine" instead. As such you will not be able to submit it to Packagist.`},
+			},
+			warnings: []ValidationIssue{
+				{"/name", "Your package name Acme/BlogEngine is invalid, it should not contain uppercase characters. We suggest using acme/blog-engine instead."},
+			},
+		},
+		{
+			name:  `ReservedName`,
+			input: `{"name": "acme/con", "description": "x", "license": "MIT"}`,
+			warnings: []ValidationIssue{
+				{"/name", "Your package name acme/con is reserved, package and vendor names can not match any of: nul, con, prn, aux, com1, com2, com3, com4, com5, com6, com7, com8, com9, lpt1, lpt2, lpt3, lpt4, lpt5, lpt6, lpt7, lpt8, lpt9."},
+			},
+		},
+		{
+			name:  `Type`,
+			input: `{"name": "acme/blog", "description": "x", "license": "MIT", "type": "Composer_Plugin"}`,
+			errors: []ValidationIssue{
+				{"/type", "invalid value (Composer_Plugin), must match [a-z0-9-]+"},
+			},
+		},
+		{
+			name:  `Licenses`,
+			input: `{"name": "acme/blog", "description": "x", "license": ["GPL-2.0+", "LGPL-3.0", "Foo", "eCos-2.0", "(MIT or proprietary)"]}`,
+			warnings: []ValidationIssue{
+				{"/license/0", `License "GPL-2.0+" is a deprecated SPDX license identifier, use "GPL-2.0-or-later" instead`},
+				{"/license/1", `License "LGPL-3.0" is a deprecated SPDX license identifier, use "LGPL-3.0-only" or "LGPL-3.0-or-later" instead`},
+				{"/license/2", "License \"Foo\" is not a valid SPDX license identifier, see https://spdx.org/licenses/ if you use an open license.\nIf the software is closed-source, you may use \"proprietary\" as license."},
+				{"/license/3", `License "eCos-2.0" is a deprecated SPDX license identifier, see https://spdx.org/licenses/`},
+			},
+		},
+		{
+			name:  `NoLicense`,
+			input: `{"name": "acme/blog", "description": "x"}`,
+			warnings: []ValidationIssue{
+				{"/license", `No license specified, it is recommended to do so. For closed-source software you may use "proprietary" as license.`},
+			},
+		},
+		{
+			name:  `Version`,
+			input: `{"name": "acme/blog", "description": "x", "license": "MIT", "version": "1.0.0-foo"}`,
+			errors: []ValidationIssue{
+				{"/version", `invalid value (1.0.0-foo): invalid version string "1.0.0-foo"`},
+			},
+			warnings: []ValidationIssue{
+				{"/version", "The version field is present, it is recommended to leave it out if the package is published on Packagist."},
+			},
+		},
+		{
+			name:  `MinimumStability`,
+			input: `{"name": "acme/blog", "description": "x", "license": "MIT", "minimum-stability": "nightly"}`,
+			errors: []ValidationIssue{
+				{"/minimum-stability", "invalid value (nightly), must be one of stable, RC, beta, alpha, dev"},
+			},
+		},
+		{
+			name: `Links`,
+			input: `{
+				"name": "acme/blog",
+				"description": "x",
+				"license": "MIT",
+				"require": {
+					"php": ">=8.1",
+					"acme/exact": "1.2.3",
+					"acme/unbound": ">=1.0",
+					"acme/any": "*",
+					"acme/invalid": "^foo",
+					"acme/ref": "dev-main#abc123",
+					"acme/self": "self.version",
+					"Acme/Upper": "^1.0",
+					"acme/both": "^1.0"
+				},
+				"require-dev": {"acme/both": "^1.0"},
+				"replace": {"acme/any": "*"}
+			}`,
+			errors: []ValidationIssue{
+				{"/require/acme~1invalid", `invalid version constraint (could not parse version constraint ^foo: invalid version string "^foo")`},
+			},
+			warnings: []ValidationIssue{
+				{"/require/Acme~1Upper", "Acme/Upper is invalid, it should not contain uppercase characters. Please use acme/upper instead."},
+				{"/require/acme~1any", "unbound version constraints (*) should be avoided"},
+				{"/require/acme~1exact", "exact version constraints (1.2.3) should be avoided if the package follows semantic versioning"},
+				{"/require/acme~1unbound", "unbound version constraints (>=1.0) should be avoided"},
+				{"/require-dev/acme~1both", "acme/both is required both in require and require-dev, this can lead to unexpected behavior"},
+				{"/replace/acme~1any", "The package acme/any in require is also listed in replace which satisfies the requirement. Remove it from replace if you wish to install it."},
+				{"/require/acme~1ref", `The package "acme/ref" is pointing to a commit-ref, this is bad practice and can cause unforeseen issues.`},
+			},
+		},
+		{
+			name: `Autoload`,
+			input: `{
+				"name": "acme/blog",
+				"description": "x",
+				"license": "MIT",
+				"autoload": {
+					"psr-4": {"Acme\\Blog": "src/", "": "lib/"},
+					"psr-0": {"": "legacy/"},
+					"psr4": {"Acme\\": "src/"}
+				},
+				"autoload-dev": {
+					"psr-4": {"Acme\\Tests": "tests/"},
+					"exclude-from-classmap": ["tests/fixtures/"]
+				}
+			}`,
+			errors: []ValidationIssue{
+				{"/autoload/psr4", "invalid value (psr4), must be one of psr-0, psr-4, classmap, files, exclude-from-classmap"},
+				{"/autoload/psr-4/Acme\\Blog", `invalid value (Acme\Blog), namespaces must end with a namespace separator, should be Acme\Blog\\`},
+				{"/autoload-dev/psr-4/Acme\\Tests", `invalid value (Acme\Tests), namespaces must end with a namespace separator, should be Acme\Tests\\`},
+			},
+			warnings: []ValidationIssue{
+				{"/autoload/psr-4/", "Defining autoload.psr-4 with an empty namespace prefix is a bad idea for performance"},
+				{"/autoload/psr-0/", "Defining autoload.psr-0 with an empty namespace prefix is a bad idea for performance"},
+			},
+		},
+		{
+			name: `Deprecated`,
+			input: `{
+				"name": "acme/blog",
+				"description": "x",
+				"license": "MIT",
+				"type": "composer-installer",
+				"target-dir": "Acme/Blog",
+				"include-path": ["lib/"]
+			}`,
+			warnings: []ValidationIssue{
+				{"/type", "The package type 'composer-installer' is deprecated. Please distribute your custom installers as plugins from now on. See https://getcomposer.org/doc/articles/plugins.md for plugin documentation."},
+				{"/target-dir", "The target-dir option is deprecated, use autoload.psr-4 instead."},
+				{"/include-path", "The include-path option is deprecated, use autoload instead."},
+			},
+		},
+		{
+			name: `URLsAndEmails`,
+			input: `{
+				"name": "acme/blog",
+				"description": "x",
+				"license": "MIT",
+				"homepage": "acme.test",
+				"authors": [{"name": "Jane", "email": "jane", "homepage": "ftp://acme.test"}],
+				"support": {"email": "support@acme.test", "irc": "https://acme.test", "issues": "https://acme.test/issues"}
+			}`,
+			warnings: []ValidationIssue{
+				{"/homepage", "invalid value (acme.test), must be an http/https URL"},
+				{"/authors/0/email", "invalid value (jane), must be a valid email address"},
+				{"/authors/0/homepage", "invalid value (ftp://acme.test), must be an http/https URL"},
+				{"/support/irc", "invalid value (https://acme.test), must be a irc://<server>/<channel> or ircs:// URL"},
+			},
+		},
+		{
+			name: `Scripts`,
+			input: `{
+				"name": "acme/blog",
+				"description": "x",
+				"license": "MIT",
+				"scripts": {"test": "phpunit"},
+				"scripts-descriptions": {"test": "Run tests", "lint": "Run linters"},
+				"scripts-aliases": {"build": ["b"]}
+			}`,
+			warnings: []ValidationIssue{
+				{"/scripts-descriptions/lint", `Description for non-existent script "lint" found in "scripts-descriptions"`},
+				{"/scripts-aliases/build", `Aliases for non-existent script "build" found in "scripts-aliases"`},
+			},
+		},
+		{
+			name:  `DuplicateKeys`,
+			input: `{"name": "acme/blog", "description": "x", "license": "MIT", "require": {"php": "^8.1", "php": "^8.2"}, "name": "acme/blog"}`,
+			errors: []ValidationIssue{
+				{"/require/php", "Duplicate key"},
+				{"/name", "Duplicate key"},
+			},
+		},
+		{
+			name:  `WrongType`,
+			input: `{"name": "acme/blog", "description": "x", "license": "MIT", "require": {"php": 8}}`,
+			errors: []ValidationIssue{
+				{"/require/php", "invalid value, must be of type string"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			result, err := ValidateBytes([]byte(test.input))
+			is.NoErr(err)
+			is.Equal(result.Errors, test.errors)
+			is.Equal(result.PublishErrors, test.publishErrors)
+			is.Equal(result.Warnings, test.warnings)
+			is.Equal(result.IsValid(), len(test.errors) == 0)
+		})
+	}
+}
+
+func TestValidateBytes_InvalidJSON(t *testing.T) {
+	is := is2.New(t)
+
+	_, err := ValidateBytes([]byte(`{"name": }`))
+	is.True(err != nil)
+}
+
+func TestValidate(t *testing.T) {
+	is := is2.New(t)
+
+	result := Validate(ComposerJSON{
+		Name:        "acme/blog",
+		Description: "The Acme blog",
+		License:     StringOrSlice{"MIT"},
+	})
+	is.True(result.IsValid())
+	is.True(result.IsPublishable())
+	is.Equal(len(result.Warnings), 0)
+
+	result = Validate(ComposerJSON{License: StringOrSlice{"MIT"}})
+	is.True(result.IsValid())
+	is.True(!result.IsPublishable())
+	is.Equal(result.PublishErrors[0].String(), "/name : The property name is required")
+}
+
+func Test_jsonPointer(t *testing.T) {
+	is := is2.New(t)
+
+	is.Equal(jsonPointer("require", "psr/log"), "/require/psr~1log")
+	is.Equal(jsonPointer("extra", "a~b"), "/extra/a~0b")
+	is.Equal(jsonPointer(), "")
+}
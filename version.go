@@ -0,0 +1,478 @@
+package gocomposer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	StabilityStable = "stable"
+	StabilityRC     = "RC"
+	StabilityBeta   = "beta"
+	StabilityAlpha  = "alpha"
+	StabilityDev    = "dev"
+)
+
+// DefaultBranchAlias is the version Composer uses for the default branch of a VCS
+// repository (e.g. dev-main) when it has no numeric branch alias. Branches named
+// master, trunk or default are also treated as this version when sorting.
+const DefaultBranchAlias = "9999999-dev"
+
+// modifierRegex matches the stability modifier of a version, e.g. "-beta2" or
+// ".RC1-dev". The groups are the stability, its number and the dev suffix.
+const modifierRegex = `[._-]?(?:(stable|beta|b|RC|alpha|a|patch|pl|p)((?:[.-]?\d+)*)?)?([.-]?dev)?`
+
+// stabilitiesRegex matches the stability flags that can be appended with "@".
+const stabilitiesRegex = `stable|RC|beta|alpha|dev`
+
+var (
+	versionAliasRegex      = regexp.MustCompile(`^([^,\s]+) +as +([^,\s]+)$`)
+	versionStabilityRegex  = regexp.MustCompile(`(?i)@(?:` + stabilitiesRegex + `)$`)
+	versionBuildRegex      = regexp.MustCompile(`^([^,\s+]+)\+[^\s]+$`)
+	versionClassicalRegex  = regexp.MustCompile(`(?i)^v?(\d{1,5})(\.\d+)?(\.\d+)?(\.\d+)?` + modifierRegex + `$`)
+	versionDateRegex       = regexp.MustCompile(`(?i)^v?(\d{4}(?:[.:-]?\d{2}){1,6}(?:[.:-]?\d{1,3}){0,2})` + modifierRegex + `$`)
+	versionDevRegex        = regexp.MustCompile(`(?i)^(.*?)[.-]?dev$`)
+	versionNonDigitRegex   = regexp.MustCompile(`\D`)
+	versionReferenceRegex  = regexp.MustCompile(`#.+$`)
+	versionStabilityModRe  = regexp.MustCompile(`(?i)` + modifierRegex + `(?:\+.*)?$`)
+	branchNumericRegex     = regexp.MustCompile(`(?i)^v?(\d+)(\.(?:\d+|[xX*]))?(\.(?:\d+|[xX*]))?(\.(?:\d+|[xX*]))?$`)
+	branchAliasPrefixRegex = regexp.MustCompile(`(?i)^((?:\d+\.)*\d+)(?:\.x)?-dev$`)
+)
+
+// Version is a package version as Composer understands it.
+type Version struct {
+	// The version as written, e.g. "v1.2-beta2" or "dev-main".
+	Pretty string
+
+	// The normalized version, e.g. "1.2.0.0-beta2" or "dev-main".
+	Normalized string
+}
+
+// ParseVersion parses and normalizes a version string. Inline aliases and stability
+// flags are stripped, so "dev-main as 1.0.x-dev" is parsed as "dev-main".
+func ParseVersion(version string) (Version, error) {
+	normalized, err := NormalizeVersion(version)
+	if err != nil {
+		return Version{}, err
+	}
+	pretty := strings.TrimSpace(version)
+	if m := versionAliasRegex.FindStringSubmatch(pretty); m != nil {
+		pretty = m[1]
+	}
+	return Version{Pretty: pretty, Normalized: normalized}, nil
+}
+
+// MustParseVersion is like ParseVersion but panics if the version cannot be parsed.
+func MustParseVersion(version string) Version {
+	v, err := ParseVersion(version)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func (v Version) String() string {
+	return v.Pretty
+}
+
+// Stability returns the stability of the version, one of the Stability constants.
+func (v Version) Stability() string {
+	return ParseStability(v.Normalized)
+}
+
+// IsDev returns true if the version has dev stability. This includes both named
+// branches like dev-main and numeric branches like 2.x-dev.
+func (v Version) IsDev() bool {
+	return v.Stability() == StabilityDev
+}
+
+// IsBranch returns true if the version is a named branch such as dev-main, which can
+// only be matched by name and not by numeric comparison.
+func (v Version) IsBranch() bool {
+	return strings.HasPrefix(v.Normalized, "dev-")
+}
+
+// Satisfies returns true if the version matches the constraint.
+func (v Version) Satisfies(c Constraint) bool {
+	return c.Intersects(&SingleConstraint{Operator: OpEqual, Version: v.Normalized})
+}
+
+// Compare returns -1, 0 or 1 depending on whether v sorts before, the same as or after
+// other. Versions are ordered the way Composer sorts them: the master, trunk and
+// default branches sort as DefaultBranchAlias, other named branches sort before all
+// numeric versions, and named branches are ordered by name among themselves.
+func (v Version) Compare(other Version) int {
+	a := normalizeDefaultBranch(v.Normalized)
+	b := normalizeDefaultBranch(other.Normalized)
+	if a == b {
+		return 0
+	}
+	if strings.HasPrefix(a, "dev-") && strings.HasPrefix(b, "dev-") {
+		return strings.Compare(a, b)
+	}
+	if versionCompare(a, b, OpLess, true) {
+		return -1
+	}
+	if versionCompare(a, b, OpEqual, true) {
+		return 0
+	}
+	return 1
+}
+
+// Less returns true if v sorts before other.
+func (v Version) Less(other Version) bool {
+	return v.Compare(other) < 0
+}
+
+// CompareVersions parses two version strings and compares them with Version.Compare.
+func CompareVersions(a, b string) (int, error) {
+	va, err := ParseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := ParseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	return va.Compare(vb), nil
+}
+
+// SortVersions sorts versions in ascending order.
+func SortVersions(versions []Version) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].Less(versions[j])
+	})
+}
+
+// normalizeDefaultBranch maps the branches Composer 1 treated as the default branch to
+// DefaultBranchAlias.
+func normalizeDefaultBranch(version string) string {
+	switch version {
+	case "dev-master", "dev-default", "dev-trunk":
+		return DefaultBranchAlias
+	}
+	return version
+}
+
+// NormalizeVersion converts a version string into the normalized form Composer uses
+// internally, e.g. "v1.2-beta2" becomes "1.2.0.0-beta2" and "2.x-dev" becomes
+// "2.9999999.9999999.9999999-dev". This is a port of Composer's
+// VersionParser::normalize.
+func NormalizeVersion(version string) (string, error) {
+	version = strings.TrimSpace(version)
+	original := version
+
+	// Strip off aliasing.
+	if m := versionAliasRegex.FindStringSubmatch(version); m != nil {
+		version = m[1]
+	}
+
+	// Strip off the stability flag.
+	if m := versionStabilityRegex.FindString(version); m != "" {
+		version = version[:len(version)-len(m)]
+	}
+
+	// Normalize master/trunk/default branches to dev-name for backwards compatibility
+	// with Composer 1 where these used to be valid constraints.
+	if version == "master" || version == "trunk" || version == "default" {
+		version = "dev-" + version
+	}
+
+	// If the requirement is branch-like, use the full name.
+	if len(version) >= 4 && strings.EqualFold(version[:4], "dev-") {
+		return "dev-" + version[4:], nil
+	}
+
+	// Strip off build metadata.
+	if m := versionBuildRegex.FindStringSubmatch(version); m != nil {
+		version = m[1]
+	}
+
+	var matches []string
+	index := 0
+	if m := versionClassicalRegex.FindStringSubmatch(version); m != nil {
+		matches = m
+		version = m[1]
+		for _, part := range m[2:5] {
+			if part == "" {
+				part = ".0"
+			}
+			version += part
+		}
+		index = 5
+	} else if m := versionDateRegex.FindStringSubmatch(version); m != nil {
+		matches = m
+		version = versionNonDigitRegex.ReplaceAllString(m[1], ".")
+		index = 2
+	}
+
+	// Add the version modifiers if a version was matched.
+	if matches != nil {
+		if matches[index] != "" {
+			if matches[index] == "stable" {
+				return version, nil
+			}
+			version += "-" + expandStability(matches[index]) + strings.TrimLeft(matches[index+1], ".-")
+		}
+		if matches[index+2] != "" {
+			version += "-dev"
+		}
+		return version, nil
+	}
+
+	// Match dev branches.
+	if m := versionDevRegex.FindStringSubmatch(version); m != nil {
+		normalized := NormalizeBranch(m[1])
+		// A branch ending with -dev is only valid if it is numeric.
+		if !strings.Contains(normalized, "dev-") {
+			return normalized, nil
+		}
+	}
+
+	return "", fmt.Errorf(`invalid version string "%s"`, original)
+}
+
+// NormalizeBranch converts a branch name into its normalized version, e.g. "1.x"
+// becomes "1.9999999.9999999.9999999-dev" and "feature" becomes "dev-feature".
+func NormalizeBranch(name string) string {
+	name = strings.TrimSpace(name)
+
+	if m := branchNumericRegex.FindStringSubmatch(name); m != nil {
+		version := m[1]
+		for _, part := range m[2:5] {
+			if part == "" {
+				part = ".x"
+			}
+			version += strings.NewReplacer("*", "x", "X", "x").Replace(part)
+		}
+		return strings.ReplaceAll(version, "x", "9999999") + "-dev"
+	}
+
+	return "dev-" + name
+}
+
+// ParseNumericAliasPrefix returns the numeric prefix of a branch alias such as
+// "2.1.x-dev", with a trailing dot, or an empty string if there is none.
+func ParseNumericAliasPrefix(branch string) string {
+	if m := branchAliasPrefixRegex.FindStringSubmatch(branch); m != nil {
+		return m[1] + "."
+	}
+	return ""
+}
+
+// ParseStability returns the stability of a version, one of the Stability
+// constants.
+func ParseStability(version string) string {
+	version = versionReferenceRegex.ReplaceAllString(version, "")
+
+	if strings.HasPrefix(version, "dev-") || strings.HasSuffix(version, "-dev") {
+		return StabilityDev
+	}
+
+	m := versionStabilityModRe.FindStringSubmatch(strings.ToLower(version))
+	if m == nil {
+		return StabilityStable
+	}
+	if m[3] != "" {
+		return StabilityDev
+	}
+	switch m[1] {
+	case "beta", "b":
+		return StabilityBeta
+	case "alpha", "a":
+		return StabilityAlpha
+	case "rc":
+		return StabilityRC
+	}
+	return StabilityStable
+}
+
+// NormalizeStability lower cases a stability name, except for RC.
+func NormalizeStability(stability string) string {
+	stability = strings.ToLower(stability)
+	if stability == "rc" {
+		return StabilityRC
+	}
+	return stability
+}
+
+// expandStability converts the short stability names to their long form.
+func expandStability(stability string) string {
+	stability = strings.ToLower(stability)
+	switch stability {
+	case "a":
+		return StabilityAlpha
+	case "b":
+		return StabilityBeta
+	case "p", "pl":
+		return "patch"
+	case "rc":
+		return StabilityRC
+	}
+	return stability
+}
+
+// phpVersionCompare is a port of PHP's version_compare function without an operator,
+// returning -1, 0 or 1. Composer compares normalized versions with it.
+func phpVersionCompare(v1, v2 string) int {
+	if v1 == "" || v2 == "" {
+		if v1 == "" && v2 == "" {
+			return 0
+		}
+		if v1 != "" {
+			return 1
+		}
+		return -1
+	}
+	if v1[0] != '#' {
+		v1 = canonicalizePHPVersion(v1)
+	}
+	if v2[0] != '#' {
+		v2 = canonicalizePHPVersion(v2)
+	}
+
+	p1, p2 := v1, v2
+	n1, n2 := true, true
+	compare := 0
+	for p1 != "" && p2 != "" && n1 && n2 {
+		var e1, e2 string
+		e1, p1, n1 = cutVersionPart(p1)
+		e2, p2, n2 = cutVersionPart(p2)
+
+		d1, d2 := startsWithDigit(e1), startsWithDigit(e2)
+		switch {
+		case d1 && d2:
+			compare = sign(phpStrtol(e1) - phpStrtol(e2))
+		case !d1 && !d2:
+			compare = compareSpecialVersionForms(e1, e2)
+		case d1:
+			compare = compareSpecialVersionForms("#N#", e2)
+		default:
+			compare = compareSpecialVersionForms(e1, "#N#")
+		}
+		if compare != 0 {
+			break
+		}
+		if !n1 {
+			p1 = e1
+		}
+		if !n2 {
+			p2 = e2
+		}
+	}
+	if compare == 0 {
+		if n1 {
+			if startsWithDigit(p1) {
+				compare = 1
+			} else {
+				compare = phpVersionCompare(p1, "#N#")
+			}
+		} else if n2 {
+			if startsWithDigit(p2) {
+				compare = -1
+			} else {
+				compare = phpVersionCompare("#N#", p2)
+			}
+		}
+	}
+	return compare
+}
+
+// cutVersionPart splits off the first dot separated element of a canonical version.
+// It returns the element, the remainder and whether a dot was found.
+func cutVersionPart(v string) (string, string, bool) {
+	i := strings.IndexByte(v, '.')
+	if i < 0 {
+		return v, "", false
+	}
+	return v[:i], v[i+1:], true
+}
+
+// canonicalizePHPVersion inserts dots between digits and non-digits and replaces
+// special characters with dots, the same way PHP does before comparing versions.
+func canonicalizePHPVersion(version string) string {
+	buf := []byte{version[0]}
+	lp := version[0]
+	for i := 1; i < len(version); i++ {
+		c := version[i]
+		isNDig := func(x byte) bool { return !isDigit(x) && x != '.' }
+		switch {
+		case c == '-' || c == '_' || c == '+':
+			if buf[len(buf)-1] != '.' {
+				buf = append(buf, '.')
+			}
+		case (isNDig(lp) && isDigit(c)) || (isDigit(lp) && isNDig(c)):
+			if buf[len(buf)-1] != '.' {
+				buf = append(buf, '.')
+			}
+			buf = append(buf, c)
+		case !isAlnum(c):
+			if buf[len(buf)-1] != '.' {
+				buf = append(buf, '.')
+			}
+		default:
+			buf = append(buf, c)
+		}
+		lp = c
+	}
+	return string(buf)
+}
+
+// compareSpecialVersionForms compares the non-numeric parts of a version using the
+// order PHP gives them: any string < dev < alpha = a < beta = b < RC = rc < # < pl = p.
+func compareSpecialVersionForms(form1, form2 string) int {
+	order := func(form string) int {
+		forms := []struct {
+			name  string
+			order int
+		}{
+			{"dev", 0}, {"alpha", 1}, {"a", 1}, {"beta", 2}, {"b", 2},
+			{"RC", 3}, {"rc", 3}, {"#", 4}, {"pl", 5}, {"p", 5},
+		}
+		for _, f := range forms {
+			if strings.HasPrefix(form, f.name) {
+				return f.order
+			}
+		}
+		return -1
+	}
+	return sign(order(form1) - order(form2))
+}
+
+// phpStrtol parses the leading digits of s like C's strtol.
+func phpStrtol(s string) int {
+	end := 0
+	for end < len(s) && isDigit(s[end]) {
+		end++
+	}
+	i, err := strconv.Atoi(s[:end])
+	if err != nil {
+		return int(^uint(0) >> 1)
+	}
+	return i
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func startsWithDigit(s string) bool {
+	return s != "" && isDigit(s[0])
+}
+
+func isAlnum(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func sign(i int) int {
+	switch {
+	case i < 0:
+		return -1
+	case i > 0:
+		return 1
+	}
+	return 0
+}
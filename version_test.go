@@ -0,0 +1,379 @@
+package gocomposer
+
+import (
+	is2 "github.com/matryer/is"
+	"testing"
+)
+
+func TestNormalizeVersion(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		output string
+	}{
+		{name: `None`, input: `1.0.0`, output: `1.0.0.0`},
+		{name: `None2`, input: `1.2.3.4`, output: `1.2.3.4`},
+		{name: `ParsesState`, input: `1.0.0RC1dev`, output: `1.0.0.0-RC1-dev`},
+		{name: `CaseInsensitive`, input: `1.0.0-rC15-dev`, output: `1.0.0.0-RC15-dev`},
+		{name: `Delimiters`, input: `1.0.0.RC.15-dev`, output: `1.0.0.0-RC15-dev`},
+		{name: `RCUppercase`, input: `1.0.0-rc1`, output: `1.0.0.0-RC1`},
+		{name: `PatchReplace`, input: `1.0.0.pl3-dev`, output: `1.0.0.0-patch3-dev`},
+		{name: `ForcesFourParts`, input: `1.0-dev`, output: `1.0.0.0-dev`},
+		{name: `ForcesFourParts2`, input: `0`, output: `0.0.0.0`},
+		{name: `ParsesLong`, input: `10.4.13-beta`, output: `10.4.13.0-beta`},
+		{name: `ParsesLong2`, input: `10.4.13beta2`, output: `10.4.13.0-beta2`},
+		{name: `ParsesLongSemver`, input: `10.4.13beta.2`, output: `10.4.13.0-beta2`},
+		{name: `ParsesLongSemver2`, input: `v1.13.11-beta.0`, output: `1.13.11.0-beta0`},
+		{name: `ParsesLongSemver3`, input: `1.13.11.0-beta0`, output: `1.13.11.0-beta0`},
+		{name: `ExpandShorthand`, input: `10.4.13-b`, output: `10.4.13.0-beta`},
+		{name: `ExpandShorthand2`, input: `10.4.13-b5`, output: `10.4.13.0-beta5`},
+		{name: `StripsLeadingV`, input: `v1.0.0`, output: `1.0.0.0`},
+		{name: `DatesAsClassical`, input: `2010.01`, output: `2010.01.0.0`},
+		{name: `DatesAsClassical2`, input: `2010.01.02`, output: `2010.01.02.0`},
+		{name: `DatesAsClassical3`, input: `2010.1.555`, output: `2010.1.555.0`},
+		{name: `DatesAsClassical4`, input: `2010.10.200`, output: `2010.10.200.0`},
+		{name: `StripsVDatetime`, input: `v20100102`, output: `20100102`},
+		{name: `DatesWithDash`, input: `2010-01-02`, output: `2010.01.02`},
+		{name: `DatesWithNumber`, input: `2010-01-02.5`, output: `2010.01.02.5`},
+		{name: `Datetime`, input: `20100102-203040`, output: `20100102.203040`},
+		{name: `DateDev`, input: `20100102.x-dev`, output: `20100102.9999999.9999999.9999999-dev`},
+		{name: `Datetime2`, input: `20100102203040-10`, output: `20100102203040.10`},
+		{name: `DatetimePatch`, input: `20100102-203040-p1`, output: `20100102.203040-patch1`},
+		{name: `Master`, input: `dev-master`, output: `dev-master`},
+		{name: `MasterWithoutDev`, input: `master`, output: `dev-master`},
+		{name: `Trunk`, input: `dev-trunk`, output: `dev-trunk`},
+		{name: `Branches`, input: `1.x-dev`, output: `1.9999999.9999999.9999999-dev`},
+		{name: `Arbitrary`, input: `dev-feature-foo`, output: `dev-feature-foo`},
+		{name: `Arbitrary2`, input: `DEV-FOOBAR`, output: `dev-FOOBAR`},
+		{name: `Arbitrary3`, input: `dev-feature/foo`, output: `dev-feature/foo`},
+		{name: `Arbitrary4`, input: `dev-feature+issue-1`, output: `dev-feature+issue-1`},
+		{name: `IgnoresAliases`, input: `dev-master as 1.0.0`, output: `dev-master`},
+		{name: `IgnoresAliases2`, input: `dev-load-varnish-only-when-used as ^2.0`, output: `dev-load-varnish-only-when-used`},
+		{name: `IgnoresAliases3`, input: `dev-load-varnish-only-when-used@dev as ^2.0@dev`, output: `dev-load-varnish-only-when-used`},
+		{name: `IgnoresStability`, input: `1.0.0+foo@dev`, output: `1.0.0.0`},
+		{name: `IgnoresStability2`, input: `dev-load-varnish-only-when-used@stable`, output: `dev-load-varnish-only-when-used`},
+		{name: `SemverMetadata`, input: `1.0.0-beta.5+foo`, output: `1.0.0.0-beta5`},
+		{name: `SemverMetadata2`, input: `1.0.0+foo`, output: `1.0.0.0`},
+		{name: `SemverMetadata3`, input: `1.0.0-alpha.3.1+foo`, output: `1.0.0.0-alpha3.1`},
+		{name: `SemverMetadata4`, input: `1.0.0-alpha2.1+foo`, output: `1.0.0.0-alpha2.1`},
+		{name: `SemverMetadata5`, input: `1.0.0-alpha-2.1-3+foo`, output: `1.0.0.0-alpha2.1-3`},
+		{name: `MetadataWithAlias`, input: `1.0.0+foo as 2.0`, output: `1.0.0.0`},
+		{name: `ZeroPadding`, input: `00.01.03.04`, output: `00.01.03.04`},
+		{name: `ZeroPadding2`, input: `000.001.003.004`, output: `000.001.003.004`},
+		{name: `ZeroPadding3`, input: `0.000.103.204`, output: `0.000.103.204`},
+		{name: `ZeroPadding4`, input: `0700`, output: `0700.0.0.0`},
+		{name: `ZeroPadding5`, input: `041.x-dev`, output: `041.9999999.9999999.9999999-dev`},
+		{name: `ZeroPadding6`, input: `dev-041.003`, output: `dev-041.003`},
+		{name: `DevWithMadName`, input: `dev-1.0.0-dev<1.0.5-dev`, output: `dev-1.0.0-dev<1.0.5-dev`},
+		{name: `DevWithSpaces`, input: `dev-foo bar`, output: `dev-foo bar`},
+		{name: `SpacePadding`, input: ` 1.0.0`, output: `1.0.0.0`},
+		{name: `SpacePadding2`, input: `1.0.0 `, output: `1.0.0.0`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			result, err := NormalizeVersion(test.input)
+
+			is.NoErr(err)
+			is.Equal(result, test.output)
+		})
+	}
+}
+
+func TestNormalizeVersion_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: `Empty`, input: ``},
+		{name: `InvalidChars`, input: `a`},
+		{name: `InvalidType`, input: `1.0.0-meh`},
+		{name: `TooManyParts`, input: `1.0.0.0.0`},
+		{name: `BranchWithoutPrefix`, input: `feature-foo`},
+		{name: `MetadataWithSpace`, input: `1.0.0+foo bar`},
+		{name: `Snapshot`, input: `1.0.0-SNAPSHOT`},
+		{name: `MatchAll`, input: `*`},
+		{name: `Constraint`, input: `^1`},
+		{name: `DanglingAlias`, input: `1.0.0 as`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			_, err := NormalizeVersion(test.input)
+
+			is.True(err != nil)
+		})
+	}
+}
+
+func TestNormalizeBranch(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		output string
+	}{
+		{name: `X`, input: `v1.x`, output: `1.9999999.9999999.9999999-dev`},
+		{name: `Star`, input: `v1.*`, output: `1.9999999.9999999.9999999-dev`},
+		{name: `Digits`, input: `v1.0`, output: `1.0.9999999.9999999-dev`},
+		{name: `Digits2`, input: `2.0`, output: `2.0.9999999.9999999-dev`},
+		{name: `LongX`, input: `v1.0.x`, output: `1.0.9999999.9999999-dev`},
+		{name: `LongStar`, input: `v1.0.3.*`, output: `1.0.3.9999999-dev`},
+		{name: `LongDigits`, input: `v2.4.0`, output: `2.4.0.9999999-dev`},
+		{name: `LongDigits2`, input: `2.4.4`, output: `2.4.4.9999999-dev`},
+		{name: `Master`, input: `master`, output: `dev-master`},
+		{name: `Trunk`, input: `trunk`, output: `dev-trunk`},
+		{name: `Arbitrary`, input: `feature-a`, output: `dev-feature-a`},
+		{name: `Arbitrary2`, input: `FOOBAR`, output: `dev-FOOBAR`},
+		{name: `Arbitrary3`, input: `feature+issue-1`, output: `dev-feature+issue-1`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			is.Equal(NormalizeBranch(test.input), test.output)
+		})
+	}
+}
+
+func TestParseStability(t *testing.T) {
+	tests := []struct {
+		input     string
+		stability string
+	}{
+		{input: `1`, stability: StabilityStable},
+		{input: `1.0`, stability: StabilityStable},
+		{input: `3.2.1`, stability: StabilityStable},
+		{input: `v3.2.1`, stability: StabilityStable},
+		{input: `v2.0.x-dev`, stability: StabilityDev},
+		{input: `v2.0.x-dev#abc123`, stability: StabilityDev},
+		{input: `v2.0.x-dev#trunk/@123`, stability: StabilityDev},
+		{input: `3.0-RC2`, stability: StabilityRC},
+		{input: `dev-master`, stability: StabilityDev},
+		{input: `3.1.2-dev`, stability: StabilityDev},
+		{input: `dev-feature+issue-1`, stability: StabilityDev},
+		{input: `3.1.2-p1`, stability: StabilityStable},
+		{input: `3.1.2-pl2`, stability: StabilityStable},
+		{input: `3.1.2-patch`, stability: StabilityStable},
+		{input: `3.1.2-alpha5`, stability: StabilityAlpha},
+		{input: `3.1.2-beta`, stability: StabilityBeta},
+		{input: `2.0B1`, stability: StabilityBeta},
+		{input: `1.2.0a1`, stability: StabilityAlpha},
+		{input: `1.2_a1`, stability: StabilityAlpha},
+		{input: `2.0.0rc1`, stability: StabilityRC},
+		{input: `1.0.0-alpha11+cs-1.1.0`, stability: StabilityAlpha},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			is := is2.New(t)
+
+			is.Equal(ParseStability(test.input), test.stability)
+		})
+	}
+}
+
+func Test_phpVersionCompare(t *testing.T) {
+	tests := []struct {
+		v1      string
+		v2      string
+		compare int
+	}{
+		{v1: `1.0.0.0`, v2: `1.0.0.0`, compare: 0},
+		{v1: `1.0.0.0`, v2: `1.0.0.1`, compare: -1},
+		{v1: `1.10.0.0`, v2: `1.9.0.0`, compare: 1},
+		{v1: `1.0.0.0-dev`, v2: `1.0.0.0`, compare: -1},
+		{v1: `1.0.0.0-alpha`, v2: `1.0.0.0-dev`, compare: 1},
+		{v1: `1.0.0.0-beta`, v2: `1.0.0.0-alpha2`, compare: 1},
+		{v1: `1.0.0.0-RC1`, v2: `1.0.0.0-beta3`, compare: 1},
+		{v1: `1.0.0.0-RC1`, v2: `1.0.0.0`, compare: -1},
+		{v1: `1.0.0.0-patch1`, v2: `1.0.0.0`, compare: 1},
+		{v1: `1.0.0.0-beta2`, v2: `1.0.0.0-beta10`, compare: -1},
+		{v1: `1.0.0.0-beta2-dev`, v2: `1.0.0.0-beta2`, compare: -1},
+		{v1: `1.9999999.9999999.9999999-dev`, v2: `2.0.0.0-dev`, compare: -1},
+		{v1: `1.0`, v2: `1.0.0`, compare: -1},
+		{v1: `1.0.0`, v2: `1.0`, compare: 1},
+		{v1: ``, v2: `1.0`, compare: -1},
+		{v1: ``, v2: ``, compare: 0},
+		{v1: `5.2`, v2: `5.2rc1`, compare: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.v1+"_"+test.v2, func(t *testing.T) {
+			is := is2.New(t)
+
+			is.Equal(phpVersionCompare(test.v1, test.v2), test.compare)
+		})
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		version Version
+	}{
+		{
+			name:    `Release`,
+			input:   `v1.2`,
+			version: Version{Pretty: `v1.2`, Normalized: `1.2.0.0`},
+		},
+		{
+			name:    `PreRelease`,
+			input:   `1.2.0-beta2`,
+			version: Version{Pretty: `1.2.0-beta2`, Normalized: `1.2.0.0-beta2`},
+		},
+		{
+			name:    `NamedBranch`,
+			input:   `dev-main`,
+			version: Version{Pretty: `dev-main`, Normalized: `dev-main`},
+		},
+		{
+			name:    `NumericBranch`,
+			input:   `2.x-dev`,
+			version: Version{Pretty: `2.x-dev`, Normalized: `2.9999999.9999999.9999999-dev`},
+		},
+		{
+			name:    `FourParts`,
+			input:   `1.0.0.0-RC1`,
+			version: Version{Pretty: `1.0.0.0-RC1`, Normalized: `1.0.0.0-RC1`},
+		},
+		{
+			name:    `InlineAlias`,
+			input:   `dev-main as 1.0.x-dev`,
+			version: Version{Pretty: `dev-main`, Normalized: `dev-main`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			result, err := ParseVersion(test.input)
+
+			is.NoErr(err)
+			is.Equal(result, test.version)
+		})
+	}
+}
+
+func TestVersion_Stability(t *testing.T) {
+	tests := []struct {
+		input     string
+		stability string
+		dev       bool
+		branch    bool
+	}{
+		{input: `1.2.0`, stability: StabilityStable},
+		{input: `1.2.0-p1`, stability: StabilityStable},
+		{input: `1.2.0-RC1`, stability: StabilityRC},
+		{input: `1.2.0-beta2`, stability: StabilityBeta},
+		{input: `1.2.0a1`, stability: StabilityAlpha},
+		{input: `2.x-dev`, stability: StabilityDev, dev: true},
+		{input: `dev-main`, stability: StabilityDev, dev: true, branch: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			is := is2.New(t)
+
+			v := MustParseVersion(test.input)
+
+			is.Equal(v.Stability(), test.stability)
+			is.Equal(v.IsDev(), test.dev)
+			is.Equal(v.IsBranch(), test.branch)
+		})
+	}
+}
+
+func TestVersion_Compare(t *testing.T) {
+	tests := []struct {
+		a       string
+		b       string
+		compare int
+	}{
+		{a: `1.0.0`, b: `1.0.0`, compare: 0},
+		{a: `v1.0`, b: `1.0.0.0`, compare: 0},
+		{a: `1.0.0`, b: `1.0.1`, compare: -1},
+		{a: `1.10.0`, b: `1.9.0`, compare: 1},
+		{a: `1.0.0-dev`, b: `1.0.0-alpha1`, compare: -1},
+		{a: `1.0.0-alpha1`, b: `1.0.0-beta1`, compare: -1},
+		{a: `1.0.0-beta1`, b: `1.0.0-RC1`, compare: -1},
+		{a: `1.0.0-RC1`, b: `1.0.0`, compare: -1},
+		{a: `1.0.0`, b: `1.0.0-p1`, compare: -1},
+		{a: `1.x-dev`, b: `1.99.0`, compare: 1},
+		{a: `1.x-dev`, b: `2.0.0-dev`, compare: -1},
+		{a: `dev-feature`, b: `0.0.1`, compare: -1},
+		{a: `dev-main`, b: `dev-feature`, compare: 1},
+		{a: `dev-master`, b: `99.0.0`, compare: 1},
+		{a: `dev-trunk`, b: `dev-master`, compare: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.a+"_"+test.b, func(t *testing.T) {
+			is := is2.New(t)
+
+			compare, err := CompareVersions(test.a, test.b)
+
+			is.NoErr(err)
+			is.Equal(compare, test.compare)
+
+			reverse, err := CompareVersions(test.b, test.a)
+
+			is.NoErr(err)
+			is.Equal(reverse, -test.compare)
+		})
+	}
+}
+
+func TestSortVersions(t *testing.T) {
+	is := is2.New(t)
+
+	input := []string{`dev-master`, `1.0.0`, `1.0.0-beta`, `2.x-dev`, `dev-feature`, `1.1.0-RC1`, `v0.9`, `1.0.0-p1`}
+	expected := []string{`dev-feature`, `v0.9`, `1.0.0-beta`, `1.0.0`, `1.0.0-p1`, `1.1.0-RC1`, `2.x-dev`, `dev-master`}
+
+	versions := make([]Version, 0, len(input))
+	for _, v := range input {
+		versions = append(versions, MustParseVersion(v))
+	}
+	SortVersions(versions)
+
+	result := make([]string, 0, len(versions))
+	for _, v := range versions {
+		result = append(result, v.String())
+	}
+	is.Equal(result, expected)
+}
+
+func TestVersion_Satisfies(t *testing.T) {
+	is := is2.New(t)
+
+	is.True(MustParseVersion(`2.3.1`).Satisfies(MustParseConstraint(`^2.0 || ~1.5.3`)))
+	is.True(!MustParseVersion(`1.6.0`).Satisfies(MustParseConstraint(`^2.0 || ~1.5.3`)))
+	is.True(MustParseVersion(`dev-main`).Satisfies(MustParseConstraint(`dev-main`)))
+}
+
+func TestParseNumericAliasPrefix(t *testing.T) {
+	tests := []struct {
+		input  string
+		output string
+	}{
+		{input: `2.x-dev`, output: `2.`},
+		{input: `2.1.x-dev`, output: `2.1.`},
+		{input: `2.1-dev`, output: `2.1.`},
+		{input: `dev-main`, output: ``},
+		{input: `2.1.0`, output: ``},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			is := is2.New(t)
+
+			is.Equal(ParseNumericAliasPrefix(test.input), test.output)
+		})
+	}
+}